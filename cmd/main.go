@@ -1,167 +1,371 @@
 package main
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/audit"
 	"E-Commerce_Website_Database/internal/config"
+	"E-Commerce_Website_Database/internal/database"
 	"E-Commerce_Website_Database/internal/handlers"
+	"E-Commerce_Website_Database/internal/health"
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/metrics"
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/migrations"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/server"
+	"E-Commerce_Website_Database/internal/service"
+	"E-Commerce_Website_Database/internal/storage"
 	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/tracing"
+	"context"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	_ "github.com/joho/godotenv/autoload"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 )
 
-// main initializes the application, sets up database connections, and starts the HTTP server.
-// It loads the configuration settings, sets up the database connection, and initializes the Gin router.
-// It then sets up the routes and starts the server on the specified port.
+// main loads the configuration and runs the subcommand given as first argument.
+// Without arguments, or with "serve", it starts the HTTP server; "migrate" manages the database schema
+// and "seed" fills the database with generated demo data.
+// The configuration is validated first, failing fast with every problem listed.
 func main() {
-	config.LoadConfig()
-	port := config.GetConfig("PORT")
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASSWORD")
-	dbHost := os.Getenv("DB_HOST")
-	dbName := os.Getenv("DB_NAME")
-	dbPort := os.Getenv("DB_PORT")
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", dbUser, dbPass, dbHost, dbPort, dbName)
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	cfg, err := config.Load(config.Options{})
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
-	r := gin.Default()
+	logger := middleware.NewLogger(os.Stdout, cfg.Log.Level)
+	slog.SetDefault(logger)
+	// slog.SetDefault routes the log package through the structured logger at info level,
+	// which would hide fatal startup errors when LOG_LEVEL is warn or error.
+	log.SetOutput(os.Stderr)
+	slog.Debug("configuration loaded", "environment", cfg.Environment, "config", cfg.String())
+
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+	switch command {
+	case "serve":
+		serve(cfg, logger)
+	case "migrate":
+		if err := runMigrate(cfg, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+	case "seed":
+		if err := runSeed(cfg, os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Seeding failed: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown command %q, expected serve, migrate or seed", command)
+	}
+}
+
+// serve connects to the database selected by DB_DRIVER, refuses to start while migrations are pending,
+// and initializes the Gin router.
+// It then sets up the routes and serves on the specified port until SIGINT or SIGTERM is received,
+// at which point in-flight requests are drained and the database pool is closed.
+// Every mutation is recorded in the audit log, and deleted records past the trash retention period
+// are purged in the background meanwhile.
+func serve(cfg config.Config, logger *slog.Logger) {
+	if err := tools.SetIDNode(uint(cfg.Server.NodeID)); err != nil {
+		log.Fatalf("Invalid node ID: %v", err)
+	}
+	if err := tools.SetSigningKey(cfg.Auth.JWTSecret); err != nil {
+		log.Fatalf("Invalid JWT secret: %v", err)
+	}
+	db, err := database.Open(cfg.Database, &gorm.Config{})
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to access database pool: %v", err)
+	}
+	if err := checkMigrations(context.Background(), db, cfg.Database.AutoMigrate); err != nil {
+		log.Fatalf("Refusing to serve: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions(cfg.Tracing))
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	if err := db.Use(&tracing.GormPlugin{}); err != nil {
+		log.Fatalf("Failed to install database tracing: %v", err)
+	}
+	if err := db.Use(&audit.Plugin{}); err != nil {
+		log.Fatalf("Failed to install the audit log: %v", err)
+	}
+	r := gin.New()
+	r.Use(gin.Recovery())
 
 	// Configuring CORS
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true                                                               // Allow all origins
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}  // Allow all methods
 	corsConfig.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"} // Allow all headers
-	corsConfig.AddExposeHeaders("Access-Control-Allow-Origin")                                      // Add this line
+	corsConfig.AddAllowHeaders(middleware.RequestIDHeader, "traceparent", "tracestate", "If-Match", "If-None-Match")
+	corsConfig.AddExposeHeaders("Access-Control-Allow-Origin") // Add this line
+	corsConfig.AddExposeHeaders(middleware.RequestIDHeader, "ETag")
 	// Allow headers
-	r.Use(LoggerMiddleware())
+	r.Use(middleware.RequestID())
+	r.Use(audit.Middleware())
+	r.Use(middleware.Logger(logger))
+	r.Use(tracing.Middleware())
 	r.Use(cors.New(corsConfig))
-	setupRoutes(r, db)
-	jwtService := &tools.JWTTokenService{}
-	r.POST("/login", func(context *gin.Context) { handlers.PostLogin(context, db, jwtService) })
-	r.GET("/protected", tools.TokenAuthMiddleware(), func(c *gin.Context) {
-		username := c.MustGet("username").(string)
-		user, err := handlers.GetUserByUN(username, db)
-		if err != nil {
-			return
-		}
-		c.JSON(http.StatusOK, gin.H{"username": username, "role": user.Role})
-	})
-	if err := r.Run(":" + port); err != nil {
+	// The metrics middleware wraps Errors so that it records the status of the problem responses.
+	r.Use(metrics.Middleware())
+	r.Use(middleware.Errors())
+	r.Use(middleware.QueryTimeout(cfg.Database.QueryTimeout))
+	if cfg.Server.RequireIfMatch {
+		r.Use(middleware.RequireIfMatch())
+	}
+	files, err := storage.NewLocal(cfg.Media.Dir)
+	if err != nil {
+		log.Fatalf("Failed to open media directory: %v", err)
+	}
+	services := service.New(repository.NewGORM(db), files, mediaLimits(cfg.Media))
+	h := handlers.New(services, &tools.JWTTokenService{})
+	setupRoutes(r, db, h, cfg)
+
+	srv := server.New(r, serverOptions(cfg.Server))
+	if cfg.Trash.Retention > 0 {
+		srv.Go(func(ctx context.Context) { purgeTrash(ctx, db, services.Media, cfg.Trash) })
+	}
+	srv.OnShutdown(func(context.Context) error { return sqlDB.Close() })
+	srv.OnShutdown(shutdownTracing)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if err := srv.Run(ctx); err != nil {
 		log.Fatalf("Failed to run server: %v", err)
 	}
 }
 
+// serverOptions converts the server settings into the options of the HTTP server.
+// TLS is enabled when both a certificate and a key file are configured.
+func serverOptions(cfg config.ServerConfig) server.Options {
+	return server.Options{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		ShutdownTimeout:   cfg.ShutdownTimeout,
+		HookTimeout:       cfg.HookTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		TLSCertFile:       cfg.TLSCertFile,
+		TLSKeyFile:        cfg.TLSKeyFile,
+	}
+}
+
+// mediaLimits converts the media settings into the limits of uploaded images.
+func mediaLimits(cfg config.MediaConfig) imaging.Limits {
+	return imaging.Limits{
+		MaxBytes:      cfg.MaxUploadSize,
+		MaxPixels:     cfg.MaxPixels,
+		ThumbnailSize: cfg.ThumbnailSize,
+	}
+}
+
+// tracingOptions converts the tracing settings into the options of the tracer provider.
+func tracingOptions(cfg config.TracingConfig) tracing.Options {
+	return tracing.Options{
+		Exporter:    cfg.Exporter,
+		Endpoint:    cfg.Endpoint,
+		Insecure:    cfg.Insecure,
+		FilePath:    cfg.File,
+		ServiceName: cfg.ServiceName,
+		SampleRatio: cfg.SampleRatio,
+	}
+}
+
 // setupRoutes defines all the routes and their handlers for the application.
-func setupRoutes(router *gin.Engine, db *gorm.DB) {
+// The handlers reach the database through services and repositories; db is only used by the metrics and health checks.
+// Deleted records go to the trash; restoring them is reserved to administrators.
+func setupRoutes(router *gin.Engine, db *gorm.DB, h *handlers.Handlers, cfg config.Config) {
+	metrics.Register(router, db, cfg.Metrics.LowStockThreshold)
+
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"message": "Welcome to ElectroMart API"})
 	})
+
+	// Liveness and readiness probes for the orchestrator.
+	checker := health.NewChecker(cfg.Health.ReadinessTimeout)
+	checker.AddCheck("database", health.DatabaseCheck(db))
+	if migrator, err := migrations.New(db); err == nil {
+		checker.AddCheck("migrations", migrator.Check)
+	}
+	checker.AddCheck("schema", health.SchemaCheck(db, &models.User{}, &models.Brands{}, &models.Category{},
+		&models.Product{}, &models.ProductVariant{}, &models.ProductImage{}, &models.Attribute{}, &models.ProductAttribute{},
+		&models.Order{}, &models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}, &models.Review{}, &audit.Entry{}))
+	checker.Register(router)
 	// Handle requests for non-existent routes.
 	router.HandleMethodNotAllowed = true
 
 	router.NoRoute(func(c *gin.Context) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "endpoint not found or method not allowed"})
+		c.Error(apperr.NotFound("endpoint not found or method not allowed"))
 	})
 
 	router.NoMethod(func(c *gin.Context) {
-		c.JSON(http.StatusMethodNotAllowed, gin.H{"error": "Method not allowed"})
+		c.Error(apperr.New(apperr.KindMethodNotAllowed, "Method not allowed"))
 	})
 
 	// User routes
-	router.GET("/users", func(c *gin.Context) { handlers.GetUsers(c, db) })
-	router.GET("/users/:id", func(c *gin.Context) { handlers.GetUser(c, db) })
-	router.POST("/users", func(c *gin.Context) { handlers.CreateUser(c, db) })
-	router.PUT("/users/:id", func(c *gin.Context) { handlers.UpdateUser(c, db) })
-	router.DELETE("/users/:id", func(c *gin.Context) { handlers.DeleteUser(c, db) })
+	router.GET("/users", h.Users.List)
+	router.GET("/users/:id", h.Users.Get)
+	router.POST("/users", h.Users.Create)
+	router.PUT("/users/:id", h.Users.Update)
+	router.PATCH("/users/:id", h.Users.Patch)
+	router.DELETE("/users/:id", h.Users.Delete)
+	router.POST("/users/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Users.Restore)
 	// Here you should use Query Param Like :search-users/?username={The username}  or search-users/?email={The email}
 	//`or by first name , last name , or address`.
-	router.GET("/search-users/", func(c *gin.Context) { handlers.SearchAllUsers(c, db) })
+	router.GET("/search-users/", h.Users.Search)
 
-	router.GET("/shippingDetails", func(c *gin.Context) { handlers.GetShippingDetails(c, db) })
-	router.GET("/shippingDetails/:id", func(c *gin.Context) { handlers.GetShippingDetail(c, db) })
-	router.POST("/shippingDetails", func(c *gin.Context) { handlers.CreateShippingDetail(c, db) })
-	router.PUT("/shippingDetails/:id", func(c *gin.Context) { handlers.UpdateShippingDetail(c, db) })
-	router.DELETE("/shippingDetails/:id", func(c *gin.Context) { handlers.DeleteShippingDetail(c, db) })
+	router.GET("/shippingDetails", h.ShippingDetails.List)
+	router.GET("/shippingDetails/:id", h.ShippingDetails.Get)
+	router.POST("/shippingDetails", h.ShippingDetails.Create)
+	router.PUT("/shippingDetails/:id", h.ShippingDetails.Update)
+	router.PATCH("/shippingDetails/:id", h.ShippingDetails.Patch)
+	router.DELETE("/shippingDetails/:id", h.ShippingDetails.Delete)
+	router.POST("/shippingDetails/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.ShippingDetails.Restore)
 	// Here you should use Query Param Like :search-shippingDetails/?order_id={exist ID}  or search-shippingDetails/?address={The address}
 	//`or by status`.
-	router.GET("/search-shippingDetails/", func(c *gin.Context) { handlers.SearchAllShippingDetails(c, db) })
+	router.GET("/search-shippingDetails/", h.ShippingDetails.Search)
 
-	router.GET("/reviews", func(c *gin.Context) { handlers.GetReviews(c, db) })
-	router.GET("/reviews/:id", func(c *gin.Context) { handlers.GetReview(c, db) })
-	router.POST("/reviews", func(c *gin.Context) { handlers.CreateReview(c, db) })
-	router.PUT("/reviews/:id", func(c *gin.Context) { handlers.UpdateReview(c, db) })
-	router.DELETE("/reviews/:id", func(c *gin.Context) { handlers.DeleteReview(c, db) })
+	router.GET("/reviews", h.Reviews.List)
+	router.GET("/reviews/:id", h.Reviews.Get)
+	router.POST("/reviews", h.Reviews.Create)
+	router.PUT("/reviews/:id", h.Reviews.Update)
+	router.PATCH("/reviews/:id", h.Reviews.Patch)
+	router.DELETE("/reviews/:id", h.Reviews.Delete)
+	router.POST("/reviews/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Reviews.Restore)
 	// Here you should use Query Param Like :search-reviews/?product_id={exist ID}  or search-reviews/?comment={The comment}
 	//`or by rating, user_id , review_date`.
-	router.GET("/search-reviews/", func(c *gin.Context) { handlers.SearchAllReviews(c, db) })
+	router.GET("/search-reviews/", h.Reviews.Search)
 
-	router.GET("/products", func(c *gin.Context) { handlers.GetProducts(c, db) })
-	router.GET("/products/:id", func(c *gin.Context) { handlers.GetProduct(c, db) })
-	router.POST("/products", func(c *gin.Context) { handlers.CreateProduct(c, db) })
-	router.PUT("/products/:id", func(c *gin.Context) { handlers.UpdateProduct(c, db) })
-	router.DELETE("/products/:id", func(c *gin.Context) { handlers.DeleteProduct(c, db) })
+	router.GET("/products", h.Products.List)
+	router.GET("/products/:id", h.Products.Get)
+	router.POST("/products", h.Products.Create)
+	router.PUT("/products/:id", h.Products.Update)
+	router.PATCH("/products/:id", h.Products.Patch)
+	router.DELETE("/products/:id", h.Products.Delete)
+	router.POST("/products/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Products.Restore)
 	// Here you should use Query Param Like :search-products/?name={The name of product}  or search-users/?price={The price}
 	//`or by brand_name , category_name`.
-	router.GET("/search-products/", func(c *gin.Context) { handlers.SearchAllProducts(c, db) })
+	router.GET("/search-products/", h.Products.Search)
+	router.GET("/products/:id/attributes", h.Products.Attributes)
+	router.PUT("/products/:id/attributes", h.Products.SetAttributes)
+	// Here you should use the attribute filters of /products, like :product-facets/?ram_gb[gte]=16
+	router.GET("/product-facets", h.Products.Facets)
+	// Images are uploaded as multipart/form-data in the file field, with optional alt and primary fields.
+	router.GET("/products/:id/images", h.ProductImages.List)
+	router.POST("/products/:id/images", h.ProductImages.Upload)
+	router.PUT("/products/:id/images/order", h.ProductImages.Reorder)
+	router.PATCH("/products/:id/images/:imageId", h.ProductImages.Patch)
+	router.DELETE("/products/:id/images/:imageId", h.ProductImages.Delete)
+	router.POST("/products/:id/images/:imageId/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.ProductImages.Restore)
+
+	router.GET("/productVariants", h.ProductVariants.List)
+	router.GET("/productVariants/:id", h.ProductVariants.Get)
+	router.POST("/productVariants", h.ProductVariants.Create)
+	router.PUT("/productVariants/:id", h.ProductVariants.Update)
+	router.PATCH("/productVariants/:id", h.ProductVariants.Patch)
+	router.DELETE("/productVariants/:id", h.ProductVariants.Delete)
+	router.POST("/productVariants/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.ProductVariants.Restore)
+	// Here you should use Query Param Like :search-productVariants/?sku={The SKU}  or search-productVariants/?product_id={exist ID}
+	//`or by stock_quantity`.
+	router.GET("/search-productVariants/", h.ProductVariants.Search)
 
-	router.GET("/brand", func(c *gin.Context) { handlers.GetBrands(c, db) })
-	router.GET("/brand/:id", func(c *gin.Context) { handlers.GetBrand(c, db) })
-	router.POST("/brand", func(c *gin.Context) { handlers.CreateBrand(c, db) })
-	router.PUT("/brand/:id", func(c *gin.Context) { handlers.UpdateBrand(c, db) })
-	router.DELETE("/brand/:id", func(c *gin.Context) { handlers.DeleteBrand(c, db) })
+	router.GET("/attributes", h.Attributes.List)
+	router.GET("/attributes/:id", h.Attributes.Get)
+	router.POST("/attributes", h.Attributes.Create)
+	router.PUT("/attributes/:id", h.Attributes.Update)
+	router.PATCH("/attributes/:id", h.Attributes.Patch)
+	router.DELETE("/attributes/:id", h.Attributes.Delete)
+	router.POST("/attributes/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Attributes.Restore)
+	// Here you should use Query Param Like :search-attributes/?name={The name}  or search-attributes/?category_id={exist ID}
+	//`or by label, type`.
+	router.GET("/search-attributes/", h.Attributes.Search)
+
+	router.GET("/brand", h.Brands.List)
+	router.GET("/brand/:id", h.Brands.Get)
+	router.POST("/brand", h.Brands.Create)
+	router.PUT("/brand/:id", h.Brands.Update)
+	router.PATCH("/brand/:id", h.Brands.Patch)
+	router.DELETE("/brand/:id", h.Brands.Delete)
+	router.POST("/brand/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Brands.Restore)
+	// The logo is uploaded as multipart/form-data in the file field.
+	router.PUT("/brand/:id/logo", h.Brands.SetLogo)
+	router.DELETE("/brand/:id/logo", h.Brands.RemoveLogo)
 	// Here you should use Query Param Like :search-brands/?name={The name}  or search-brands/?description={The description}
-	router.GET("/search-brands/", func(c *gin.Context) { handlers.SearchAllBrands(c, db) })
+	router.GET("/search-brands/", h.Brands.Search)
 
-	router.GET("/categories", func(c *gin.Context) { handlers.GetCategories(c, db) })
-	router.GET("/categories/:id", func(c *gin.Context) { handlers.GetCategory(c, db) })
-	router.POST("/categories", func(c *gin.Context) { handlers.CreateCategory(c, db) })
-	router.PUT("/categories/:id", func(c *gin.Context) { handlers.UpdateCategory(c, db) })
-	router.DELETE("/categories/:id", func(c *gin.Context) { handlers.DeleteCategory(c, db) })
+	router.GET("/categories", h.Categories.List)
+	router.GET("/categories/:id", h.Categories.Get)
+	router.POST("/categories", h.Categories.Create)
+	router.PUT("/categories/:id", h.Categories.Update)
+	router.PATCH("/categories/:id", h.Categories.Patch)
+	router.DELETE("/categories/:id", h.Categories.Delete)
+	router.POST("/categories/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Categories.Restore)
+	router.POST("/categories/:id/move", h.Categories.Move)
+	router.GET("/categories/:id/tree", h.Categories.Subtree)
+	router.GET("/categories/:id/breadcrumbs", h.Categories.Breadcrumbs)
+	router.GET("/categories/:id/products", h.Categories.Products)
+	router.GET("/categories/:id/facets", h.Categories.Facets)
+	router.GET("/categories/:id/attributes", h.Categories.Attributes)
+	router.GET("/category-tree", h.Categories.Tree)
 	// Here you should use Query Param Like :search-categories/?name={The name}  or search-categories/?description={The description}
-	router.GET("/search-categories/", func(c *gin.Context) { handlers.SearchAllCategories(c, db) })
+	router.GET("/search-categories/", h.Categories.Search)
 
-	router.GET("/orders", func(c *gin.Context) { handlers.GetOrders(c, db) })
-	router.GET("/orders/:id", func(c *gin.Context) { handlers.GetOrder(c, db) })
-	router.POST("/orders", func(c *gin.Context) { handlers.CreateOrder(c, db) })
-	router.PUT("/orders/:id", func(c *gin.Context) { handlers.UpdateOrder(c, db) })
-	router.DELETE("/orders/:id", func(c *gin.Context) { handlers.DeleteOrder(c, db) })
+	router.GET("/orders", h.Orders.List)
+	router.GET("/orders/:id", h.Orders.Get)
+	router.POST("/orders", h.Orders.Create)
+	router.PUT("/orders/:id", h.Orders.Update)
+	router.PATCH("/orders/:id", h.Orders.Patch)
+	router.DELETE("/orders/:id", h.Orders.Delete)
+	router.POST("/orders/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Orders.Restore)
 	// Here you should use Query Param Like :search-orders/?user_id={exist ID}  or search-orders/?total_amount={The amount}
 	//`or by status`.
-	router.GET("/search-orders/", func(c *gin.Context) { handlers.SearchAllOrders(c, db) })
+	router.GET("/search-orders/", h.Orders.Search)
 
-	router.GET("/orderItems", func(c *gin.Context) { handlers.GetOrderItems(c, db) })
-	router.GET("/orderItems/:id", func(c *gin.Context) { handlers.GetOrderItem(c, db) })
-	router.POST("/orderItems", func(c *gin.Context) { handlers.CreateOrderItem(c, db) })
-	router.PUT("/orderItems/:id", func(c *gin.Context) { handlers.UpdateOrderItem(c, db) })
-	router.DELETE("/orderItems/:id", func(c *gin.Context) { handlers.DeleteOrderItem(c, db) })
+	router.GET("/orderItems", h.OrderItems.List)
+	router.GET("/orderItems/:id", h.OrderItems.Get)
+	router.POST("/orderItems", h.OrderItems.Create)
+	router.PUT("/orderItems/:id", h.OrderItems.Update)
+	router.PATCH("/orderItems/:id", h.OrderItems.Patch)
+	router.DELETE("/orderItems/:id", h.OrderItems.Delete)
+	router.POST("/orderItems/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.OrderItems.Restore)
 	// Here you should use Query Param Like :search-orderItems/?order_id={the order id}  or search-orderItems/?quantity={The quantity}
 	//`or by product id `.
-	router.GET("/search-orderItems/", func(c *gin.Context) { handlers.SearchAllOrderItems(c, db) })
+	router.GET("/search-orderItems/", h.OrderItems.Search)
 
-	router.GET("/payments", func(c *gin.Context) { handlers.GetPayments(c, db) })
-	router.GET("/payments/:id", func(c *gin.Context) { handlers.GetPayment(c, db) })
-	router.POST("/payments", func(c *gin.Context) { handlers.CreatePayment(c, db) })
-	router.PUT("/payments/:id", func(c *gin.Context) { handlers.UpdatePayment(c, db) })
-	router.DELETE("/payments/:id", func(c *gin.Context) { handlers.DeletePayment(c, db) })
+	router.GET("/payments", h.Payments.List)
+	router.GET("/payments/:id", h.Payments.Get)
+	router.POST("/payments", h.Payments.Create)
+	router.PUT("/payments/:id", h.Payments.Update)
+	router.PATCH("/payments/:id", h.Payments.Patch)
+	router.DELETE("/payments/:id", h.Payments.Delete)
+	router.POST("/payments/:id/restore", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Payments.Restore)
 	// Here you should use Query Param Like :search-payments/?payment_method={cash}  or search-payments/?amount={The amount}
 	//`or by order id `.
-	router.GET("/search-payments/", func(c *gin.Context) { handlers.SearchAllPayments(c, db) })
-}
+	router.GET("/search-payments/", h.Payments.Search)
 
-// LoggerMiddleware is a middleware function that logs the request headers.
-// It logs the headers before and after the request is processed.
-func LoggerMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		fmt.Println("Request received")
-		c.Next()
-		fmt.Println("Headers:", c.Writer.Header())
-	}
+	// Files of uploaded images, at the url and thumbnail_url of images and logos.
+	router.GET(models.MediaPath+"*key", h.Media.Serve)
+	router.HEAD(models.MediaPath+"*key", h.Media.Serve)
+
+	// Audit log of every mutation, reserved to administrators.
+	// Here you should use Query Param Like :audit?entity=product&id={exist ID}  or audit?actor={The username}
+	router.GET("/audit", tools.TokenAuthMiddleware(), tools.AdminOnly(), h.Audit.List)
+
+	router.POST("/login", h.Auth.Login)
+	router.GET("/protected", tools.TokenAuthMiddleware(), h.Auth.Protected)
 }
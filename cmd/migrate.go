@@ -0,0 +1,116 @@
+package main
+
+import (
+	"E-Commerce_Website_Database/internal/config"
+	"E-Commerce_Website_Database/internal/database"
+	"E-Commerce_Website_Database/internal/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// migrateUsage describes the arguments of the migrate subcommand.
+const migrateUsage = "usage: migrate up | down [steps] | status | create <name>"
+
+// runMigrate runs the migrate subcommand:
+//   - up applies every pending migration,
+//   - down [steps] reverts the last applied migration, or the given number of them,
+//   - status lists every migration and when it was applied,
+//   - create <name> writes empty up and down files of a new migration for every dialect.
+func runMigrate(cfg config.Config, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	if args[0] == "create" {
+		if len(args) != 2 {
+			return errors.New(migrateUsage)
+		}
+		files, err := migrations.Create(migrations.SourceDir, args[1])
+		for _, file := range files {
+			fmt.Fprintf(out, "created %s\n", file)
+		}
+		return err
+	}
+
+	db, err := database.Open(cfg.Database, &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			fmt.Fprintf(out, "applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Fprintln(out, "database schema is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(out, "reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(reverted) == 0 {
+			fmt.Fprintln(out, "no applied migration to revert")
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return nil
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+// checkMigrations makes sure the database schema is up to date before the server starts.
+// Pending migrations are applied when autoMigrate is set, otherwise they are reported as an error.
+func checkMigrations(ctx context.Context, db *gorm.DB, autoMigrate bool) error {
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+	if autoMigrate {
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			slog.Info("migration applied", "version", migration.Version, "name", migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := migrator.Check(ctx); err != nil {
+		return fmt.Errorf("%w, run `go run ./cmd migrate up` or set DB_AUTO_MIGRATE=true", err)
+	}
+	return nil
+}
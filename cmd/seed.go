@@ -0,0 +1,71 @@
+package main
+
+import (
+	"E-Commerce_Website_Database/internal/config"
+	"E-Commerce_Website_Database/internal/database"
+	"E-Commerce_Website_Database/internal/seed"
+	"context"
+	"flag"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"sort"
+	"strings"
+)
+
+// runSeed runs the seed subcommand, which fills the database with generated demo data:
+//
+//	seed [-preset small|medium|large] [-seed 42] [-password secret] [-reset]
+//
+// The same preset and seed always generate the same rows. -reset deletes all existing rows first.
+func runSeed(cfg config.Config, args []string, out io.Writer) error {
+	presetNames := make([]string, 0, len(seed.Presets))
+	for name := range seed.Presets {
+		presetNames = append(presetNames, name)
+	}
+	sort.Strings(presetNames)
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.SetOutput(out)
+	presetName := flags.String("preset", "small", "dataset size, one of "+strings.Join(presetNames, ", "))
+	rngSeed := flags.Int64("seed", seed.DefaultSeed, "seed of the random generator")
+	password := flags.String("password", seed.DefaultPassword, "password of every generated user")
+	reset := flags.Bool("reset", false, "delete all existing rows before seeding")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	preset, ok := seed.Presets[*presetName]
+	if !ok {
+		return fmt.Errorf("unknown preset %q, expected one of %s", *presetName, strings.Join(presetNames, ", "))
+	}
+
+	db, err := database.Open(cfg.Database, &gorm.Config{})
+	if err != nil {
+		return err
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+	ctx := context.Background()
+	if err := checkMigrations(ctx, db, cfg.Database.AutoMigrate); err != nil {
+		return err
+	}
+
+	dataset, err := seed.Generate(seed.Options{Seed: *rngSeed, Preset: preset, Password: *password})
+	if err != nil {
+		return err
+	}
+	if *reset {
+		if err := seed.Reset(ctx, db); err != nil {
+			return fmt.Errorf("failed to delete existing rows: %w", err)
+		}
+	}
+	if err := seed.Insert(ctx, db, dataset, 0); err != nil {
+		return fmt.Errorf("failed to insert seed data: %w", err)
+	}
+	fmt.Fprintf(out, "seeded %s preset with seed %d: %s\n", *presetName, *rngSeed, dataset.Counts())
+	if len(dataset.Users) > 0 {
+		fmt.Fprintf(out, "admin user: %s (password %q)\n", dataset.Users[0].Username, *password)
+	}
+	return nil
+}
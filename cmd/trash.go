@@ -0,0 +1,51 @@
+package main
+
+import (
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/config"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/service"
+	"context"
+	"gorm.io/gorm"
+	"log/slog"
+	"time"
+)
+
+// trashPurgeActor is the actor recorded for the rows purged from the trash.
+const trashPurgeActor = "system:trash-purge"
+
+// mediaSweepDelay is how old a stored file must be to be swept, so that the files of uploads whose record
+// is still being written are spared.
+const mediaSweepDelay = time.Hour
+
+// purgeTrash permanently deletes the records deleted longer than the retention period ago,
+// once at startup and then every purge interval, until ctx is cancelled.
+// The purges are recorded in the audit log with the trashPurgeActor actor. The files of images no record
+// references any more, such as those of the purged images, are then deleted from media.
+func purgeTrash(ctx context.Context, db *gorm.DB, media *service.Media, cfg config.TrashConfig) {
+	ctx = audit.WithActor(ctx, trashPurgeActor)
+	ticker := time.NewTicker(cfg.PurgeInterval)
+	defer ticker.Stop()
+	for {
+		purged, err := models.PurgeDeleted(ctx, db, time.Now().Add(-cfg.Retention))
+		if err != nil && ctx.Err() == nil {
+			slog.Error("trash purge failed", "error", err)
+		}
+		for table, count := range purged {
+			slog.Info("trash purged", "table", table, "rows", count)
+		}
+		swept, err := media.Sweep(ctx, time.Now().Add(-mediaSweepDelay))
+		if err != nil && ctx.Err() == nil {
+			slog.Error("media sweep failed", "error", err)
+		}
+		if swept > 0 {
+			slog.Info("media swept", "files", swept)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
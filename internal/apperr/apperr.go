@@ -0,0 +1,184 @@
+package apperr
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+// Kind classifies an application error. It decides the HTTP status of the response and is sent to clients
+// as a machine-readable code.
+type Kind string
+
+const (
+	KindBadRequest           Kind = "bad_request"
+	KindValidation           Kind = "validation"
+	KindUnauthorized         Kind = "unauthorized"
+	KindForbidden            Kind = "forbidden"
+	KindNotFound             Kind = "not_found"
+	KindMethodNotAllowed     Kind = "method_not_allowed"
+	KindConflict             Kind = "conflict"
+	KindPreconditionFailed   Kind = "precondition_failed"
+	KindUnsupportedMediaType Kind = "unsupported_media_type"
+	KindTooLarge             Kind = "too_large"
+	KindPreconditionRequired Kind = "precondition_required"
+	KindInternal             Kind = "internal"
+	KindUnavailable          Kind = "unavailable"
+	KindTimeout              Kind = "timeout"
+)
+
+// kindStatus maps each kind to its HTTP status.
+var kindStatus = map[Kind]int{
+	KindBadRequest:           http.StatusBadRequest,
+	KindValidation:           http.StatusBadRequest,
+	KindUnauthorized:         http.StatusUnauthorized,
+	KindForbidden:            http.StatusForbidden,
+	KindNotFound:             http.StatusNotFound,
+	KindMethodNotAllowed:     http.StatusMethodNotAllowed,
+	KindConflict:             http.StatusConflict,
+	KindPreconditionFailed:   http.StatusPreconditionFailed,
+	KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+	KindTooLarge:             http.StatusRequestEntityTooLarge,
+	KindPreconditionRequired: http.StatusPreconditionRequired,
+	KindInternal:             http.StatusInternalServerError,
+	KindUnavailable:          http.StatusServiceUnavailable,
+	KindTimeout:              http.StatusGatewayTimeout,
+}
+
+// Status returns the HTTP status of errors of kind k, 500 for unknown kinds.
+func (k Kind) Status() int {
+	if status, ok := kindStatus[k]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error meant to be answered to a client. Message is shown to the client, while the cause Err
+// is only logged, so that database errors and other internals do not leak into responses.
+// Extensions are added as members of the response, such as the dependents blocking a deletion.
+type Error struct {
+	Kind       Kind
+	Message    string
+	Err        error
+	Extensions map[string]interface{}
+}
+
+// Error returns the message followed by the cause, if any.
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Message
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// With adds the extension member name to the response of the error and returns the error.
+func (e *Error) With(name string, value interface{}) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]interface{}{}
+	}
+	e.Extensions[name] = value
+	return e
+}
+
+// New returns an error of the given kind.
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Wrap returns an error of the given kind caused by err.
+func Wrap(kind Kind, message string, err error) *Error {
+	return &Error{Kind: kind, Message: message, Err: err}
+}
+
+// BadRequest returns the error of a malformed request, such as invalid JSON or query parameters.
+// The cause is meant for the client and is appended to the message.
+func BadRequest(message string, err error) *Error {
+	if err != nil {
+		message += ": " + err.Error()
+	}
+	return New(KindBadRequest, message)
+}
+
+// Validation returns the error of a request body with invalid fields, err holding the field errors.
+// An err that is already an *Error, such as a reference that could not be looked up, is returned as is.
+func Validation(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Wrap(KindValidation, "Validation error", err)
+}
+
+// Unauthorized returns the error of a request without valid credentials.
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Forbidden returns the error of a request the authenticated user is not allowed to make.
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// NotFound returns the error of a request for a record that does not exist.
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Conflict returns the error of a request conflicting with the current state of the records, caused by err.
+func Conflict(message string, err error) *Error {
+	return Wrap(KindConflict, message, err)
+}
+
+// Internal returns the error of a failure the client cannot do anything about, caused by err.
+func Internal(message string, err error) *Error {
+	return Wrap(KindInternal, message, err)
+}
+
+// FromDB classifies an error returned by GORM: a missing record is not found, a unique or foreign key
+// constraint violation is a conflict, a query stopped by the deadline of its context is a timeout, a cancelled
+// query or a lost connection makes the database unavailable, and anything else is an internal error with
+// failure as message. Constraint violations are only recognized on connections opened with TranslateError.
+func FromDB(err error, failure string) *Error {
+	var appErr *Error
+	switch {
+	case errors.As(err, &appErr):
+		return appErr
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return Wrap(KindNotFound, "Record not found", err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return Conflict("A record with the same unique values already exists", err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return Conflict("A referenced record does not exist or is still referenced", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(KindTimeout, "The database did not answer in time", err)
+	case errors.Is(err, context.Canceled), errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return Wrap(KindUnavailable, "The database is unavailable", err)
+	}
+	return Internal(failure, err)
+}
+
+// Lookup is FromDB for the error of reading a record by ID, answering a missing record with notFound.
+// Other failures, such as a lost connection, are internal errors rather than not found.
+func Lookup(err error, notFound string) *Error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Wrap(KindNotFound, notFound, err)
+	}
+	return FromDB(err, "Internal server error")
+}
+
+// KindOf returns the kind of err, KindInternal for errors that are not an *Error.
+func KindOf(err error) Kind {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+	return KindInternal
+}
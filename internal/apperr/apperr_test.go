@@ -0,0 +1,83 @@
+package apperr
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"testing"
+)
+
+// TestFromDB checks how database errors are classified, including a unique violation reported by SQLite
+// on a connection opened with TranslateError.
+func TestFromDB(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	type account struct {
+		ID    uint
+		Email string `gorm:"uniqueIndex"`
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	db.Create(&account{Email: "a@example.com"})
+	duplicate := db.Create(&account{Email: "a@example.com"}).Error
+
+	tests := []struct {
+		name     string
+		err      error
+		expected Kind
+	}{
+		{"record not found", gorm.ErrRecordNotFound, KindNotFound},
+		{"duplicated key", duplicate, KindConflict},
+		{"foreign key", gorm.ErrForeignKeyViolated, KindConflict},
+		{"connection", errors.New("connection refused"), KindInternal},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), KindTimeout},
+		{"cancelled", context.Canceled, KindUnavailable},
+		{"bad connection", driver.ErrBadConn, KindUnavailable},
+		{"application error", Forbidden("no"), KindForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, FromDB(tt.err, "Error creating account").Kind)
+		})
+	}
+	assert.Equal(t, "Error creating account", FromDB(errors.New("connection refused"), "Error creating account").Message)
+}
+
+// TestLookup checks that only a missing record is answered as not found with the given message.
+func TestLookup(t *testing.T) {
+	err := Lookup(gorm.ErrRecordNotFound, "Product not found")
+	assert.Equal(t, KindNotFound, err.Kind)
+	assert.Equal(t, "Product not found", err.Message)
+	assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+
+	err = Lookup(errors.New("connection refused"), "Product not found")
+	assert.Equal(t, KindInternal, err.Kind)
+	assert.Equal(t, http.StatusInternalServerError, err.Kind.Status())
+}
+
+// TestValidation checks that field errors are answered as a validation error, while an application error
+// returned in their place is kept as it is.
+func TestValidation(t *testing.T) {
+	assert.Equal(t, KindValidation, Validation(errors.New("name: must not be empty")).Kind)
+	timeout := FromDB(context.DeadlineExceeded, "Error checking a referenced record")
+	assert.Same(t, timeout, Validation(timeout))
+}
+
+// TestKindOf checks the kind of wrapped application errors and of plain errors.
+func TestKindOf(t *testing.T) {
+	wrapped := errors.Join(errors.New("context"), NotFound("Brand not found"))
+	assert.Equal(t, KindNotFound, KindOf(wrapped))
+	assert.Equal(t, KindInternal, KindOf(errors.New("boom")))
+	assert.Equal(t, http.StatusPreconditionRequired, KindPreconditionRequired.Status())
+	assert.Equal(t, http.StatusServiceUnavailable, KindUnavailable.Status())
+	assert.Equal(t, http.StatusGatewayTimeout, KindTimeout.Status())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, KindTooLarge.Status())
+}
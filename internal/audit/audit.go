@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"E-Commerce_Website_Database/internal/tools"
+	"context"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Actions recorded in Entry.Action. Delete moves a row to the trash, purge removes it permanently.
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionPurge   = "purge"
+)
+
+// Anonymous is the actor recorded for changes made without a valid token.
+const Anonymous = "anonymous"
+
+// Entities maps the audited tables to the entity names used in the audit log.
+var Entities = map[string]string{
+	"users":              "user",
+	"brands":             "brand",
+	"categories":         "category",
+	"products":           "product",
+	"product_variants":   "product_variant",
+	"attributes":         "attribute",
+	"product_attributes": "product_attribute",
+	"product_images":     "product_image",
+	"orders":             "order",
+	"order_items":        "order_item",
+	"payments":           "payment",
+	"shipping_details":   "shipping_detail",
+	"reviews":            "review",
+}
+
+// Change is the value of a column before and after a mutation. Before is empty for created rows
+// and After for permanently deleted ones.
+type Change struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// Entry is one audited mutation of one row, with who made it, when, in which request, and the changed columns.
+type Entry struct {
+	ID        uint              `gorm:"primaryKey" json:"id"`
+	CreatedAt time.Time         `gorm:"index" json:"created_at"`
+	Entity    string            `gorm:"size:64;index:idx_audit_log_entity" json:"entity"`
+	EntityID  uint              `gorm:"index:idx_audit_log_entity" json:"entity_id"`
+	Action    string            `gorm:"size:16" json:"action"`
+	Actor     string            `gorm:"size:191" json:"actor"`
+	RequestID string            `gorm:"size:128" json:"request_id"`
+	Changes   map[string]Change `gorm:"type:text;serializer:json" json:"changes"`
+}
+
+// TableName stores entries in the audit_log table.
+func (Entry) TableName() string {
+	return "audit_log"
+}
+
+// Filter selects audit entries. Zero values match everything; Limit caps the number of entries returned.
+type Filter struct {
+	Entity   string
+	EntityID uint
+	Actor    string
+	Limit    int
+}
+
+// Find returns the entries matching the filter, most recent first.
+func Find(db *gorm.DB, filter Filter) ([]Entry, error) {
+	query := db.Model(&Entry{})
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	entries := []Entry{}
+	if err := query.Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// actorKey is the request context key under which the actor is stored.
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the username recorded as the actor of the mutations made with it.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored in ctx by WithActor, or Anonymous if there is none.
+func ActorFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+			return actor
+		}
+	}
+	return Anonymous
+}
+
+// Middleware stores the username of the request's JWT, when it carries a valid one, as the actor in the
+// request context. It never rejects a request; routes requiring a token still use tools.TokenAuthMiddleware.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if claims, err := tools.ParseToken(c.GetHeader("Authorization")); err == nil {
+			if username, ok := claims["username"].(string); ok {
+				c.Request = c.Request.WithContext(WithActor(c.Request.Context(), username))
+			}
+		}
+		c.Next()
+	}
+}
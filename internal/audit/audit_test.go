@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/tools"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestActorFromContext tests that the actor defaults to Anonymous when none was stored.
+func TestActorFromContext(t *testing.T) {
+	assert.Equal(t, Anonymous, ActorFromContext(context.Background()))
+	assert.Equal(t, "alice", ActorFromContext(WithActor(context.Background(), "alice")))
+}
+
+// TestMiddleware tests that mutations made while handling a request are recorded with the username of its token
+// and its request ID, and that requests without a valid token are recorded as anonymous rather than rejected.
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupAuditDB(t)
+	router := gin.New()
+	router.Use(middleware.RequestID(), Middleware())
+	router.POST("/brand", func(c *gin.Context) {
+		brand := models.Brands{Name: c.Query("name")}
+		db.WithContext(c.Request.Context()).Create(&brand)
+		c.JSON(http.StatusCreated, brand)
+	})
+
+	if err := tools.SetSigningKey("test-secret"); err != nil {
+		t.Fatalf("failed to set signing key: %v", err)
+	}
+	tokenService := tools.JWTTokenService{}
+	token, err := tokenService.GenerateTokenWithClaims("bob", "admin")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	for _, auth := range []string{"Bearer " + token, "Bearer invalid"} {
+		req, _ := http.NewRequest("POST", "/brand?name=Acme", nil)
+		req.Header.Set("Authorization", auth)
+		req.Header.Set(middleware.RequestIDHeader, "req-"+auth[len(auth)-4:])
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusCreated, rr.Code)
+	}
+
+	found, err := Find(db, Filter{Entity: "brand"})
+	assert.NoError(t, err)
+	if !assert.Len(t, found, 2) {
+		return
+	}
+	assert.Equal(t, Anonymous, found[0].Actor)
+	assert.Equal(t, "req-alid", found[0].RequestID)
+	assert.Equal(t, "bob", found[1].Actor)
+	assert.Equal(t, "req-"+token[len(token)-4:], found[1].RequestID)
+}
+
+// TestFind tests that entries are filtered by entity, entity ID and actor, most recent first and up to the limit.
+func TestFind(t *testing.T) {
+	db := setupAuditDB(t)
+	for _, entry := range []Entry{
+		{Entity: "brand", EntityID: 1, Action: ActionCreate, Actor: "alice"},
+		{Entity: "brand", EntityID: 2, Action: ActionCreate, Actor: "bob"},
+		{Entity: "brand", EntityID: 1, Action: ActionUpdate, Actor: "bob"},
+		{Entity: "product", EntityID: 1, Action: ActionCreate, Actor: "alice"},
+	} {
+		db.Create(&entry)
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		expected []uint
+	}{
+		{"All", Filter{}, []uint{4, 3, 2, 1}},
+		{"Entity", Filter{Entity: "brand"}, []uint{3, 2, 1}},
+		{"Entity ID", Filter{Entity: "brand", EntityID: 1}, []uint{3, 1}},
+		{"Actor", Filter{Actor: "bob"}, []uint{3, 2}},
+		{"Limit", Filter{Limit: 1}, []uint{4}},
+		{"None", Filter{Entity: "order"}, []uint{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := Find(db, tt.filter)
+			assert.NoError(t, err)
+			ids := []uint{}
+			for _, entry := range found {
+				ids = append(ids, entry.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package audit
+
+import (
+	"E-Commerce_Website_Database/internal/middleware"
+	"encoding/json"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+)
+
+// beforeKey is the statement setting under which the plugin stores the rows as they were before an update or delete.
+const beforeKey = "audit:before"
+
+// redacted replaces the values of secret columns in the audit log.
+const redacted = "******"
+
+// Columns left out of the audit log: the timestamps GORM maintains change on every write, and secrets are redacted.
+var (
+	ignoredColumns = map[string]bool{"created_at": true, "updated_at": true}
+	secretColumns  = map[string]bool{"password": true}
+)
+
+// Plugin is a GORM plugin writing an Entry for every row created, updated or deleted in the tables of Entities.
+// Entries are written with the statement's connection, so they are part of its transaction, and carry
+// the actor and request ID found in the statement context: queries must be issued with db.WithContext(ctx).
+type Plugin struct{}
+
+// Name returns the name under which the plugin is registered with GORM.
+func (p *Plugin) Name() string {
+	return "electromart:audit"
+}
+
+// Initialize registers the audit callbacks around GORM's create, update and delete callbacks.
+// It returns an error if any of the callbacks could not be registered.
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	errs := []error{
+		callbacks.Create().After("gorm:create").Register("audit:after_create", afterCreate),
+		callbacks.Update().Before("gorm:update").Register("audit:before_update", loadBefore),
+		callbacks.Update().After("gorm:update").Register("audit:after_update", afterUpdate),
+		callbacks.Delete().Before("gorm:delete").Register("audit:before_delete", loadBefore),
+		callbacks.Delete().After("gorm:delete").Register("audit:after_delete", afterDelete),
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// afterCreate records the values of every created row.
+func afterCreate(db *gorm.DB) {
+	entity, ok := audited(db)
+	if !ok || db.Error != nil || db.RowsAffected == 0 {
+		return
+	}
+	var entries []Entry
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		after := values(db, row)
+		changes := map[string]Change{}
+		for column, value := range after {
+			changes[column] = Change{After: value}
+		}
+		entries = append(entries, newEntry(db, entity, ActionCreate, primaryKey(db, row), changes))
+	})
+	write(db, entries)
+}
+
+// loadBefore stores the rows an update or delete is about to change, found by its conditions and the primary key
+// of its model. Statements with neither are not audited.
+func loadBefore(db *gorm.DB) {
+	if _, ok := audited(db); !ok || db.Error != nil {
+		return
+	}
+	stmt := db.Statement
+	query := db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(stmt.Schema.Table)
+	conditions := false
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+		query.Statement.AddClause(clause.Where{Exprs: where.Exprs})
+		conditions = true
+	}
+	if field := stmt.Schema.PrioritizedPrimaryField; field != nil && stmt.ReflectValue.Kind() == reflect.Struct {
+		if id, zero := field.ValueOf(stmt.Context, stmt.ReflectValue); !zero {
+			query = query.Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id})
+			conditions = true
+		}
+	}
+	if !conditions {
+		return
+	}
+
+	rows := reflect.New(reflect.SliceOf(stmt.Schema.ModelType))
+	if err := query.Find(rows.Interface()).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	db.InstanceSet(beforeKey, rows.Elem())
+}
+
+// afterUpdate records the changed columns of every updated row. An update that only sets deleted_at
+// is recorded as a delete into the trash, and one that only clears it as a restore.
+func afterUpdate(db *gorm.DB) {
+	entity, before, ok := beforeRows(db)
+	if !ok {
+		return
+	}
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	ids := make([]interface{}, 0, before.Len())
+	for i := 0; i < before.Len(); i++ {
+		id, _ := field.ValueOf(db.Statement.Context, before.Index(i))
+		ids = append(ids, id)
+	}
+	after := reflect.New(before.Type())
+	if err := db.Session(&gorm.Session{NewDB: true}).Unscoped().Table(db.Statement.Schema.Table).
+		Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Values: ids}).
+		Find(after.Interface()).Error; err != nil {
+		db.AddError(err)
+		return
+	}
+	afterByID := map[uint]reflect.Value{}
+	eachRow(after.Elem(), func(row reflect.Value) {
+		afterByID[primaryKey(db, row)] = row
+	})
+
+	var entries []Entry
+	eachRow(before, func(row reflect.Value) {
+		id := primaryKey(db, row)
+		updated, found := afterByID[id]
+		if !found {
+			return
+		}
+		changes := diff(values(db, row), values(db, updated))
+		if len(changes) == 0 {
+			return
+		}
+		action := ActionUpdate
+		if deletedAt, ok := changes["deleted_at"]; ok && len(changes) == 1 {
+			action = ActionDelete
+			if deletedAt.After == nil {
+				action = ActionRestore
+			}
+		}
+		entries = append(entries, newEntry(db, entity, action, id, changes))
+	})
+	write(db, entries)
+}
+
+// afterDelete records the values of every deleted row. Deletes bypassing the trash are recorded as purges.
+func afterDelete(db *gorm.DB) {
+	entity, before, ok := beforeRows(db)
+	if !ok {
+		return
+	}
+	action := ActionPurge
+	if !db.Statement.Unscoped && db.Statement.Schema.LookUpField("deleted_at") != nil {
+		action = ActionDelete
+	}
+	var entries []Entry
+	eachRow(before, func(row reflect.Value) {
+		changes := map[string]Change{}
+		for column, value := range values(db, row) {
+			changes[column] = Change{Before: value}
+		}
+		entries = append(entries, newEntry(db, entity, action, primaryKey(db, row), changes))
+	})
+	write(db, entries)
+}
+
+// audited returns the entity name of the statement's table, and false if the table is not audited.
+func audited(db *gorm.DB) (string, bool) {
+	if db.Statement.Schema == nil {
+		return "", false
+	}
+	entity, ok := Entities[db.Statement.Schema.Table]
+	return entity, ok
+}
+
+// beforeRows returns the rows stored by loadBefore, and false if there are none or the statement failed.
+func beforeRows(db *gorm.DB) (string, reflect.Value, bool) {
+	entity, ok := audited(db)
+	if !ok || db.Error != nil || db.RowsAffected == 0 {
+		return "", reflect.Value{}, false
+	}
+	value, ok := db.InstanceGet(beforeKey)
+	if !ok {
+		return "", reflect.Value{}, false
+	}
+	rows, ok := value.(reflect.Value)
+	return entity, rows, ok && rows.Len() > 0
+}
+
+// eachRow calls fn with every struct held by value, which may be a struct, a slice or a pointer to either.
+func eachRow(value reflect.Value, fn func(reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		fn(value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			eachRow(value.Index(i), fn)
+		}
+	}
+}
+
+// primaryKey returns the primary key of a row of the statement's model.
+func primaryKey(db *gorm.DB, row reflect.Value) uint {
+	field := db.Statement.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return 0
+	}
+	id, _ := field.ValueOf(db.Statement.Context, row)
+	switch id := reflect.ValueOf(id); id.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(id.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint(id.Int())
+	}
+	return 0
+}
+
+// values returns the audited column values of a row, with unset soft-delete timestamps as nil.
+func values(db *gorm.DB, row reflect.Value) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, column := range db.Statement.Schema.DBNames {
+		if ignoredColumns[column] {
+			continue
+		}
+		value, _ := db.Statement.Schema.FieldsByDBName[column].ValueOf(db.Statement.Context, row)
+		if deletedAt, ok := value.(gorm.DeletedAt); ok {
+			value = nil
+			if deletedAt.Valid {
+				value = deletedAt.Time
+			}
+		}
+		result[column] = value
+	}
+	return result
+}
+
+// diff returns the columns whose value differs between before and after.
+func diff(before, after map[string]interface{}) map[string]Change {
+	changes := map[string]Change{}
+	for column, old := range before {
+		updated := after[column]
+		oldJSON, _ := json.Marshal(old)
+		updatedJSON, _ := json.Marshal(updated)
+		if string(oldJSON) != string(updatedJSON) {
+			changes[column] = Change{Before: old, After: updated}
+		}
+	}
+	return changes
+}
+
+// redact hides the values of secret columns, keeping only whether they were set, so that changes to them
+// are recorded without disclosing them.
+func redact(changes map[string]Change) {
+	for column, change := range changes {
+		if !secretColumns[column] {
+			continue
+		}
+		if change.Before != nil && !reflect.ValueOf(change.Before).IsZero() {
+			change.Before = redacted
+		}
+		if change.After != nil && !reflect.ValueOf(change.After).IsZero() {
+			change.After = redacted
+		}
+		changes[column] = change
+	}
+}
+
+// newEntry builds an entry with the actor and request ID of the statement context, with secrets redacted.
+func newEntry(db *gorm.DB, entity, action string, id uint, changes map[string]Change) Entry {
+	ctx := db.Statement.Context
+	redact(changes)
+	return Entry{
+		Entity:    entity,
+		EntityID:  id,
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		RequestID: middleware.RequestIDFromContext(ctx),
+		Changes:   changes,
+	}
+}
+
+// write stores the entries with the statement's connection. A failure fails the statement, so that no mutation
+// goes unrecorded when it runs in a transaction.
+func write(db *gorm.DB, entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&entries).Error; err != nil {
+		db.AddError(err)
+	}
+}
@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+// setupAuditDB opens a SQLite database private to the test with the application schema, the audit log
+// and the plugin installed, and returns it bound to a context carrying the actor alice.
+func setupAuditDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "audit.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Brands{}, &models.Category{}, &models.Product{}, &models.User{}, &models.Order{},
+		&models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}, &models.Review{}, &Entry{}); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	if err := db.Use(&Plugin{}); err != nil {
+		t.Fatalf("Failed to install the plugin: %v", err)
+	}
+	return db.WithContext(WithActor(context.Background(), "alice"))
+}
+
+// entries returns the audit log of one entity row, oldest first.
+func entries(t *testing.T, db *gorm.DB, entity string, id uint) []Entry {
+	var found []Entry
+	if err := db.Where("entity = ? AND entity_id = ?", entity, id).Order("id").Find(&found).Error; err != nil {
+		t.Fatalf("Failed to read the audit log: %v", err)
+	}
+	return found
+}
+
+// TestPlugin_CreateUpdate tests that creating a row records all its columns and that updating it records
+// only the changed ones, both with the actor of the context.
+func TestPlugin_CreateUpdate(t *testing.T) {
+	db := setupAuditDB(t)
+
+	brand := models.Brands{Name: "Acme", Description: "Gadgets"}
+	assert.NoError(t, db.Create(&brand).Error)
+	assert.NoError(t, db.Model(&brand).Updates(models.Brands{Name: "Acme Corp"}).Error)
+
+	found := entries(t, db, "brand", brand.ID)
+	if !assert.Len(t, found, 2) {
+		return
+	}
+	assert.Equal(t, ActionCreate, found[0].Action)
+	assert.Equal(t, "alice", found[0].Actor)
+	assert.Equal(t, Change{After: "Acme"}, found[0].Changes["name"])
+	assert.NotContains(t, found[0].Changes, "created_at")
+
+	assert.Equal(t, ActionUpdate, found[1].Action)
+	assert.Equal(t, map[string]Change{"name": {Before: "Acme", After: "Acme Corp"}}, found[1].Changes)
+}
+
+// TestPlugin_DeleteRestorePurge tests that moving a row to the trash, restoring it and purging it
+// are recorded as delete, restore and purge.
+func TestPlugin_DeleteRestorePurge(t *testing.T) {
+	db := setupAuditDB(t)
+
+	brand := models.Brands{Name: "Acme"}
+	db.Create(&brand)
+	assert.NoError(t, models.Delete(db, &models.Brands{}, brand.ID))
+	assert.NoError(t, models.Restore(db, &models.Brands{}, brand.ID))
+	assert.NoError(t, models.Purge(db, &models.Brands{}, brand.ID))
+
+	var actions []string
+	for _, entry := range entries(t, db, "brand", brand.ID) {
+		actions = append(actions, entry.Action)
+	}
+	assert.Equal(t, []string{ActionCreate, ActionDelete, ActionRestore, ActionPurge}, actions)
+}
+
+// TestPlugin_RedactsPassword tests that passwords never reach the audit log.
+func TestPlugin_RedactsPassword(t *testing.T) {
+	db := setupAuditDB(t)
+
+	user := models.User{Username: "bob", Email: "bob@example.com", Password: "Secret123!"}
+	db.Create(&user)
+	db.Model(&user).Update("password", "Secret456!")
+
+	found := entries(t, db, "user", user.ID)
+	if !assert.Len(t, found, 2) {
+		return
+	}
+	assert.Equal(t, Change{After: redacted}, found[0].Changes["password"])
+	assert.Equal(t, map[string]Change{"password": {Before: redacted, After: redacted}}, found[1].Changes)
+}
+
+// TestPlugin_Rollback tests that entries written in a transaction that is rolled back are discarded with it.
+func TestPlugin_Rollback(t *testing.T) {
+	db := setupAuditDB(t)
+
+	tx := db.Begin()
+	tx.Create(&models.Brands{Name: "Acme"})
+	tx.Rollback()
+
+	var n int64
+	db.Model(&Entry{}).Count(&n)
+	assert.Zero(t, n)
+}
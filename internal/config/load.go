@@ -0,0 +1,304 @@
+package config
+
+import (
+	"E-Commerce_Website_Database/internal/tools"
+	"errors"
+	"fmt"
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redactedSecret replaces the value of secret settings when the configuration is printed.
+const redactedSecret = "******"
+
+// Options controls where Load looks for configuration.
+type Options struct {
+	// File is an optional YAML (.yaml, .yml) or TOML (.toml) configuration file. Defaults to $CONFIG_FILE.
+	File string
+	// DotEnvFile is the .env file to read. Defaults to ".env"; a missing file is not an error.
+	DotEnvFile string
+	// Environ supplies the process environment. Defaults to os.Environ.
+	Environ func() []string
+}
+
+// ValidationError lists every problem found in a configuration, so they can all be fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns all the problems, one per line.
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Load builds the configuration from, in increasing order of precedence:
+//  1. the defaults of the profile selected by APP_ENV (dev when unset),
+//  2. the configuration file, followed by its profile specific sibling (config.yaml then config.prod.yaml),
+//  3. the .env file,
+//  4. the process environment.
+//
+// The result is validated, and every problem found is reported in a single *ValidationError.
+func Load(opts Options) (Config, error) {
+	if opts.DotEnvFile == "" {
+		opts.DotEnvFile = ".env"
+	}
+	if opts.Environ == nil {
+		opts.Environ = os.Environ
+	}
+
+	env, err := readEnvironment(opts)
+	if err != nil {
+		return Config{}, err
+	}
+
+	profile := env["APP_ENV"]
+	if profile == "" {
+		profile = ProfileDev
+	}
+	cfg := Defaults(profile)
+
+	file := opts.File
+	if file == "" {
+		file = env["CONFIG_FILE"]
+	}
+	if file != "" {
+		if err := decodeFile(file, &cfg); err != nil {
+			return Config{}, err
+		}
+		if err := decodeFile(profileFile(file, profile), &cfg); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return Config{}, err
+		}
+	}
+
+	var problems []string
+	applyEnvironment(reflect.ValueOf(&cfg).Elem(), env, &problems)
+	cfg.Environment = profile
+
+	if err := cfg.Validate(); err != nil {
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			problems = append(problems, validationErr.Problems...)
+		}
+	}
+	if len(problems) > 0 {
+		return cfg, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// Validate checks the configuration for missing required settings and out of range values.
+// It returns a *ValidationError listing every problem, or nil if the configuration is valid.
+func (c Config) Validate() error {
+	var problems []string
+	require := func(ok bool, problem string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(problem, args...))
+		}
+	}
+
+	require(c.Environment == ProfileDev || c.Environment == ProfileTest || c.Environment == ProfileProd,
+		"APP_ENV must be one of dev, test, prod, got %q", c.Environment)
+
+	require(c.Server.Port > 0 && c.Server.Port <= 65535, "PORT must be between 1 and 65535, got %d", c.Server.Port)
+	require(c.Server.ReadTimeout >= 0, "HTTP_READ_TIMEOUT must not be negative")
+	require(c.Server.ReadHeaderTimeout >= 0, "HTTP_READ_HEADER_TIMEOUT must not be negative")
+	require(c.Server.WriteTimeout >= 0, "HTTP_WRITE_TIMEOUT must not be negative")
+	require(c.Server.IdleTimeout >= 0, "HTTP_IDLE_TIMEOUT must not be negative")
+	require(c.Server.ShutdownTimeout >= 0, "SHUTDOWN_TIMEOUT must not be negative")
+	require(c.Server.HookTimeout >= 0, "SHUTDOWN_HOOK_TIMEOUT must not be negative")
+	require(c.Server.MaxHeaderBytes >= 0, "HTTP_MAX_HEADER_BYTES must not be negative")
+	nodeSet := c.Environment != ProfileProd || c.Server.NodeID != unsetNodeID
+	require(nodeSet, "NODE_ID is required in the prod profile, it must differ between instances sharing a database")
+	require(!nodeSet || c.Server.NodeID >= 0 && c.Server.NodeID <= tools.MaxIDNode, "NODE_ID must be between 0 and %d, got %d", tools.MaxIDNode, c.Server.NodeID)
+	require((c.Server.TLSCertFile == "") == (c.Server.TLSKeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+
+	driver := strings.ToLower(c.Database.Driver)
+	require(driver == DriverMySQL || driver == DriverPostgres || driver == DriverSQLite,
+		"DB_DRIVER must be one of mysql, postgres, sqlite, got %q", c.Database.Driver)
+	if driver == DriverSQLite {
+		require(c.Database.Name != "", "DB_NAME is required, it is the path of the SQLite database file")
+	} else {
+		require(c.Database.Host != "", "DB_HOST is required")
+		require(c.Database.Port >= 0 && c.Database.Port <= 65535, "DB_PORT must be between 1 and 65535, got %d", c.Database.Port)
+		require(c.Database.User != "", "DB_USER is required")
+		require(c.Database.Name != "", "DB_NAME is required")
+		if c.Environment == ProfileProd {
+			require(c.Database.Password != "", "DB_PASSWORD is required in the prod profile")
+		}
+	}
+	require(c.Database.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	require(c.Database.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	require(c.Database.ConnMaxLifetime >= 0, "DB_CONN_MAX_LIFETIME must not be negative")
+	require(c.Database.ConnMaxIdleTime >= 0, "DB_CONN_MAX_IDLE_TIME must not be negative")
+	require(c.Database.QueryTimeout >= 0, "DB_QUERY_TIMEOUT must not be negative")
+
+	level := strings.ToLower(c.Log.Level)
+	require(level == "debug" || level == "info" || level == "warn" || level == "warning" || level == "error",
+		"LOG_LEVEL must be one of debug, info, warn, error, got %q", c.Log.Level)
+
+	exporter := strings.ToLower(c.Tracing.Exporter)
+	require(exporter == "" || exporter == "none" || exporter == "otlp" || exporter == "stdout" || exporter == "file",
+		"TRACING_EXPORTER must be one of none, otlp, stdout, file, got %q", c.Tracing.Exporter)
+	require(exporter != "file" || c.Tracing.File != "", "TRACING_FILE is required when TRACING_EXPORTER is file")
+	require(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	require(c.Metrics.LowStockThreshold >= 0, "LOW_STOCK_THRESHOLD must not be negative")
+	require(c.Health.ReadinessTimeout >= 0, "READINESS_TIMEOUT must not be negative")
+	require(c.Trash.Retention >= 0, "TRASH_RETENTION must not be negative")
+	require(c.Trash.Retention == 0 || c.Trash.PurgeInterval > 0, "TRASH_PURGE_INTERVAL must be positive when TRASH_RETENTION is set")
+	require(c.Media.Dir != "", "MEDIA_DIR is required, it is the directory of uploaded images")
+	require(c.Media.MaxUploadSize > 0, "MEDIA_MAX_UPLOAD_SIZE must be positive")
+	require(c.Media.MaxPixels > 0, "MEDIA_MAX_PIXELS must be positive")
+	require(c.Media.ThumbnailSize > 0, "MEDIA_THUMBNAIL_SIZE must be positive")
+
+	require(c.Auth.JWTSecret != "", "JWT_SECRET is required, it signs the tokens issued by /login")
+	if c.Environment == ProfileProd && c.Auth.JWTSecret != "" {
+		require(len(c.Auth.JWTSecret) >= MinProdJWTSecret && c.Auth.JWTSecret != devJWTSecret,
+			"JWT_SECRET must be a random value of at least %d characters in the prod profile", MinProdJWTSecret)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// String renders the configuration as YAML with every secret setting redacted,
+// so that it can be logged safely at startup.
+func (c Config) String() string {
+	redacted := c
+	redactSecrets(reflect.ValueOf(&redacted).Elem())
+	content, err := yaml.Marshal(redacted)
+	if err != nil {
+		return fmt.Sprintf("<unprintable configuration: %v>", err)
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// readEnvironment merges the .env file with the process environment, the latter taking precedence.
+func readEnvironment(opts Options) (map[string]string, error) {
+	env := map[string]string{}
+	dotEnv, err := godotenv.Read(opts.DotEnvFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read %s: %w", opts.DotEnvFile, err)
+	}
+	for key, value := range dotEnv {
+		env[key] = value
+	}
+	for _, entry := range opts.Environ() {
+		if key, value, found := strings.Cut(entry, "="); found {
+			env[key] = value
+		}
+	}
+	return env, nil
+}
+
+// profileFile returns the path of the profile specific sibling of a configuration file,
+// e.g. config.prod.yaml for config.yaml and the prod profile.
+func profileFile(file string, profile string) string {
+	extension := filepath.Ext(file)
+	return strings.TrimSuffix(file, extension) + "." + profile + extension
+}
+
+// decodeFile decodes a YAML or TOML file, chosen by extension, on top of the values already in cfg.
+func decodeFile(file string, cfg *Config) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(content, cfg); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+	default:
+		return fmt.Errorf("unsupported configuration file format %q, expected .yaml, .yml or .toml", filepath.Ext(file))
+	}
+	return nil
+}
+
+// applyEnvironment sets every field with an env tag whose variable is present in env.
+// Values that cannot be parsed are recorded in problems instead of aborting, so all of them are reported.
+func applyEnvironment(value reflect.Value, env map[string]string, problems *[]string) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		structField := value.Type().Field(i)
+
+		if field.Kind() == reflect.Struct {
+			applyEnvironment(field, env, problems)
+			continue
+		}
+		key := structField.Tag.Get("env")
+		raw, ok := env[key]
+		if key == "" || !ok || raw == "" {
+			continue
+		}
+		if err := setField(field, strings.TrimSpace(raw)); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", key, err))
+		}
+	}
+}
+
+// setField parses raw according to the kind of field and stores the result.
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", raw)
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int, reflect.Int64:
+		number, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(number)
+	case reflect.Float64:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(number)
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(flag)
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
+
+// redactSecrets replaces the value of every non-empty string field tagged secret:"true".
+func redactSecrets(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		if field.Kind() == reflect.Struct {
+			redactSecrets(field)
+			continue
+		}
+		if value.Type().Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.String && field.String() != "" {
+			field.SetString(redactedSecret)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package config
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// environ returns an Options.Environ function serving the given variables instead of the process environment.
+func environ(variables ...string) func() []string {
+	return func() []string { return variables }
+}
+
+// writeFile creates a file with the given content in dir and returns its path.
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// requiredDatabase lists the variables needed for a configuration to pass validation.
+var requiredDatabase = []string{"DB_USER=shop", "DB_NAME=electromart"}
+
+// TestLoadDefaults checks that a configuration with only the required settings uses the dev profile defaults.
+// It also checks that a missing .env file is not an error.
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := Load(Options{DotEnvFile: filepath.Join(t.TempDir(), ".env"), Environ: environ(requiredDatabase...)})
+	assert.NoError(t, err)
+	assert.Equal(t, ProfileDev, cfg.Environment)
+	assert.Equal(t, 8081, cfg.Server.Port)
+	assert.Equal(t, 15*time.Second, cfg.Server.ReadTimeout)
+	assert.Equal(t, "debug", cfg.Log.Level)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, "shop", cfg.Database.User)
+	assert.Equal(t, 10*time.Second, cfg.Database.QueryTimeout)
+}
+
+// TestLoadPrecedence checks the precedence of the configuration sources.
+// A YAML file overrides the defaults, its profile sibling overrides the file, the .env file overrides both,
+// and the process environment overrides everything.
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "config.yaml", `
+server:
+  port: 9000
+  write_timeout: 45s
+database:
+  host: db.internal
+  user: file-user
+  name: electromart
+log:
+  level: warn
+tracing:
+  sample_ratio: 0.5
+`)
+	writeFile(t, dir, "config.test.yaml", "database:\n  host: db.test\n")
+	dotEnv := writeFile(t, dir, ".env", "DB_USER=dotenv-user\nLOG_LEVEL=error\n")
+
+	cfg, err := Load(Options{File: file, DotEnvFile: dotEnv, Environ: environ("APP_ENV=test", "LOG_LEVEL=info")})
+	assert.NoError(t, err)
+	assert.Equal(t, ProfileTest, cfg.Environment)
+	assert.Equal(t, 9000, cfg.Server.Port, "the file should override the defaults")
+	assert.Equal(t, 45*time.Second, cfg.Server.WriteTimeout, "durations should be read from the file")
+	assert.Equal(t, 5*time.Second, cfg.Server.ShutdownTimeout, "the test profile defaults should apply")
+	assert.Equal(t, "db.test", cfg.Database.Host, "the profile file should override the base file")
+	assert.Equal(t, "dotenv-user", cfg.Database.User, ".env should override the file")
+	assert.Equal(t, "info", cfg.Log.Level, "the environment should override .env")
+	assert.Equal(t, 0.5, cfg.Tracing.SampleRatio)
+}
+
+// TestLoadTOML checks that a TOML file selected through CONFIG_FILE is decoded.
+func TestLoadTOML(t *testing.T) {
+	dir := t.TempDir()
+	file := writeFile(t, dir, "config.toml", `
+[database]
+user = "shop"
+name = "electromart"
+port = 5432
+
+[health]
+readiness_timeout = "3s"
+`)
+
+	cfg, err := Load(Options{DotEnvFile: filepath.Join(dir, ".env"), Environ: environ("CONFIG_FILE=" + file)})
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, cfg.Database.Port)
+	assert.Equal(t, 3*time.Second, cfg.Health.ReadinessTimeout)
+}
+
+// TestLoadInvalidFile checks that unreadable, malformed and unsupported configuration files are reported.
+func TestLoadInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	dotEnv := filepath.Join(dir, ".env")
+
+	_, err := Load(Options{File: filepath.Join(dir, "missing.yaml"), DotEnvFile: dotEnv, Environ: environ()})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Load(Options{File: writeFile(t, dir, "broken.yaml", "server: [port"), DotEnvFile: dotEnv, Environ: environ()})
+	assert.ErrorContains(t, err, "failed to parse")
+
+	_, err = Load(Options{File: writeFile(t, dir, "config.json", "{}"), DotEnvFile: dotEnv, Environ: environ()})
+	assert.ErrorContains(t, err, "unsupported configuration file format")
+}
+
+// TestLoadReportsAllProblems checks that Load fails with a ValidationError listing every problem at once,
+// including values that cannot be parsed and missing required settings.
+func TestLoadReportsAllProblems(t *testing.T) {
+	_, err := Load(Options{
+		DotEnvFile: filepath.Join(t.TempDir(), ".env"),
+		Environ:    environ("APP_ENV=prod", "PORT=http", "HTTP_READ_TIMEOUT=soon", "TRACING_INSECURE=maybe", "TRASH_PURGE_INTERVAL=0s"),
+	})
+
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(err, &validationErr)) {
+		assert.Contains(t, validationErr.Problems, `PORT: invalid integer "http"`)
+		assert.Contains(t, validationErr.Problems, `HTTP_READ_TIMEOUT: invalid duration "soon"`)
+		assert.Contains(t, validationErr.Problems, `TRACING_INSECURE: invalid boolean "maybe"`)
+		assert.Contains(t, validationErr.Problems, "DB_USER is required")
+		assert.Contains(t, validationErr.Problems, "DB_NAME is required")
+		assert.Contains(t, validationErr.Problems, "DB_PASSWORD is required in the prod profile")
+		assert.Contains(t, validationErr.Problems, "JWT_SECRET is required, it signs the tokens issued by /login")
+		assert.Contains(t, validationErr.Problems, "TRASH_PURGE_INTERVAL must be positive when TRASH_RETENTION is set")
+	}
+	assert.Contains(t, err.Error(), "invalid configuration:")
+}
+
+// TestValidate checks the range and consistency checks of Validate on an otherwise valid configuration.
+func TestValidate(t *testing.T) {
+	cfg := Defaults(ProfileDev)
+	cfg.Database.User = "shop"
+	cfg.Database.Name = "electromart"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Environment = "staging"
+	cfg.Server.Port = 70000
+	cfg.Server.TLSCertFile = "cert.pem"
+	cfg.Log.Level = "verbose"
+	cfg.Tracing.Exporter = "file"
+	cfg.Tracing.SampleRatio = 2
+	cfg.Server.NodeID = 32
+	cfg.Media.MaxUploadSize = 0
+
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(cfg.Validate(), &validationErr)) {
+		assert.Len(t, validationErr.Problems, 8)
+		assert.Contains(t, validationErr.Problems, "NODE_ID must be between 0 and 31, got 32")
+		assert.Contains(t, validationErr.Problems, "MEDIA_MAX_UPLOAD_SIZE must be positive")
+	}
+}
+
+// TestConfigStringRedactsSecrets checks that printing the configuration hides the database password
+// while keeping the other settings readable.
+func TestConfigStringRedactsSecrets(t *testing.T) {
+	cfg := Defaults(ProfileDev)
+	cfg.Database.User = "shop"
+	cfg.Database.Password = "hunter2"
+	cfg.Auth.JWTSecret = "correct-horse-battery-staple"
+
+	printed := cfg.String()
+	assert.NotContains(t, printed, "hunter2")
+	assert.NotContains(t, printed, "correct-horse-battery-staple")
+	assert.Contains(t, printed, redactedSecret)
+	assert.Contains(t, printed, "user: shop")
+	assert.Equal(t, "hunter2", cfg.Database.Password, "String should not modify the configuration")
+}
+
+// TestValidateJWTSecret checks that prod has no JWT secret of its own and refuses short ones, while the dev
+// profile comes with a development secret.
+func TestValidateJWTSecret(t *testing.T) {
+	dev := Defaults(ProfileDev)
+	assert.NotEmpty(t, dev.Auth.JWTSecret)
+
+	cfg := Defaults(ProfileProd)
+	cfg.Database = DatabaseConfig{Driver: DriverSQLite, Name: "electromart.db"}
+	cfg.Server.NodeID = 1
+	assert.ErrorContains(t, cfg.Validate(), "JWT_SECRET is required")
+	cfg.Auth.JWTSecret = dev.Auth.JWTSecret
+	assert.ErrorContains(t, cfg.Validate(), "JWT_SECRET must be a random value of at least 32 characters")
+	cfg.Auth.JWTSecret = strings.Repeat("k", MinProdJWTSecret)
+	assert.NoError(t, cfg.Validate())
+}
+
+// TestValidateNodeID checks that prod has no node ID of its own, so that each instance must be given one, while
+// the dev profile defaults to node 0.
+func TestValidateNodeID(t *testing.T) {
+	assert.Equal(t, 0, Defaults(ProfileDev).Server.NodeID)
+
+	cfg := Defaults(ProfileProd)
+	cfg.Database = DatabaseConfig{Driver: DriverSQLite, Name: "electromart.db"}
+	cfg.Auth.JWTSecret = strings.Repeat("k", MinProdJWTSecret)
+	var validationErr *ValidationError
+	if assert.True(t, errors.As(cfg.Validate(), &validationErr)) {
+		assert.Equal(t, []string{"NODE_ID is required in the prod profile, it must differ between instances sharing a database"},
+			validationErr.Problems)
+	}
+	cfg.Server.NodeID = 0
+	assert.NoError(t, cfg.Validate(), "node 0 can be chosen explicitly")
+}
+
+// TestValidateSQLite checks that SQLite only requires the database file name,
+// and that unknown drivers are rejected.
+func TestValidateSQLite(t *testing.T) {
+	cfg := Defaults(ProfileProd)
+	cfg.Database = DatabaseConfig{Driver: DriverSQLite, Name: "electromart.db"}
+	cfg.Server.NodeID = 1
+	cfg.Auth.JWTSecret = strings.Repeat("k", MinProdJWTSecret)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Database.Name = ""
+	assert.ErrorContains(t, cfg.Validate(), "path of the SQLite database file")
+
+	cfg.Database.Driver = "oracle"
+	assert.ErrorContains(t, cfg.Validate(), "DB_DRIVER must be one of mysql, postgres, sqlite")
+}
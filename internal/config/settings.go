@@ -0,0 +1,186 @@
+package config
+
+import (
+	"time"
+)
+
+// Supported values for Config.Environment. Each profile comes with its own defaults, see Defaults.
+const (
+	ProfileDev  = "dev"
+	ProfileTest = "test"
+	ProfileProd = "prod"
+)
+
+// Config is the typed configuration of the application.
+// Every setting can come from the built-in profile defaults, a YAML or TOML file, the .env file
+// or the process environment, in increasing order of precedence (see Load).
+// The env tag names the environment variable of a setting and secret marks values hidden by String.
+type Config struct {
+	Environment string         `yaml:"environment" toml:"environment" env:"APP_ENV"`
+	Server      ServerConfig   `yaml:"server" toml:"server"`
+	Database    DatabaseConfig `yaml:"database" toml:"database"`
+	Log         LogConfig      `yaml:"log" toml:"log"`
+	Tracing     TracingConfig  `yaml:"tracing" toml:"tracing"`
+	Metrics     MetricsConfig  `yaml:"metrics" toml:"metrics"`
+	Health      HealthConfig   `yaml:"health" toml:"health"`
+	Trash       TrashConfig    `yaml:"trash" toml:"trash"`
+	Media       MediaConfig    `yaml:"media" toml:"media"`
+	Auth        AuthConfig     `yaml:"auth" toml:"auth"`
+}
+
+// ServerConfig holds the HTTP server settings.
+// RequireIfMatch refuses updates and deletes that do not carry the ETag of the record they modify.
+// NodeID is encoded in the IDs of the rows created by this instance, so instances sharing a database need different ones.
+// It defaults to 0 in the dev and test profiles and must be set in prod, where instances run side by side.
+type ServerConfig struct {
+	Port              int           `yaml:"port" toml:"port" env:"PORT"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" toml:"read_timeout" env:"HTTP_READ_TIMEOUT"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" toml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" toml:"write_timeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" toml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes" toml:"max_header_bytes" env:"HTTP_MAX_HEADER_BYTES"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	HookTimeout       time.Duration `yaml:"shutdown_hook_timeout" toml:"shutdown_hook_timeout" env:"SHUTDOWN_HOOK_TIMEOUT"`
+	TLSCertFile       string        `yaml:"tls_cert_file" toml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile        string        `yaml:"tls_key_file" toml:"tls_key_file" env:"TLS_KEY_FILE"`
+	RequireIfMatch    bool          `yaml:"require_if_match" toml:"require_if_match" env:"REQUIRE_IF_MATCH"`
+	NodeID            int           `yaml:"node_id" toml:"node_id" env:"NODE_ID"`
+}
+
+// Supported values for DatabaseConfig.Driver.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DatabaseConfig holds the database connection and pool settings.
+// For SQLite, Name is the path of the database file and the network settings are ignored.
+// A zero Port selects the default port of the driver.
+// AutoMigrate applies pending schema migrations at startup instead of refusing to serve.
+// QueryTimeout bounds the time the queries of a request may take, zero for no bound.
+type DatabaseConfig struct {
+	Driver          string        `yaml:"driver" toml:"driver" env:"DB_DRIVER"`
+	Host            string        `yaml:"host" toml:"host" env:"DB_HOST"`
+	Port            int           `yaml:"port" toml:"port" env:"DB_PORT"`
+	User            string        `yaml:"user" toml:"user" env:"DB_USER"`
+	Password        string        `yaml:"password" toml:"password" env:"DB_PASSWORD" secret:"true"`
+	Name            string        `yaml:"name" toml:"name" env:"DB_NAME"`
+	SSLMode         string        `yaml:"ssl_mode" toml:"ssl_mode" env:"DB_SSLMODE"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME"`
+	AutoMigrate     bool          `yaml:"auto_migrate" toml:"auto_migrate" env:"DB_AUTO_MIGRATE"`
+	QueryTimeout    time.Duration `yaml:"query_timeout" toml:"query_timeout" env:"DB_QUERY_TIMEOUT"`
+}
+
+// LogConfig holds the logging settings.
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level" env:"LOG_LEVEL"`
+}
+
+// TracingConfig holds the OpenTelemetry tracing settings.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" toml:"exporter" env:"TRACING_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" toml:"endpoint" env:"TRACING_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" toml:"insecure" env:"TRACING_INSECURE"`
+	File        string  `yaml:"file" toml:"file" env:"TRACING_FILE"`
+	SampleRatio float64 `yaml:"sample_ratio" toml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	ServiceName string  `yaml:"service_name" toml:"service_name" env:"OTEL_SERVICE_NAME"`
+}
+
+// MetricsConfig holds the Prometheus metrics settings.
+type MetricsConfig struct {
+	LowStockThreshold int `yaml:"low_stock_threshold" toml:"low_stock_threshold" env:"LOW_STOCK_THRESHOLD"`
+}
+
+// HealthConfig holds the health check settings.
+type HealthConfig struct {
+	ReadinessTimeout time.Duration `yaml:"readiness_timeout" toml:"readiness_timeout" env:"READINESS_TIMEOUT"`
+}
+
+// TrashConfig holds the settings of the job purging deleted records.
+// Records stay restorable for Retention after their deletion; a zero Retention disables the purge.
+type TrashConfig struct {
+	Retention     time.Duration `yaml:"retention" toml:"retention" env:"TRASH_RETENTION"`
+	PurgeInterval time.Duration `yaml:"purge_interval" toml:"purge_interval" env:"TRASH_PURGE_INTERVAL"`
+}
+
+// MediaConfig holds the settings of uploaded images. Their files and thumbnails are stored below Dir.
+// Uploads over MaxUploadSize bytes or MaxPixels pixels are refused, and thumbnails fit in a square of ThumbnailSize pixels.
+type MediaConfig struct {
+	Dir           string `yaml:"dir" toml:"dir" env:"MEDIA_DIR"`
+	MaxUploadSize int64  `yaml:"max_upload_size" toml:"max_upload_size" env:"MEDIA_MAX_UPLOAD_SIZE"`
+	MaxPixels     int    `yaml:"max_pixels" toml:"max_pixels" env:"MEDIA_MAX_PIXELS"`
+	ThumbnailSize int    `yaml:"thumbnail_size" toml:"thumbnail_size" env:"MEDIA_THUMBNAIL_SIZE"`
+}
+
+// AuthConfig holds the authentication settings. JWTSecret signs and verifies the tokens issued by /login;
+// changing it invalidates every token issued before.
+type AuthConfig struct {
+	JWTSecret string `yaml:"jwt_secret" toml:"jwt_secret" env:"JWT_SECRET" secret:"true"`
+}
+
+// devJWTSecret is the JWT secret of the dev and test profiles. It is public, so prod has none and requires one.
+const devJWTSecret = "electromart-dev-only-jwt-secret"
+
+// MinProdJWTSecret is the shortest JWT secret accepted in the prod profile.
+const MinProdJWTSecret = 32
+
+// unsetNodeID is the node ID of the prod profile, which has none so that NODE_ID must be set.
+const unsetNodeID = -1
+
+// Defaults returns the default configuration of the given profile.
+// Development logs at debug level, test only logs warnings and prod logs at info level and samples 10% of traces.
+// Dev and test sign tokens with a development JWT secret, while prod has none, so that JWT_SECRET must be set.
+// Likewise prod has no node ID, so that every instance is given its own NODE_ID.
+// Unknown profiles get the development defaults, and are later rejected by Validate.
+func Defaults(profile string) Config {
+	cfg := Config{
+		Environment: profile,
+		Server: ServerConfig{
+			Port:              8081,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   30 * time.Second,
+			HookTimeout:       10 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Driver:          DriverMySQL,
+			Host:            "localhost",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			QueryTimeout:    10 * time.Second,
+		},
+		Log: LogConfig{Level: "debug"},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			SampleRatio: 1,
+			ServiceName: "electromart-api",
+		},
+		Metrics: MetricsConfig{LowStockThreshold: 5},
+		Health:  HealthConfig{ReadinessTimeout: 2 * time.Second},
+		Trash:   TrashConfig{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+		Media:   MediaConfig{Dir: "media", MaxUploadSize: 5 << 20, MaxPixels: 25_000_000, ThumbnailSize: 320},
+		Auth:    AuthConfig{JWTSecret: devJWTSecret},
+	}
+
+	switch profile {
+	case ProfileTest:
+		cfg.Log.Level = "warn"
+		cfg.Server.ShutdownTimeout = 5 * time.Second
+	case ProfileProd:
+		cfg.Log.Level = "info"
+		cfg.Tracing.SampleRatio = 0.1
+		cfg.Auth.JWTSecret = ""
+		cfg.Server.NodeID = unsetNodeID
+	}
+	return cfg
+}
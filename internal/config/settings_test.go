@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// TestDefaults checks the settings that differ between the dev, test and prod profiles,
+// and that unknown profiles fall back to the dev defaults.
+func TestDefaults(t *testing.T) {
+	dev := Defaults(ProfileDev)
+	assert.Equal(t, "debug", dev.Log.Level)
+	assert.Equal(t, 1.0, dev.Tracing.SampleRatio)
+	assert.Equal(t, 30*time.Second, dev.Server.ShutdownTimeout)
+	assert.Equal(t, 10*time.Second, dev.Server.HookTimeout)
+
+	test := Defaults(ProfileTest)
+	assert.Equal(t, "warn", test.Log.Level)
+	assert.Equal(t, 5*time.Second, test.Server.ShutdownTimeout)
+
+	prod := Defaults(ProfileProd)
+	assert.Equal(t, "info", prod.Log.Level)
+	assert.Equal(t, 0.1, prod.Tracing.SampleRatio)
+
+	unknown := Defaults("staging")
+	assert.Equal(t, "staging", unknown.Environment)
+	assert.Equal(t, dev.Log.Level, unknown.Log.Level)
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"E-Commerce_Website_Database/internal/config"
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestDSN checks the data source name built for every driver, including escaping of special characters
+// in the password and the default port used when none is configured.
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.DatabaseConfig
+		expected string
+	}{
+		{
+			name:     "mysql",
+			cfg:      config.DatabaseConfig{Driver: "mysql", Host: "db", User: "shop", Password: "secret", Name: "electromart"},
+			expected: "shop:secret@tcp(db:3306)/electromart?parseTime=true",
+		},
+		{
+			name:     "mysql is the default driver",
+			cfg:      config.DatabaseConfig{Host: "db", Port: 3307, User: "shop", Name: "electromart"},
+			expected: "shop@tcp(db:3307)/electromart?parseTime=true",
+		},
+		{
+			name:     "postgres",
+			cfg:      config.DatabaseConfig{Driver: "postgres", Host: "db", User: "shop", Password: "p@ss/word", Name: "electromart", SSLMode: "disable"},
+			expected: "postgres://shop:p%40ss%2Fword@db:5432/electromart?sslmode=disable",
+		},
+		{
+			name:     "sqlite",
+			cfg:      config.DatabaseConfig{Driver: "SQLite", Name: "electromart.db"},
+			expected: "file:electromart.db?_busy_timeout=5000&_foreign_keys=on",
+		},
+		{
+			name:     "sqlite with parameters",
+			cfg:      config.DatabaseConfig{Driver: "sqlite", Name: "file::memory:?cache=shared"},
+			expected: "file::memory:?cache=shared&_busy_timeout=5000&_foreign_keys=on",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := DSN(tt.cfg)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, dsn)
+		})
+	}
+}
+
+// TestDSNUnsupportedDriver checks that an unknown driver is rejected by DSN and Open.
+func TestDSNUnsupportedDriver(t *testing.T) {
+	_, err := DSN(config.DatabaseConfig{Driver: "oracle"})
+	assert.ErrorContains(t, err, "unsupported database driver")
+
+	_, err = Open(config.DatabaseConfig{Driver: "oracle"}, nil)
+	assert.Error(t, err)
+}
+
+// TestOpenSQLite opens a SQLite database file and checks that the pool settings and foreign keys are applied.
+func TestOpenSQLite(t *testing.T) {
+	db, err := Open(config.DatabaseConfig{
+		Driver:          config.DriverSQLite,
+		Name:            filepath.Join(t.TempDir(), "electromart.db"),
+		MaxOpenConns:    4,
+		MaxIdleConns:    2,
+		ConnMaxLifetime: time.Minute,
+	}, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	defer sqlDB.Close()
+
+	assert.Equal(t, 4, sqlDB.Stats().MaxOpenConnections)
+
+	var foreignKeys int
+	assert.NoError(t, db.Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error)
+	assert.Equal(t, 1, foreignKeys)
+}
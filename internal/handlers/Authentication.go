@@ -1,19 +1,25 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/service"
 	"E-Commerce_Website_Database/internal/tools"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 	"net/http"
 )
 
-// PostLogin handles the login request.
+// AuthHandler serves the login and the routes of logged in users.
+type AuthHandler struct {
+	users        *service.Users
+	tokenService tools.TokenService
+}
+
+// Login handles the login request.
 // It validates the user credentials and generates a JWT token if the credentials are correct.
 // It sends an HTTP 200 OK response with the token if successful.
 // In case of incorrect credentials, it sends an HTTP 401 Unauthorized response.
 // If there is a server error, it sends an HTTP 500 Internal Server Error.
-func PostLogin(c *gin.Context, db *gorm.DB, tokenService tools.TokenService) {
+func (h *AuthHandler) Login(c *gin.Context) {
 	var loginCredentials struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
@@ -21,34 +27,35 @@ func PostLogin(c *gin.Context, db *gorm.DB, tokenService tools.TokenService) {
 
 	// Attempt to bind JSON payload to struct
 	if err := c.ShouldBindJSON(&loginCredentials); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "incorrect parameters"})
+		c.Error(apperr.BadRequest("incorrect parameters", err))
 		return
 	}
 
-	// Fetch user from database based on username
-	user, err := GetUserByUN(loginCredentials.Username, db)
+	user, err := h.users.Authenticate(c.Request.Context(), loginCredentials.Username, loginCredentials.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "server error"})
-		return
-	}
-	if user == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication failed", "details": "user not found"})
-		return
-	}
-
-	// Compare provided password with the hashed password from database
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginCredentials.Password)); err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "authentication failed", "details": "incorrect password"})
+		c.Error(err)
 		return
 	}
 
 	// Generate token with claims
-	tokenString, err := tokenService.GenerateTokenWithClaims(user.Username, user.Role)
+	tokenString, err := h.tokenService.GenerateTokenWithClaims(user.Username, user.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not generate token"})
+		c.Error(apperr.Internal("could not generate token", err))
 		return
 	}
 
 	// Return the token string in response
 	c.JSON(http.StatusOK, gin.H{"token": tokenString})
 }
+
+// Protected answers the username and role of the user logged in with the token checked by tools.TokenAuthMiddleware.
+// A user deleted since the token was issued is answered with HTTP 404 Not Found.
+func (h *AuthHandler) Protected(c *gin.Context) {
+	username := c.MustGet("username").(string)
+	user, err := h.users.GetByUsername(c.Request.Context(), username)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"username": username, "role": user.Role})
+}
@@ -14,6 +14,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
+	"E-Commerce_Website_Database/internal/storage"
 )
 
 // Set up your mock token service
@@ -66,7 +72,7 @@ func TestPostLogin(t *testing.T) {
 			username:         "nonexistent",
 			password:         "password",
 			expectedStatus:   http.StatusInternalServerError,
-			expectedResponse: `"error":"server error"`,
+			expectedResponse: `"detail":"server error"`,
 		},
 		{
 			name: "Incorrect password",
@@ -78,7 +84,7 @@ func TestPostLogin(t *testing.T) {
 			username:         "user",
 			password:         "wrongpassword",
 			expectedStatus:   http.StatusUnauthorized,
-			expectedResponse: `"error":"authentication failed"`,
+			expectedResponse: `"detail":"authentication failed"`,
 		},
 		{
 			name: "Token generation failure",
@@ -90,7 +96,7 @@ func TestPostLogin(t *testing.T) {
 			username:         "user",
 			password:         "correctpassword",
 			expectedStatus:   http.StatusInternalServerError,
-			expectedResponse: `"error":"could not generate token"`,
+			expectedResponse: `"detail":"could not generate token"`,
 			tokenError:       errors.New("token generation failed"),
 		},
 	}
@@ -98,6 +104,7 @@ func TestPostLogin(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			router := gin.Default()
+			router.Use(middleware.Errors())
 			mockTokenService := new(MockTokenService)
 			if tc.tokenError == nil {
 				mockTokenService.On("GenerateTokenWithClaims", tc.username, "user").Return("mockedtoken", nil)
@@ -109,7 +116,7 @@ func TestPostLogin(t *testing.T) {
 				db, mock, _ := sqlmock.New()
 				gormDB, _ := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
 				tc.setupMock(gormDB, mock)
-				PostLogin(c, gormDB, mockTokenService)
+				New(service.New(repository.NewGORM(gormDB), storage.NewMemory(), imaging.DefaultLimits), mockTokenService).Auth.Login(c)
 			})
 
 			bodyData := map[string]string{"username": tc.username, "password": tc.password}
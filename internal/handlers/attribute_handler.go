@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AttributeHandler serves the attribute routes.
+type AttributeHandler struct {
+	attributes *service.Attributes
+}
+
+// Get fetches a single attribute by the ID provided in the URL.
+// It responds with HTTP 404 Not Found if the attribute does not exist.
+func (h *AttributeHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	attribute, err := h.attributes.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, attribute)
+}
+
+// List retrieves the attributes of every category, an empty list if there are none.
+func (h *AttributeHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	attributes, err := h.attributes.List(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, attributes)
+}
+
+// Search retrieves the attributes matching the query string: name and label match a substring, type and category_id
+// are compared exactly. It responds with an empty list if no attributes match.
+func (h *AttributeHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	searchParams := map[string]interface{}{}
+
+	for _, field := range []string{"name", "label", "type", "category_id"} {
+		if value := c.Query(field); value != "" {
+			cleanValue := strings.TrimSpace(value)
+			if field != "category_id" {
+				searchParams[field] = cleanValue
+			} else if numVal, err := strconv.Atoi(cleanValue); err == nil {
+				searchParams[field] = numVal
+			}
+		}
+	}
+
+	attributes, err := h.attributes.Search(c.Request.Context(), searchParams, q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, attributes)
+}
+
+// Create adds an attribute to a category from JSON input.
+// It responds with HTTP 201 Created and the attribute, or HTTP 400 Bad Request if the input is invalid,
+// e.g. a name already taken along the branch of the category.
+func (h *AttributeHandler) Create(c *gin.Context) {
+	var newAttribute models.Attribute
+	if err := c.ShouldBindJSON(&newAttribute); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+
+	attribute, err := h.attributes.Create(c.Request.Context(), newAttribute)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attribute)
+}
+
+// Update replaces the attribute with the ID provided in the URL by the JSON input.
+// It responds like Create, with HTTP 200 OK on success and HTTP 404 Not Found if the attribute does not exist;
+// the category and type of an attribute products have values of cannot change.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *AttributeHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	attribute, err := h.attributes.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, attribute.Version) {
+		return
+	}
+
+	var updatedAttribute models.Attribute
+	if err := c.ShouldBindJSON(&updatedAttribute); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+
+	if err := h.attributes.Update(ctx, attribute, updatedAttribute); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, attribute)
+}
+
+// Patch applies a JSON merge patch (RFC 7396) to the attribute with the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *AttributeHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	attribute, err := h.attributes.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, attribute.Version) {
+		return
+	}
+
+	fields, ok := applyMergePatch(c, attribute, attributePatchFields)
+	if !ok {
+		return
+	}
+	if err := h.attributes.Patch(ctx, attribute, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, attribute)
+}
+
+// Delete moves the attribute with the ID provided in the URL to the trash, responding with HTTP 204 No Content.
+// The values of products are kept with it, hidden from their specification sheets until it is restored.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *AttributeHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.attributes, paramID(c))
+}
+
+// Restore takes a deleted attribute out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored attribute, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if its category is still deleted.
+func (h *AttributeHandler) Restore(c *gin.Context) {
+	attribute, err := h.attributes.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, attribute)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setupRouterAndDBAttribute sets up the router and database in memory, including the migration of Category,
+// Product, Attribute and ProductAttribute models, with unique index violations reported as conflicts.
+// It returns the router, database, and a teardown function to clean up the database after tests finish.
+func setupRouterAndDBAttribute(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Errors())
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.Category{}, &models.Product{}, &models.Attribute{}, &models.ProductAttribute{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	// Function to clean up the database after tests finish
+	teardown := func() {
+		if err := db.Migrator().DropTable(&models.Category{}, &models.Product{}, &models.Attribute{}, &models.ProductAttribute{}); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	return router, db, teardown
+}
+
+// TestAttributeIntegration checks the attribute routes: defining attributes on a category and its child, filling
+// the specification sheets of products, filtering products by their values, counting facets and listing the
+// attributes of a category.
+func TestAttributeIntegration(t *testing.T) {
+	router, db, teardown := setupRouterAndDBAttribute(t)
+	defer teardown()
+
+	computers := models.Category{Model: gorm.Model{ID: 1}, Name: "Computers", Path: models.CategoryPath(nil, 1)}
+	db.Create(&computers)
+	db.Create(&models.Category{Model: gorm.Model{ID: 2}, Name: "Laptops", Parent_ID: &computers.ID, Path: models.CategoryPath(&computers, 2)})
+	db.Create(&models.Product{Model: gorm.Model{ID: 1}, Name: "Laptop", Price: 1299, Category_ID: 2})
+	db.Create(&models.Product{Model: gorm.Model{ID: 2}, Name: "Netbook", Price: 399, Category_ID: 2})
+	db.Create(&models.Product{Model: gorm.Model{ID: 3}, Name: "Desktop", Price: 999, Category_ID: 1})
+
+	h := newHandlers(db)
+	router.POST("/attributes", h.Attributes.Create)
+	router.GET("/products", h.Products.List)
+	router.GET("/products/:id", h.Products.Get)
+	router.PUT("/products/:id/attributes", h.Products.SetAttributes)
+	router.GET("/product-facets", h.Products.Facets)
+	router.GET("/categories/:id/products", h.Categories.Products)
+	router.GET("/categories/:id/facets", h.Categories.Facets)
+	router.GET("/categories/:id/attributes", h.Categories.Attributes)
+	request := func(method, url, body string) (*httptest.ResponseRecorder, interface{}) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	rr, _ := request("POST", "/attributes", `{"category_id": 1, "name": "ram_gb", "label": "RAM", "type": "number", "unit": "GB"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr, _ = request("POST", "/attributes", `{"category_id": 2, "name": "wifi", "label": "Wi-Fi", "type": "boolean"}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr, response := request("POST", "/attributes", `{"category_id": 2, "name": "ram_gb", "label": "Memory", "type": "number"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "a name should differ along a branch")
+	assert.Contains(t, response.(map[string]interface{})["errors"], "name")
+
+	rr, _ = request("PUT", "/products/1/attributes", `{"ram_gb": 32, "wifi": true}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr, _ = request("PUT", "/products/2/attributes", `{"ram_gb": 8, "wifi": true}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr, _ = request("PUT", "/products/3/attributes", `{"ram_gb": 16}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr, response = request("PUT", "/products/3/attributes", `{"wifi": true}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "a desktop should only have the attributes of its category")
+	assert.Contains(t, response.(map[string]interface{})["errors"], "wifi")
+	rr, response = request("GET", "/products/3", "")
+	assert.Equal(t, 2.0, response.(map[string]interface{})["version"], "replacing the sheet should increment the version")
+	req, _ := http.NewRequest("PUT", "/products/3/attributes", strings.NewReader(`{"ram_gb": 64}`))
+	req.Header.Set("If-Match", `"1"`)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+	rr, response = request("GET", "/products?ram_gb[gte]=16", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response, 2)
+	rr, response = request("GET", "/products?ram_gb[gte]=16&wifi=true", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response, 1)
+	rr, response = request("GET", "/products?weight_kg[eq]=2", "")
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, response.(map[string]interface{})["errors"], "weight_kg[eq]")
+	rr, response = request("GET", "/products?page=2&sort=price&_=1700000000", "")
+	assert.Equal(t, http.StatusOK, rr.Code, "parameters that are not filters should be ignored")
+	assert.Len(t, response, 3)
+	rr, response = request("GET", "/categories/1/products?ram_gb[gte]=16&page=2", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response, 2)
+
+	rr, response = request("GET", "/products/1?include=attributes", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response.(map[string]interface{})["attributes"], 2)
+
+	rr, response = request("GET", "/product-facets?wifi=true", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.Len(t, response, 2) {
+		ram := response.([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "ram_gb", ram["name"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"value": 8.0, "count": 1.0},
+			map[string]interface{}{"value": 32.0, "count": 1.0},
+		}, ram["values"])
+	}
+	rr, response = request("GET", "/categories/1/facets?ram_gb[lt]=16", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if assert.Len(t, response, 2) {
+		wifi := response.([]interface{})[1].(map[string]interface{})
+		assert.Equal(t, []interface{}{map[string]interface{}{"value": true, "count": 1.0}}, wifi["values"])
+	}
+
+	rr, response = request("GET", "/categories/2/attributes", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response, 2)
+	rr, response = request("GET", "/categories/1/attributes", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response, 1)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// Page sizes of the audit log: entries returned when no limit is given, and the most that can be requested.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler serves the audit log.
+type AuditHandler struct {
+	audit *service.Audit
+}
+
+// List lists the audit log entries, most recent first, filtered by the entity, id and actor query parameters.
+// The limit query parameter caps the number of entries, 100 by default and at most 1000.
+// Unknown entities or malformed numbers are answered with HTTP 400 Bad Request, and a failing query with HTTP 500.
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := audit.Filter{Entity: c.Query("entity"), Actor: c.Query("actor"), Limit: defaultAuditLimit}
+	if filter.Entity != "" && !knownEntity(filter.Entity) {
+		c.Error(apperr.New(apperr.KindBadRequest, "Invalid entity: unknown entity "+strconv.Quote(filter.Entity)))
+		return
+	}
+	if id := c.Query("id"); id != "" {
+		entityID, err := strconv.ParseUint(id, 10, 64)
+		if err != nil || entityID == 0 {
+			c.Error(apperr.New(apperr.KindBadRequest, "Invalid id: id must be a positive integer"))
+			return
+		}
+		filter.EntityID = uint(entityID)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > maxAuditLimit {
+			c.Error(apperr.New(apperr.KindBadRequest, "Invalid limit: limit must be between 1 and "+strconv.Itoa(maxAuditLimit)))
+			return
+		}
+		filter.Limit = n
+	}
+
+	entries, err := h.audit.Find(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// knownEntity reports whether entity is the name of an audited entity.
+func knownEntity(entity string) bool {
+	for _, name := range audit.Entities {
+		if name == entity {
+			return true
+		}
+	}
+	return false
+}
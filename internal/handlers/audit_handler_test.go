@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+)
+
+// setupRouterAndDBAudit sets up the router and an in-memory database with the audit plugin installed,
+// and returns a function to clean up the database after the tests.
+func setupRouterAndDBAudit(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Errors())
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Brands{}, &models.Product{}, &audit.Entry{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	if err := db.Use(&audit.Plugin{}); err != nil {
+		t.Fatalf("failed to install the audit plugin: %v", err)
+	}
+
+	teardown := func() {
+		if err := db.Migrator().DropTable(&models.Brands{}, &models.Product{}, &audit.Entry{}); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	return router, db, teardown
+}
+
+// TestGetAuditLog checks that the audit log of one brand lists its creation and update, most recent first,
+// and that unknown entities and out of range limits are answered with HTTP 400.
+func TestGetAuditLog(t *testing.T) {
+	router, db, teardown := setupRouterAndDBAudit(t)
+	defer teardown()
+
+	brand := models.Brands{Name: "Acme"}
+	db.Create(&brand)
+	db.Model(&brand).Update("name", "Acme Corp")
+	db.Create(&models.Brands{Name: "Other"})
+
+	router.GET("/audit", newHandlers(db).Audit.List)
+
+	req, _ := http.NewRequest("GET", "/audit?entity=brand&id=1", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var entries []audit.Entry
+	if err := json.Unmarshal(rr.Body.Bytes(), &entries); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, audit.ActionUpdate, entries[0].Action)
+		assert.Equal(t, map[string]audit.Change{"name": {Before: "Acme", After: "Acme Corp"}}, entries[0].Changes)
+		assert.Equal(t, audit.ActionCreate, entries[1].Action)
+	}
+
+	for _, query := range []string{"entity=widget", "id=abc", "limit=0", "limit=1001"} {
+		req, _ := http.NewRequest("GET", "/audit?"+query, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
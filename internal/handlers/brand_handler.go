@@ -1,45 +1,61 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strings"
 )
 
-// GetBrand fetches a single brand based on the ID provided in the URL.
+// BrandHandler serves the brand routes.
+type BrandHandler struct {
+	brands *service.Brands
+	media  *service.Media
+}
+
+// Get fetches a single brand based on the ID provided in the URL.
 // It returns the brand if found or appropriate error messages for missing ID or not found scenarios.
 // In case of an error, it sends an HTTP 500 Internal Server Error.
-func GetBrand(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var brand models.Brands
-
-	if err := db.Where("id = ?", id).First(&brand).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+func (h *BrandHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, brand)
+	brand, err := h.brands.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, brand)
 }
 
-// GetBrands retrieves all brands from the database.
-// It sends an HTTP 200 OK response with a list of brands or a message if no brands exist.
+// List retrieves all brands from the database.
+// It sends an HTTP 200 OK response with a list of brands, empty if there are none.
 // In case of an error, it sends an HTTP 500 Internal Server Error.
-func GetBrands(c *gin.Context, db *gorm.DB) {
-	brands, err := models.GetAllBrands(db)
+func (h *BrandHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	brands, err := h.brands.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving brands"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, brands)
+	respondWithETag(c, brands)
 }
 
-// SearchAllBrands retrieves all brands from the database based on the search parameters provided in the query string.
-// It responds with a list of brands if successful or an informational message if no brands exist.
+// Search retrieves all brands from the database based on the search parameters provided in the query string.
+// It responds with a list of brands if successful, empty if no brands match.
 // On failure, it returns an HTTP 500 Internal Server Error.
-func SearchAllBrands(c *gin.Context, db *gorm.DB) {
+func (h *BrandHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"name", "description"} {
@@ -49,121 +65,156 @@ func SearchAllBrands(c *gin.Context, db *gorm.DB) {
 		}
 	}
 
-	brands, err := models.SearchBrand(db, searchParams)
+	brands, err := h.brands.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve brands", "details": err.Error()})
-		return
-	}
-	if !tools.CheckString(brands.Name, 250) && !tools.CheckString(brands.Description, 1000) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No brand found"})
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusOK, brands)
+	respondWithETag(c, brands)
 }
 
-// CreateBrand adds a new brand to the database based on the JSON data provided in the request body.
+// Create adds a new brand to the database based on the JSON data provided in the request body.
 // It responds with the newly created brand or an error message if the data is invalid or creation fails.
 // The brand's name and description fields are validated for correct formatting.
 // If the brand is successfully created, it sends an HTTP 201 Created response.
 // In case of a validation error, it sends an HTTP 400 Bad Request response.
-func CreateBrand(c *gin.Context, db *gorm.DB) {
+func (h *BrandHandler) Create(c *gin.Context) {
 	var newBrand models.Brands
 	if err := c.ShouldBindJSON(&newBrand); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	brand := models.Brands{
-		Name:        newBrand.Name,
-		Description: newBrand.Description,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkBrand(brand, newBrand); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
-		return
-	}
-
-	if err := db.Create(&brand).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create brand", "details": err.Error()})
+	brand, err := h.brands.Create(c.Request.Context(), newBrand)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, brand)
 }
 
-// UpdateBrand modifies an existing brand based on the ID provided in the URL.
+// Update modifies an existing brand based on the ID provided in the URL.
 // It updates the brand's name and description with the provided data and responds accordingly.
 // If the brand is not found, it sends an HTTP 404 Not Found response.
 // If the update is successful, it sends an HTTP 200 OK response with the updated brand.
 // If the update fails, it sends an HTTP 500 Internal Server Error.
-func UpdateBrand(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.BrandExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *BrandHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	brand, err := h.brands.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, brand.Version) {
 		return
 	}
 
 	var updatedBrand models.Brands
 	if err := c.ShouldBindJSON(&updatedBrand); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var brand models.Brands
-	if err := db.Where("id = ?", id).First(&brand).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Brand not found"})
+	if err := h.brands.Update(ctx, brand, updatedBrand); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, brand)
+}
 
-	brand.Name = updatedBrand.Name
-	brand.Description = updatedBrand.Description
+// Patch applies a JSON merge patch (RFC 7396) to an existing brand based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *BrandHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	brand, err := h.brands.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, brand.Version) {
+		return
+	}
 
-	if failed, err := checkBrand(brand, updatedBrand); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+	fields, ok := applyMergePatch(c, brand, brandPatchFields)
+	if !ok {
 		return
 	}
+	if err := h.brands.Patch(ctx, brand, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, brand)
+}
 
-	if err := db.Save(&brand).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update brand", "details": err.Error()})
+// SetLogo replaces the logo of the brand with the ID provided in the URL by the image uploaded in the file field
+// of a multipart/form-data body, stored with a thumbnail.
+// It responds with HTTP 200 OK and the brand, HTTP 404 Not Found if the brand does not exist, HTTP 413 Request
+// Entity Too Large for images over the limits, or HTTP 415 Unsupported Media Type if the file is not a JPEG, PNG
+// or GIF image, whatever its name or declared type.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *BrandHandler) SetLogo(c *gin.Context) {
+	ctx := c.Request.Context()
+	brand, err := h.brands.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, brand.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, brand)
+	file, ok := readUpload(c, h.media.MaxUploadSize())
+	if !ok {
+		return
+	}
+	defer file.Close()
+	if err := h.brands.SetLogo(ctx, brand, file); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, brand)
 }
 
-// DeleteBrand removes a brand from the database based on the ID provided in the URL.
-// It responds with an HTTP 204 No Content on success or an error message if the brand is not found or if deletion fails.
-func DeleteBrand(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
-
-	if !models.BrandExists(db, convertedId) {
-		fmt.Println("Brands does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Brands not found"})
+// RemoveLogo removes the logo of the brand with the ID provided in the URL.
+// It responds with HTTP 200 OK and the brand, or HTTP 404 Not Found if the brand does not exist.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *BrandHandler) RemoveLogo(c *gin.Context) {
+	ctx := c.Request.Context()
+	brand, err := h.brands.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, brand.Version) {
 		return
 	}
 
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.Brands{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting brands"})
+	if err := h.brands.RemoveLogo(ctx, brand); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, brand)
+}
 
-	c.JSON(http.StatusNoContent, nil)
+// Delete removes a brand from the database based on the ID provided in the URL.
+// It responds with an HTTP 204 No Content on success or an error message if the brand is not found or if deletion fails.
+// A brand still used by products is not deleted and HTTP 409 Conflict lists the products referencing it.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *BrandHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.brands, paramID(c))
 }
 
-// checkBrand validates the input data for a brand and returns an error if the data is invalid.
-// It checks the brand's name and description fields for correct formatting.
-func checkBrand(brand models.Brands, newBrand models.Brands) (bool, error) {
-	switch true {
-	case !brand.SetName(newBrand.Name):
-		return true, fmt.Errorf("name is wrong formatted")
-	case !brand.SetDescription(newBrand.Description):
-		return true, fmt.Errorf("description is wrong formatted")
+// Restore takes a deleted brand out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored brand, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+func (h *BrandHandler) Restore(c *gin.Context) {
+	brand, err := h.brands.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
 	}
-	return false, nil
+	c.JSON(http.StatusOK, brand)
 }
@@ -12,7 +12,9 @@ import (
 	"strings"
 	"testing"
 
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/problem"
 )
 
 // setupRouterAndDB sets up the router and database in memory, and returns a function to clean up the database after the tests.
@@ -20,19 +22,20 @@ import (
 func setupRouterAndDB(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Brands{}); err != nil {
+	if err := db.AutoMigrate(&models.Brands{}, &models.Product{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
 	// Function to clean up the database after tests finish
 	teardown := func() {
-		if err := db.Migrator().DropTable(&models.Brands{}); err != nil {
+		if err := db.Migrator().DropTable(&models.Brands{}, &models.Product{}); err != nil {
 			t.Fatalf("failed to drop table: %v", err)
 		}
 	}
@@ -47,9 +50,7 @@ func TestGetBrandIntegration(t *testing.T) {
 
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Test Brand", Description: "Test Description"})
 
-	router.GET("/brands/:id", func(c *gin.Context) {
-		GetBrand(c, db)
-	})
+	router.GET("/brands/:id", newHandlers(db).Brands.Get)
 
 	req, _ := http.NewRequest("GET", "/brands/1", nil)
 	rr := httptest.NewRecorder()
@@ -78,9 +79,7 @@ func TestGetBrandIntegrationInvalid(t *testing.T) {
 
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Test Brand", Description: "Test Description"})
 
-	router.GET("/brands/:id", func(c *gin.Context) {
-		GetBrand(c, db)
-	})
+	router.GET("/brands/:id", newHandlers(db).Brands.Get)
 
 	req, _ := http.NewRequest("GET", "/brands/2", nil)
 	rr := httptest.NewRecorder()
@@ -93,7 +92,7 @@ func TestGetBrandIntegrationInvalid(t *testing.T) {
 
 	// Check the status code
 	assert.Equal(t, http.StatusNotFound, rr.Code)
-	assert.Contains(t, response["error"], "Brand not found")
+	assert.Contains(t, response["detail"], "Brand not found")
 }
 
 // TestGetBrandsIntegration checks if the GetBrands function returns all brands from the database.
@@ -106,9 +105,7 @@ func TestGetBrandsIntegration(t *testing.T) {
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Brand 1", Description: "Description 1"})
 	db.Create(&models.Brands{Model: gorm.Model{ID: 2}, Name: "Brand 2", Description: "Description 2"})
 
-	router.GET("/brands", func(c *gin.Context) {
-		GetBrands(c, db)
-	})
+	router.GET("/brands", newHandlers(db).Brands.List)
 
 	req, _ := http.NewRequest("GET", "/brands", nil)
 	rr := httptest.NewRecorder()
@@ -140,52 +137,49 @@ func TestSearchAllBrandsIntegration(t *testing.T) {
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Brand 1", Description: "Description 1"})
 	db.Create(&models.Brands{Model: gorm.Model{ID: 2}, Name: "Brand 2", Description: "Description 2"})
 
-	router.GET("/brands/search", func(c *gin.Context) {
-		SearchAllBrands(c, db)
-	})
+	router.GET("/brands/search", newHandlers(db).Brands.Search)
 
 	req, _ := http.NewRequest("GET", "/brands/search?name=Brand 1", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	var response models.Brands
+	var response []models.Brands
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatal("Failed to parse response JSON")
 	}
 
 	// Check if the correct brand is retrieved
-	assert.NotNil(t, response)
-	assert.Equal(t, "Brand 1", response.Name)
-	assert.Equal(t, "Description 1", response.Description)
+	assert.Len(t, response, 1)
+	assert.Equal(t, "Brand 1", response[0].Name)
+	assert.Equal(t, "Description 1", response[0].Description)
 
 	// Check the status code
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
 
 // TestSearchAllBrandsIntegrationEmpty checks if the SearchAllBrands function returns
-// a message when no brands match the search criteria.
-// It should return a status code of 404 and a JSON response with an error message.
+// an empty list when no brands match the search criteria.
+// It should return a status code of 200 and an empty JSON array.
 func TestSearchAllBrandsIntegrationEmpty(t *testing.T) {
 	router, db, teardown := setupRouterAndDB(t)
 	defer teardown()
 
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Brand 1", Description: "Description 1"})
 
-	router.GET("/brands/search", func(c *gin.Context) {
-		SearchAllBrands(c, db)
-	})
+	router.GET("/brands/search", newHandlers(db).Brands.Search)
 
 	req, _ := http.NewRequest("GET", "/brands/search?name=Brand 2", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	var response models.Brands
+	var response []models.Brands
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	// Check the status code
-	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	// Check the status code and that no brands are returned
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, response)
 }
 
 // TestCreateBrand_Success ensures that a brand can be successfully created with valid data.
@@ -195,9 +189,7 @@ func TestCreateBrand_Success(t *testing.T) {
 	router, db, teardown := setupRouterAndDB(t)
 	defer teardown()
 
-	router.POST("/brands", func(c *gin.Context) {
-		CreateBrand(c, db)
-	})
+	router.POST("/brands", newHandlers(db).Brands.Create)
 
 	newBrand := `{"name":"Valid Brand", "description":"Valid Description"}`
 	req, _ := http.NewRequest("POST", "/brands", bytes.NewBufferString(newBrand))
@@ -223,9 +215,7 @@ func TestCreateBrand_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDB(t)
 	defer teardown()
 
-	router.POST("/brands", func(c *gin.Context) {
-		CreateBrand(c, db)
-	})
+	router.POST("/brands", newHandlers(db).Brands.Create)
 
 	newBrand := `{"name":""}`
 	req, _ := http.NewRequest("POST", "/brands", bytes.NewBufferString(newBrand))
@@ -241,7 +231,9 @@ func TestCreateBrand_InvalidData(t *testing.T) {
 	}
 
 	// Check response body
-	assert.Contains(t, response["error"], "Validation error")
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Validation error", response["title"])
+	assert.Contains(t, response["errors"], "name")
 }
 
 // TestUpdateBrandValid Checks the ability to update an existing brand with valid data.
@@ -256,9 +248,7 @@ func TestUpdateBrandValid(t *testing.T) {
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Old Brand", Description: "Old Description"})
 
 	// Set up the PUT route
-	router.PUT("/brands/:id", func(c *gin.Context) {
-		UpdateBrand(c, db)
-	})
+	router.PUT("/brands/:id", newHandlers(db).Brands.Update)
 
 	// Update brand via HTTP PUT
 	updatedBrand := `{"name":"Updated Brand", "description":"Updated Description"}`
@@ -287,9 +277,7 @@ func TestUpdateBrandInvalid(t *testing.T) {
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Old Brand", Description: "Old Description"})
 
 	// Set up the PUT route
-	router.PUT("/brands/:id", func(c *gin.Context) {
-		UpdateBrand(c, db)
-	})
+	router.PUT("/brands/:id", newHandlers(db).Brands.Update)
 
 	// Update brand via HTTP PUT
 	updatedBrand := `{"name":"", "description":""}`
@@ -304,8 +292,10 @@ func TestUpdateBrandInvalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	// checks that the response contains the correct error message
-	assert.Contains(t, response["error"], "Validation error")
+	// checks that the response reports every invalid field
+	assert.Equal(t, "Validation error", response["title"])
+	assert.Contains(t, response["errors"], "name")
+	assert.Contains(t, response["errors"], "description")
 
 }
 
@@ -317,9 +307,7 @@ func TestDeleteBrandValid(t *testing.T) {
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Brand to Delete", Description: "Description"})
 
 	// Set up the DELETE route
-	router.DELETE("/brands/:id", func(c *gin.Context) {
-		DeleteBrand(c, db)
-	})
+	router.DELETE("/brands/:id", newHandlers(db).Brands.Delete)
 
 	// Delete brand via HTTP DELETE
 	req, _ := http.NewRequest("DELETE", "/brands/1", nil)
@@ -340,9 +328,7 @@ func TestDeleteBrandInvalid(t *testing.T) {
 	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Brand to Delete", Description: "Description"})
 
 	// Set up the DELETE route
-	router.DELETE("/brands/:id", func(c *gin.Context) {
-		DeleteBrand(c, db)
-	})
+	router.DELETE("/brands/:id", newHandlers(db).Brands.Delete)
 
 	// Delete brand via HTTP DELETE
 	req, _ := http.NewRequest("DELETE", "/brands/2", nil)
@@ -356,5 +342,5 @@ func TestDeleteBrandInvalid(t *testing.T) {
 	}
 
 	// Check if the response contains the error message
-	assert.Contains(t, response["error"], "Brands not found")
+	assert.Contains(t, response["detail"], "Brand not found")
 }
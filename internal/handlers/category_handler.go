@@ -1,45 +1,59 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strings"
 )
 
-// GetCategory fetches a single category based on its ID provided in the URL path.
-// It checks for valid category data and returns an HTTP 200 OK with the category details or an error if not found or data is invalid.
-func GetCategory(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var category models.Category
+// CategoryHandler serves the category routes.
+type CategoryHandler struct {
+	categories *service.Categories
+}
 
-	if err := db.Where("id = ?", id).First(&category).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+// Get fetches a single category based on its ID provided in the URL path.
+// It checks for valid category data and returns an HTTP 200 OK with the category details or an error if not found or data is invalid.
+func (h *CategoryHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, category)
-
+	category, err := h.categories.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, category)
 }
 
-// GetCategories retrieves all categories from the database.
-// Responds with a list of categories if successful or an informational message if no categories exist.
+// List retrieves all categories from the database.
+// Responds with a list of categories if successful, empty if there are none.
 // On failure, it returns an HTTP 500 Internal Server Error.
-func GetCategories(c *gin.Context, db *gorm.DB) {
-	categories, err := models.GetAllCategories(db)
+func (h *CategoryHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	categories, err := h.categories.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving categories"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, categories)
+	respondWithETag(c, categories)
 }
 
-// SearchAllCategories retrieves all categories from the database based on the search parameters provided in the query string.
-// Responds with a list of categories if successful or an informational message if no categories exist.
+// Search retrieves all categories from the database based on the search parameters provided in the query string.
+// Responds with a list of categories if successful, empty if no categories match.
 // On failure, it returns an HTTP 500 Internal Server Error.
-func SearchAllCategories(c *gin.Context, db *gorm.DB) {
+func (h *CategoryHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"name", "description"} {
@@ -49,116 +63,207 @@ func SearchAllCategories(c *gin.Context, db *gorm.DB) {
 		}
 	}
 
-	categories, err := models.SearchCategory(db, searchParams)
+	categories, err := h.categories.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories", "details": err.Error()})
+		c.Error(err)
 		return
 	}
-	if !tools.CheckString(categories.Name, 250) && !tools.CheckString(categories.Description, 1000) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No category found"})
-		return
-	}
-	c.JSON(http.StatusOK, categories)
+	respondWithETag(c, categories)
 }
 
-// CreateCategory handles the creation of a new category via JSON input.
+// Create handles the creation of a new category via JSON input.
 // It validates input and responds with the created category object or an error message on failure.
-func CreateCategory(c *gin.Context, db *gorm.DB) {
+func (h *CategoryHandler) Create(c *gin.Context) {
 	var newCategory models.Category
 	if err := c.ShouldBindJSON(&newCategory); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
-		return
-	}
-
-	category := models.Category{
-		Name:        newCategory.Name,
-		Description: newCategory.Description,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkCategory(category, newCategory); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	if err := db.Create(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create category", "details": err.Error()})
+	category, err := h.categories.Create(c.Request.Context(), newCategory)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, category)
 }
 
-// UpdateCategory modifies an existing category based on its ID.
+// Update modifies an existing category based on its ID.
 // It validates the input data and updates the category in the database, responding with the updated data or an error.
-func UpdateCategory(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.CategoryExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *CategoryHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	category, err := h.categories.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, category.Version) {
 		return
 	}
 
 	var updatedCategory models.Category
 	if err := c.ShouldBindJSON(&updatedCategory); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var category models.Category
-	if err := db.Where("id = ?", id).First(&category).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+	if err := h.categories.Update(ctx, category, updatedCategory); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, category)
+}
 
-	category.Name = updatedCategory.Name
-	category.Description = updatedCategory.Description
-
-	if failed, err := checkCategory(category, updatedCategory); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing category based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *CategoryHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	category, err := h.categories.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, category.Version) {
 		return
 	}
 
-	if err := db.Save(&category).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category", "details": err.Error()})
+	fields, ok := applyMergePatch(c, category, categoryPatchFields)
+	if !ok {
+		return
+	}
+	if err := h.categories.Patch(ctx, category, fields); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, category)
+}
 
+// Delete removes a category from the database based on its ID.
+// It handles the deletion process and returns an HTTP 204 No Content on success or an error message if the category is not found or deletion fails.
+// A category still used by products is not deleted and HTTP 409 Conflict lists the products referencing it.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.categories, paramID(c))
+}
+
+// Restore takes a deleted category out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored category, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+func (h *CategoryHandler) Restore(c *gin.Context) {
+	category, err := h.categories.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
 	c.JSON(http.StatusOK, category)
 }
 
-// DeleteCategory removes a category from the database based on its ID.
-// It handles the deletion process and returns an HTTP 204 No Content on success or an error message if the category is not found or deletion fails.
-func DeleteCategory(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
+// Tree retrieves every category nested below its parent, the root categories first.
+// Each category lists its children, an empty list for the leaves of the tree.
+func (h *CategoryHandler) Tree(c *gin.Context) {
+	trees, err := h.categories.Tree(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, trees)
+}
+
+// Subtree retrieves the category with the ID provided in the URL with its descendants nested below it.
+// It responds with HTTP 404 Not Found if the category does not exist.
+func (h *CategoryHandler) Subtree(c *gin.Context) {
+	tree, err := h.categories.Subtree(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	// The tree is answered as a value, so that its ETag covers the descendants and not only the version of its root.
+	respondWithETag(c, *tree)
+}
 
-	if !models.CategoryExists(db, convertedId) {
-		fmt.Println("Category does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+// Breadcrumbs retrieves the categories from the root down to the category with the ID provided in the URL.
+// It responds with HTTP 404 Not Found if the category does not exist.
+func (h *CategoryHandler) Breadcrumbs(c *gin.Context) {
+	breadcrumbs, err := h.categories.Breadcrumbs(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, breadcrumbs)
+}
+
+// Products retrieves the products of the category with the ID provided in the URL and of all its descendants.
+// Like the product routes, it accepts the include and trashed query parameters, and filters on the attributes of
+// the category, of its ancestors and of its descendants.
+// It responds with HTTP 404 Not Found if the category does not exist.
+func (h *CategoryHandler) Products(c *gin.Context) {
+	q, ok := readQuery(c, productIncludes)
+	if !ok {
+		return
+	}
+	products, err := h.categories.Products(c.Request.Context(), paramID(c), filterParams(c), q)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	respondWithETag(c, products)
+}
 
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.Category{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting category"})
+// Facets retrieves the values of the attributes of the category, of its ancestors and of its descendants among
+// the products listed by Products, with the number of products having each. It accepts the same filters.
+// It responds with HTTP 404 Not Found if the category does not exist.
+func (h *CategoryHandler) Facets(c *gin.Context) {
+	facets, err := h.categories.Facets(c.Request.Context(), paramID(c), filterParams(c))
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	respondWithETag(c, facets)
+}
 
-	c.JSON(http.StatusNoContent, nil)
+// Attributes retrieves the schema of the products of the category with the ID provided in the URL: the attributes
+// it defines and those it inherits from its ancestors, from the root down.
+// It responds with HTTP 404 Not Found if the category does not exist.
+func (h *CategoryHandler) Attributes(c *gin.Context) {
+	attributes, err := h.categories.Attributes(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, attributes)
 }
 
-// checkCategory validates the input data for a category and returns an error if the data is invalid.
-// It checks the name and description fields for correct formatting.
-// Returns true if the data is invalid, along with an error message.
-// Returns false if the data is valid.
-func checkCategory(category models.Category, newCategory models.Category) (bool, error) {
-	switch true {
-	case !category.SetName(newCategory.Name):
-		return true, fmt.Errorf("name is wrong formatted")
-	case !category.SetDescription(newCategory.Description):
-		return true, fmt.Errorf("description is wrong formatted")
+// Move places the category with the ID provided in the URL, together with its subtree, below the category given as
+// {"parent_id": 12} in the body, or at the root with {"parent_id": null}. It responds like Patch; a parent that is
+// the category itself or one of its descendants is answered with HTTP 400 Bad Request, and a parent moved or deleted
+// by a concurrent request with HTTP 409 Conflict.
+func (h *CategoryHandler) Move(c *gin.Context) {
+	ctx := c.Request.Context()
+	category, err := h.categories.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, category.Version) {
+		return
+	}
+
+	var body map[string]*uint
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+	parentID, ok := body["parent_id"]
+	if !ok {
+		c.Error(apperr.New(apperr.KindBadRequest, "Invalid JSON data: parent_id is required, null for the root"))
+		return
+	}
+	if err := h.categories.Move(ctx, category, parentID); err != nil {
+		c.Error(err)
+		return
 	}
-	return false, nil
+	respondSaved(c, category)
 }
@@ -13,7 +13,9 @@ import (
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/problem"
 )
 
 // setupRouterAndDB sets up the router and database in memory, and returns
@@ -22,19 +24,20 @@ import (
 func setupRouterAndDBForCategoryHandler(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Category{}); err != nil {
+	if err := db.AutoMigrate(&models.Category{}, &models.Product{}, &models.Attribute{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
 	// Function to clean up the database after tests finish
 	teardown := func() {
-		if err := db.Migrator().DropTable(&models.Category{}); err != nil {
+		if err := db.Migrator().DropTable(&models.Category{}, &models.Product{}, &models.Attribute{}); err != nil {
 			t.Fatalf("failed to drop table: %v", err)
 		}
 	}
@@ -51,9 +54,7 @@ func TestGetCategoryIntegration(t *testing.T) {
 
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Test Category", Description: "Test Description"})
 
-	router.GET("/categories/:id", func(c *gin.Context) {
-		GetCategory(c, db)
-	})
+	router.GET("/categories/:id", newHandlers(db).Categories.Get)
 
 	req, _ := http.NewRequest("GET", "/categories/1", nil)
 	rr := httptest.NewRecorder()
@@ -82,9 +83,7 @@ func TestGetCategoryIntegrationInvalid(t *testing.T) {
 
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Test Category", Description: "Test Description"})
 
-	router.GET("/categories/:id", func(c *gin.Context) {
-		GetCategory(c, db)
-	})
+	router.GET("/categories/:id", newHandlers(db).Categories.Get)
 
 	req, _ := http.NewRequest("GET", "/categories/2", nil)
 	rr := httptest.NewRecorder()
@@ -97,7 +96,7 @@ func TestGetCategoryIntegrationInvalid(t *testing.T) {
 
 	// Check the status code
 	assert.Equal(t, http.StatusNotFound, rr.Code)
-	assert.Contains(t, response["error"], "Category not found")
+	assert.Contains(t, response["detail"], "Category not found")
 }
 
 // TestGetCategoriesIntegration checks if GetCategories function returns all categories from the database.
@@ -111,9 +110,7 @@ func TestGetCategoriesIntegration(t *testing.T) {
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Category 1", Description: "Description 1"})
 	db.Create(&models.Category{Model: gorm.Model{ID: 2}, Name: "Category 2", Description: "Description 2"})
 
-	router.GET("/categories", func(c *gin.Context) {
-		GetCategories(c, db)
-	})
+	router.GET("/categories", newHandlers(db).Categories.List)
 
 	req, _ := http.NewRequest("GET", "/categories", nil)
 	rr := httptest.NewRecorder()
@@ -146,53 +143,50 @@ func TestSearchAllCategoriesIntegration(t *testing.T) {
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Category 1", Description: "Description 1"})
 	db.Create(&models.Category{Model: gorm.Model{ID: 2}, Name: "Category 2", Description: "Description 2"})
 
-	router.GET("/categories/search", func(c *gin.Context) {
-		SearchAllCategories(c, db)
-	})
+	router.GET("/categories/search", newHandlers(db).Categories.Search)
 
 	req, _ := http.NewRequest("GET", "/categories/search?name=Category 1", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	var response models.Category
+	var response []models.Category
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatal("Failed to parse response JSON")
 	}
 
 	// Check if the correct category is retrieved
-	assert.NotNil(t, response)
-	assert.Equal(t, "Category 1", response.Name)
-	assert.Equal(t, "Description 1", response.Description)
+	assert.Len(t, response, 1)
+	assert.Equal(t, "Category 1", response[0].Name)
+	assert.Equal(t, "Description 1", response[0].Description)
 
 	// Check the status code
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
 
-// TestSearchAllCategoriesIntegrationEmpty checks if SearchAllCategories function returns a message when no categories match the search criteria.
+// TestSearchAllCategoriesIntegrationEmpty checks if SearchAllCategories function returns an empty list when no categories match the search criteria.
 // It uses the setupRouterAndDBForCategoryHandler function to set up the environment.
 // It creates a new category in the database and sends an HTTP GET request to the /categories/search endpoint with a search parameter that does not match any categories.
-// It checks the response status code and the error message in the response body.
+// It checks the response status code and that the response body is an empty list.
 func TestSearchAllCategoriesIntegrationEmpty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBForCategoryHandler(t)
 	defer teardown()
 
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Category 1", Description: "Description 1"})
 
-	router.GET("/categories/search", func(c *gin.Context) {
-		SearchAllCategories(c, db)
-	})
+	router.GET("/categories/search", newHandlers(db).Categories.Search)
 
 	req, _ := http.NewRequest("GET", "/categories/search?name=Category 2", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	var response models.Category
+	var response []models.Category
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	// Check the status code
-	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	// Check the status code and that no categories are returned
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, response)
 }
 
 // TestCreateCategory_Success ensures that a category can be successfully created with valid data.
@@ -203,9 +197,7 @@ func TestCreateCategory_Success(t *testing.T) {
 	router, db, teardown := setupRouterAndDBForCategoryHandler(t)
 	defer teardown()
 
-	router.POST("/categories", func(c *gin.Context) {
-		CreateCategory(c, db)
-	})
+	router.POST("/categories", newHandlers(db).Categories.Create)
 
 	newCategory := `{"name":"Valid Category", "description":"Valid Description"}`
 	req, _ := http.NewRequest("POST", "/categories", bytes.NewBufferString(newCategory))
@@ -233,9 +225,7 @@ func TestCreateCategory_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDBForCategoryHandler(t)
 	defer teardown()
 
-	router.POST("/categories", func(c *gin.Context) {
-		CreateCategory(c, db)
-	})
+	router.POST("/categories", newHandlers(db).Categories.Create)
 
 	newCategory := `{"name":""}`
 	req, _ := http.NewRequest("POST", "/categories", bytes.NewBufferString(newCategory))
@@ -251,7 +241,9 @@ func TestCreateCategory_InvalidData(t *testing.T) {
 	}
 
 	// Check response body
-	assert.Contains(t, response["error"], "Validation error")
+	assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "Validation error", response["title"])
+	assert.Contains(t, response["errors"], "name")
 }
 
 // TestUpdateCategoryValid checks the ability to update an existing category.
@@ -265,9 +257,7 @@ func TestUpdateCategoryValid(t *testing.T) {
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Old Category", Description: "Old Description"})
 
 	// Set up the PUT route
-	router.PUT("/categories/:id", func(c *gin.Context) {
-		UpdateCategory(c, db)
-	})
+	router.PUT("/categories/:id", newHandlers(db).Categories.Update)
 
 	// Update category via HTTP PUT
 	updatedCategory := `{"name":"Updated Category", "description":"Updated Description"}`
@@ -298,9 +288,7 @@ func TestUpdateCategoryInvalid(t *testing.T) {
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Old Category", Description: "Old Description"})
 
 	// Set up the PUT route
-	router.PUT("/categories/:id", func(c *gin.Context) {
-		UpdateCategory(c, db)
-	})
+	router.PUT("/categories/:id", newHandlers(db).Categories.Update)
 
 	// Update category via HTTP PUT
 	updatedCategory := `{"name":"", "description":""}`
@@ -315,8 +303,10 @@ func TestUpdateCategoryInvalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	// Check that the response contains the correct error message
-	assert.Contains(t, response["error"], "Validation error")
+	// Check that the response reports every invalid field
+	assert.Equal(t, "Validation error", response["title"])
+	assert.Contains(t, response["errors"], "name")
+	assert.Contains(t, response["errors"], "description")
 }
 
 // TestDeleteCategoryValid checks that a category is deleted from the database.
@@ -331,9 +321,7 @@ func TestDeleteCategoryValid(t *testing.T) {
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Category to Delete", Description: "Description"})
 
 	// Set up the DELETE route
-	router.DELETE("/categories/:id", func(c *gin.Context) {
-		DeleteCategory(c, db)
-	})
+	router.DELETE("/categories/:id", newHandlers(db).Categories.Delete)
 
 	// Delete category via HTTP DELETE
 	req, _ := http.NewRequest("DELETE", "/categories/1", nil)
@@ -355,9 +343,7 @@ func TestDeleteCategoryInvalid(t *testing.T) {
 	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Category to Delete", Description: "Description"})
 
 	// Set up the DELETE route
-	router.DELETE("/categories/:id", func(c *gin.Context) {
-		DeleteCategory(c, db)
-	})
+	router.DELETE("/categories/:id", newHandlers(db).Categories.Delete)
 
 	// Delete category via HTTP DELETE
 	req, _ := http.NewRequest("DELETE", "/categories/2", nil)
@@ -371,5 +357,69 @@ func TestDeleteCategoryInvalid(t *testing.T) {
 	}
 
 	// Check if the response contains the error message
-	assert.Contains(t, response["error"], "Category not found")
+	assert.Contains(t, response["detail"], "Category not found")
+}
+
+// TestCategoryTreeIntegration checks the tree endpoints: the tree, breadcrumbs and products of the subtree of a
+// category, moving a category with and without a parent, moving it below its own descendant, and deleting a parent.
+func TestCategoryTreeIntegration(t *testing.T) {
+	router, db, teardown := setupRouterAndDBForCategoryHandler(t)
+	defer teardown()
+
+	parent := uint(1)
+	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Computers", Description: "Description", Path: "/1/"})
+	db.Create(&models.Category{Model: gorm.Model{ID: 2}, Name: "Laptops", Description: "Description", Parent_ID: &parent, Path: "/1/2/"})
+	db.Create(&models.Category{Model: gorm.Model{ID: 3}, Name: "Portable", Description: "Description", Path: "/3/"})
+	db.Create(&models.Product{Model: gorm.Model{ID: 7}, Name: "Laptop", Category_ID: 2})
+
+	h := newHandlers(db).Categories
+	router.GET("/category-tree", h.Tree)
+	router.GET("/categories/:id/tree", h.Subtree)
+	router.GET("/categories/:id/breadcrumbs", h.Breadcrumbs)
+	router.GET("/categories/:id/products", h.Products)
+	router.POST("/categories/:id/move", h.Move)
+	router.DELETE("/categories/:id", h.Delete)
+	request := func(method, url, body string) (*httptest.ResponseRecorder, interface{}) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	rr, response := request("GET", "/category-tree", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if trees, ok := response.([]interface{}); assert.True(t, ok) && assert.Len(t, trees, 2) {
+		assert.Len(t, trees[0].(map[string]interface{})["children"], 1)
+	}
+	rr, response = request("GET", "/categories/1/products", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response, 1, "the products of a descendant should be listed")
+	rr, _ = request("GET", "/categories/9/tree", "")
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	rr, _ = request("DELETE", "/categories/1", "")
+	assert.Equal(t, http.StatusConflict, rr.Code, "a category with children should not be deletable")
+
+	rr, _ = request("POST", "/categories/2/move", `{}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	rr, response = request("POST", "/categories/1/move", `{"parent_id": 2}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, response.(map[string]interface{})["errors"], "parent_id")
+
+	rr, response = request("POST", "/categories/2/move", `{"parent_id": 3}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "/3/2/", response.(map[string]interface{})["path"])
+	rr, response = request("GET", "/categories/2/breadcrumbs", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	if breadcrumbs, ok := response.([]interface{}); assert.True(t, ok) && assert.Len(t, breadcrumbs, 2) {
+		assert.Equal(t, "Portable", breadcrumbs[0].(map[string]interface{})["name"])
+	}
+
+	rr, response = request("POST", "/categories/2/move", `{"parent_id": null}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Nil(t, response.(map[string]interface{})["parent_id"])
+	assert.Equal(t, "/2/", response.(map[string]interface{})["path"])
 }
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// deleter is implemented by the services, which move rows to the trash, provided they still have a version,
+// and read their version.
+type deleter interface {
+	versioned
+	Delete(ctx context.Context, id, version uint) error
+}
+
+// deleteRecord moves the row with the given ID to the trash and answers HTTP 204 No Content on success.
+// The row must match the If-Match header of the request, if any, until it is deleted. Otherwise the error of
+// the service is attached to c: not found when the row is gone, precondition failed when it was changed since
+// If-Match was checked, or a conflict listing the rows blocking the deletion as dependents.
+func deleteRecord(c *gin.Context, records deleter, id uint) {
+	version, ok := ifMatchVersion(c, records, id)
+	if !ok {
+		return
+	}
+	if err := records.Delete(c.Request.Context(), id, version); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
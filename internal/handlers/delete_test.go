@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// TestDeleteBrand_Referenced checks that a brand used by a product is answered with HTTP 409 Conflict
+// listing the blocking products, and that the brand is kept.
+func TestDeleteBrand_Referenced(t *testing.T) {
+	router, db, _, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	var brand models.Brands
+	db.First(&brand)
+	router.DELETE("/brands/:id", newHandlers(db).Brands.Delete)
+
+	req, _ := http.NewRequest("DELETE", "/brands/"+strconv.Itoa(int(brand.ID)), nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+
+	var response struct {
+		Code       string           `json:"code"`
+		Detail     string           `json:"detail"`
+		Dependents map[string]int64 `json:"dependents"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	assert.Equal(t, map[string]int64{"products": 1}, response.Dependents)
+	assert.Equal(t, "conflict", response.Code)
+	assert.Equal(t, "Still referenced by other records: brands row is still referenced by 1 products", response.Detail)
+	assert.True(t, models.BrandExists(db, brand.ID))
+}
+
+// TestDeleteOrder_Cascade checks that deleting an order moves it to the trash with its items, payments and shipping details.
+func TestDeleteOrder_Cascade(t *testing.T) {
+	router, db, order, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	router.DELETE("/orders/:id", newHandlers(db).Orders.Delete)
+
+	req, _ := http.NewRequest("DELETE", "/orders/"+strconv.Itoa(int(order.ID)), nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+
+	for _, model := range []interface{}{&models.Order{}, &models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}} {
+		var live, trashed int64
+		db.Model(model).Count(&live)
+		db.Unscoped().Model(model).Where("deleted_at IS NOT NULL").Count(&trashed)
+		assert.Zero(t, live)
+		assert.Equal(t, int64(1), trashed)
+	}
+}
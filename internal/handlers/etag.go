@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// versionETag returns the strong entity tag of a row at the given version.
+func versionETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// bodyETag returns a weak entity tag derived from a response body, for representations without a single version
+// such as lists or rows with their related resources.
+func bodyETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// matchETag reports whether one of the comma-separated entity tags of an If-Match or If-None-Match header matches tag.
+// "*" matches any tag. Weak tags only match with weak comparison, as used by If-None-Match.
+func matchETag(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if weak {
+			if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(tag, "W/") {
+				return true
+			}
+		} else if candidate == tag && !strings.HasPrefix(tag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithETag writes body with HTTP 200 OK and its ETag, or HTTP 304 Not Modified when it matches If-None-Match.
+// Single rows requested without query parameters are tagged with their version, anything else with a hash of the body.
+func respondWithETag(c *gin.Context, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.Error(apperr.Internal("Failed to encode response", err))
+		return
+	}
+	tag := bodyETag(data)
+	if row, ok := body.(models.Versioner); ok && c.Request.URL.RawQuery == "" {
+		tag = versionETag(row.CurrentVersion())
+	}
+
+	c.Header("ETag", tag)
+	if header := c.GetHeader("If-None-Match"); header != "" && matchETag(header, tag, true) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// checkIfMatch compares the If-Match header of the request with the version of the row about to be modified.
+// Requests without If-Match are let through; on a mismatch a precondition failed error is attached to c and false returned.
+func checkIfMatch(c *gin.Context, version uint) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || matchETag(header, versionETag(version), false) {
+		return true
+	}
+	c.Header("ETag", versionETag(version))
+	c.Error(apperr.New(apperr.KindPreconditionFailed, "If-Match does not match the current version "+versionETag(version)))
+	return false
+}
+
+// versioned is implemented by the services, which read the version of a row without loading it.
+type versioned interface {
+	Version(ctx context.Context, id uint) (uint, error)
+}
+
+// ifMatchVersion is checkIfMatch for the row with the given ID, whose version is read from records only when
+// If-Match is present. It returns the version matched, for the write to require the row to still have it, or 0
+// without If-Match. A missing row is let through with 0 so that the handler answers it as usual.
+func ifMatchVersion(c *gin.Context, records versioned, id uint) (uint, bool) {
+	if c.GetHeader("If-Match") == "" {
+		return 0, true
+	}
+	version, err := records.Version(c.Request.Context(), id)
+	if err != nil {
+		return 0, true
+	}
+	return version, checkIfMatch(c, version)
+}
+
+// respondSaved writes a row that was just saved with HTTP 200 OK and the ETag of its new version.
+func respondSaved(c *gin.Context, row models.Versioner) {
+	c.Header("ETag", versionETag(row.CurrentVersion()))
+	c.JSON(http.StatusOK, row)
+}
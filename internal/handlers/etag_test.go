@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestGetBrand_ETag checks that a brand is served with its version as ETag, and that sending it back
+// in If-None-Match is answered with HTTP 304 Not Modified until the brand changes.
+func TestGetBrand_ETag(t *testing.T) {
+	router, db, teardown := setupRouterAndDB(t)
+	defer teardown()
+
+	brand := models.Brands{Name: "Acme", Description: "Gadgets"}
+	db.Create(&brand)
+	router.GET("/brands/:id", newHandlers(db).Brands.Get)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/brands/1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"1"`, rr.Header().Get("ETag"))
+
+	rr = get(`"1"`)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	assert.Empty(t, rr.Body.String())
+
+	brand.Name = "Acme Corp"
+	assert.NoError(t, models.SaveVersioned(db, &brand))
+	rr = get(`"1"`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+}
+
+// TestGetBrands_ETag checks that lists are served with a weak ETag that changes with their content.
+func TestGetBrands_ETag(t *testing.T) {
+	router, db, teardown := setupRouterAndDB(t)
+	defer teardown()
+
+	db.Create(&models.Brands{Name: "Acme", Description: "Gadgets"})
+	router.GET("/brands", newHandlers(db).Brands.List)
+
+	req, _ := http.NewRequest("GET", "/brands", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	tag := rr.Header().Get("ETag")
+	assert.True(t, strings.HasPrefix(tag, `W/"`), tag)
+
+	req.Header.Set("If-None-Match", tag)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+
+	db.Create(&models.Brands{Name: "Other", Description: "Gadgets"})
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotEqual(t, tag, rr.Header().Get("ETag"))
+}
+
+// TestUpdateBrand_IfMatch checks that an update carrying the current ETag succeeds and returns the next one,
+// while replaying the now stale ETag is answered with HTTP 412 Precondition Failed and leaves the brand unchanged.
+func TestUpdateBrand_IfMatch(t *testing.T) {
+	router, db, teardown := setupRouterAndDB(t)
+	defer teardown()
+
+	db.Create(&models.Brands{Name: "Acme", Description: "Gadgets"})
+	router.PUT("/brands/:id", newHandlers(db).Brands.Update)
+
+	put := func(name, ifMatch string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/brands/1", strings.NewReader(`{"name": "`+name+`", "description": "Gadgets"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", ifMatch)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := put("Acme Corp", `"1"`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+	rr = put("Lost update", `"1"`)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	var brand models.Brands
+	db.First(&brand, 1)
+	assert.Equal(t, "Acme Corp", brand.Name)
+	assert.Equal(t, uint(2), brand.Version)
+}
+
+// TestDeleteBrand_IfMatch checks that a delete with a stale ETag is answered with HTTP 412 and keeps the brand.
+func TestDeleteBrand_IfMatch(t *testing.T) {
+	router, db, teardown := setupRouterAndDB(t)
+	defer teardown()
+
+	db.Create(&models.Brands{Name: "Acme", Description: "Gadgets", Versioned: models.Versioned{Version: 3}})
+	router.DELETE("/brands/:id", newHandlers(db).Brands.Delete)
+
+	req, _ := http.NewRequest("DELETE", "/brands/1", nil)
+	req.Header.Set("If-Match", `"2"`)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	assert.True(t, models.BrandExists(db, 1))
+
+	req.Header.Set("If-Match", `"3"`)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
+
+// TestDeleteBrand_ChangedAfterIfMatch checks that a brand changed between the check of If-Match and its deletion
+// is kept, and the delete answered with HTTP 412.
+func TestDeleteBrand_ChangedAfterIfMatch(t *testing.T) {
+	router, db, teardown := setupRouterAndDB(t)
+	defer teardown()
+
+	db.Create(&models.Brands{Name: "Acme", Description: "Gadgets"})
+	router.DELETE("/brands/:id", newHandlers(db).Brands.Delete)
+	// The first read of the brand, that of its version for If-Match, is followed by a concurrent update.
+	updated := false
+	db.Callback().Query().After("gorm:query").Register("concurrent_update", func(tx *gorm.DB) {
+		if tx.Statement.Table == "brands" && !updated {
+			updated = true
+			tx.Session(&gorm.Session{NewDB: true}).Exec("UPDATE brands SET name = 'Acme Corp', version = version + 1 WHERE id = 1")
+		}
+	})
+	defer db.Callback().Query().Remove("concurrent_update")
+
+	req, _ := http.NewRequest("DELETE", "/brands/1", nil)
+	req.Header.Set("If-Match", `"1"`)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.True(t, updated)
+	assert.Equal(t, http.StatusPreconditionFailed, rr.Code)
+	assert.True(t, models.BrandExists(db, 1))
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/service"
+	"E-Commerce_Website_Database/internal/tools"
+	"github.com/gin-gonic/gin"
+)
+
+// Handlers holds the HTTP handlers of every resource. They parse requests and write responses,
+// leaving the business rules and the storage of records to the services.
+type Handlers struct {
+	Users           *UserHandler
+	Brands          *BrandHandler
+	Categories      *CategoryHandler
+	Products        *ProductHandler
+	ProductVariants *ProductVariantHandler
+	ProductImages   *ProductImageHandler
+	Attributes      *AttributeHandler
+	Orders          *OrderHandler
+	OrderItems      *OrderItemHandler
+	Payments        *PaymentHandler
+	ShippingDetails *ShippingDetailHandler
+	Reviews         *ReviewHandler
+	Auth            *AuthHandler
+	Audit           *AuditHandler
+	Media           *MediaHandler
+}
+
+// New returns the handlers of every resource backed by services. Tokens of logged in users are issued by tokenService.
+func New(services *service.Services, tokenService tools.TokenService) *Handlers {
+	return &Handlers{
+		Users:           &UserHandler{users: services.Users},
+		Brands:          &BrandHandler{brands: services.Brands, media: services.Media},
+		Categories:      &CategoryHandler{categories: services.Categories},
+		Products:        &ProductHandler{products: services.Products},
+		ProductVariants: &ProductVariantHandler{variants: services.ProductVariants},
+		ProductImages:   &ProductImageHandler{images: services.ProductImages, media: services.Media},
+		Attributes:      &AttributeHandler{attributes: services.Attributes},
+		Orders:          &OrderHandler{orders: services.Orders},
+		OrderItems:      &OrderItemHandler{orderItems: services.OrderItems},
+		Payments:        &PaymentHandler{payments: services.Payments},
+		ShippingDetails: &ShippingDetailHandler{shippingDetails: services.ShippingDetails},
+		Reviews:         &ReviewHandler{reviews: services.Reviews},
+		Auth:            &AuthHandler{users: services.Users, tokenService: tokenService},
+		Audit:           &AuditHandler{audit: services.Audit},
+		Media:           &MediaHandler{media: services.Media},
+	}
+}
+
+// paramID returns the ID given in the URL, 0 when it is not a number so that no row is found.
+func paramID(c *gin.Context) uint {
+	return tools.ConvertStringToUint(c.Param("id"))
+}
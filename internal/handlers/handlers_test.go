@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"gorm.io/gorm"
+
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
+	"E-Commerce_Website_Database/internal/storage"
+	"E-Commerce_Website_Database/internal/tools"
+)
+
+// newHandlers returns the handlers backed by db through the GORM repositories, wired as in cmd/main.go.
+func newHandlers(db *gorm.DB) *Handlers {
+	return New(service.New(repository.NewGORM(db), storage.NewMemory(), imaging.DefaultLimits), &tools.JWTTokenService{})
+}
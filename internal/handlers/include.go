@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/repository"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"sort"
+	"strings"
+)
+
+// Associations that can be eagerly loaded with the include query parameter, mapped to their GORM preload path.
+var (
+	productIncludes   = map[string]string{"brand": "Brand", "category": "Category", "variants": "Variants", "attributes": "Attributes.Attribute", "images": "Images"}
+	orderIncludes     = map[string]string{"items": "Items", "items.product": "Items.Product", "items.variant": "Items.Variant", "payments": "Payments", "shipping": "Shipping"}
+	orderItemIncludes = map[string]string{"product": "Product", "variant": "Variant"}
+	reviewIncludes    = map[string]string{"product": "Product"}
+)
+
+// withIncludes returns the preload paths of the associations listed in the comma separated include query parameter,
+// e.g. ?include=items,payments. Unknown names attach a bad request error to c, in which case ok is false.
+func withIncludes(c *gin.Context, allowed map[string]string) ([]string, bool) {
+	include := strings.TrimSpace(c.Query("include"))
+	if include == "" {
+		return nil, true
+	}
+	var paths []string
+	for _, name := range strings.Split(include, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		path, found := allowed[name]
+		if !found {
+			names := make([]string, 0, len(allowed))
+			for allowedName := range allowed {
+				names = append(names, allowedName)
+			}
+			sort.Strings(names)
+			c.Error(apperr.New(apperr.KindBadRequest, fmt.Sprintf("Invalid include: unknown include %q, expected one of %s", name, strings.Join(names, ", "))))
+			return nil, false
+		}
+		paths = append(paths, path)
+	}
+	return paths, true
+}
+
+// readQuery returns the options of a read given by the trashed and include query parameters.
+// includes lists the associations of the resource that can be loaded; include is ignored when it is nil.
+// Invalid parameters attach an error to c, in which case ok is false.
+func readQuery(c *gin.Context, includes map[string]string) (repository.Query, bool) {
+	trashed, ok := withTrashed(c)
+	if !ok || includes == nil {
+		return repository.Query{Trashed: trashed}, ok
+	}
+	paths, ok := withIncludes(c, includes)
+	if !ok {
+		return repository.Query{}, false
+	}
+	return repository.Query{Trashed: trashed, Includes: paths}, true
+}
+
+// filterParams returns the query string of a product listing without the include and trashed parameters,
+// leaving the filters on the values of attributes, e.g. ram_gb[gte]=16.
+func filterParams(c *gin.Context) map[string][]string {
+	params := c.Request.URL.Query()
+	delete(params, "include")
+	delete(params, "trashed")
+	return params
+}
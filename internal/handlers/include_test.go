@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// setupRouterAndDBInclude sets up the router and an in-memory database with an order, its items, payment and shipping details.
+// It returns the router, database, the order and a teardown function dropping the tables.
+func setupRouterAndDBInclude(t *testing.T) (*gin.Engine, *gorm.DB, models.Order, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Errors())
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	tables := []interface{}{&models.Brands{}, &models.Category{}, &models.Product{}, &models.User{}, &models.Order{},
+		&models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}}
+	if err := db.AutoMigrate(tables...); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	brand := models.Brands{Name: "Acme"}
+	db.Create(&brand)
+	category := models.Category{Name: "Laptops", Description: "Portable computers"}
+	db.Create(&category)
+	product := models.Product{Name: "Laptop", Price: 999.99, Stock_quantity: 3, Brand_ID: brand.ID, Category_ID: category.ID}
+	db.Create(&product)
+	user := models.User{Username: "buyer", Email: "buyer@example.com", Role: "regular"}
+	db.Create(&user)
+	order := models.Order{User_ID: user.ID, Order_date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Total_amount: 999.99, Status: "shipped"}
+	db.Create(&order)
+	db.Create(&models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 1, Subtotal: 999.99})
+	db.Create(&models.Payment{Order_ID: order.ID, Payment_method: "credit_card", Amount: 999.99, Payment_date: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), Status: "completed"})
+	db.Create(&models.ShippingDetails{Order_ID: order.ID, Address: "1 Main Street", Shipping_Date: time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), Status: "shipped"})
+
+	teardown := func() {
+		if err := db.Migrator().DropTable(tables...); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	return router, db, order, teardown
+}
+
+// TestGetOrder_Include checks that ?include loads the requested associations of an order, including nested ones,
+// and that the associations are left out of the response when they are not requested.
+func TestGetOrder_Include(t *testing.T) {
+	router, db, order, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	router.GET("/orders/:id", newHandlers(db).Orders.Get)
+
+	req, _ := http.NewRequest("GET", "/orders/"+strconv.Itoa(int(order.ID))+"?include=items.product,payments,%20shipping", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response models.Order
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	if assert.Len(t, response.Items, 1) && assert.NotNil(t, response.Items[0].Product) {
+		assert.Equal(t, "Laptop", response.Items[0].Product.Name)
+	}
+	assert.Len(t, response.Payments, 1)
+	assert.Len(t, response.Shipping, 1)
+
+	req, _ = http.NewRequest("GET", "/orders/"+strconv.Itoa(int(order.ID)), nil)
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	var plain map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &plain); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	assert.NotContains(t, plain, "items")
+	assert.NotContains(t, plain, "payments")
+	assert.NotContains(t, plain, "shipping")
+}
+
+// TestGetProducts_Include checks that the brand and category of every listed product are loaded with ?include.
+func TestGetProducts_Include(t *testing.T) {
+	router, db, _, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	router.GET("/products", newHandlers(db).Products.List)
+
+	req, _ := http.NewRequest("GET", "/products?include=brand,category", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response []models.Product
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	if assert.Len(t, response, 1) && assert.NotNil(t, response[0].Brand) && assert.NotNil(t, response[0].Category) {
+		assert.Equal(t, "Acme", response[0].Brand.Name)
+		assert.Equal(t, "Laptops", response[0].Category.Name)
+	}
+}
+
+// TestSearchAllOrders_InvalidInclude checks that an unknown include is answered with an HTTP 400 Bad Request.
+func TestSearchAllOrders_InvalidInclude(t *testing.T) {
+	router, db, _, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	router.GET("/orders/search", newHandlers(db).Orders.Search)
+
+	req, _ := http.NewRequest("GET", "/orders/search?status=shipped&include=items,user", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, rr.Body.String(), `unknown include \"user\"`)
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/service"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// mediaMaxAge is the Cache-Control of stored files. A stored file never changes, a new upload getting a new key,
+// so clients and proxies may keep it for a year without revalidating.
+const mediaMaxAge = "public, max-age=31536000, immutable"
+
+// MediaHandler serves the files of uploaded images.
+type MediaHandler struct {
+	media *service.Media
+}
+
+// Serve writes the file stored under the key following /media/ in the URL, with its media type and cache headers.
+// Conditional and range requests are answered by http.ServeContent, and HEAD requests get the headers only.
+// It responds with HTTP 404 Not Found if no file is stored under the key.
+func (h *MediaHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	object, err := h.media.Open(c.Request.Context(), key)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer object.Close()
+
+	sum := sha256.Sum256([]byte(key))
+	c.Header("ETag", `"`+hex.EncodeToString(sum[:8])+`"`)
+	c.Header("Cache-Control", mediaMaxAge)
+	c.Header("X-Content-Type-Options", "nosniff")
+	if object.ContentType != "" {
+		c.Header("Content-Type", object.ContentType)
+	}
+	http.ServeContent(c.Writer, c.Request, key, object.ModTime, object)
+}
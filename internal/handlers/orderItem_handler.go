@@ -1,53 +1,67 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
-// GetOrderItem fetches a single order item by ID provided in the URL.
-// It validates the order item data and returns the order item details or an error message if not found or data is invalid.
-func GetOrderItem(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var orderItem models.OrderItem
+// OrderItemHandler serves the order item routes.
+type OrderItemHandler struct {
+	orderItems *service.OrderItems
+}
 
-	if err := db.Where("id = ?", id).First(&orderItem).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+// Get fetches a single order item by ID provided in the URL.
+// It validates the order item data and returns the order item details or an error message if not found or data is invalid.
+func (h *OrderItemHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, orderItemIncludes)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, orderItem)
-
+	orderItem, err := h.orderItems.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, orderItem)
 }
 
-// GetOrderItems retrieves all order items from the database.
+// List retrieves all order items from the database.
 // It returns a list of order items in JSON format or an error message if the retrieval fails.
-func GetOrderItems(c *gin.Context, db *gorm.DB) {
-	orderItems, err := models.GetAllOrderItems(db)
+func (h *OrderItemHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, orderItemIncludes)
+	if !ok {
+		return
+	}
+	orderItems, err := h.orderItems.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving order items"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, orderItems)
+	respondWithETag(c, orderItems)
 }
 
-// SearchAllOrderItems retrieves all order items from the database based on the search parameters provided in the query string.
-// It responds with a list of order items if successful or an informational message if no order items exist.
+// Search retrieves all order items from the database based on the search parameters provided in the query string.
+// It responds with a list of order items if successful, empty if no order items match.
 // On failure, it returns an HTTP 500 Internal Server Error.
-// The search parameters include order_id, product_id, quantity, and subtotal.
-func SearchAllOrderItems(c *gin.Context, db *gorm.DB) {
+// The search parameters include order_id, product_id, variant_id, quantity, and subtotal.
+func (h *OrderItemHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, orderItemIncludes)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
-	for _, field := range []string{"order_id", "product_id", "quantity", "subtotal"} {
+	for _, field := range []string{"order_id", "product_id", "variant_id", "quantity", "subtotal"} {
 		if value := c.Query(field); value != "" {
 			cleanValue := strings.TrimSpace(value)
 			switch field {
-			case "order_id", "product_id", "quantity":
+			case "order_id", "product_id", "variant_id", "quantity":
 				if numVal, err := strconv.Atoi(cleanValue); err == nil {
 					searchParams[field] = numVal
 				}
@@ -59,128 +73,105 @@ func SearchAllOrderItems(c *gin.Context, db *gorm.DB) {
 		}
 	}
 
-	orderItems, err := models.SearchOrderItem(db, searchParams)
+	orderItems, err := h.orderItems.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order items", "details": err.Error()})
-		return
-	}
-
-	if len(orderItems) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No order items found"})
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusOK, orderItems)
+	respondWithETag(c, orderItems)
 }
 
-// CreateOrderItem handles the creation of a new order item from JSON input.
+// Create handles the creation of a new order item from JSON input.
 // It checks the existence of the product, validates input, and persists the new order item in the database.
+// The subtotal is the price of the product or variant times the quantity, and the total_amount of the order is updated;
+// a quantity above the stock is answered with HTTP 400 Bad Request.
 // Responds with the created order item or an error message.
-func CreateOrderItem(c *gin.Context, db *gorm.DB) {
+func (h *OrderItemHandler) Create(c *gin.Context) {
 	var newOrderItem models.OrderItem
 	if err := c.ShouldBindJSON(&newOrderItem); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	orderItem := models.OrderItem{
-		Order_ID:   newOrderItem.Order_ID,
-		Product_ID: newOrderItem.Product_ID,
-		Quantity:   newOrderItem.Quantity,
-		Subtotal:   newOrderItem.Subtotal,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkOrderItem(orderItem, newOrderItem, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
-		return
-	}
-
-	if err := db.Create(&orderItem).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order item", "details": err.Error()})
+	orderItem, err := h.orderItems.Create(c.Request.Context(), newOrderItem)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, orderItem)
 }
 
-// UpdateOrderItem modifies an existing order item based on the JSON input and the ID provided in the URL.
+// Update modifies an existing order item based on the JSON input and the ID provided in the URL.
 // It checks product existence, validates the input data, and updates the order item in the database.
+// The item is priced again and the total_amount of its order updated, as by Create.
 // Responds with the updated order item or an error message.
-func UpdateOrderItem(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.OrderItemExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *OrderItemHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderItem, err := h.orderItems.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, orderItem.Version) {
 		return
 	}
 
 	var updatedOrderItem models.OrderItem
 	if err := c.ShouldBindJSON(&updatedOrderItem); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var orderItem models.OrderItem
-	if err := db.Where("id = ?", id).First(&orderItem).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+	if err := h.orderItems.Update(ctx, orderItem, updatedOrderItem); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, orderItem)
+}
 
-	orderItem.Order_ID = updatedOrderItem.Order_ID
-	orderItem.Product_ID = updatedOrderItem.Product_ID
-	orderItem.Quantity = updatedOrderItem.Quantity
-	orderItem.Subtotal = updatedOrderItem.Subtotal
-
-	if failed, err := checkOrderItem(orderItem, updatedOrderItem, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing order item based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *OrderItemHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	orderItem, err := h.orderItems.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := db.Save(&orderItem).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order item", "details": err.Error()})
+	if !checkIfMatch(c, orderItem.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, orderItem)
-}
-
-// DeleteOrderItem removes an order item from the database based on its ID.
-// It handles the deletion process and responds with HTTP 204 No Content
-// on success or an error message if not found or deletion fails.
-func DeleteOrderItem(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
-
-	if !models.OrderItemExists(db, convertedId) {
-		fmt.Println("Order item does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order item not found"})
+	fields, ok := applyMergePatch(c, orderItem, orderItemPatchFields)
+	if !ok {
 		return
 	}
-
-	if err := db.Unscoped().Where("id = ?", id).Delete(&models.OrderItem{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting order item"})
+	if err := h.orderItems.Patch(ctx, orderItem, fields); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, orderItem)
+}
 
-	c.JSON(http.StatusNoContent, nil)
+// Delete removes an order item from the database based on its ID.
+// It handles the deletion process and responds with HTTP 204 No Content
+// on success or an error message if not found or deletion fails.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *OrderItemHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.orderItems, paramID(c))
 }
 
-// checkOrderItem validates the input data for an order item and returns an error if the data is invalid.
-// It checks the order_id, product_id, quantity, and subtotal fields for correct formatting.
-// It also verifies the existence of the order and product in the database.
-func checkOrderItem(orderItem models.OrderItem, newOrderItem models.OrderItem, db *gorm.DB) (bool, error) {
-	switch true {
-	case !orderItem.SetOrderID(newOrderItem.Order_ID, db):
-		return true, fmt.Errorf("invalid order_id or not existing")
-	case !orderItem.SetProductID(newOrderItem.Product_ID, db):
-		return true, fmt.Errorf("invalid product_id or not existing")
-	case !orderItem.SetQuantity(newOrderItem.Quantity):
-		return true, fmt.Errorf("invalid quantity")
-	case !orderItem.SetSubtotal(newOrderItem.Subtotal):
-		return true, fmt.Errorf("invalid subtotal")
-	}
-	return false, nil
+// Restore takes a deleted order item out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored order item, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+func (h *OrderItemHandler) Restore(c *gin.Context) {
+	orderItem, err := h.orderItems.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, orderItem)
 }
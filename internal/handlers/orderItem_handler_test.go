@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
 	"bytes"
 	"encoding/json"
@@ -15,24 +16,25 @@ import (
 	"testing"
 )
 
-// setupRouterAndDBOrderItem sets up the router and database in memory, including the migration of Order, Product, and OrderItem models.
+// setupRouterAndDBOrderItem sets up the router and database in memory, including the migration of Order, Product, ProductVariant, and OrderItem models.
 // It returns the router, database, and a teardown function to clean up the database after tests finish.
 func setupRouterAndDBOrderItem(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Order{}, &models.Product{}, &models.OrderItem{}); err != nil {
+	if err := db.AutoMigrate(&models.Order{}, &models.Product{}, &models.ProductVariant{}, &models.OrderItem{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
 	// Function to clean up the database after tests finish
 	teardown := func() {
-		if err := db.Migrator().DropTable(&models.Order{}, &models.Product{}, &models.OrderItem{}); err != nil {
+		if err := db.Migrator().DropTable(&models.Order{}, &models.Product{}, &models.ProductVariant{}, &models.OrderItem{}); err != nil {
 			t.Fatalf("failed to drop table: %v", err)
 		}
 	}
@@ -51,12 +53,10 @@ func TestGetOrderItem_Success(t *testing.T) {
 	product := models.Product{Name: "Test Product", Price: 10.00}
 	db.Create(&order)
 	db.Create(&product)
-	orderItem := models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 5, Subtotal: 50.00}
+	orderItem := models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 5, Subtotal: 50.00}
 	db.Create(&orderItem)
 
-	router.GET("/orderItems/:id", func(c *gin.Context) {
-		GetOrderItem(c, db)
-	})
+	router.GET("/orderItems/:id", newHandlers(db).OrderItems.Get)
 
 	req, _ := http.NewRequest("GET", "/orderItems/"+strconv.Itoa(int(orderItem.ID)), nil)
 	rr := httptest.NewRecorder()
@@ -79,9 +79,7 @@ func TestGetOrderItem_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	router.GET("/orderItems/:id", func(c *gin.Context) {
-		GetOrderItem(c, db)
-	})
+	router.GET("/orderItems/:id", newHandlers(db).OrderItems.Get)
 
 	req, _ := http.NewRequest("GET", "/orderItems/999", nil)
 	rr := httptest.NewRecorder()
@@ -93,7 +91,7 @@ func TestGetOrderItem_NotFound(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Order item not found")
+	assert.Contains(t, response["detail"], "Order item not found")
 }
 
 // TestGetOrderItems_Success checks if GetOrderItems returns all order items from the database.
@@ -107,12 +105,10 @@ func TestGetOrderItems_Success(t *testing.T) {
 	product := models.Product{Name: "Test Product", Price: 20.00}
 	db.Create(&order)
 	db.Create(&product)
-	db.Create(&models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 2, Subtotal: 40.00})
-	db.Create(&models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 3, Subtotal: 60.00})
+	db.Create(&models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 2, Subtotal: 40.00})
+	db.Create(&models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 3, Subtotal: 60.00})
 
-	router.GET("/orderItems", func(c *gin.Context) {
-		GetOrderItems(c, db)
-	})
+	router.GET("/orderItems", newHandlers(db).OrderItems.List)
 
 	req, _ := http.NewRequest("GET", "/orderItems", nil)
 	rr := httptest.NewRecorder()
@@ -134,9 +130,7 @@ func TestGetOrderItems_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	router.GET("/orderItems", func(c *gin.Context) {
-		GetOrderItems(c, db)
-	})
+	router.GET("/orderItems", newHandlers(db).OrderItems.List)
 
 	req, _ := http.NewRequest("GET", "/orderItems", nil)
 	rr := httptest.NewRecorder()
@@ -162,11 +156,9 @@ func TestSearchAllOrderItems_Success(t *testing.T) {
 	product := models.Product{Name: "Test Product", Price: 20.00}
 	db.Create(&order)
 	db.Create(&product)
-	db.Create(&models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 10, Subtotal: 200.00})
+	db.Create(&models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 10, Subtotal: 200.00})
 
-	router.GET("/orderItems/search", func(c *gin.Context) {
-		SearchAllOrderItems(c, db)
-	})
+	router.GET("/orderItems/search", newHandlers(db).OrderItems.Search)
 
 	req, _ := http.NewRequest("GET", "/orderItems/search?quantity=10", nil)
 	rr := httptest.NewRecorder()
@@ -183,27 +175,25 @@ func TestSearchAllOrderItems_Success(t *testing.T) {
 }
 
 // TestSearchAllOrderItems_NotFound checks if SearchAllOrderItems responds correctly when no order items match the search criteria.
-// It fetches order items with a quantity that does not exist in the database and checks the response status code and error message.
-// The test expects a 404 Not Found status code and an error message indicating that no order items were found.
+// It fetches order items with a quantity that does not exist in the database and checks the response status code and body.
+// The test expects a 200 OK status code and an empty list.
 func TestSearchAllOrderItems_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	router.GET("/orderItems/search", func(c *gin.Context) {
-		SearchAllOrderItems(c, db)
-	})
+	router.GET("/orderItems/search", newHandlers(db).OrderItems.Search)
 
 	req, _ := http.NewRequest("GET", "/orderItems/search?quantity=100", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusNotFound, rr.Code)
-	var response map[string]interface{}
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []models.OrderItem
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "No order items found")
+	assert.Empty(t, response)
 }
 
 // TestCreateOrderItem_Success checks that an order item can be successfully created with valid data.
@@ -213,16 +203,14 @@ func TestCreateOrderItem_Success(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	order := models.Order{Total_amount: 100.00}
-	product := models.Product{Name: "Test Product", Price: 10.00}
+	order := models.Order{}
+	product := models.Product{Name: "Test Product", Price: 10.00, Stock_quantity: 20}
 	db.Create(&order)
 	db.Create(&product)
 
-	router.POST("/orderItems", func(c *gin.Context) {
-		CreateOrderItem(c, db)
-	})
+	router.POST("/orderItems", newHandlers(db).OrderItems.Create)
 
-	newOrderItem := fmt.Sprintf(`{"order_id": %d, "product_id": %d, "quantity": 5, "subtotal": 50.00}`, order.ID, product.ID)
+	newOrderItem := fmt.Sprintf(`{"order_id": %d, "product_id": %d, "quantity": 5, "subtotal": 1.00}`, order.ID, product.ID)
 	req, _ := http.NewRequest("POST", "/orderItems", bytes.NewBufferString(newOrderItem))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -234,10 +222,12 @@ func TestCreateOrderItem_Success(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Equal(t, uint32(order.ID), response.Order_ID)
-	assert.Equal(t, uint32(product.ID), response.Product_ID)
+	assert.Equal(t, order.ID, response.Order_ID)
+	assert.Equal(t, product.ID, response.Product_ID)
 	assert.Equal(t, 5, response.Quantity)
-	assert.Equal(t, 50.00, response.Subtotal)
+	assert.Equal(t, 50.00, response.Subtotal, "the subtotal is the price times the quantity, not the one sent")
+	db.First(&order, order.ID)
+	assert.Equal(t, 50.00, order.Total_amount)
 }
 
 // TestCreateOrderItem_InvalidData checks the response when incomplete or incorrect data is sent.
@@ -247,9 +237,7 @@ func TestCreateOrderItem_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	router.POST("/orderItems", func(c *gin.Context) {
-		CreateOrderItem(c, db)
-	})
+	router.POST("/orderItems", newHandlers(db).OrderItems.Create)
 
 	newOrderItem := `{"order_id": "", "product_id": "", "quantity": "five", "subtotal": "fifty"}`
 	req, _ := http.NewRequest("POST", "/orderItems", bytes.NewBufferString(newOrderItem))
@@ -263,7 +251,7 @@ func TestCreateOrderItem_InvalidData(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Invalid JSON data")
+	assert.Contains(t, response["detail"], "Invalid JSON data")
 }
 
 // TestUpdateOrderItem_Valid checks the ability to update an existing order item.
@@ -273,18 +261,16 @@ func TestUpdateOrderItem_Valid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	order := models.Order{Total_amount: 100.00}
-	product := models.Product{Name: "Test Product", Price: 10.00}
+	order := models.Order{Total_amount: 50.00}
+	product := models.Product{Name: "Test Product", Price: 10.00, Stock_quantity: 20}
 	db.Create(&order)
 	db.Create(&product)
-	orderItem := models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 5, Subtotal: 50.00}
+	orderItem := models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 5, Subtotal: 50.00}
 	db.Create(&orderItem)
 
-	router.PUT("/orderItems/:id", func(c *gin.Context) {
-		UpdateOrderItem(c, db)
-	})
+	router.PUT("/orderItems/:id", newHandlers(db).OrderItems.Update)
 
-	updatedOrderItem := fmt.Sprintf(`{"order_id": %d, "product_id": %d, "quantity": 10, "subtotal": 100.00}`, order.ID, product.ID)
+	updatedOrderItem := fmt.Sprintf(`{"order_id": %d, "product_id": %d, "quantity": 10}`, order.ID, product.ID)
 	req, _ := http.NewRequest("PUT", "/orderItems/"+strconv.Itoa(int(orderItem.ID)), bytes.NewBufferString(updatedOrderItem))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -298,6 +284,8 @@ func TestUpdateOrderItem_Valid(t *testing.T) {
 
 	assert.Equal(t, 10, response.Quantity)
 	assert.Equal(t, 100.00, response.Subtotal)
+	db.First(&order, order.ID)
+	assert.Equal(t, 100.00, order.Total_amount)
 }
 
 // TestUpdateOrderItem_Invalid checks the error message with invalid data.
@@ -311,12 +299,10 @@ func TestUpdateOrderItem_Invalid(t *testing.T) {
 	product := models.Product{Name: "Test Product", Price: 10.00}
 	db.Create(&order)
 	db.Create(&product)
-	orderItem := models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 5, Subtotal: 50.00}
+	orderItem := models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 5, Subtotal: 50.00}
 	db.Create(&orderItem)
 
-	router.PUT("/orderItems/:id", func(c *gin.Context) {
-		UpdateOrderItem(c, db)
-	})
+	router.PUT("/orderItems/:id", newHandlers(db).OrderItems.Update)
 
 	updatedOrderItem := `{"order_id": "", "product_id": "", "quantity": "", "subtotal": ""}`
 	req, _ := http.NewRequest("PUT", "/orderItems/"+strconv.Itoa(int(orderItem.ID)), bytes.NewBufferString(updatedOrderItem))
@@ -330,7 +316,7 @@ func TestUpdateOrderItem_Invalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Invalid JSON data")
+	assert.Contains(t, response["detail"], "Invalid JSON data")
 }
 
 // TestDeleteOrderItem_Valid checks that an order item is deleted from the database.
@@ -344,12 +330,10 @@ func TestDeleteOrderItem_Valid(t *testing.T) {
 	product := models.Product{Name: "Test Product", Price: 10.00}
 	db.Create(&order)
 	db.Create(&product)
-	orderItem := models.OrderItem{Order_ID: uint32(order.ID), Product_ID: uint32(product.ID), Quantity: 5, Subtotal: 50.00}
+	orderItem := models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 5, Subtotal: 50.00}
 	db.Create(&orderItem)
 
-	router.DELETE("/orderItems/:id", func(c *gin.Context) {
-		DeleteOrderItem(c, db)
-	})
+	router.DELETE("/orderItems/:id", newHandlers(db).OrderItems.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/orderItems/"+strconv.Itoa(int(orderItem.ID)), nil)
 	rr := httptest.NewRecorder()
@@ -366,9 +350,7 @@ func TestDeleteOrderItem_Invalid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrderItem(t)
 	defer teardown()
 
-	router.DELETE("/orderItems/:id", func(c *gin.Context) {
-		DeleteOrderItem(c, db)
-	})
+	router.DELETE("/orderItems/:id", newHandlers(db).OrderItems.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/orderItems/999", nil)
 	rr := httptest.NewRecorder()
@@ -380,5 +362,5 @@ func TestDeleteOrderItem_Invalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Order item not found")
+	assert.Contains(t, response["detail"], "Order item not found")
 }
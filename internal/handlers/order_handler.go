@@ -1,46 +1,62 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/metrics"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// GetOrder retrieves a single order by ID from the database.
-// It checks the validity of the order data and returns the order details or appropriate error messages.
-func GetOrder(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var order models.Order
+// OrderHandler serves the order routes.
+type OrderHandler struct {
+	orders *service.Orders
+}
 
-	if err := db.Where("id = ?", id).First(&order).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+// Get retrieves a single order by ID from the database.
+// It checks the validity of the order data and returns the order details or appropriate error messages.
+func (h *OrderHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, orderIncludes)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, order)
-
+	order, err := h.orders.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, order)
 }
 
-// GetOrders handles the retrieval of all orders from the database.
+// List handles the retrieval of all orders from the database.
 // It returns a JSON response with a list of orders or an error message if the retrieval fails.
-func GetOrders(c *gin.Context, db *gorm.DB) {
-	orders, err := models.GetAllOrders(db)
+func (h *OrderHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, orderIncludes)
+	if !ok {
+		return
+	}
+	orders, err := h.orders.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving orders"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, orders)
+	respondWithETag(c, orders)
 }
 
-// SearchAllOrders retrieves all orders from the database based on the search parameters provided in the query string.
-// It responds with a list of orders if successful or an informational message if no orders exist.
+// Search retrieves all orders from the database based on the search parameters provided in the query string.
+// It responds with a list of orders if successful, empty if no orders match.
 // On failure, it returns an HTTP 500 Internal Server Error.
 // The search parameters include user_id, order_date, total_amount, and status.
-func SearchAllOrders(c *gin.Context, db *gorm.DB) {
+func (h *OrderHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, orderIncludes)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"user_id", "order_date", "total_amount", "status"} {
@@ -52,7 +68,9 @@ func SearchAllOrders(c *gin.Context, db *gorm.DB) {
 					searchParams[field] = numVal
 				}
 			case "order_date":
-				searchParams[field] = cleanValue
+				if day, err := time.Parse(time.DateOnly, cleanValue); err == nil {
+					searchParams[field] = day
+				}
 			case "total_amount":
 				if numVal, err := strconv.ParseFloat(cleanValue, 64); err == nil {
 					searchParams[field] = numVal
@@ -65,127 +83,105 @@ func SearchAllOrders(c *gin.Context, db *gorm.DB) {
 		}
 	}
 
-	orders, err := models.SearchOrder(db, searchParams)
+	orders, err := h.orders.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve order", "details": err.Error()})
-		return
-	}
-
-	if len(orders) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No orders found"})
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusOK, orders)
+	respondWithETag(c, orders)
 }
 
-// CreateOrder handles the creation of a new order based on the JSON input.
+// Create handles the creation of a new order based on the JSON input.
 // It validates the input and creates the order in the database, returning the created order or an error message.
-// The user_id, order_date, total_amount, and status fields are validated for correct formatting.
-func CreateOrder(c *gin.Context, db *gorm.DB) {
+// The user_id, order_date, and status fields are validated for correct formatting. The total_amount is computed from
+// the items of the order, starting at 0; a total_amount in the body is ignored.
+func (h *OrderHandler) Create(c *gin.Context) {
 	var newOrder models.Order
 	if err := c.ShouldBindJSON(&newOrder); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
-		return
-	}
-
-	order := models.Order{
-		User_ID:      newOrder.User_ID,
-		Order_date:   newOrder.Order_date,
-		Total_amount: newOrder.Total_amount,
-		Status:       newOrder.Status,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkOrder(order, newOrder, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	if err := db.Create(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create order", "details": err.Error()})
+	order, err := h.orders.Create(c.Request.Context(), newOrder)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	metrics.OrdersCreated.Inc()
 
 	c.JSON(http.StatusCreated, order)
 }
 
-// UpdateOrder handles the updating of an existing order based on the JSON input and the ID provided in the URL.
+// Update handles the updating of an existing order based on the JSON input and the ID provided in the URL.
 // It validates the input and updates the order in the database, returning the updated order or an error message.
-// The user_id, order_date, total_amount, and status fields are validated for correct formatting.
-func UpdateOrder(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.OrderExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+// The user_id, order_date, and status fields are validated for correct formatting; the total_amount is kept.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *OrderHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	order, err := h.orders.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, order.Version) {
 		return
 	}
 
 	var updatedOrder models.Order
 	if err := c.ShouldBindJSON(&updatedOrder); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var order models.Order
-	if err := db.Where("id = ?", id).First(&order).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	if err := h.orders.Update(ctx, order, updatedOrder); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, order)
+}
 
-	order.User_ID = updatedOrder.User_ID
-	order.Order_date = updatedOrder.Order_date
-	order.Total_amount = updatedOrder.Total_amount
-	order.Status = updatedOrder.Status
-
-	if failed, err := checkOrder(order, updatedOrder, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing order based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *OrderHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	order, err := h.orders.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := db.Save(&order).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update order", "details": err.Error()})
+	if !checkIfMatch(c, order.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, order)
-}
-
-// DeleteOrder removes an order from the database based on the ID provided in the URL.
-// It responds with HTTP 204 No Content on successful deletion or an error message if the order is not found or deletion fails.
-// The order is soft-deleted using the Unscoped method to preserve data integrity.
-func DeleteOrder(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
-
-	if !models.OrderExists(db, convertedId) {
-		fmt.Println("Order does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	fields, ok := applyMergePatch(c, order, orderPatchFields)
+	if !ok {
 		return
 	}
-
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.Order{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting order"})
+	if err := h.orders.Patch(ctx, order, fields); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, order)
+}
 
-	c.JSON(http.StatusNoContent, nil)
+// Delete removes an order from the database based on the ID provided in the URL.
+// It responds with HTTP 204 No Content on successful deletion or an error message if the order is not found or deletion fails.
+// Its items, payments and shipping details are deleted with it.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *OrderHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.orders, paramID(c))
 }
 
-// checkOrder validates the input data for an order and returns an error if the data is invalid.
-// It checks the order's user_id, order_date, total_amount, and status fields for correct formatting.
-func checkOrder(order models.Order, newOrder models.Order, db *gorm.DB) (bool, error) {
-	switch true {
-	case !order.SetUserID(newOrder.User_ID, db):
-		return true, fmt.Errorf("invalid user_id or not existing")
-	case !order.SetOrderDate(newOrder.Order_date):
-		return true, fmt.Errorf("order date is not expected")
-	case !order.SetTotalAmount(newOrder.Total_amount):
-		return true, fmt.Errorf("invalid amount")
-	case !order.SetStatus(newOrder.Status):
-		return true, fmt.Errorf("payment status is not expected")
-	}
-	return false, nil
+// Restore takes a deleted order out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored order, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+// Its items, payments and shipping details deleted with it are restored too.
+func (h *OrderHandler) Restore(c *gin.Context) {
+	order, err := h.orders.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, order)
 }
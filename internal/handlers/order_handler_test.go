@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
 	"bytes"
 	"encoding/json"
@@ -12,28 +13,30 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 )
 
 // setupRouterAndDBOrder sets up the router and database in memory, and returns a function to clean up the database after tests.
 // It returns the router, database, and a teardown function.
-// It creates a new in-memory SQLite database and migrates the Order and User models.
+// It creates a new in-memory SQLite database and migrates the Order and User models with the order children.
 func setupRouterAndDBOrder(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	// Migrate both the Order and User models
-	if err := db.AutoMigrate(&models.Order{}, &models.User{}); err != nil {
+	// Migrate the Order and User models, and the tables deleted with an order
+	if err := db.AutoMigrate(&models.Order{}, &models.User{}, &models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
 	// Function to clean up the database after tests finish
 	teardown := func() {
-		if err := db.Migrator().DropTable(&models.Order{}, &models.User{}); err != nil {
+		if err := db.Migrator().DropTable(&models.Order{}, &models.User{}, &models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}); err != nil {
 			t.Fatalf("failed to drop table: %v", err)
 		}
 	}
@@ -47,12 +50,10 @@ func TestGetOrder_Success(t *testing.T) {
 	defer teardown()
 
 	// Insert mock order
-	order := models.Order{User_ID: 1, Order_date: "2021-09-15", Total_amount: 100.00, Status: "completed"}
+	order := models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"}
 	db.Create(&order)
 
-	router.GET("/orders/:id", func(c *gin.Context) {
-		GetOrder(c, db)
-	})
+	router.GET("/orders/:id", newHandlers(db).Orders.Get)
 
 	// Create a request to get the order
 	orderID := strconv.Itoa(int(order.ID))
@@ -80,9 +81,7 @@ func TestGetOrder_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrder(t)
 	defer teardown()
 
-	router.GET("/orders/:id", func(c *gin.Context) {
-		GetOrder(c, db)
-	})
+	router.GET("/orders/:id", newHandlers(db).Orders.Get)
 
 	req, _ := http.NewRequest("GET", "/orders/999", nil)
 	rr := httptest.NewRecorder()
@@ -94,7 +93,7 @@ func TestGetOrder_NotFound(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Order not found")
+	assert.Contains(t, response["detail"], "Order not found")
 }
 
 // TestGetOrders_Success checks if GetOrders returns all orders from the database.
@@ -105,12 +104,10 @@ func TestGetOrders_Success(t *testing.T) {
 	defer teardown()
 
 	// Insert mock orders
-	db.Create(&models.Order{User_ID: 1, Order_date: "2021-09-15", Total_amount: 100.00, Status: "completed"})
-	db.Create(&models.Order{User_ID: 2, Order_date: "2021-09-16", Total_amount: 200.00, Status: "pending"})
+	db.Create(&models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"})
+	db.Create(&models.Order{User_ID: 2, Order_date: time.Date(2021, 9, 16, 0, 0, 0, 0, time.UTC), Total_amount: 200.00, Status: "pending"})
 
-	router.GET("/orders", func(c *gin.Context) {
-		GetOrders(c, db)
-	})
+	router.GET("/orders", newHandlers(db).Orders.List)
 
 	req, _ := http.NewRequest("GET", "/orders", nil)
 	rr := httptest.NewRecorder()
@@ -132,9 +129,7 @@ func TestGetOrders_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrder(t)
 	defer teardown()
 
-	router.GET("/orders", func(c *gin.Context) {
-		GetOrders(c, db)
-	})
+	router.GET("/orders", newHandlers(db).Orders.List)
 
 	req, _ := http.NewRequest("GET", "/orders", nil)
 	rr := httptest.NewRecorder()
@@ -156,12 +151,10 @@ func TestSearchAllOrders_Success(t *testing.T) {
 	defer teardown()
 
 	// Insert mock orders
-	db.Create(&models.Order{User_ID: 1, Order_date: "2021-09-15", Total_amount: 100.00, Status: "completed"})
-	db.Create(&models.Order{User_ID: 2, Order_date: "2021-09-16", Total_amount: 200.00, Status: "pending"})
+	db.Create(&models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"})
+	db.Create(&models.Order{User_ID: 2, Order_date: time.Date(2021, 9, 16, 0, 0, 0, 0, time.UTC), Total_amount: 200.00, Status: "pending"})
 
-	router.GET("/orders/search", func(c *gin.Context) {
-		SearchAllOrders(c, db)
-	})
+	router.GET("/orders/search", newHandlers(db).Orders.Search)
 
 	req, _ := http.NewRequest("GET", "/orders/search?status=completed", nil)
 	rr := httptest.NewRecorder()
@@ -177,28 +170,49 @@ func TestSearchAllOrders_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, rr.Code)
 }
 
+// TestSearchAllOrders_ByDate checks that searching by order date matches every order placed on that day in UTC.
+func TestSearchAllOrders_ByDate(t *testing.T) {
+	router, db, teardown := setupRouterAndDBOrder(t)
+	defer teardown()
+
+	db.Create(&models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"})
+	db.Create(&models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 23, 59, 59, 0, time.UTC), Total_amount: 150.00, Status: "completed"})
+	db.Create(&models.Order{User_ID: 2, Order_date: time.Date(2021, 9, 16, 0, 0, 0, 0, time.UTC), Total_amount: 200.00, Status: "pending"})
+
+	router.GET("/orders/search", newHandlers(db).Orders.Search)
+
+	req, _ := http.NewRequest("GET", "/orders/search?order_date=2021-09-15", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []models.Order
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	assert.Len(t, response, 2)
+}
+
 // TestSearchAllOrders_NotFound checks if SearchAllOrders responds correctly when no orders match the search criteria.
 // It sends an HTTP GET request to the SearchAllOrders handler with a search parameter that doesn't match any orders and checks the response.
-// The response should be an HTTP 404 Not Found with an error message.
+// The response should be an HTTP 200 OK with an empty list.
 func TestSearchAllOrders_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBOrder(t)
 	defer teardown()
 
-	router.GET("/orders/search", func(c *gin.Context) {
-		SearchAllOrders(c, db)
-	})
+	router.GET("/orders/search", newHandlers(db).Orders.Search)
 
 	req, _ := http.NewRequest("GET", "/orders/search?status=shipped", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusNotFound, rr.Code)
-	var response map[string]interface{}
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response []models.Order
 	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Equal(t, "No orders found", response["error"])
+	assert.Empty(t, response)
 }
 
 // TestCreateOrder_Success checks that an order can be successfully created with valid data.
@@ -214,11 +228,9 @@ func TestCreateOrder_Success(t *testing.T) {
 		t.Fatalf("failed to create user: %v", err)
 	}
 
-	router.POST("/orders", func(c *gin.Context) {
-		CreateOrder(c, db)
-	})
+	router.POST("/orders", newHandlers(db).Orders.Create)
 
-	newOrder := `{"user_id": ` + strconv.Itoa(int(user.ID)) + `, "order_date": "2021-09-15", "total_amount": 100.00, "status": "completed"}`
+	newOrder := `{"user_id": ` + strconv.Itoa(int(user.ID)) + `, "order_date": "2021-09-15T00:00:00Z", "total_amount": 100.00, "status": "completed"}`
 	req, _ := http.NewRequest("POST", "/orders", bytes.NewBufferString(newOrder))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -230,9 +242,9 @@ func TestCreateOrder_Success(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Equal(t, uint32(user.ID), response.User_ID)
-	assert.Equal(t, "2021-09-15", response.Order_date)
-	assert.Equal(t, 100.00, response.Total_amount)
+	assert.Equal(t, user.ID, response.User_ID)
+	assert.Equal(t, time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), response.Order_date)
+	assert.Equal(t, 0.00, response.Total_amount, "the total follows the items, not the body")
 	assert.Equal(t, "completed", response.Status)
 }
 
@@ -243,9 +255,7 @@ func TestCreateOrder_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDB(t)
 	defer teardown()
 
-	router.POST("/orders", func(c *gin.Context) {
-		CreateOrder(c, db)
-	})
+	router.POST("/orders", newHandlers(db).Orders.Create)
 
 	newOrder := `{"user_id": "", "order_date": "", "total_amount": "100.00", "status": "completed"}`
 	req, _ := http.NewRequest("POST", "/orders", bytes.NewBufferString(newOrder))
@@ -259,7 +269,7 @@ func TestCreateOrder_InvalidData(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Invalid JSON data")
+	assert.Contains(t, response["detail"], "Invalid JSON data")
 }
 
 // TestUpdateOrder_Valid checks the ability to update an existing order.
@@ -276,15 +286,13 @@ func TestUpdateOrder_Valid(t *testing.T) {
 	}
 
 	// Create original order with the user's actual ID
-	order := models.Order{User_ID: uint32(user.ID), Order_date: "2021-09-15", Total_amount: 100.00, Status: "completed"}
+	order := models.Order{User_ID: user.ID, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"}
 	db.Create(&order)
 
-	router.PUT("/orders/:id", func(c *gin.Context) {
-		UpdateOrder(c, db)
-	})
+	router.PUT("/orders/:id", newHandlers(db).Orders.Update)
 
 	// Ensure you're using the correct User_ID
-	updatedOrder := `{"user_id": ` + strconv.Itoa(int(user.ID)) + `, "order_date": "2021-10-15", "total_amount": 150.00, "status": "pending"}`
+	updatedOrder := `{"user_id": ` + strconv.Itoa(int(user.ID)) + `, "order_date": "2021-10-15T00:00:00Z", "total_amount": 150.00, "status": "pending"}`
 	orderID := strconv.Itoa(int(order.ID))
 	req, _ := http.NewRequest("PUT", "/orders/"+orderID, bytes.NewBufferString(updatedOrder))
 	req.Header.Set("Content-Type", "application/json")
@@ -297,9 +305,9 @@ func TestUpdateOrder_Valid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Equal(t, uint32(user.ID), response.User_ID)
-	assert.Equal(t, "2021-10-15", response.Order_date)
-	assert.Equal(t, 150.00, response.Total_amount)
+	assert.Equal(t, user.ID, response.User_ID)
+	assert.Equal(t, time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC), response.Order_date)
+	assert.Equal(t, 100.00, response.Total_amount, "the total is kept")
 	assert.Equal(t, "pending", response.Status)
 }
 
@@ -311,12 +319,10 @@ func TestUpdateOrder_Invalid(t *testing.T) {
 	defer teardown()
 
 	// Create original order
-	order := models.Order{User_ID: 1, Order_date: "2021-09-15", Total_amount: 100.00, Status: "completed"}
+	order := models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"}
 	db.Create(&order)
 
-	router.PUT("/orders/:id", func(c *gin.Context) {
-		UpdateOrder(c, db)
-	})
+	router.PUT("/orders/:id", newHandlers(db).Orders.Update)
 
 	// Update order via HTTP PUT with invalid data
 	updatedOrder := `{"user_id": "", "order_date": "", "total_amount": "", "status": "pending"}`
@@ -332,7 +338,7 @@ func TestUpdateOrder_Invalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Invalid JSON data")
+	assert.Contains(t, response["detail"], "Invalid JSON data")
 }
 
 // TestDeleteOrder_Valid checks that an order is deleted from the database.
@@ -343,12 +349,10 @@ func TestDeleteOrder_Valid(t *testing.T) {
 	defer teardown()
 
 	// Create an order to delete
-	order := models.Order{User_ID: 1, Order_date: "2021-09-15", Total_amount: 100.00, Status: "completed"}
+	order := models.Order{User_ID: 1, Order_date: time.Date(2021, 9, 15, 0, 0, 0, 0, time.UTC), Total_amount: 100.00, Status: "completed"}
 	db.Create(&order)
 
-	router.DELETE("/orders/:id", func(c *gin.Context) {
-		DeleteOrder(c, db)
-	})
+	router.DELETE("/orders/:id", newHandlers(db).Orders.Delete)
 
 	// Delete order via HTTP DELETE
 	orderID := strconv.Itoa(int(order.ID))
@@ -363,12 +367,10 @@ func TestDeleteOrder_Valid(t *testing.T) {
 // It sends an HTTP DELETE request to the DeleteOrder handler with an invalid ID and checks the response.
 // The response should be an HTTP 404 Not Found with an error message.
 func TestDeleteOrder_Invalid(t *testing.T) {
-	router, db, teardown := setupRouterAndDB(t)
+	router, db, teardown := setupRouterAndDBOrder(t)
 	defer teardown()
 
-	router.DELETE("/orders/:id", func(c *gin.Context) {
-		DeleteOrder(c, db)
-	})
+	router.DELETE("/orders/:id", newHandlers(db).Orders.Delete)
 
 	// Delete order via HTTP DELETE with non-existing ID
 	req, _ := http.NewRequest("DELETE", "/orders/999", nil)
@@ -381,5 +383,5 @@ func TestDeleteOrder_Invalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Order not found")
+	assert.Contains(t, response["detail"], "Order not found")
 }
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"reflect"
+	"sort"
+)
+
+// MergePatchContentType is the media type of RFC 7396 JSON merge patches. Plain application/json is accepted as well.
+const MergePatchContentType = "application/merge-patch+json"
+
+// Fields of each resource that can be changed with PATCH. They are both the JSON names and the column names.
+var (
+	attributePatchFields      = []string{"category_id", "name", "label", "type", "unit"}
+	brandPatchFields          = []string{"name", "description"}
+	categoryPatchFields       = []string{"name", "description", "parent_id"}
+	orderPatchFields          = []string{"user_id", "order_date", "status"}
+	orderItemPatchFields      = []string{"order_id", "product_id", "variant_id", "quantity"}
+	paymentPatchFields        = []string{"order_id", "payment_method", "amount", "payment_date", "status"}
+	productPatchFields        = []string{"name", "description", "price", "stock_quantity", "brand_id", "category_id"}
+	productImagePatchFields   = []string{"alt", "primary"}
+	productVariantPatchFields = []string{"product_id", "sku", "options", "price", "stock_quantity"}
+	reviewPatchFields         = []string{"product_id", "user_id", "rating", "comment", "review_date"}
+	shippingDetailPatchFields = []string{"order_id", "address", "shipping_date", "estimated_arrival", "status"}
+	userPatchFields           = []string{"username", "password", "email", "first_name", "last_name", "address", "mobile"}
+)
+
+// applyMergePatch reads a JSON merge patch from the request body and applies it to row, a pointer to a model:
+// supplied members replace the current values, null resets a field to its zero value and omitted fields are kept.
+// It returns the sorted names of the patched fields. Only the fields listed in patchable may appear in the patch.
+// Otherwise an unsupported media type or bad request error is attached to c and ok is false.
+func applyMergePatch(c *gin.Context, row interface{}, patchable []string) (fields []string, ok bool) {
+	if contentType := c.ContentType(); contentType != MergePatchContentType && contentType != gin.MIMEJSON {
+		c.Error(apperr.New(apperr.KindUnsupportedMediaType, "Send a JSON merge patch as "+MergePatchContentType))
+		return nil, false
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return nil, false
+	}
+	var patch map[string]interface{}
+	if err := json.Unmarshal(body, &patch); err != nil || patch == nil {
+		c.Error(apperr.New(apperr.KindBadRequest, "Invalid JSON data: the patch must be a JSON object"))
+		return nil, false
+	}
+	for field := range patch {
+		if !contains(patchable, field) {
+			c.Error(apperr.New(apperr.KindBadRequest, "Invalid patch: field "+field+" cannot be patched"))
+			return nil, false
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var document interface{}
+	current, err := json.Marshal(row)
+	if err == nil {
+		err = json.Unmarshal(current, &document)
+	}
+	if err == nil {
+		current, err = json.Marshal(mergePatch(document, patch))
+	}
+	if err != nil {
+		c.Error(apperr.Internal("Failed to apply patch", err))
+		return nil, false
+	}
+
+	patched := reflect.New(reflect.TypeOf(row).Elem())
+	if err := json.Unmarshal(current, patched.Interface()); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return nil, false
+	}
+	reflect.ValueOf(row).Elem().Set(patched.Elem())
+	return fields, true
+}
+
+// mergePatch applies patch to target following the MergePatch algorithm of RFC 7396.
+func mergePatch(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	document, ok := target.(map[string]interface{})
+	if !ok {
+		document = map[string]interface{}{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(document, name)
+		} else {
+			document[name] = mergePatch(document[name], value)
+		}
+	}
+	return document
+}
+
+// contains reports whether values holds value.
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// patch sends a PATCH request with the given body and content type to router.
+func patch(router *gin.Engine, path, contentType, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("PATCH", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+// TestPatchProduct checks that a merge patch only changes the supplied fields of a product,
+// and that validation only applies to them.
+func TestPatchProduct(t *testing.T) {
+	router, db, _, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	var product models.Product
+	db.First(&product)
+	router.PATCH("/products/:id", newHandlers(db).Products.Patch)
+	path := "/products/" + strconv.Itoa(int(product.ID))
+
+	rr := patch(router, path, MergePatchContentType, `{"price": 899.5}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+	var patched models.Product
+	db.First(&patched, product.ID)
+	assert.Equal(t, 899.5, patched.Price)
+	assert.Equal(t, product.Name, patched.Name)
+	assert.Equal(t, product.Stock_quantity, patched.Stock_quantity)
+	assert.Equal(t, product.Brand_ID, patched.Brand_ID)
+	assert.Equal(t, product.CreatedAt.Unix(), patched.CreatedAt.Unix())
+}
+
+// TestPatchProduct_Invalid checks that patches that are not JSON objects, touch fields that cannot be patched,
+// carry invalid values or use another media type are refused without changing the product.
+func TestPatchProduct_Invalid(t *testing.T) {
+	router, db, _, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	var product models.Product
+	db.First(&product)
+	router.PATCH("/products/:id", newHandlers(db).Products.Patch)
+	path := "/products/" + strconv.Itoa(int(product.ID))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"Not an object", MergePatchContentType, `[1]`, http.StatusBadRequest},
+		{"Read-only field", MergePatchContentType, `{"id": 7}`, http.StatusBadRequest},
+		{"Unknown field", MergePatchContentType, `{"colour": "red"}`, http.StatusBadRequest},
+		{"Wrong type", MergePatchContentType, `{"price": "cheap"}`, http.StatusBadRequest},
+		{"Invalid value", MergePatchContentType, `{"price": -1}`, http.StatusBadRequest},
+		{"Null resets to an invalid value", MergePatchContentType, `{"brand_id": null}`, http.StatusBadRequest},
+		{"Media type", "text/plain", `{"price": 1}`, http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := patch(router, path, tt.contentType, tt.body)
+			assert.Equal(t, tt.expected, rr.Code, rr.Body.String())
+		})
+	}
+
+	var unchanged models.Product
+	db.First(&unchanged, product.ID)
+	assert.Equal(t, product.Price, unchanged.Price)
+	assert.Equal(t, product.Brand_ID, unchanged.Brand_ID)
+	assert.Equal(t, uint(1), unchanged.Version)
+}
+
+// TestPatchUser_Password checks that a password set with a merge patch is validated and stored hashed.
+func TestPatchUser_Password(t *testing.T) {
+	router, db, _, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	var user models.User
+	db.First(&user)
+	router.PATCH("/users/:id", newHandlers(db).Users.Patch)
+	path := "/users/" + strconv.Itoa(int(user.ID))
+
+	rr := patch(router, path, gin.MIMEJSON, `{"password": "weak"}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+
+	rr = patch(router, path, gin.MIMEJSON, `{"password": "Str0ng!Passw0rd"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var patched models.User
+	db.First(&patched, user.ID)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(patched.Password), []byte("Str0ng!Passw0rd")))
+	assert.Equal(t, user.Email, patched.Email)
+}
+
+// TestMergePatch tests the merge algorithm against the examples of RFC 7396, appendix A.
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		target   string
+		patch    string
+		expected string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+	for _, tt := range tests {
+		var target, patch interface{}
+		assert.NoError(t, json.Unmarshal([]byte(tt.target), &target))
+		assert.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+		merged, err := json.Marshal(mergePatch(target, patch))
+		assert.NoError(t, err)
+		assert.JSONEq(t, tt.expected, string(merged), "%s + %s", tt.target, tt.patch)
+	}
+}
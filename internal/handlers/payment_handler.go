@@ -1,45 +1,62 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/metrics"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// GetPayment fetches a single payment by its ID from the URL parameters.
-// It validates payment data and returns the payment details or an error message if the payment is not found or the data is invalid.
-func GetPayment(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var payment models.Payment
+// PaymentHandler serves the payment routes.
+type PaymentHandler struct {
+	payments *service.Payments
+}
 
-	if err := db.Where("id = ?", id).First(&payment).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+// Get fetches a single payment by its ID from the URL parameters.
+// It validates payment data and returns the payment details or an error message if the payment is not found or the data is invalid.
+func (h *PaymentHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, payment)
+	payment, err := h.payments.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, payment)
 }
 
-// GetPayments retrieves all payments from the database.
+// List retrieves all payments from the database.
 // It returns a list of payments or an error message if the retrieval fails.
-func GetPayments(c *gin.Context, db *gorm.DB) {
-	payments, err := models.GetAllPayments(db)
+func (h *PaymentHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	payments, err := h.payments.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving payments"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, payments)
+	respondWithETag(c, payments)
 }
 
-// SearchAllPayments retrieves all payments from the database based on the search parameters provided in the query string.
-// It responds with a list of payments if successful or an informational message if no payments exist.
+// Search retrieves all payments from the database based on the search parameters provided in the query string.
+// It responds with a list of payments if successful, empty if no payments match.
 // On failure, it returns an HTTP 500 Internal Server Error.
 // The search parameters include order_id, payment_method, amount, payment_date, and status.
-func SearchAllPayments(c *gin.Context, db *gorm.DB) {
+func (h *PaymentHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"order_id", "payment_method", "amount", "payment_date", "status"} {
@@ -57,137 +74,119 @@ func SearchAllPayments(c *gin.Context, db *gorm.DB) {
 			case "payment_method", "status":
 				searchParams[field] = strings.ToLower(cleanValue)
 			case "payment_date":
-				searchParams[field] = cleanValue
+				if day, err := time.Parse(time.DateOnly, cleanValue); err == nil {
+					searchParams[field] = day
+				}
 			default:
 				searchParams[field] = cleanValue
 			}
 		}
 	}
 
-	payments, err := models.SearchPayment(db, searchParams)
+	payments, err := h.payments.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve payment", "details": err.Error()})
-		return
-	}
-
-	if len(payments) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No payments found"})
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusOK, payments)
+	respondWithETag(c, payments)
 }
 
-// CreatePayment adds a new payment record to the database based on the JSON data provided in the request body.
+// Create adds a new payment record to the database based on the JSON data provided in the request body.
 // It validates the input data and responds with the created payment or an error message if the data is invalid or creation fails.
-func CreatePayment(c *gin.Context, db *gorm.DB) {
+func (h *PaymentHandler) Create(c *gin.Context) {
 	var newPayment models.Payment
 	if err := c.ShouldBindJSON(&newPayment); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
-		return
-	}
-
-	payment := models.Payment{
-		Order_ID:       newPayment.Order_ID,
-		Payment_method: newPayment.Payment_method,
-		Amount:         newPayment.Amount,
-		Payment_date:   newPayment.Payment_date,
-		Status:         newPayment.Status,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkPayment(payment, newPayment, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	if err := db.Create(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create payment", "details": err.Error()})
+	payment, err := h.payments.Create(c.Request.Context(), newPayment)
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	metrics.Payments.WithLabelValues(payment.Status).Inc()
 
 	c.JSON(http.StatusCreated, payment)
 }
 
-// UpdatePayment modifies an existing payment record based on the JSON input and the ID provided in the URL.
+// Update modifies an existing payment record based on the JSON input and the ID provided in the URL.
 // It checks the validity of the input data and updates the payment in the database, responding
 // with the updated payment or an error message.
-func UpdatePayment(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.PaymentExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *PaymentHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	payment, err := h.payments.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, payment.Version) {
 		return
 	}
 
 	var updatedPayment models.Payment
 	if err := c.ShouldBindJSON(&updatedPayment); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var payment models.Payment
-	if err := db.Where("id = ?", id).First(&payment).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+	previousStatus := payment.Status
+	if err := h.payments.Update(ctx, payment, updatedPayment); err != nil {
+		c.Error(err)
 		return
 	}
+	if payment.Status != previousStatus {
+		metrics.Payments.WithLabelValues(payment.Status).Inc()
+	}
+	respondSaved(c, payment)
+}
 
-	payment.Order_ID = updatedPayment.Order_ID
-	payment.Payment_method = updatedPayment.Payment_method
-	payment.Amount = updatedPayment.Amount
-	payment.Payment_date = updatedPayment.Payment_date
-	payment.Status = updatedPayment.Status
-
-	if failed, err := checkPayment(payment, updatedPayment, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing payment based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *PaymentHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	payment, err := h.payments.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := db.Save(&payment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update payment", "details": err.Error()})
+	if !checkIfMatch(c, payment.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, payment)
-}
-
-// DeletePayment removes a payment record from the database based on its ID provided in the URL.
-// It handles the deletion process and responds with HTTP 204 No Content on success or an
-// error message if the payment is not found or deletion fails.
-func DeletePayment(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
-
-	if !models.PaymentExists(db, convertedId) {
-		fmt.Println("Payment does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+	previousStatus := payment.Status
+	fields, ok := applyMergePatch(c, payment, paymentPatchFields)
+	if !ok {
 		return
 	}
-
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.Payment{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting payment"})
+	if err := h.payments.Patch(ctx, payment, fields); err != nil {
+		c.Error(err)
 		return
 	}
+	if payment.Status != previousStatus {
+		metrics.Payments.WithLabelValues(payment.Status).Inc()
+	}
+	respondSaved(c, payment)
+}
 
-	c.JSON(http.StatusNoContent, nil)
+// Delete removes a payment record from the database based on its ID provided in the URL.
+// It handles the deletion process and responds with HTTP 204 No Content on success or an
+// error message if the payment is not found or deletion fails.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *PaymentHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.payments, paramID(c))
 }
 
-// checkPayment validates the input data for a payment and returns an error if the data is invalid.
-// It checks the payment's order_id, payment_method, amount, payment_date, and status fields for correct formatting.
-func checkPayment(payment models.Payment, newPayment models.Payment, db *gorm.DB) (bool, error) {
-	switch true {
-	case !payment.SetOrderID(newPayment.Order_ID, db):
-		return true, fmt.Errorf("invalid order_id or not existing")
-	case !payment.SetPaymentMethod(newPayment.Payment_method):
-		return true, fmt.Errorf("payment metode is not expected")
-	case !payment.SetAmount(newPayment.Amount):
-		return true, fmt.Errorf("invalid amount")
-	case !payment.SetPaymentDate(newPayment.Payment_date):
-		return true, fmt.Errorf("invalid payment date")
-	case !payment.SetStatus(newPayment.Status):
-		return true, fmt.Errorf("payment status is not expected")
-	}
-	return false, nil
+// Restore takes a deleted payment out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored payment, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+func (h *PaymentHandler) Restore(c *gin.Context) {
+	payment, err := h.payments.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, payment)
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
 	"bytes"
 	"encoding/json"
@@ -13,6 +14,7 @@ import (
 	"net/http/httptest"
 	"strconv"
 	"testing"
+	"time"
 )
 
 // setupRouterAndDBPayment sets up the router and database in memory, including the migration of Order and Payment models.
@@ -20,6 +22,7 @@ import (
 func setupRouterAndDBPayment(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
@@ -48,12 +51,10 @@ func TestGetPayment_Success(t *testing.T) {
 
 	order := models.Order{Total_amount: 100.00}
 	db.Create(&order)
-	payment := models.Payment{Order_ID: uint32(order.ID), Payment_method: "credit card", Amount: 100.00, Payment_date: "2022-01-01", Status: "completed"}
+	payment := models.Payment{Order_ID: order.ID, Payment_method: "credit card", Amount: 100.00, Payment_date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Status: "completed"}
 	db.Create(&payment)
 
-	router.GET("/payments/:id", func(c *gin.Context) {
-		GetPayment(c, db)
-	})
+	router.GET("/payments/:id", newHandlers(db).Payments.Get)
 
 	req, _ := http.NewRequest("GET", "/payments/"+strconv.Itoa(int(payment.ID)), nil)
 	rr := httptest.NewRecorder()
@@ -75,9 +76,7 @@ func TestGetPayment_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBPayment(t)
 	defer teardown()
 
-	router.GET("/payments/:id", func(c *gin.Context) {
-		GetPayment(c, db)
-	})
+	router.GET("/payments/:id", newHandlers(db).Payments.Get)
 
 	req, _ := http.NewRequest("GET", "/payments/999", nil)
 	rr := httptest.NewRecorder()
@@ -89,7 +88,7 @@ func TestGetPayment_NotFound(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Payment not found")
+	assert.Contains(t, response["detail"], "Payment not found")
 }
 
 // TestGetPayments_Success verifies that all payments are correctly retrieved from the database.
@@ -102,12 +101,10 @@ func TestGetPayments_Success(t *testing.T) {
 
 	order := models.Order{Total_amount: 200.00}
 	db.Create(&order)
-	db.Create(&models.Payment{Order_ID: uint32(order.ID), Payment_method: "credit card", Amount: 100.00, Payment_date: "2022-01-01", Status: "completed"})
-	db.Create(&models.Payment{Order_ID: uint32(order.ID), Payment_method: "paypal", Amount: 100.00, Payment_date: "2022-01-02", Status: "completed"})
+	db.Create(&models.Payment{Order_ID: order.ID, Payment_method: "credit card", Amount: 100.00, Payment_date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Status: "completed"})
+	db.Create(&models.Payment{Order_ID: order.ID, Payment_method: "paypal", Amount: 100.00, Payment_date: time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC), Status: "completed"})
 
-	router.GET("/payments", func(c *gin.Context) {
-		GetPayments(c, db)
-	})
+	router.GET("/payments", newHandlers(db).Payments.List)
 
 	req, _ := http.NewRequest("GET", "/payments", nil)
 	rr := httptest.NewRecorder()
@@ -132,11 +129,9 @@ func TestSearchAllPayments_Success(t *testing.T) {
 
 	order := models.Order{Total_amount: 300.00}
 	db.Create(&order)
-	db.Create(&models.Payment{Order_ID: uint32(order.ID), Payment_method: "paypal", Amount: 300.00, Payment_date: "2022-01-01", Status: "completed"})
+	db.Create(&models.Payment{Order_ID: order.ID, Payment_method: "paypal", Amount: 300.00, Payment_date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Status: "completed"})
 
-	router.GET("/payments/search", func(c *gin.Context) {
-		SearchAllPayments(c, db)
-	})
+	router.GET("/payments/search", newHandlers(db).Payments.Search)
 
 	req, _ := http.NewRequest("GET", "/payments/search?payment_method=paypal", nil)
 	rr := httptest.NewRecorder()
@@ -162,11 +157,9 @@ func TestCreatePayment_Success(t *testing.T) {
 	order := models.Order{Total_amount: 500.00}
 	db.Create(&order)
 
-	router.POST("/payments", func(c *gin.Context) {
-		CreatePayment(c, db)
-	})
+	router.POST("/payments", newHandlers(db).Payments.Create)
 
-	newPayment := fmt.Sprintf(`{"order_id": %d, "payment_method": "debit card", "amount": 500.00, "payment_date": "2022-01-01", "status": "completed"}`, order.ID)
+	newPayment := fmt.Sprintf(`{"order_id": %d, "payment_method": "debit card", "amount": 500.00, "payment_date": "2022-01-01T00:00:00Z", "status": "completed"}`, order.ID)
 	req, _ := http.NewRequest("POST", "/payments", bytes.NewBufferString(newPayment))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -178,7 +171,7 @@ func TestCreatePayment_Success(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Equal(t, uint32(order.ID), response.Order_ID)
+	assert.Equal(t, order.ID, response.Order_ID)
 	assert.Equal(t, "completed", response.Status)
 }
 
@@ -190,9 +183,7 @@ func TestCreatePayment_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDBPayment(t)
 	defer teardown()
 
-	router.POST("/payments", func(c *gin.Context) {
-		CreatePayment(c, db)
-	})
+	router.POST("/payments", newHandlers(db).Payments.Create)
 
 	newPayment := `{"order_id": "", "payment_method": "123", "amount": "five hundred", "payment_date": "01-01-2022", "status": "completed"}`
 	req, _ := http.NewRequest("POST", "/payments", bytes.NewBufferString(newPayment))
@@ -206,7 +197,7 @@ func TestCreatePayment_InvalidData(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Invalid JSON data")
+	assert.Contains(t, response["detail"], "Invalid JSON data")
 }
 
 // TestUpdatePayment_Valid checks the ability to update an existing payment.
@@ -219,14 +210,12 @@ func TestUpdatePayment_Valid(t *testing.T) {
 
 	order := models.Order{Total_amount: 400.00}
 	db.Create(&order)
-	payment := models.Payment{Order_ID: uint32(order.ID), Payment_method: "debit card", Amount: 400.00, Payment_date: "2022-01-01", Status: "pending"}
+	payment := models.Payment{Order_ID: order.ID, Payment_method: "debit card", Amount: 400.00, Payment_date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Status: "pending"}
 	db.Create(&payment)
 
-	router.PUT("/payments/:id", func(c *gin.Context) {
-		UpdatePayment(c, db)
-	})
+	router.PUT("/payments/:id", newHandlers(db).Payments.Update)
 
-	updatedPayment := fmt.Sprintf(`{"order_id": %d, "payment_method": "debit card", "amount": 400.00, "payment_date": "2022-01-01", "status": "completed"}`, order.ID)
+	updatedPayment := fmt.Sprintf(`{"order_id": %d, "payment_method": "debit card", "amount": 400.00, "payment_date": "2022-01-01T00:00:00Z", "status": "completed"}`, order.ID)
 	req, _ := http.NewRequest("PUT", "/payments/"+strconv.Itoa(int(payment.ID)), bytes.NewBufferString(updatedPayment))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -251,12 +240,10 @@ func TestUpdatePayment_Invalid(t *testing.T) {
 
 	order := models.Order{Total_amount: 300.00}
 	db.Create(&order)
-	payment := models.Payment{Order_ID: uint32(order.ID), Payment_method: "credit card", Amount: 300.00, Payment_date: "2022-01-01", Status: "pending"}
+	payment := models.Payment{Order_ID: order.ID, Payment_method: "credit card", Amount: 300.00, Payment_date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Status: "pending"}
 	db.Create(&payment)
 
-	router.PUT("/payments/:id", func(c *gin.Context) {
-		UpdatePayment(c, db)
-	})
+	router.PUT("/payments/:id", newHandlers(db).Payments.Update)
 
 	updatedPayment := `{"order_id": "", "payment_method": "", "amount": "", "payment_date": "", "status": ""}`
 	req, _ := http.NewRequest("PUT", "/payments/"+strconv.Itoa(int(payment.ID)), bytes.NewBufferString(updatedPayment))
@@ -270,7 +257,7 @@ func TestUpdatePayment_Invalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Invalid JSON data")
+	assert.Contains(t, response["detail"], "Invalid JSON data")
 }
 
 // TestDeletePayment_Valid checks that a payment is deleted from the database.
@@ -283,12 +270,10 @@ func TestDeletePayment_Valid(t *testing.T) {
 
 	order := models.Order{Total_amount: 100.00}
 	db.Create(&order)
-	payment := models.Payment{Order_ID: uint32(order.ID), Payment_method: "credit card", Amount: 100.00, Payment_date: "2022-01-01", Status: "completed"}
+	payment := models.Payment{Order_ID: order.ID, Payment_method: "credit card", Amount: 100.00, Payment_date: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Status: "completed"}
 	db.Create(&payment)
 
-	router.DELETE("/payments/:id", func(c *gin.Context) {
-		DeletePayment(c, db)
-	})
+	router.DELETE("/payments/:id", newHandlers(db).Payments.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/payments/"+strconv.Itoa(int(payment.ID)), nil)
 	rr := httptest.NewRecorder()
@@ -305,9 +290,7 @@ func TestDeletePayment_Invalid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBPayment(t)
 	defer teardown()
 
-	router.DELETE("/payments/:id", func(c *gin.Context) {
-		DeletePayment(c, db)
-	})
+	router.DELETE("/payments/:id", newHandlers(db).Payments.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/payments/999", nil)
 	rr := httptest.NewRecorder()
@@ -319,5 +302,5 @@ func TestDeletePayment_Invalid(t *testing.T) {
 		t.Fatal("Failed to parse response JSON")
 	}
 
-	assert.Contains(t, response["error"], "Payment not found")
+	assert.Contains(t, response["detail"], "Payment not found")
 }
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
+	"E-Commerce_Website_Database/internal/tools"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// ProductImageHandler serves the routes of the galleries of products.
+type ProductImageHandler struct {
+	images *service.ProductImages
+	media  *service.Media
+}
+
+// List retrieves the images of the product with the ID provided in the URL in their order.
+// It responds with HTTP 200 OK and the images, empty if there are none, or HTTP 404 Not Found if the product
+// does not exist.
+func (h *ProductImageHandler) List(c *gin.Context) {
+	images, err := h.images.OfProduct(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, images)
+}
+
+// Upload adds the image uploaded in the file field of a multipart/form-data body to the end of the gallery
+// of the product with the ID provided in the URL, stored with a thumbnail. The optional alt field describes
+// the image, and primary=true makes it the primary image; the first image of a product is always primary.
+// It responds with HTTP 201 Created and the image, HTTP 404 Not Found if the product does not exist, HTTP 400
+// Bad Request for invalid fields, HTTP 413 Request Entity Too Large for images over the limits, or HTTP 415
+// Unsupported Media Type if the file is not a JPEG, PNG or GIF image, whatever its name or declared type.
+func (h *ProductImageHandler) Upload(c *gin.Context) {
+	file, ok := readUpload(c, h.media.MaxUploadSize())
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	input := models.ProductImage{Alt: c.PostForm("alt")}
+	if value := c.PostForm("primary"); value != "" {
+		primary, err := strconv.ParseBool(value)
+		if err != nil {
+			c.Error(apperr.BadRequest("Invalid primary: primary must be true or false", nil))
+			return
+		}
+		input.Primary = primary
+	}
+
+	image, err := h.images.Upload(c.Request.Context(), paramID(c), file, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, image)
+}
+
+// Patch applies a JSON merge patch (RFC 7396) to the image with the ID provided in the URL of the product.
+// Its alt text can be changed, and "primary": true makes it the primary image in place of another one.
+// It responds with HTTP 200 OK and the image, HTTP 404 Not Found if the image does not exist, HTTP 400 Bad Request
+// for invalid fields, or HTTP 415 Unsupported Media Type when the body is not JSON.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductImageHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	image, err := h.images.Image(ctx, paramID(c), imageID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, image.Version) {
+		return
+	}
+
+	fields, ok := applyMergePatch(c, image, productImagePatchFields)
+	if !ok {
+		return
+	}
+	if err := h.images.Patch(ctx, image, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, image)
+}
+
+// Reorder arranges the gallery of the product with the ID provided in the URL in the order of the JSON input,
+// {"image_ids": [...]} listing each of its images exactly once.
+// It responds with HTTP 200 OK and the images in their new order, HTTP 404 Not Found if the product does not exist,
+// or HTTP 400 Bad Request if the list does not hold every image once.
+func (h *ProductImageHandler) Reorder(c *gin.Context) {
+	var input struct {
+		Image_IDs []uint `json:"image_ids"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+
+	images, err := h.images.Reorder(c.Request.Context(), paramID(c), input.Image_IDs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, images)
+}
+
+// Delete moves the image with the ID provided in the URL of the product to the trash, responding with
+// HTTP 204 No Content. Its files are deleted once it is purged from the trash.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductImageHandler) Delete(c *gin.Context) {
+	version, ok := ifMatchVersion(c, h.images, imageID(c))
+	if !ok {
+		return
+	}
+	if err := h.images.Delete(c.Request.Context(), paramID(c), imageID(c), version); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// Restore takes a deleted image of the product out of the trash based on the IDs provided in the URL.
+// It responds with HTTP 200 OK and the restored image, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if its product is still deleted.
+func (h *ProductImageHandler) Restore(c *gin.Context) {
+	image, err := h.images.Restore(c.Request.Context(), paramID(c), imageID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, image)
+}
+
+// imageID returns the ID of the image given in the URL, 0 when it is not a number so that no image is found.
+func imageID(c *gin.Context) uint {
+	return tools.ConvertStringToUint(c.Param("imageId"))
+}
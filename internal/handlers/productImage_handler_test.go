@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
+	"E-Commerce_Website_Database/internal/storage"
+	"E-Commerce_Website_Database/internal/tools"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setupRouterAndDBProductImage sets up the router and database in memory, including the migration of Brands,
+// Category, Product and ProductImage models, and handlers storing files in a temporary directory with small limits.
+// It returns the router, database, handlers, and a teardown function to clean up the database after tests finish.
+func setupRouterAndDBProductImage(t *testing.T) (*gin.Engine, *gorm.DB, *Handlers, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Errors())
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.Brands{}, &models.Category{}, &models.Product{}, &models.ProductImage{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	files, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to open media directory: %v", err)
+	}
+	limits := imaging.Limits{MaxBytes: 4096, MaxPixels: 1_000_000, ThumbnailSize: 16}
+	h := New(service.New(repository.NewGORM(db), files, limits), &tools.JWTTokenService{})
+
+	// Function to clean up the database after tests finish
+	teardown := func() {
+		if err := db.Migrator().DropTable(&models.Brands{}, &models.Category{}, &models.Product{}, &models.ProductImage{}); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	return router, db, h, teardown
+}
+
+// multipartBody returns a multipart/form-data body holding data in the file field, named name, and the other fields,
+// along with its content type.
+func multipartBody(t *testing.T, name string, data []byte, fields map[string]string) (*bytes.Buffer, string) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for field, value := range fields {
+		writer.WriteField(field, value)
+	}
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		t.Fatalf("failed to create form file: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+	return &body, writer.FormDataContentType()
+}
+
+// testPNG returns a width by height black PNG image.
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestProductImageIntegration checks the image routes: uploading images to the gallery of a product, serving their
+// files with cache headers, reordering them, choosing the primary image and deleting one, and uploading the logo
+// of a brand.
+func TestProductImageIntegration(t *testing.T) {
+	router, db, h, teardown := setupRouterAndDBProductImage(t)
+	defer teardown()
+
+	db.Create(&models.Brands{Model: gorm.Model{ID: 1}, Name: "Acme"})
+	db.Create(&models.Category{Model: gorm.Model{ID: 1}, Name: "Laptops", Path: models.CategoryPath(nil, 1)})
+	db.Create(&models.Product{Model: gorm.Model{ID: 1}, Name: "Laptop", Price: 1299, Brand_ID: 1, Category_ID: 1})
+
+	router.GET("/products/:id", h.Products.Get)
+	router.GET("/products/:id/images", h.ProductImages.List)
+	router.POST("/products/:id/images", h.ProductImages.Upload)
+	router.PUT("/products/:id/images/order", h.ProductImages.Reorder)
+	router.PATCH("/products/:id/images/:imageId", h.ProductImages.Patch)
+	router.DELETE("/products/:id/images/:imageId", h.ProductImages.Delete)
+	router.PUT("/brand/:id/logo", h.Brands.SetLogo)
+	router.DELETE("/brand/:id/logo", h.Brands.RemoveLogo)
+	router.GET(models.MediaPath+"*key", h.Media.Serve)
+	router.HEAD(models.MediaPath+"*key", h.Media.Serve)
+	serve := func(req *http.Request) (*httptest.ResponseRecorder, map[string]interface{}) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+	upload := func(method, url, name string, data []byte, fields map[string]string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		body, contentType := multipartBody(t, name, data, fields)
+		req, _ := http.NewRequest(method, url, body)
+		req.Header.Set("Content-Type", contentType)
+		return serve(req)
+	}
+	request := func(method, url, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		return serve(req)
+	}
+
+	front := testPNG(t, 64, 32)
+	rr, first := upload("POST", "/products/1/images", "front.jpg", front, map[string]string{"alt": "Front view"})
+	if !assert.Equal(t, http.StatusCreated, rr.Code, rr.Body.String()) {
+		return
+	}
+	assert.Equal(t, "image/png", first["content_type"], "the type is sniffed from the content, not the name")
+	assert.Equal(t, true, first["primary"])
+	assert.Equal(t, "Front view", first["alt"])
+	rr, second := upload("POST", "/products/1/images", "back.png", testPNG(t, 8, 8), map[string]string{"primary": "true"})
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, true, second["primary"])
+
+	rr, response := upload("POST", "/products/1/images", "page.png", []byte("<html><body>not an image</body></html>"), nil)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	assert.Equal(t, "/problems/unsupported-media-type", response["type"])
+	rr, response = upload("POST", "/products/1/images", "huge.png", bytes.Repeat([]byte{0}, 100<<10), nil)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+	assert.Equal(t, "/problems/too-large", response["type"])
+	rr, _ = request("POST", "/products/1/images", `{"file": "front.png"}`)
+	assert.Equal(t, http.StatusUnsupportedMediaType, rr.Code)
+	rr, _ = upload("POST", "/products/99/images", "front.png", front, nil)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	req, _ := http.NewRequest("GET", first["url"].(string), nil)
+	rr, _ = serve(req)
+	if assert.Equal(t, http.StatusOK, rr.Code) {
+		assert.Equal(t, front, rr.Body.Bytes())
+		assert.Equal(t, "image/png", rr.Header().Get("Content-Type"))
+		assert.Equal(t, "public, max-age=31536000, immutable", rr.Header().Get("Cache-Control"))
+		assert.Equal(t, "nosniff", rr.Header().Get("X-Content-Type-Options"))
+	}
+	req, _ = http.NewRequest("GET", first["url"].(string), nil)
+	req.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	rr, _ = serve(req)
+	assert.Equal(t, http.StatusNotModified, rr.Code)
+	req, _ = http.NewRequest("HEAD", first["thumbnail_url"].(string), nil)
+	rr, _ = serve(req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Body.Bytes())
+	req, _ = http.NewRequest("GET", models.MediaPath+"../../etc/passwd", nil)
+	rr, _ = serve(req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+
+	firstID, secondID := uint(first["ID"].(float64)), uint(second["ID"].(float64))
+	rr, _ = request("PUT", "/products/1/images/order", fmt.Sprintf(`{"image_ids": [%d]}`, secondID))
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "every image should be listed")
+	rr, _ = request("PUT", "/products/1/images/order", fmt.Sprintf(`{"image_ids": [%d, %d]}`, secondID, firstID))
+	assert.Equal(t, http.StatusOK, rr.Code)
+	rr, response = request("GET", "/products/1?include=images", "")
+	if assert.Equal(t, http.StatusOK, rr.Code) {
+		images := response["images"].([]interface{})
+		if assert.Len(t, images, 2) {
+			assert.Equal(t, second["ID"], images[0].(map[string]interface{})["ID"])
+		}
+	}
+
+	rr, response = request("PATCH", fmt.Sprintf("/products/1/images/%d", firstID), `{"primary": true, "alt": "Front"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, true, response["primary"])
+	assert.Equal(t, "Front", response["alt"])
+	rr, _ = request("PATCH", fmt.Sprintf("/products/1/images/%d", firstID), `{"primary": false}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "the primary image changes by making another one primary")
+	rr, _ = request("DELETE", fmt.Sprintf("/products/1/images/%d", firstID), "")
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+	req, _ = http.NewRequest("GET", "/products/1/images", nil)
+	rr, _ = serve(req)
+	var images []map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &images)
+	if assert.Len(t, images, 1) {
+		assert.Equal(t, true, images[0]["primary"], "the remaining image becomes primary")
+	}
+
+	rr, brand := upload("PUT", "/brand/1/logo", "logo.png", testPNG(t, 40, 20), nil)
+	if assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String()) {
+		logo := brand["logo"].(map[string]interface{})
+		assert.Equal(t, float64(40), logo["width"])
+		rr, brand = request("DELETE", "/brand/1/logo", "")
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Nil(t, brand["logo"])
+		var stored models.Brands
+		db.First(&stored, 1)
+		assert.Nil(t, stored.Logo, "the logo columns are cleared")
+		req, _ = http.NewRequest("GET", logo["url"].(string), nil)
+		rr, _ = serve(req)
+		assert.Equal(t, http.StatusNotFound, rr.Code, "the files of a removed logo are deleted")
+	}
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProductVariantHandler serves the product variant routes.
+type ProductVariantHandler struct {
+	variants *service.ProductVariants
+}
+
+// Get fetches a single product variant by the ID provided in the URL.
+// It responds with HTTP 404 Not Found if the variant does not exist.
+func (h *ProductVariantHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	variant, err := h.variants.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, variant)
+}
+
+// List retrieves the variants of every product, an empty list if there are none.
+func (h *ProductVariantHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	variants, err := h.variants.List(c.Request.Context(), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, variants)
+}
+
+// Search retrieves the variants matching the query string: sku matches a substring, product_id and stock_quantity
+// are compared exactly. It responds with an empty list if no variants match.
+func (h *ProductVariantHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	searchParams := map[string]interface{}{}
+
+	for _, field := range []string{"sku", "product_id", "stock_quantity"} {
+		if value := c.Query(field); value != "" {
+			cleanValue := strings.TrimSpace(value)
+			if field == "sku" {
+				searchParams[field] = cleanValue
+			} else if numVal, err := strconv.Atoi(cleanValue); err == nil {
+				searchParams[field] = numVal
+			}
+		}
+	}
+
+	variants, err := h.variants.Search(c.Request.Context(), searchParams, q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, variants)
+}
+
+// Create adds a variant to a product from JSON input.
+// It responds with HTTP 201 Created and the variant, HTTP 400 Bad Request if the input is invalid,
+// or HTTP 409 Conflict if another variant already has its SKU.
+func (h *ProductVariantHandler) Create(c *gin.Context) {
+	var newVariant models.ProductVariant
+	if err := c.ShouldBindJSON(&newVariant); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+
+	variant, err := h.variants.Create(c.Request.Context(), newVariant)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, variant)
+}
+
+// Update replaces the variant with the ID provided in the URL by the JSON input.
+// It responds like Create, with HTTP 200 OK on success and HTTP 404 Not Found if the variant does not exist.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductVariantHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	variant, err := h.variants.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, variant.Version) {
+		return
+	}
+
+	var updatedVariant models.ProductVariant
+	if err := c.ShouldBindJSON(&updatedVariant); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+
+	if err := h.variants.Update(ctx, variant, updatedVariant); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, variant)
+}
+
+// Patch applies a JSON merge patch (RFC 7396) to the variant with the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept,
+// so a null price makes the variant use the price of its product.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *ProductVariantHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	variant, err := h.variants.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, variant.Version) {
+		return
+	}
+
+	fields, ok := applyMergePatch(c, variant, productVariantPatchFields)
+	if !ok {
+		return
+	}
+	if err := h.variants.Patch(ctx, variant, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, variant)
+}
+
+// Delete moves the variant with the ID provided in the URL to the trash, responding with HTTP 204 No Content.
+// A variant that was ordered is answered with HTTP 409 Conflict.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductVariantHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.variants, paramID(c))
+}
+
+// Restore takes a deleted variant out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored variant, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if its product is still deleted.
+func (h *ProductVariantHandler) Restore(c *gin.Context) {
+	variant, err := h.variants.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, variant)
+}
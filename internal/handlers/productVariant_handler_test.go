@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// setupRouterAndDBProductVariant sets up the router and database in memory, including the migration of Product,
+// ProductVariant, Order and OrderItem models, with unique index violations reported as conflicts.
+// It returns the router, database, and a teardown function to clean up the database after tests finish.
+func setupRouterAndDBProductVariant(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.Use(middleware.Errors())
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	if err := db.AutoMigrate(&models.Product{}, &models.ProductVariant{}, &models.Order{}, &models.OrderItem{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	// Function to clean up the database after tests finish
+	teardown := func() {
+		if err := db.Migrator().DropTable(&models.Product{}, &models.ProductVariant{}, &models.Order{}, &models.OrderItem{}); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	return router, db, teardown
+}
+
+// TestProductVariantIntegration checks the variant routes: creating variants of a product, refusing a duplicate SKU
+// or combination of options, reading the option matrix of the product, patching the price back to that of the
+// product, and ordering a variant of the product only.
+func TestProductVariantIntegration(t *testing.T) {
+	router, db, teardown := setupRouterAndDBProductVariant(t)
+	defer teardown()
+
+	db.Create(&models.Product{Model: gorm.Model{ID: 1}, Name: "Phone", Price: 999})
+	db.Create(&models.Product{Model: gorm.Model{ID: 2}, Name: "Charger", Price: 29})
+	db.Create(&models.Order{Model: gorm.Model{ID: 5}, Total_amount: 999})
+
+	h := newHandlers(db)
+	router.POST("/productVariants", h.ProductVariants.Create)
+	router.PATCH("/productVariants/:id", h.ProductVariants.Patch)
+	router.GET("/products/:id", h.Products.Get)
+	router.POST("/orderItems", h.OrderItems.Create)
+	request := func(method, url, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		var response map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		return rr, response
+	}
+
+	rr, _ := request("POST", "/productVariants", `{"product_id": 1, "sku": "PHN-128-BLK", "options": {"color": "black", "storage": "128GB"}, "price": 949, "stock_quantity": 4}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	rr, _ = request("POST", "/productVariants", `{"product_id": 1, "sku": "PHN-256-WHT", "options": {"color": "white", "storage": "256GB"}}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+
+	rr, _ = request("POST", "/productVariants", `{"product_id": 1, "sku": "PHN-128-BLK", "options": {"color": "black", "storage": "256GB"}}`)
+	assert.Equal(t, http.StatusConflict, rr.Code, "a SKU should be unique")
+	var black models.ProductVariant
+	assert.NoError(t, db.Where("sku = ?", "PHN-128-BLK").First(&black).Error)
+	blackID := strconv.FormatUint(uint64(black.ID), 10)
+	rr, response := request("POST", "/productVariants", `{"product_id": 1, "sku": "PHN-128-BLK-2", "options": {"color": "black", "storage": "128GB"}}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Contains(t, response["errors"], "options")
+
+	rr, response = request("GET", "/products/1?include=variants", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, response["variants"], 2)
+	assert.Equal(t, map[string]interface{}{
+		"color":   []interface{}{"black", "white"},
+		"storage": []interface{}{"128GB", "256GB"},
+	}, response["options"])
+	rr, response = request("GET", "/products/2?include=variants", "")
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, response, "options")
+
+	rr, response = request("PATCH", "/productVariants/"+blackID, `{"price": null}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Nil(t, response["price"])
+	assert.Equal(t, 4.0, response["stock_quantity"])
+
+	rr, _ = request("POST", "/orderItems", `{"order_id": 5, "product_id": 1, "quantity": 1, "subtotal": 999}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "an item of a product with variants should select one")
+	rr, _ = request("POST", "/orderItems", `{"order_id": 5, "product_id": 2, "variant_id": `+blackID+`, "quantity": 1, "subtotal": 29}`)
+	assert.Equal(t, http.StatusBadRequest, rr.Code, "the variant should belong to the ordered product")
+	rr, response = request("POST", "/orderItems", `{"order_id": 5, "product_id": 1, "variant_id": `+blackID+`, "quantity": 1, "subtotal": 999}`)
+	assert.Equal(t, http.StatusCreated, rr.Code)
+	assert.Equal(t, float64(black.ID), response["variant_id"])
+}
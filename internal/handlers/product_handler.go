@@ -1,49 +1,66 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"strings"
 )
 
-// GetProduct retrieves a single product by its ID.
+// ProductHandler serves the product routes.
+type ProductHandler struct {
+	products *service.Products
+}
+
+// Get retrieves a single product by its ID.
 // It checks for the product's existence and validity of its data, then returns the product details or an error message.
 // If the product is not found, it responds with an HTTP 404 Not Found status.
 // If the product is found, it responds with an HTTP 200 OK status and the product details in JSON format.
-func GetProduct(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var product models.Product
-
-	if err := db.Where("id = ?", id).First(&product).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+func (h *ProductHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, productIncludes)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, product)
+	product, err := h.products.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, product)
 }
 
-// GetProducts retrieves all products from the database.
+// List retrieves all products from the database.
 // It returns a JSON response with a list of products or an error message if the retrieval fails.
-// If there are no products in the database, it responds with an HTTP 404 Not Found status.
+// If there are no products in the database, it responds with an empty list.
 // If the retrieval is successful, it responds with an HTTP 200 OK status and the list of products in JSON format.
-func GetProducts(c *gin.Context, db *gorm.DB) {
-	products, err := models.GetAllProducts(db)
+// Other query parameters than include and trashed filter the products by the values of their attributes,
+// e.g. ?ram_gb[gte]=16&wifi=true; an unknown attribute or a value not of its type is answered with HTTP 400 Bad Request.
+func (h *ProductHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, productIncludes)
+	if !ok {
+		return
+	}
+	products, err := h.products.Filter(c.Request.Context(), filterParams(c), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving products"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, products)
+	respondWithETag(c, products)
 }
 
-// SearchAllProducts performs a search on products based on provided query parameters.
+// Search performs a search on products based on provided query parameters.
 // It constructs a search query dynamically and returns the matching products or an appropriate error message.
-// If no products are found, it responds with an HTTP 404 Not Found status.
+// If no products are found, it responds with an HTTP 200 OK status and an empty list.
 // If the search is successful, it responds with an HTTP 200 OK status and the list of products in JSON format.
-func SearchAllProducts(c *gin.Context, db *gorm.DB) {
+func (h *ProductHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, productIncludes)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"name", "description", "price", "stock_quantity", "brand_name", "category_name"} {
@@ -59,139 +76,162 @@ func SearchAllProducts(c *gin.Context, db *gorm.DB) {
 		}
 	}
 
-	// Search for products based on the search parameters
-	products, err := models.SearchProduct(db, searchParams)
+	products, err := h.products.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve products", "details": err.Error()})
-		return
-	}
-
-	if len(products) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No products found"})
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusOK, products)
+	respondWithETag(c, products)
 }
 
-// CreateProduct handles the creation of a new product from JSON input.
+// Create handles the creation of a new product from JSON input.
 // It validates the input and stores the new product in the database, responding with the created product or an error message.
 // If the input data is invalid, it responds with an HTTP 400 Bad Request status and an error message.
 // If the product is created successfully, it responds with an HTTP 201 Created status and the product details in JSON format.
-func CreateProduct(c *gin.Context, db *gorm.DB) {
+func (h *ProductHandler) Create(c *gin.Context) {
 	var newProduct models.Product
 	if err := c.ShouldBindJSON(&newProduct); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	product := models.Product{
-		Name:           newProduct.Name,
-		Description:    newProduct.Description,
-		Price:          newProduct.Price,
-		Stock_quantity: newProduct.Stock_quantity,
-		Brand_ID:       newProduct.Brand_ID,
-		Category_ID:    newProduct.Category_ID,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkProduct(product, newProduct, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
-		return
-	}
-
-	if err := db.Create(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create product", "details": err.Error()})
+	product, err := h.products.Create(c.Request.Context(), newProduct)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, product)
 }
 
-// UpdateProduct handles the updating of an existing product.
+// Update handles the updating of an existing product.
 // It validates the provided input and updates the product in the database, responding with the updated product or an error message.
 // If the product does not exist, it responds with an HTTP 404 Not Found status.
 // If the input data is invalid, it responds with an HTTP 400 Bad Request status and an error message.
 // If the update is successful, it responds with an HTTP 200 OK status and the updated product details in JSON format.
-func UpdateProduct(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.ProductExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	product, err := h.products.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, product.Version) {
 		return
 	}
 
 	var newProduct models.Product
 	if err := c.ShouldBindJSON(&newProduct); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var product models.Product
-	if err := db.Where("id = ?", id).First(&product).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found during update"})
+	if err := h.products.Update(ctx, product, newProduct); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, product)
+}
 
-	product.Name = newProduct.Name
-	product.Description = newProduct.Description
-	product.Price = newProduct.Price
-	product.Stock_quantity = newProduct.Stock_quantity
-	product.Brand_ID = newProduct.Brand_ID
-	product.Category_ID = newProduct.Category_ID
-
-	if failed, err := checkProduct(product, newProduct, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing product based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *ProductHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	product, err := h.products.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := db.Save(&product).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product", "details": err.Error()})
+	if !checkIfMatch(c, product.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	fields, ok := applyMergePatch(c, product, productPatchFields)
+	if !ok {
+		return
+	}
+	if err := h.products.Patch(ctx, product, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, product)
 }
 
-// DeleteProduct handles the deletion of a product by its ID.
+// Delete handles the deletion of a product by its ID.
 // It validates the product's existence and removes it from the database, responding with an appropriate message.
 // If the product does not exist, it responds with an HTTP 404 Not Found status.
 // If the deletion is successful, it responds with an HTTP 204 No Content status.
-func DeleteProduct(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
+// Reviews of the product are deleted with it, while a product that was ordered is answered with HTTP 409 Conflict.
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.products, paramID(c))
+}
 
-	if !models.ProductExists(db, convertedId) {
-		fmt.Println("Product does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+// Restore takes a deleted product out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored product, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+// Its reviews deleted with it are restored too.
+func (h *ProductHandler) Restore(c *gin.Context) {
+	product, err := h.products.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, product)
+}
+
+// Facets retrieves the values of the attributes of every category among the products, with the number of products
+// having each. It accepts the attribute filters of List; the values of a filtered attribute are counted without
+// its own filters, so that they can be offered as alternatives.
+func (h *ProductHandler) Facets(c *gin.Context) {
+	facets, err := h.products.Facets(c.Request.Context(), filterParams(c))
+	if err != nil {
+		c.Error(err)
 		return
 	}
+	respondWithETag(c, facets)
+}
 
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.Product{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting product"})
+// Attributes retrieves the specification sheet of the product with the ID provided in the URL, the values of
+// its attributes. It responds with HTTP 404 Not Found if the product does not exist.
+func (h *ProductHandler) Attributes(c *gin.Context) {
+	values, err := h.products.Attributes(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusNoContent, gin.H{"message": "Product deleted"})
+	respondWithETag(c, values)
 }
 
-// checkProduct performs validation checks on product data.
-// It returns a boolean indicating failure and an error with the validation issue.
-func checkProduct(product models.Product, newProduct models.Product, db *gorm.DB) (bool, error) {
-	switch true {
-	case !product.SetName(newProduct.Name):
-		return true, fmt.Errorf("name is wrong formatted")
-	case !product.SetDescription(newProduct.Description):
-		return true, fmt.Errorf("description is wrong formatted")
-	case !product.SetPrice(newProduct.Price):
-		return true, fmt.Errorf("invalid price")
-	case !product.SetStockQuantity(newProduct.Stock_quantity):
-		return true, fmt.Errorf("invalid stock quantity")
-	case !product.SetBrandID(newProduct.Brand_ID, db):
-		return true, fmt.Errorf("invalid brand_id or not existing")
-	case !product.SetCategoryID(newProduct.Category_ID, db):
-		return true, fmt.Errorf("invalid category_id or not existing")
-	}
-	return false, nil
+// SetAttributes replaces the specification sheet of the product with the ID provided in the URL by the JSON input,
+// values keyed by attribute name such as {"ram_gb": 16, "wifi": true}; attributes left out or null lose their value.
+// It responds with HTTP 200 OK and the new sheet, HTTP 404 Not Found if the product does not exist, or HTTP 400
+// Bad Request if a name is not that of an attribute of its category or a value is not of the type of its attribute.
+// The sheet is part of the product: replacing it increments the version of the product, and an If-Match header not
+// matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ProductHandler) SetAttributes(c *gin.Context) {
+	ctx := c.Request.Context()
+	product, err := h.products.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, product.Version) {
+		return
+	}
+
+	var input map[string]interface{}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
+		return
+	}
+
+	values, err := h.products.SetAttributes(ctx, product, input)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, values)
 }
@@ -12,6 +12,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
 )
 
@@ -21,19 +22,20 @@ import (
 func setupRouterAndDBProduct(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.Product{}, &models.Brands{}, &models.Category{}); err != nil {
+	if err := db.AutoMigrate(&models.Product{}, &models.Brands{}, &models.Category{}, &models.OrderItem{}, &models.Review{}, &models.ProductImage{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
 	// Function to clean up the database after tests finish
 	teardown := func() {
-		if err := db.Migrator().DropTable(&models.Product{}, &models.Brands{}, &models.Category{}); err != nil {
+		if err := db.Migrator().DropTable(&models.Product{}, &models.Brands{}, &models.Category{}, &models.OrderItem{}, &models.Review{}, &models.ProductImage{}); err != nil {
 			t.Fatalf("failed to drop table: %v", err)
 		}
 	}
@@ -50,9 +52,7 @@ func TestGetProduct_Success(t *testing.T) {
 	product := models.Product{Name: "Sample Product", Price: 19.99}
 	db.Create(&product)
 
-	router.GET("/products/:id", func(c *gin.Context) {
-		GetProduct(c, db)
-	})
+	router.GET("/products/:id", newHandlers(db).Products.Get)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/products/%d", product.ID), nil)
 	rr := httptest.NewRecorder()
@@ -74,9 +74,7 @@ func TestGetProduct_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBProduct(t)
 	defer teardown()
 
-	router.GET("/products/:id", func(c *gin.Context) {
-		GetProduct(c, db)
-	})
+	router.GET("/products/:id", newHandlers(db).Products.Get)
 
 	req, _ := http.NewRequest("GET", "/products/999", nil)
 	rr := httptest.NewRecorder()
@@ -95,9 +93,7 @@ func TestGetProducts_Success(t *testing.T) {
 	db.Create(&models.Product{Name: "Sample Product 1", Price: 10.00})
 	db.Create(&models.Product{Name: "Sample Product 2", Price: 20.00})
 
-	router.GET("/products", func(c *gin.Context) {
-		GetProducts(c, db)
-	})
+	router.GET("/products", newHandlers(db).Products.List)
 
 	req, _ := http.NewRequest("GET", "/products", nil)
 	rr := httptest.NewRecorder()
@@ -119,9 +115,7 @@ func TestGetProducts_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBProduct(t)
 	defer teardown()
 
-	router.GET("/products", func(c *gin.Context) {
-		GetProducts(c, db)
-	})
+	router.GET("/products", newHandlers(db).Products.List)
 
 	req, _ := http.NewRequest("GET", "/products", nil)
 	rr := httptest.NewRecorder()
@@ -150,12 +144,10 @@ func TestSearchProducts_Success(t *testing.T) {
 	db.Create(&category)
 
 	// Create products that should match the search query
-	db.Create(&models.Product{Name: "Gadget 1", Description: "Hei", Price: 99.99, Stock_quantity: 50, Brand_ID: uint32(brand.ID), Category_ID: uint32(category.ID)})
-	db.Create(&models.Product{Name: "Gadget 2", Description: "Hei", Price: 149.99, Stock_quantity: 100, Brand_ID: uint32(brand.ID), Category_ID: uint32(category.ID)})
+	db.Create(&models.Product{Name: "Gadget 1", Description: "Hei", Price: 99.99, Stock_quantity: 50, Brand_ID: brand.ID, Category_ID: category.ID})
+	db.Create(&models.Product{Name: "Gadget 2", Description: "Hei", Price: 149.99, Stock_quantity: 100, Brand_ID: brand.ID, Category_ID: category.ID})
 
-	router.GET("/products/search/", func(c *gin.Context) {
-		SearchAllProducts(c, db)
-	})
+	router.GET("/products/search/", newHandlers(db).Products.Search)
 
 	// Define a request with a search query
 	req, _ := http.NewRequest("GET", "/products/search/?name=Gadg", nil)
@@ -173,21 +165,22 @@ func TestSearchProducts_Success(t *testing.T) {
 
 // TestSearchProducts_Empty tests the SearchAllProducts handler with no products matching the search query.
 // It sends a GET request with a search query that should not match any products and checks the response.
-// The test passes if the response status code is 404 Not Found.
+// The test passes if the response status code is 200 OK with an empty list.
 func TestSearchProducts_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBProduct(t)
 	defer teardown()
 
 	router.GET("/products/search", func(c *gin.Context) {
 		c.Request.URL.RawQuery = "name=Nonexistent"
-		SearchAllProducts(c, db)
+		newHandlers(db).Products.Search(c)
 	})
 
 	req, _ := http.NewRequest("GET", "/products/search", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, "[]", rr.Body.String())
 }
 
 // TestCreateProduct_Success tests the CreateProduct handler with valid input data.
@@ -202,9 +195,7 @@ func TestCreateProduct_Success(t *testing.T) {
 	category := models.Category{Name: "Electronics"}
 	db.Create(&category)
 
-	router.POST("/products", func(c *gin.Context) {
-		CreateProduct(c, db)
-	})
+	router.POST("/products", newHandlers(db).Products.Create)
 
 	newProduct := fmt.Sprintf(`{"name": "New Product", "price": 25.50, "description": "A brand new product", "stock_quantity": 100, "brand_id": %d, "category_id": %d}`, brand.ID, category.ID)
 	req, _ := http.NewRequest("POST", "/products", bytes.NewBufferString(newProduct))
@@ -232,9 +223,7 @@ func TestCreateProduct_InvalidData(t *testing.T) {
 	db.Create(&brand)
 	category := models.Category{Name: "Electronics"}
 	db.Create(&category)
-	router.POST("/products", func(c *gin.Context) {
-		CreateProduct(c, db)
-	})
+	router.POST("/products", newHandlers(db).Products.Create)
 
 	newProduct := fmt.Sprintf(`{"name": "", "price": 25.50, "description": "", "stock_quantity": , "brand_id": %d, "category_id": %d}`, brand.ID, category.ID)
 	req, _ := http.NewRequest("POST", "/products", bytes.NewBufferString(newProduct))
@@ -257,14 +246,12 @@ func TestUpdateProduct_Success(t *testing.T) {
 	category := models.Category{Name: "Gadgets"}
 	db.Create(&category)
 
-	product := models.Product{Name: "Old Product", Price: 15.00, Brand_ID: uint32(brand.ID), Category_ID: uint32(category.ID)}
+	product := models.Product{Name: "Old Product", Price: 15.00, Brand_ID: brand.ID, Category_ID: category.ID}
 	db.Create(&product)
 
-	router.PUT("/products/:id", func(c *gin.Context) {
-		UpdateProduct(c, db)
-	})
+	router.PUT("/products/:id", newHandlers(db).Products.Update)
 
-	updateData := fmt.Sprintf(`{"name": "Updated Product", "price": 20.00,"description": "A brand new product", "stock_quantity": 100, "brand_id": %d, "category_id": %d}`, uint32(category.ID), uint32(brand.ID))
+	updateData := fmt.Sprintf(`{"name": "Updated Product", "price": 20.00,"description": "A brand new product", "stock_quantity": 100, "brand_id": %d, "category_id": %d}`, category.ID, brand.ID)
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/products/%d", product.ID), bytes.NewBufferString(updateData))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -286,9 +273,7 @@ func TestUpdateProduct_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBProduct(t)
 	defer teardown()
 
-	router.PUT("/products/:id", func(c *gin.Context) {
-		UpdateProduct(c, db)
-	})
+	router.PUT("/products/:id", newHandlers(db).Products.Update)
 
 	updateData := `{"name": "Updated Product", "price": 50.00}`
 	req, _ := http.NewRequest("PUT", "/products/999", bytes.NewBufferString(updateData))
@@ -309,9 +294,7 @@ func TestDeleteProduct_Valid(t *testing.T) {
 	product := models.Product{Name: "Delete Product", Price: 30.00}
 	db.Create(&product)
 
-	router.DELETE("/products/:id", func(c *gin.Context) {
-		DeleteProduct(c, db)
-	})
+	router.DELETE("/products/:id", newHandlers(db).Products.Delete)
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/products/%d", product.ID), nil)
 	rr := httptest.NewRecorder()
@@ -327,9 +310,7 @@ func TestDeleteProduct_Invalid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBProduct(t)
 	defer teardown()
 
-	router.DELETE("/products/:id", func(c *gin.Context) {
-		DeleteProduct(c, db)
-	})
+	router.DELETE("/products/:id", newHandlers(db).Products.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/products/999", nil)
 	rr := httptest.NewRecorder()
@@ -1,49 +1,65 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// GetReview retrieves a single review by its ID.
+// ReviewHandler serves the review routes.
+type ReviewHandler struct {
+	reviews *service.Reviews
+}
+
+// Get retrieves a single review by its ID.
 // It checks for the review's existence and validity of its data, then returns the review details or an error message.
 // If the review is not found, it responds with an HTTP 404 Not Found status.
 // If the review is found, it responds with an HTTP 200 OK status and the review details in JSON format.
-func GetReview(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var review models.Review
-
-	if err := db.Where("id = ?", id).First(&review).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+func (h *ReviewHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, reviewIncludes)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, review)
+	review, err := h.reviews.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, review)
 }
 
-// GetReviews retrieves all reviews from the database.
+// List retrieves all reviews from the database.
 // It returns a JSON response with a list of reviews or an error message if the retrieval fails.
-// If there are no reviews in the database, it responds with an HTTP 404 Not Found status.
+// If there are no reviews in the database, it responds with an empty list.
 // If the retrieval is successful, it responds with an HTTP 200 OK status and the list of reviews in JSON format.
-func GetReviews(c *gin.Context, db *gorm.DB) {
-	reviews, err := models.GetAllReviews(db)
+func (h *ReviewHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, reviewIncludes)
+	if !ok {
+		return
+	}
+	reviews, err := h.reviews.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving reviews"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, reviews)
+	respondWithETag(c, reviews)
 }
 
-// SearchAllReviews performs a search on reviews based on provided query parameters.
+// Search performs a search on reviews based on provided query parameters.
 // It constructs a search query dynamically and returns the matching reviews or an appropriate error message.
-// If no reviews are found, it responds with an HTTP 404 Not Found status.
+// If no reviews are found, it responds with an HTTP 200 OK status and an empty list.
 // If the search is successful, it responds with an HTTP 200 OK status and the list of reviews in JSON format.
-func SearchAllReviews(c *gin.Context, db *gorm.DB) {
+func (h *ReviewHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, reviewIncludes)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"product_id", "user_id", "rating", "comment", "review_date"} {
@@ -54,144 +70,117 @@ func SearchAllReviews(c *gin.Context, db *gorm.DB) {
 				if numVal, err := strconv.Atoi(cleanValue); err == nil {
 					searchParams[field] = numVal
 				}
-			case "comment", "review_date":
+			case "comment":
 				searchParams[field] = cleanValue
+			case "review_date":
+				if day, err := time.Parse(time.DateOnly, cleanValue); err == nil {
+					searchParams[field] = day
+				}
 			default:
 				searchParams[field] = cleanValue
 			}
 		}
 	}
 
-	reviews, err := models.SearchReview(db, searchParams)
+	reviews, err := h.reviews.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reviews", "details": err.Error()})
-		return
-	}
-
-	if len(reviews) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No reviews found"})
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusOK, reviews)
+	respondWithETag(c, reviews)
 }
 
-// CreateReview adds a new review to the database.
+// Create adds a new review to the database.
 // It validates the review data and responds with the newly created review or an error message.
 // If the review data is invalid, it responds with an HTTP 400 Bad Request status.
 // If the creation is successful, it responds with an HTTP 201 Created status and the created review in JSON format.
-func CreateReview(c *gin.Context, db *gorm.DB) {
+func (h *ReviewHandler) Create(c *gin.Context) {
 	var newReview models.Review
 	if err := c.ShouldBindJSON(&newReview); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	review := models.Review{
-		Product_ID:  newReview.Product_ID,
-		User_ID:     newReview.User_ID,
-		Rating:      newReview.Rating,
-		Comment:     newReview.Comment,
-		Review_Date: newReview.Review_Date,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkReview(review, newReview, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
-		return
-	}
-
-	if err := db.Create(&review).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review", "details": err.Error()})
+	review, err := h.reviews.Create(c.Request.Context(), newReview)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, review)
 }
 
-// UpdateReview updates an existing review in the database.
+// Update updates an existing review in the database.
 // It validates the updated review data and responds with the updated review or an error message.
 // If the review data is invalid, it responds with an HTTP 400 Bad Request status.
 // If the update is successful, it responds with an HTTP 200 OK status and the updated review in JSON format.
-func UpdateReview(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.ReviewExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "review not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ReviewHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	review, err := h.reviews.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, review.Version) {
 		return
 	}
 
 	var updatedReview models.Review
 	if err := c.ShouldBindJSON(&updatedReview); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var review models.Review
-	if err := db.Where("id = ?", id).First(&review).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shipping Detail not found"})
+	if err := h.reviews.Update(ctx, review, updatedReview); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, review)
+}
 
-	review.Product_ID = updatedReview.Product_ID
-	review.User_ID = updatedReview.User_ID
-	review.Rating = updatedReview.Rating
-	review.Comment = updatedReview.Comment
-	review.Review_Date = updatedReview.Review_Date
-
-	if failed, err := checkReview(review, updatedReview, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing review based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *ReviewHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	review, err := h.reviews.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := db.Save(&review).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review", "details": err.Error()})
+	if !checkIfMatch(c, review.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, review)
+	fields, ok := applyMergePatch(c, review, reviewPatchFields)
+	if !ok {
+		return
+	}
+	if err := h.reviews.Patch(ctx, review, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, review)
 }
 
-// DeleteReview removes a review from the database.
+// Delete removes a review from the database.
 // It checks for the review's existence and responds with an appropriate status code.
 // If the review is not found, it responds with an HTTP 404 Not Found status.
 // If the deletion is successful, it responds with an HTTP 204 No Content status.
-func DeleteReview(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
-
-	if !models.ReviewExists(db, convertedId) {
-		fmt.Println("Review does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
-		return
-	}
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ReviewHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.reviews, paramID(c))
+}
 
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.Review{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting Review"})
+// Restore takes a deleted review out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored review, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+func (h *ReviewHandler) Restore(c *gin.Context) {
+	review, err := h.reviews.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusNoContent, nil)
-}
-
-// checkReview validates the review data before creating or updating a review.
-// It checks the product ID, user ID, rating, comment, and review date for validity.
-// If any of the data is invalid, it returns an error message and true, indicating a failure.
-// If all data is valid, it returns false and nil, indicating success.
-func checkReview(review models.Review, newReview models.Review, db *gorm.DB) (bool, error) {
-	switch true {
-	case !review.SetProductID(newReview.Product_ID, db):
-		return true, fmt.Errorf("invalid Product id or not existing")
-	case !review.SetUserID(newReview.User_ID, db):
-		return true, fmt.Errorf("invalid user id or not existing")
-	case !review.SetRating(newReview.Rating):
-		return true, fmt.Errorf("the rate must be between 1 and 5(best)")
-	case !review.SetComment(newReview.Comment):
-		return true, fmt.Errorf("the comment is not valid")
-	case !review.SetReviewDate(newReview.Review_Date):
-		return true, fmt.Errorf("review date is not expected")
-	}
-	return false, nil
+	c.JSON(http.StatusOK, review)
 }
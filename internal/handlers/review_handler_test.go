@@ -11,7 +11,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
 )
 
@@ -20,6 +22,7 @@ import (
 func setupRouterAndDBReview(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
@@ -50,12 +53,10 @@ func TestGetReview_Success(t *testing.T) {
 	db.Create(&user)
 	product := models.Product{Name: "Test Product", Price: 10.99}
 	db.Create(&product)
-	review := models.Review{Product_ID: uint32(product.ID), User_ID: uint32(user.ID), Rating: 5, Comment: "Great product"}
+	review := models.Review{Product_ID: product.ID, User_ID: user.ID, Rating: 5, Comment: "Great product"}
 	db.Create(&review)
 
-	router.GET("/reviews/:id", func(c *gin.Context) {
-		GetReview(c, db)
-	})
+	router.GET("/reviews/:id", newHandlers(db).Reviews.Get)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/reviews/%d", review.ID), nil)
 	rr := httptest.NewRecorder()
@@ -77,9 +78,7 @@ func TestGetReview_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBReview(t)
 	defer teardown()
 
-	router.GET("/reviews/:id", func(c *gin.Context) {
-		GetReview(c, db)
-	})
+	router.GET("/reviews/:id", newHandlers(db).Reviews.Get)
 
 	req, _ := http.NewRequest("GET", "/reviews/999", nil)
 	rr := httptest.NewRecorder()
@@ -99,12 +98,10 @@ func TestGetReviews_Success(t *testing.T) {
 	db.Create(&user)
 	product := models.Product{Name: "Product One", Price: 15.00}
 	db.Create(&product)
-	db.Create(&models.Review{Product_ID: uint32(product.ID), User_ID: uint32(user.ID), Rating: 4, Comment: "Good"})
-	db.Create(&models.Review{Product_ID: uint32(product.ID), User_ID: uint32(user.ID), Rating: 3, Comment: "Average"})
+	db.Create(&models.Review{Product_ID: product.ID, User_ID: user.ID, Rating: 4, Comment: "Good"})
+	db.Create(&models.Review{Product_ID: product.ID, User_ID: user.ID, Rating: 3, Comment: "Average"})
 
-	router.GET("/reviews", func(c *gin.Context) {
-		GetReviews(c, db)
-	})
+	router.GET("/reviews", newHandlers(db).Reviews.List)
 
 	req, _ := http.NewRequest("GET", "/reviews", nil)
 	rr := httptest.NewRecorder()
@@ -127,9 +124,7 @@ func TestGetReviews_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBReview(t)
 	defer teardown()
 
-	router.GET("/reviews", func(c *gin.Context) {
-		GetReviews(c, db)
-	})
+	router.GET("/reviews", newHandlers(db).Reviews.List)
 
 	req, _ := http.NewRequest("GET", "/reviews", nil)
 	rr := httptest.NewRecorder()
@@ -155,11 +150,11 @@ func TestSearchAllReviews_Success(t *testing.T) {
 	db.Create(&user)
 	product := models.Product{Name: "Product Two", Price: 20.00}
 	db.Create(&product)
-	db.Create(&models.Review{Product_ID: uint32(product.ID), User_ID: uint32(user.ID), Rating: 5, Comment: "Excellent"})
+	db.Create(&models.Review{Product_ID: product.ID, User_ID: user.ID, Rating: 5, Comment: "Excellent"})
 
 	router.GET("/reviews/search", func(c *gin.Context) {
 		c.Request.URL.RawQuery = "rating=5"
-		SearchAllReviews(c, db)
+		newHandlers(db).Reviews.Search(c)
 	})
 
 	req, _ := http.NewRequest("GET", "/reviews/search", nil)
@@ -176,21 +171,22 @@ func TestSearchAllReviews_Success(t *testing.T) {
 }
 
 // TestSearchAllReviews_Empty tests searching for reviews that do not match any existing data.
-// The test passes if the response status code is 404, indicating that no reviews were found.
+// The test passes if the response status code is 200 with an empty list, indicating that no reviews were found.
 func TestSearchAllReviews_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBReview(t)
 	defer teardown()
 
 	router.GET("/reviews/search", func(c *gin.Context) {
 		c.Request.URL.RawQuery = "rating=1"
-		SearchAllReviews(c, db)
+		newHandlers(db).Reviews.Search(c)
 	})
 
 	req, _ := http.NewRequest("GET", "/reviews/search", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, "[]", rr.Body.String())
 }
 
 // TestCreateReview_Success tests successful creation of a review.
@@ -205,11 +201,9 @@ func TestCreateReview_Success(t *testing.T) {
 	product := models.Product{Name: "New Product", Price: 25.99}
 	db.Create(&product)
 
-	router.POST("/reviews", func(c *gin.Context) {
-		CreateReview(c, db)
-	})
+	router.POST("/reviews", newHandlers(db).Reviews.Create)
 
-	newReview := fmt.Sprintf(`{"product_id": %d, "user_id": %d, "rating": 5, "comment": "Fantastic!", "review_date": "2023-01-05"}`, product.ID, user.ID)
+	newReview := fmt.Sprintf(`{"product_id": %d, "user_id": %d, "rating": 5, "comment": "Fantastic!", "review_date": "2023-01-05T00:00:00Z"}`, product.ID, user.ID)
 	req, _ := http.NewRequest("POST", "/reviews", bytes.NewBufferString(newReview))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -233,9 +227,7 @@ func TestCreateReview_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDBReview(t)
 	defer teardown()
 
-	router.POST("/reviews", func(c *gin.Context) {
-		CreateReview(c, db)
-	})
+	router.POST("/reviews", newHandlers(db).Reviews.Create)
 
 	newReview := `{"product_id": 999, "user_id": 999, "rating": 6, "comment": ""}`
 	req, _ := http.NewRequest("POST", "/reviews", bytes.NewBufferString(newReview))
@@ -257,14 +249,12 @@ func TestUpdateReview_Success(t *testing.T) {
 	db.Create(&user)
 	product := models.Product{Name: "Product Update", Price: 30.00}
 	db.Create(&product)
-	review := models.Review{Product_ID: uint32(product.ID), User_ID: uint32(user.ID), Rating: 3, Comment: "Okay", Review_Date: "2023-01-05"}
+	review := models.Review{Product_ID: product.ID, User_ID: user.ID, Rating: 3, Comment: "Okay", Review_Date: time.Date(2023, 1, 5, 0, 0, 0, 0, time.UTC)}
 	db.Create(&review)
 
-	router.PUT("/reviews/:id", func(c *gin.Context) {
-		UpdateReview(c, db)
-	})
+	router.PUT("/reviews/:id", newHandlers(db).Reviews.Update)
 
-	updateData := fmt.Sprintf(`{"product_id": %d, "user_id": %d, "rating": 4, "comment": "Better", "review_date": "2023-01-06"}`, product.ID, user.ID)
+	updateData := fmt.Sprintf(`{"product_id": %d, "user_id": %d, "rating": 4, "comment": "Better", "review_date": "2023-01-06T00:00:00Z"}`, product.ID, user.ID)
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/reviews/%d", review.ID), bytes.NewBufferString(updateData))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -286,9 +276,7 @@ func TestUpdateReview_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBReview(t)
 	defer teardown()
 
-	router.PUT("/reviews/:id", func(c *gin.Context) {
-		UpdateReview(c, db)
-	})
+	router.PUT("/reviews/:id", newHandlers(db).Reviews.Update)
 
 	updateData := `{"product_id": 1, "user_id": 1, "rating": 5, "comment": "Excellent"}`
 	req, _ := http.NewRequest("PUT", "/reviews/999", bytes.NewBufferString(updateData))
@@ -310,12 +298,10 @@ func TestDeleteReview_Valid(t *testing.T) {
 	db.Create(&user)
 	product := models.Product{Name: "Product Delete", Price: 35.00}
 	db.Create(&product)
-	review := models.Review{Product_ID: uint32(product.ID), User_ID: uint32(user.ID), Rating: 2, Comment: "Not good"}
+	review := models.Review{Product_ID: product.ID, User_ID: user.ID, Rating: 2, Comment: "Not good"}
 	db.Create(&review)
 
-	router.DELETE("/reviews/:id", func(c *gin.Context) {
-		DeleteReview(c, db)
-	})
+	router.DELETE("/reviews/:id", newHandlers(db).Reviews.Delete)
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/reviews/%d", review.ID), nil)
 	rr := httptest.NewRecorder()
@@ -331,9 +317,7 @@ func TestDeleteReview_Invalid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBReview(t)
 	defer teardown()
 
-	router.DELETE("/reviews/:id", func(c *gin.Context) {
-		DeleteReview(c, db)
-	})
+	router.DELETE("/reviews/:id", newHandlers(db).Reviews.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/reviews/999", nil)
 	rr := httptest.NewRecorder()
@@ -1,49 +1,65 @@
 package handlers
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
 	"E-Commerce_Website_Database/internal/models"
-	"E-Commerce_Website_Database/internal/tools"
-	"fmt"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/service"
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
-// GetShippingDetail retrieves a single shipping detail by its ID.
+// ShippingDetailHandler serves the shipping detail routes.
+type ShippingDetailHandler struct {
+	shippingDetails *service.ShippingDetails
+}
+
+// Get retrieves a single shipping detail by its ID.
 // It checks for the shipping detail's existence and validity of its data, then returns the shipping detail details or an error message.
 // If the shipping detail is not found, it responds with an HTTP 404 Not Found status.
 // If the shipping detail is found, it responds with an HTTP 200 OK status and the shipping detail details in JSON format.
-func GetShippingDetail(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	var shippingDetail models.ShippingDetails
-
-	if err := db.Where("id = ?", id).First(&shippingDetail).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shipping Detail not found"})
+func (h *ShippingDetailHandler) Get(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
 		return
 	}
-	c.JSON(http.StatusOK, shippingDetail)
+	shippingDetail, err := h.shippingDetails.Get(c.Request.Context(), paramID(c), q)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	respondWithETag(c, shippingDetail)
 }
 
-// GetShippingDetails retrieves all shipping details from the database.
+// List retrieves all shipping details from the database.
 // It returns a JSON response with a list of shipping details or an error message if the retrieval fails.
-// If there are no shipping details in the database, it responds with an HTTP 404 Not Found status.
+// If there are no shipping details in the database, it responds with an empty list.
 // If the retrieval is successful, it responds with an HTTP 200 OK status and the list of shipping details in JSON format.
-func GetShippingDetails(c *gin.Context, db *gorm.DB) {
-	shippingDetails, err := models.GetAllShippingDetails(db)
+func (h *ShippingDetailHandler) List(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
+	shippingDetails, err := h.shippingDetails.List(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error retrieving Shipping Details"})
+		c.Error(err)
 		return
 	}
-	c.JSON(http.StatusOK, shippingDetails)
+	respondWithETag(c, shippingDetails)
 }
 
-// SearchAllShippingDetails performs a search on shipping details based on provided query parameters.
+// Search performs a search on shipping details based on provided query parameters.
 // It constructs a search query dynamically and returns the matching shipping details or an appropriate error message.
-// If no shipping details are found, it responds with an HTTP 404 Not Found status.
+// If no shipping details are found, it responds with an HTTP 200 OK status and an empty list.
 // If the search is successful, it responds with an HTTP 200 OK status and the list of shipping details in JSON format.
-func SearchAllShippingDetails(c *gin.Context, db *gorm.DB) {
+func (h *ShippingDetailHandler) Search(c *gin.Context) {
+	q, ok := readQuery(c, nil)
+	if !ok {
+		return
+	}
 	searchParams := map[string]interface{}{}
 
 	for _, field := range []string{"order_id", "address", "shipping_date", "estimated_arrival", "status"} {
@@ -54,8 +70,12 @@ func SearchAllShippingDetails(c *gin.Context, db *gorm.DB) {
 				if numVal, err := strconv.Atoi(cleanValue); err == nil {
 					searchParams[field] = numVal
 				}
-			case "address", "shipping_date", "estimated_arrival":
+			case "address":
 				searchParams[field] = cleanValue
+			case "shipping_date", "estimated_arrival":
+				if day, err := time.Parse(time.DateOnly, cleanValue); err == nil {
+					searchParams[field] = day
+				}
 			case "status":
 				searchParams[field] = strings.ToLower(cleanValue)
 			default:
@@ -64,136 +84,105 @@ func SearchAllShippingDetails(c *gin.Context, db *gorm.DB) {
 		}
 	}
 
-	shippingDetail, err := models.SearchShippingDetails(db, searchParams)
+	shippingDetails, err := h.shippingDetails.Search(c.Request.Context(), searchParams, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Shipping Details", "details": err.Error()})
+		c.Error(err)
 		return
 	}
-
-	if len(shippingDetail) == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "No Shipping Details found"})
-		return
-	}
-
-	c.JSON(http.StatusOK, shippingDetail)
+	respondWithETag(c, shippingDetails)
 }
 
-// CreateShippingDetail creates a new shipping detail record in the database.
+// Create creates a new shipping detail record in the database.
 // It validates the incoming JSON data, creates a new shipping detail, and returns the newly created shipping detail or an error message.
 // If the JSON data is invalid, it responds with an HTTP 400 Bad Request status.
 // If the creation is successful, it responds with an HTTP 201 Created status and the created shipping detail in JSON format.
-func CreateShippingDetail(c *gin.Context, db *gorm.DB) {
+func (h *ShippingDetailHandler) Create(c *gin.Context) {
 	var newShippingDetail models.ShippingDetails
 	if err := c.ShouldBindJSON(&newShippingDetail); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	shippingDetail := models.ShippingDetails{
-		Order_ID:          newShippingDetail.Order_ID,
-		Address:           newShippingDetail.Address,
-		Shipping_Date:     newShippingDetail.Shipping_Date,
-		Estimated_Arrival: newShippingDetail.Estimated_Arrival,
-		Status:            newShippingDetail.Status,
-		Model: gorm.Model{
-			ID: uint(tools.GenerateUUID()),
-		},
-	}
-
-	if failed, err := checkShippingDetail(shippingDetail, newShippingDetail, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
-		return
-	}
-
-	if err := db.Create(&shippingDetail).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create Shipping Detail", "details": err.Error()})
+	shippingDetail, err := h.shippingDetails.Create(c.Request.Context(), newShippingDetail)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, shippingDetail)
 }
 
-// UpdateShippingDetail updates an existing shipping detail record in the database.
+// Update updates an existing shipping detail record in the database.
 // It validates the incoming JSON data, updates the shipping detail, and returns the updated shipping detail or an error message.
 // If the JSON data is invalid, it responds with an HTTP 400 Bad Request status.
 // If the update is successful, it responds with an HTTP 200 OK status and the updated shipping detail in JSON format.
-func UpdateShippingDetail(c *gin.Context, db *gorm.DB) {
-	id := tools.ConvertStringToUint(c.Param("id"))
-
-	if !models.ShippingDetailsExists(db, id) {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shipping Detail not found"})
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ShippingDetailHandler) Update(c *gin.Context) {
+	ctx := c.Request.Context()
+	shippingDetail, err := h.shippingDetails.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !checkIfMatch(c, shippingDetail.Version) {
 		return
 	}
 
 	var updatedShippingDetail models.ShippingDetails
 	if err := c.ShouldBindJSON(&updatedShippingDetail); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON data", "details": err.Error()})
+		c.Error(apperr.BadRequest("Invalid JSON data", err))
 		return
 	}
 
-	var shippingDetail models.ShippingDetails
-	if err := db.Where("id = ?", id).First(&shippingDetail).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shipping Detail not found"})
+	if err := h.shippingDetails.Update(ctx, shippingDetail, updatedShippingDetail); err != nil {
+		c.Error(err)
 		return
 	}
+	respondSaved(c, shippingDetail)
+}
 
-	shippingDetail.Order_ID = updatedShippingDetail.Order_ID
-	shippingDetail.Address = updatedShippingDetail.Address
-	shippingDetail.Shipping_Date = updatedShippingDetail.Shipping_Date
-	shippingDetail.Estimated_Arrival = updatedShippingDetail.Estimated_Arrival
-	shippingDetail.Status = updatedShippingDetail.Status
-
-	if failed, err := checkShippingDetail(shippingDetail, updatedShippingDetail, db); failed {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Validation error", "details": err.Error()})
+// Patch applies a JSON merge patch (RFC 7396) to an existing shipping detail based on the ID provided in the URL.
+// Only the fields present in the patch are validated and updated; null resets a field and omitted fields are kept.
+// It responds like Update, and with HTTP 415 Unsupported Media Type when the body is not JSON.
+func (h *ShippingDetailHandler) Patch(c *gin.Context) {
+	ctx := c.Request.Context()
+	shippingDetail, err := h.shippingDetails.Get(ctx, paramID(c), repository.Query{})
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	if err := db.Save(&shippingDetail).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shipping detail", "details": err.Error()})
+	if !checkIfMatch(c, shippingDetail.Version) {
 		return
 	}
 
-	c.JSON(http.StatusOK, shippingDetail)
+	fields, ok := applyMergePatch(c, shippingDetail, shippingDetailPatchFields)
+	if !ok {
+		return
+	}
+	if err := h.shippingDetails.Patch(ctx, shippingDetail, fields); err != nil {
+		c.Error(err)
+		return
+	}
+	respondSaved(c, shippingDetail)
 }
 
-// DeleteShippingDetail deletes a shipping detail record from the database.
+// Delete deletes a shipping detail record from the database.
 // It checks for the existence of the shipping detail, deletes it, and responds with an appropriate status code.
 // If the shipping detail does not exist, it responds with an HTTP 404 Not Found status.
 // If the deletion is successful, it responds with an HTTP 204 No Content status.
-func DeleteShippingDetail(c *gin.Context, db *gorm.DB) {
-	id := c.Param("id")
-	convertedId := tools.ConvertStringToUint(id)
-
-	if !models.ShippingDetailsExists(db, convertedId) {
-		fmt.Println("Shipping Detail does not exist")
-		c.JSON(http.StatusNotFound, gin.H{"error": "Shipping Detail not found"})
-		return
-	}
+// An If-Match header not matching the current version is answered with HTTP 412 Precondition Failed.
+func (h *ShippingDetailHandler) Delete(c *gin.Context) {
+	deleteRecord(c, h.shippingDetails, paramID(c))
+}
 
-	if err := db.Unscoped().Where("id = ?", convertedId).Delete(&models.ShippingDetails{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error deleting Shipping Detail"})
+// Restore takes a deleted shipping detail out of the trash based on the ID provided in the URL.
+// It responds with HTTP 200 OK and the restored shipping detail, HTTP 404 Not Found if it is not in the trash,
+// or HTTP 409 Conflict if a record it references is still deleted.
+func (h *ShippingDetailHandler) Restore(c *gin.Context) {
+	shippingDetail, err := h.shippingDetails.Restore(c.Request.Context(), paramID(c))
+	if err != nil {
+		c.Error(err)
 		return
 	}
-
-	c.JSON(http.StatusNoContent, nil)
-}
-
-// checkShippingDetail validates the new shipping detail data against the existing shipping detail.
-// It checks the order ID, address, shipping date, estimated arrival, and status fields for validity.
-// If any field is invalid, it returns an error message and true, indicating a failed validation.
-// If all fields are valid, it returns false and nil.
-func checkShippingDetail(shippingDetail models.ShippingDetails, newShippingDetail models.ShippingDetails, db *gorm.DB) (bool, error) {
-	switch true {
-	case !shippingDetail.SetOrderID(newShippingDetail.Order_ID, db):
-		return true, fmt.Errorf("invalid order id or not existing")
-	case !shippingDetail.SetAddress(newShippingDetail.Address):
-		return true, fmt.Errorf("invalid address")
-	case !shippingDetail.SetShippingDate(newShippingDetail.Shipping_Date):
-		return true, fmt.Errorf("shipping date is not expected")
-	case !shippingDetail.SetEstimatedArrival(newShippingDetail.Estimated_Arrival):
-		return true, fmt.Errorf("estimate date is not expected")
-	case !shippingDetail.SetStatus(newShippingDetail.Status):
-		return true, fmt.Errorf("status is not valid")
-	}
-	return false, nil
+	c.JSON(http.StatusOK, shippingDetail)
 }
@@ -11,7 +11,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
 )
 
@@ -20,6 +22,7 @@ import (
 func setupRouterAndDBShippingDetail(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
 	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
 	if err != nil {
@@ -50,12 +53,10 @@ func TestGetShippingDetail_Success(t *testing.T) {
 	order := models.Order{Total_amount: 150.50}
 	db.Create(&order)
 
-	shippingDetail := models.ShippingDetails{Order_ID: uint32(order.ID), Address: "123 First St", Shipping_Date: "2023-04-01", Estimated_Arrival: "2023-04-05", Status: "shipped"}
+	shippingDetail := models.ShippingDetails{Order_ID: order.ID, Address: "123 First St", Shipping_Date: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC), Status: "shipped"}
 	db.Create(&shippingDetail)
 
-	router.GET("/shippingDetails/:id", func(c *gin.Context) {
-		GetShippingDetail(c, db)
-	})
+	router.GET("/shippingDetails/:id", newHandlers(db).ShippingDetails.Get)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/shippingDetails/%d", shippingDetail.ID), nil)
 	rr := httptest.NewRecorder()
@@ -78,9 +79,7 @@ func TestGetShippingDetail_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBShippingDetail(t)
 	defer teardown()
 
-	router.GET("/shippingDetails/:id", func(c *gin.Context) {
-		GetShippingDetail(c, db)
-	})
+	router.GET("/shippingDetails/:id", newHandlers(db).ShippingDetails.Get)
 
 	req, _ := http.NewRequest("GET", "/shippingDetails/9999", nil)
 	rr := httptest.NewRecorder()
@@ -101,16 +100,14 @@ func TestGetShippingDetails_Success(t *testing.T) {
 	db.Create(&order)
 
 	shippingDetails := []models.ShippingDetails{
-		{Order_ID: uint32(order.ID), Address: "123 First St", Shipping_Date: "2023-04-01", Estimated_Arrival: "2023-04-05", Status: "shipped"},
-		{Order_ID: uint32(order.ID), Address: "456 Second St", Shipping_Date: "2023-05-01", Estimated_Arrival: "2023-05-05", Status: "pending"},
+		{Order_ID: order.ID, Address: "123 First St", Shipping_Date: time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2023, 4, 5, 0, 0, 0, 0, time.UTC), Status: "shipped"},
+		{Order_ID: order.ID, Address: "456 Second St", Shipping_Date: time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2023, 5, 5, 0, 0, 0, 0, time.UTC), Status: "pending"},
 	}
 	for _, detail := range shippingDetails {
 		db.Create(&detail)
 	}
 
-	router.GET("/shippingDetails", func(c *gin.Context) {
-		GetShippingDetails(c, db)
-	})
+	router.GET("/shippingDetails", newHandlers(db).ShippingDetails.List)
 
 	req, _ := http.NewRequest("GET", "/shippingDetails", nil)
 	rr := httptest.NewRecorder()
@@ -133,9 +130,7 @@ func TestGetShippingDetails_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBShippingDetail(t)
 	defer teardown()
 
-	router.GET("/shippingDetails", func(c *gin.Context) {
-		GetShippingDetails(c, db)
-	})
+	router.GET("/shippingDetails", newHandlers(db).ShippingDetails.List)
 
 	req, _ := http.NewRequest("GET", "/shippingDetails", nil)
 	rr := httptest.NewRecorder()
@@ -161,12 +156,10 @@ func TestSearchAllShippingDetails_Success(t *testing.T) {
 	order := models.Order{Total_amount: 250.50}
 	db.Create(&order)
 
-	shippingDetail := models.ShippingDetails{Order_ID: uint32(order.ID), Address: "789 Off St", Shipping_Date: "2023-06-01", Estimated_Arrival: "2023-06-05", Status: "in transit"}
+	shippingDetail := models.ShippingDetails{Order_ID: order.ID, Address: "789 Off St", Shipping_Date: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2023, 6, 5, 0, 0, 0, 0, time.UTC), Status: "in transit"}
 	db.Create(&shippingDetail)
 
-	router.GET("/shippingDetails/search/", func(c *gin.Context) {
-		SearchAllShippingDetails(c, db)
-	})
+	router.GET("/shippingDetails/search/", newHandlers(db).ShippingDetails.Search)
 
 	req, _ := http.NewRequest("GET", "/shippingDetails/search/?status=in+transit", nil)
 	rr := httptest.NewRecorder()
@@ -190,15 +183,14 @@ func TestSearchAllShippingDetails_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBShippingDetail(t)
 	defer teardown()
 
-	router.GET("/shippingDetails/search/", func(c *gin.Context) {
-		SearchAllShippingDetails(c, db)
-	})
+	router.GET("/shippingDetails/search/", newHandlers(db).ShippingDetails.Search)
 
 	req, _ := http.NewRequest("GET", "/shippingDetails/search/?status=delivered", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, "[]", rr.Body.String())
 }
 
 // TestCreateShippingDetail_Success tests the successful creation of a new shipping detail.
@@ -212,11 +204,9 @@ func TestCreateShippingDetail_Success(t *testing.T) {
 	order := models.Order{Total_amount: 300.50}
 	db.Create(&order)
 
-	router.POST("/shippingDetails", func(c *gin.Context) {
-		CreateShippingDetail(c, db)
-	})
+	router.POST("/shippingDetails", newHandlers(db).ShippingDetails.Create)
 
-	newDetail := `{"order_id": %d, "address": "New Address", "shipping_date": "2023-07-01", "estimated_arrival": "2023-07-05", "status": "pending"}`
+	newDetail := `{"order_id": %d, "address": "New Address", "shipping_date": "2023-07-01T00:00:00Z", "estimated_arrival": "2023-07-05T00:00:00Z", "status": "pending"}`
 	newDetail = fmt.Sprintf(newDetail, order.ID)
 	req, _ := http.NewRequest("POST", "/shippingDetails", bytes.NewBufferString(newDetail))
 	req.Header.Set("Content-Type", "application/json")
@@ -240,9 +230,7 @@ func TestCreateShippingDetail_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDBShippingDetail(t)
 	defer teardown()
 
-	router.POST("/shippingDetails", func(c *gin.Context) {
-		CreateShippingDetail(c, db)
-	})
+	router.POST("/shippingDetails", newHandlers(db).ShippingDetails.Create)
 
 	newDetail := `{"order_id": 999, "address": "", "shipping_date": "bad date", "estimated_arrival": "", "status": "unknown"}`
 	req, _ := http.NewRequest("POST", "/shippingDetails", bytes.NewBufferString(newDetail))
@@ -263,14 +251,12 @@ func TestUpdateShippingDetail_Success(t *testing.T) {
 
 	order := models.Order{Total_amount: 350.50}
 	db.Create(&order)
-	originalDetail := models.ShippingDetails{Order_ID: uint32(order.ID), Address: "Original Address", Shipping_Date: "2023-08-01", Estimated_Arrival: "2023-08-05", Status: "pending"}
+	originalDetail := models.ShippingDetails{Order_ID: order.ID, Address: "Original Address", Shipping_Date: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2023, 8, 5, 0, 0, 0, 0, time.UTC), Status: "pending"}
 	db.Create(&originalDetail)
 
-	router.PUT("/shippingDetails/:id", func(c *gin.Context) {
-		UpdateShippingDetail(c, db)
-	})
+	router.PUT("/shippingDetails/:id", newHandlers(db).ShippingDetails.Update)
 
-	updateDetail := fmt.Sprintf(`{"order_id": %d, "address": "Updated Address", "shipping_date": "2023-08-01", "estimated_arrival": "2023-08-10", "status": "shipped"}`, order.ID)
+	updateDetail := fmt.Sprintf(`{"order_id": %d, "address": "Updated Address", "shipping_date": "2023-08-01T00:00:00Z", "estimated_arrival": "2023-08-10T00:00:00Z", "status": "shipped"}`, order.ID)
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/shippingDetails/%d", originalDetail.ID), bytes.NewBufferString(updateDetail))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -283,7 +269,7 @@ func TestUpdateShippingDetail_Success(t *testing.T) {
 	}
 
 	assert.Equal(t, "Updated Address", response.Address)
-	assert.Equal(t, "2023-08-10", response.Estimated_Arrival)
+	assert.Equal(t, time.Date(2023, 8, 10, 0, 0, 0, 0, time.UTC), response.Estimated_Arrival)
 }
 
 // TestUpdateShippingDetail_NotFound tests the scenario where an attempt is made to update a non-existing shipping detail.
@@ -294,11 +280,9 @@ func TestUpdateShippingDetail_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBShippingDetail(t)
 	defer teardown()
 
-	router.PUT("/shippingDetails/:id", func(c *gin.Context) {
-		UpdateShippingDetail(c, db)
-	})
+	router.PUT("/shippingDetails/:id", newHandlers(db).ShippingDetails.Update)
 
-	updateDetail := `{"order_id": 1, "address": "Nonexistent Address", "shipping_date": "2023-09-01", "estimated_arrival": "2023-09-05", "status": "pending"}`
+	updateDetail := `{"order_id": 1, "address": "Nonexistent Address", "shipping_date": "2023-09-01T00:00:00Z", "estimated_arrival": "2023-09-05T00:00:00Z", "status": "pending"}`
 	req, _ := http.NewRequest("PUT", "/shippingDetails/999", bytes.NewBufferString(updateDetail))
 	req.Header.Set("Content-Type", "application/json")
 	rr := httptest.NewRecorder()
@@ -317,12 +301,10 @@ func TestDeleteShippingDetail_Valid(t *testing.T) {
 
 	order := models.Order{Total_amount: 400.50}
 	db.Create(&order)
-	detailToDelete := models.ShippingDetails{Order_ID: uint32(order.ID), Address: "Delete Me", Shipping_Date: "2023-10-01", Estimated_Arrival: "2023-10-05", Status: "pending"}
+	detailToDelete := models.ShippingDetails{Order_ID: order.ID, Address: "Delete Me", Shipping_Date: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), Estimated_Arrival: time.Date(2023, 10, 5, 0, 0, 0, 0, time.UTC), Status: "pending"}
 	db.Create(&detailToDelete)
 
-	router.DELETE("/shippingDetails/:id", func(c *gin.Context) {
-		DeleteShippingDetail(c, db)
-	})
+	router.DELETE("/shippingDetails/:id", newHandlers(db).ShippingDetails.Delete)
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/shippingDetails/%d", detailToDelete.ID), nil)
 	rr := httptest.NewRecorder()
@@ -339,9 +321,7 @@ func TestDeleteShippingDetail_Invalid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBShippingDetail(t)
 	defer teardown()
 
-	router.DELETE("/shippingDetails/:id", func(c *gin.Context) {
-		DeleteShippingDetail(c, db)
-	})
+	router.DELETE("/shippingDetails/:id", newHandlers(db).ShippingDetails.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/shippingDetails/999", nil)
 	rr := httptest.NewRecorder()
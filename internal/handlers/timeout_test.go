@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowQuery counts far enough to keep SQLite busy for seconds unless the context of the query is cancelled.
+const slowQuery = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 100000000) SELECT count(*) FROM c"
+
+// setupSlowDB returns a database with one brand, and the tables of categories and products, whose queries are all
+// preceded by slowQuery, standing in for a database under heavy load.
+func setupSlowDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "slow.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Brands{}, &models.Category{}, &models.Product{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+	db.Create(&models.Brands{Name: "Acme", Description: "Gadgets"})
+	err = db.Callback().Query().Before("gorm:query").Register("test:slow", func(tx *gorm.DB) {
+		var n int64
+		if err := tx.Statement.ConnPool.QueryRowContext(tx.Statement.Context, slowQuery).Scan(&n); err != nil {
+			tx.AddError(err)
+		}
+	})
+	if err != nil {
+		t.Fatalf("failed to register callback: %v", err)
+	}
+	return db
+}
+
+// TestQueryTimeout checks that a request whose queries run past the deadline is answered with HTTP 504
+// without waiting for the database, and that the queries of a cancelled request are answered with HTTP 503.
+func TestQueryTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupSlowDB(t)
+	router := gin.New()
+	router.Use(middleware.Errors(), middleware.QueryTimeout(50*time.Millisecond))
+	router.GET("/brands", newHandlers(db).Brands.List)
+
+	tests := []struct {
+		name   string
+		ctx    func() (context.Context, context.CancelFunc)
+		status int
+		code   string
+	}{
+		{"deadline", func() (context.Context, context.CancelFunc) { return context.Background(), func() {} }, http.StatusGatewayTimeout, "timeout"},
+		{"cancelled", func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(10*time.Millisecond, cancel)
+			return ctx, cancel
+		}, http.StatusServiceUnavailable, "unavailable"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, "GET", "/brands", nil)
+			rr := httptest.NewRecorder()
+			start := time.Now()
+			router.ServeHTTP(rr, req)
+			assert.Less(t, time.Since(start), 2*time.Second)
+			assert.Equal(t, tt.status, rr.Code)
+
+			var response struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatal("Failed to parse response JSON")
+			}
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
+
+// TestQueryTimeout_References checks that a reference that could not be looked up before the deadline, such as
+// the brand of a new product or the parent of a new category, is answered with HTTP 504 rather than as a reference
+// to a missing record.
+func TestQueryTimeout_References(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := setupSlowDB(t)
+	h := newHandlers(db)
+	router := gin.New()
+	router.Use(middleware.Errors(), middleware.QueryTimeout(50*time.Millisecond))
+	router.POST("/products", h.Products.Create)
+	router.POST("/categories", h.Categories.Create)
+
+	tests := []struct {
+		url  string
+		body string
+	}{
+		{"/products", `{"name": "Laptop", "price": 999, "stock_quantity": 5, "brand_id": 1, "category_id": 1}`},
+		{"/categories", `{"name": "Laptops", "description": "Portable computers", "parent_id": 1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", tt.url, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, http.StatusGatewayTimeout, rr.Code, rr.Body.String())
+
+			var response struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatal("Failed to parse response JSON")
+			}
+			assert.Equal(t, "timeout", response.Code)
+		})
+	}
+}
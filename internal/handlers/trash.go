@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"github.com/gin-gonic/gin"
+)
+
+// withTrashed returns the rows selected by the trashed query parameter: without it deleted rows are hidden,
+// "with" lists them along with the others and "only" lists nothing but them.
+// Viewing deleted rows requires an admin token; otherwise an unauthorized or forbidden error is attached to c and ok is false.
+func withTrashed(c *gin.Context) (repository.Trashed, bool) {
+	trashed := c.Query("trashed")
+	if trashed == "" {
+		return repository.WithoutTrashed, true
+	}
+	if trashed != "with" && trashed != "only" {
+		c.Error(apperr.New(apperr.KindBadRequest, "Invalid trashed: trashed must be with or only"))
+		return 0, false
+	}
+
+	claims, err := tools.ParseToken(c.GetHeader("Authorization"))
+	if err != nil {
+		c.Error(apperr.Unauthorized("Invalid token: " + err.Error()))
+		return 0, false
+	}
+	if claims["role"] != "admin" {
+		c.Error(apperr.Forbidden("Admin role required to view deleted records"))
+		return 0, false
+	}
+
+	if trashed == "only" {
+		return repository.OnlyTrashed, true
+	}
+	return repository.WithTrashed, true
+}
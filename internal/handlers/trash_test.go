@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/tools"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// bearerToken returns the Authorization header value of a token with the given role.
+func bearerToken(t *testing.T, role string) string {
+	if err := tools.SetSigningKey("test-secret"); err != nil {
+		t.Fatalf("failed to set signing key: %v", err)
+	}
+	tokenService := tools.JWTTokenService{}
+	token, err := tokenService.GenerateTokenWithClaims("tester", role)
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	return "Bearer " + token
+}
+
+// TestGetOrders_Trashed checks that deleted orders are hidden by default, listed with ?trashed=only or ?trashed=with
+// for administrators, and that other users are refused.
+func TestGetOrders_Trashed(t *testing.T) {
+	router, db, order, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	deleted := models.Order{User_ID: order.User_ID, Order_date: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), Total_amount: 10, Status: "cancelled"}
+	db.Create(&deleted)
+	assert.NoError(t, models.Delete(db, &models.Order{}, deleted.ID))
+
+	router.GET("/orders", newHandlers(db).Orders.List)
+
+	cases := []struct {
+		query, role string
+		code        int
+		ids         []uint
+	}{
+		{"", "", http.StatusOK, []uint{order.ID}},
+		{"?trashed=only", "admin", http.StatusOK, []uint{deleted.ID}},
+		{"?trashed=with", "admin", http.StatusOK, []uint{order.ID, deleted.ID}},
+		{"?trashed=only", "regular", http.StatusForbidden, nil},
+		{"?trashed=only", "", http.StatusUnauthorized, nil},
+		{"?trashed=all", "admin", http.StatusBadRequest, nil},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("GET", "/orders"+tc.query, nil)
+		if tc.role != "" {
+			req.Header.Set("Authorization", bearerToken(t, tc.role))
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, tc.code, rr.Code, tc.query+" as "+tc.role)
+		if tc.code != http.StatusOK {
+			continue
+		}
+		var response []models.Order
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatal("Failed to parse response JSON")
+		}
+		ids := []uint{}
+		for _, o := range response {
+			ids = append(ids, o.ID)
+		}
+		assert.ElementsMatch(t, tc.ids, ids, tc.query)
+	}
+}
+
+// TestRestoreOrder checks that a deleted order is restored with its items, and that restoring
+// an order that is not in the trash is answered with HTTP 404 Not Found.
+func TestRestoreOrder(t *testing.T) {
+	router, db, order, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	assert.NoError(t, models.Delete(db, &models.Order{}, order.ID))
+	router.POST("/orders/:id/restore", newHandlers(db).Orders.Restore)
+
+	req, _ := http.NewRequest("POST", "/orders/"+strconv.Itoa(int(order.ID))+"/restore", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	var response models.Order
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	assert.Equal(t, order.ID, response.ID)
+	var items int64
+	db.Model(&models.OrderItem{}).Count(&items)
+	assert.Equal(t, int64(1), items)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+// TestRestoreOrderItem_DeletedOrder checks that an item cannot be restored while its order is deleted.
+func TestRestoreOrderItem_DeletedOrder(t *testing.T) {
+	router, db, order, teardown := setupRouterAndDBInclude(t)
+	defer teardown()
+
+	var item models.OrderItem
+	db.First(&item)
+	assert.NoError(t, models.Delete(db, &models.Order{}, order.ID))
+	router.POST("/orderItems/:id/restore", newHandlers(db).OrderItems.Restore)
+
+	req, _ := http.NewRequest("POST", "/orderItems/"+strconv.Itoa(int(item.ID))+"/restore", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusConflict, rr.Code)
+	assert.Contains(t, rr.Body.String(), "must be restored first")
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"mime/multipart"
+	"net/http"
+)
+
+// uploadOverhead is the room left in the body of uploads for the boundaries and fields around the file.
+const uploadOverhead = 64 << 10
+
+// readUpload returns the file uploaded in the file field of a multipart/form-data request, to be closed by the caller.
+// The body is cut off past maxSize bytes of file, so that oversized uploads are refused before being read entirely.
+// Otherwise an unsupported media type, too large or bad request error is attached to c and ok is false.
+func readUpload(c *gin.Context, maxSize int64) (file multipart.File, ok bool) {
+	if c.ContentType() != gin.MIMEMultipartPOSTForm {
+		c.Error(apperr.New(apperr.KindUnsupportedMediaType, "Upload the image as multipart/form-data in the file field"))
+		return nil, false
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+uploadOverhead)
+	file, _, err := c.Request.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.Error(apperr.New(apperr.KindTooLarge, fmt.Sprintf("Images are limited to %d bytes", maxSize)))
+		return nil, false
+	case errors.Is(err, http.ErrMissingFile):
+		c.Error(apperr.New(apperr.KindBadRequest, "Missing file: upload the image in the file field"))
+		return nil, false
+	case err != nil:
+		c.Error(apperr.BadRequest("Invalid multipart form", err))
+		return nil, false
+	}
+	return file, true
+}
@@ -12,7 +12,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"E-Commerce_Website_Database/internal/middleware"
 	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/problem"
+	"E-Commerce_Website_Database/internal/validation"
 )
 
 // setupRouterAndDBUser sets up the router and the database for testing user-related endpoints.
@@ -20,19 +23,21 @@ import (
 func setupRouterAndDBUser(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
 	gin.SetMode(gin.TestMode)
 	router := gin.Default()
+	router.Use(middleware.Errors())
 
-	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	// Errors are translated as by database.Open, so that unique violations are answered with 409 Conflict.
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{TranslateError: true})
 	if err != nil {
 		t.Fatalf("failed to open database: %v", err)
 	}
 
-	if err := db.AutoMigrate(&models.User{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Order{}, &models.Review{}); err != nil {
 		t.Fatalf("failed to migrate database: %v", err)
 	}
 
 	// Function to clean up the database after tests finish
 	teardown := func() {
-		if err := db.Migrator().DropTable(&models.User{}); err != nil {
+		if err := db.Migrator().DropTable(&models.User{}, &models.Order{}, &models.Review{}); err != nil {
 			t.Fatalf("failed to drop table: %v", err)
 		}
 	}
@@ -50,9 +55,7 @@ func TestGetUser_Success(t *testing.T) {
 	user := models.User{Username: "User1", Email: "user1@example.com", First_Name: "user", Last_Name: "1", Address: "123 First St"}
 	db.Create(&user)
 
-	router.GET("/users/:id", func(c *gin.Context) {
-		GetUser(c, db)
-	})
+	router.GET("/users/:id", newHandlers(db).Users.Get)
 
 	req, _ := http.NewRequest("GET", fmt.Sprintf("/users/%d", user.ID), nil)
 	rr := httptest.NewRecorder()
@@ -75,9 +78,7 @@ func TestGetUser_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.GET("/users/:id", func(c *gin.Context) {
-		GetUser(c, db)
-	})
+	router.GET("/users/:id", newHandlers(db).Users.Get)
 
 	req, _ := http.NewRequest("GET", "/users/999", nil)
 	rr := httptest.NewRecorder()
@@ -102,9 +103,7 @@ func TestGetUsers_Success(t *testing.T) {
 		db.Create(&u)
 	}
 
-	router.GET("/users", func(c *gin.Context) {
-		GetUsers(c, db)
-	})
+	router.GET("/users", newHandlers(db).Users.List)
 
 	req, _ := http.NewRequest("GET", "/users", nil)
 	rr := httptest.NewRecorder()
@@ -127,9 +126,7 @@ func TestGetUsers_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.GET("/users", func(c *gin.Context) {
-		GetUsers(c, db)
-	})
+	router.GET("/users", newHandlers(db).Users.List)
 
 	req, _ := http.NewRequest("GET", "/users", nil)
 	rr := httptest.NewRecorder()
@@ -147,7 +144,7 @@ func TestGetUsers_Empty(t *testing.T) {
 // TestSearchAllUsers_Success tests successful searching of users based on specific criteria.
 // It creates a user, sends a GET request with search criteria to retrieve the user, and checks the response.
 // If the search is successful, it responds with an HTTP 200 OK status and the user details in JSON format.
-// If no users are found, it responds with an HTTP 404 Not Found status.
+// If no users are found, it responds with an HTTP 200 OK status and an empty list.
 func TestSearchAllUsers_Success(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
@@ -155,9 +152,7 @@ func TestSearchAllUsers_Success(t *testing.T) {
 	user := models.User{Username: "searchuser", Email: "search@example.com", First_Name: "Search", Last_Name: "User", Address: "789 Search"}
 	db.Create(&user)
 
-	router.GET("/users/search", func(c *gin.Context) {
-		SearchAllUsers(c, db)
-	})
+	router.GET("/users/search", newHandlers(db).Users.Search)
 
 	req, _ := http.NewRequest("GET", "/users/search?username=searchuser", nil)
 	rr := httptest.NewRecorder()
@@ -175,21 +170,20 @@ func TestSearchAllUsers_Success(t *testing.T) {
 
 // TestSearchAllUsers_Empty tests the scenario where a search query matches no existing users.
 // It sends a GET request with search criteria to retrieve a non-existing user and checks the response.
-// If no users are found, it responds with an HTTP 404 Not Found status.
+// If no users are found, it responds with an HTTP 200 OK status and an empty list.
 // If the search is successful, it responds with an HTTP 200 OK status and the user details in JSON format.
 func TestSearchAllUsers_Empty(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.GET("/users/search", func(c *gin.Context) {
-		SearchAllUsers(c, db)
-	})
+	router.GET("/users/search", newHandlers(db).Users.Search)
 
 	req, _ := http.NewRequest("GET", "/users/search?username=nonexistent", nil)
 	rr := httptest.NewRecorder()
 	router.ServeHTTP(rr, req)
 
-	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, "[]", rr.Body.String())
 }
 
 // TestCreateUser_Success tests successful creation of a new user.
@@ -200,9 +194,7 @@ func TestCreateUser_Success(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.POST("/users", func(c *gin.Context) {
-		CreateUser(c, db)
-	})
+	router.POST("/users", newHandlers(db).Users.Create)
 
 	newUser := `{"username": "newUser", "password": "Password123", "email": "uniq12@example.com", "first_name": "NewName", "last_name": "Username", "address": "New Street", "role": "admin"}`
 	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(newUser))
@@ -219,17 +211,43 @@ func TestCreateUser_Success(t *testing.T) {
 	assert.Equal(t, "newUser", response.Username)
 }
 
+// TestCreateUser_Duplicate tests creation of a user with a username that is already taken.
+// The unique constraint of the username is violated, so it responds with an HTTP 409 Conflict status
+// without exposing the database error.
+func TestCreateUser_Duplicate(t *testing.T) {
+	router, db, teardown := setupRouterAndDBUser(t)
+	defer teardown()
+
+	router.POST("/users", newHandlers(db).Users.Create)
+
+	for _, expected := range []int{http.StatusCreated, http.StatusConflict} {
+		newUser := `{"username": "takenUser", "password": "Password123", "email": "taken@example.com", "first_name": "Taken", "last_name": "User", "address": "Street"}`
+		req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(newUser))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, expected, rr.Code)
+
+		if expected == http.StatusConflict {
+			var response problem.Problem
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatal("Failed to parse response JSON")
+			}
+			assert.Equal(t, "conflict", response.Code)
+			assert.NotContains(t, response.Detail, "UNIQUE")
+		}
+	}
+}
+
 // TestCreateUser_InvalidData tests creation of a user with invalid data.
 // It sends a POST request with invalid user details to create a new user and checks the response.
 // If the JSON data is invalid, it responds with an HTTP 400 Bad Request status.
-// If the user is created successfully, it responds with an HTTP 201 Created status and the user details in JSON format.
+// The problem details list every invalid field with its error code, not only the first one.
 func TestCreateUser_InvalidData(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.POST("/users", func(c *gin.Context) {
-		CreateUser(c, db)
-	})
+	router.POST("/users", newHandlers(db).Users.Create)
 
 	newUser := `{"username": "", "password": "", "email": "bademail", "first_name": "123", "last_name": "", "address": ""}`
 	req, _ := http.NewRequest("POST", "/users", bytes.NewBufferString(newUser))
@@ -238,6 +256,22 @@ func TestCreateUser_InvalidData(t *testing.T) {
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	var response problem.Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	codes := map[string]string{}
+	for field, errs := range response.Errors {
+		codes[field] = errs[0].Code
+	}
+	assert.Equal(t, map[string]string{
+		"username":  validation.CodeRequired,
+		"password":  validation.CodeWeakPassword,
+		"email":     validation.CodeInvalidFormat,
+		"last_name": validation.CodeRequired,
+		"address":   validation.CodeRequired,
+	}, codes)
+	assert.Equal(t, "/users", response.Instance)
 }
 
 // TestUpdateUser_Success tests successful updating of an existing user.
@@ -251,9 +285,7 @@ func TestUpdateUser_Success(t *testing.T) {
 	user := models.User{Username: "updateuser", Email: "update@example.com", First_Name: "Update", Last_Name: "User", Address: "200 Update St"}
 	db.Create(&user)
 
-	router.PUT("/users/:id", func(c *gin.Context) {
-		UpdateUser(c, db)
-	})
+	router.PUT("/users/:id", newHandlers(db).Users.Update)
 
 	updateUser := fmt.Sprintf(`{"username": "updated", "password": "Newpassword123", "email": "updated@example.com", "first_name": "Updated", "last_name": "User", "address": "200 Updated", "role": "admin"}`)
 	req, _ := http.NewRequest("PUT", fmt.Sprintf("/users/%d", user.ID), bytes.NewBufferString(updateUser))
@@ -278,9 +310,7 @@ func TestUpdateUser_NotFound(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.PUT("/users/:id", func(c *gin.Context) {
-		UpdateUser(c, db)
-	})
+	router.PUT("/users/:id", newHandlers(db).Users.Update)
 
 	updateUser := `{"username": "nonexistent", "password": "password123", "email": "nonexistent@example.com", "first_name": "Nonexistent", "last_name": "User", "address": "300 Nonexistent St"}`
 	req, _ := http.NewRequest("PUT", "/users/999", bytes.NewBufferString(updateUser))
@@ -302,9 +332,7 @@ func TestDeleteUser_Valid(t *testing.T) {
 	user := models.User{Username: "deleteuser", Email: "delete@example.com", First_Name: "Delete", Last_Name: "User", Address: "400 Delete St"}
 	db.Create(&user)
 
-	router.DELETE("/users/:id", func(c *gin.Context) {
-		DeleteUser(c, db)
-	})
+	router.DELETE("/users/:id", newHandlers(db).Users.Delete)
 
 	req, _ := http.NewRequest("DELETE", fmt.Sprintf("/users/%d", user.ID), nil)
 	rr := httptest.NewRecorder()
@@ -321,9 +349,7 @@ func TestDeleteUser_Invalid(t *testing.T) {
 	router, db, teardown := setupRouterAndDBUser(t)
 	defer teardown()
 
-	router.DELETE("/users/:id", func(c *gin.Context) {
-		DeleteUser(c, db)
-	})
+	router.DELETE("/users/:id", newHandlers(db).Users.Delete)
 
 	req, _ := http.NewRequest("DELETE", "/users/999", nil)
 	rr := httptest.NewRecorder()
@@ -0,0 +1,180 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout bounds how long a single readiness check may run when no timeout is configured.
+const DefaultTimeout = 2 * time.Second
+
+// Status values reported by the probes.
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// CheckFunc verifies that a dependency is usable. It must return promptly once ctx is done.
+type CheckFunc func(ctx context.Context) error
+
+// CheckResult is the outcome of a single readiness check as reported by /readyz.
+type CheckResult struct {
+	Status   string  `json:"status"`
+	Duration float64 `json:"duration_ms"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Report is the JSON body returned by the probe endpoints.
+type Report struct {
+	Status string                 `json:"status"`
+	Uptime string                 `json:"uptime,omitempty"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Checker runs the registered readiness checks and serves the liveness and readiness endpoints.
+type Checker struct {
+	timeout time.Duration
+	started time.Time
+	mu      sync.RWMutex
+	checks  map[string]CheckFunc
+}
+
+// NewChecker creates a Checker whose individual checks are cancelled after timeout.
+// A zero or negative timeout falls back to DefaultTimeout.
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout, started: time.Now(), checks: map[string]CheckFunc{}}
+}
+
+// AddCheck registers a named readiness check. Registering a name twice replaces the earlier check.
+func (h *Checker) AddCheck(name string, check CheckFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Register adds the GET /healthz and GET /readyz endpoints to the router.
+func (h *Checker) Register(router gin.IRoutes) {
+	router.GET("/healthz", h.Liveness)
+	router.GET("/readyz", h.Readiness)
+}
+
+// Liveness reports that the process is up and able to serve HTTP requests.
+// It never touches dependencies, so a slow database does not get a healthy instance restarted.
+func (h *Checker) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, Report{Status: StatusOK, Uptime: time.Since(h.started).Round(time.Second).String()})
+}
+
+// Readiness runs every registered check concurrently and reports whether the instance should receive traffic.
+// It responds with HTTP 200 OK when all checks pass and HTTP 503 Service Unavailable otherwise,
+// including the status, duration and error of each check in the body.
+func (h *Checker) Readiness(c *gin.Context) {
+	report := h.Run(c.Request.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Run executes every registered check concurrently, each bounded by the checker timeout, and aggregates the results.
+func (h *Checker) Run(ctx context.Context) Report {
+	h.mu.RLock()
+	names := make([]string, 0, len(h.checks))
+	checks := make(map[string]CheckFunc, len(h.checks))
+	for name, check := range h.checks {
+		names = append(names, name)
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, check CheckFunc) {
+			defer wg.Done()
+			results[i] = h.runCheck(ctx, check)
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+// runCheck executes a single check with the checker timeout, converting timeouts and panics into failures.
+func (h *Checker) runCheck(ctx context.Context, check CheckFunc) (result CheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("check panicked: %v", recovered)
+			}
+		}()
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", h.timeout)
+	}
+
+	result = CheckResult{Status: StatusOK, Duration: float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// DatabaseCheck returns a check that pings the database behind the GORM handle.
+// It fails when the connection pool cannot reach the server, e.g. after losing the MySQL connection.
+func DatabaseCheck(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("failed to access database pool: %w", err)
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// SchemaCheck returns a check that verifies the tables of the given models exist,
+// catching instances started against a database whose schema has not been created.
+func SchemaCheck(db *gorm.DB, models ...interface{}) CheckFunc {
+	return func(ctx context.Context) error {
+		migrator := db.WithContext(ctx).Migrator()
+		for _, model := range models {
+			if !migrator.HasTable(model) {
+				stmt := &gorm.Statement{DB: db}
+				if err := stmt.Parse(model); err == nil {
+					return fmt.Errorf("table %s is missing", stmt.Schema.Table)
+				}
+				return fmt.Errorf("table for %T is missing", model)
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// setupRouterWithChecker creates a Gin engine serving the probes of the given checker.
+func setupRouterWithChecker(checker *Checker) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	checker.Register(router)
+	return router
+}
+
+// probe sends a GET request to the given probe path and decodes the JSON report.
+func probe(t *testing.T, router *gin.Engine, path string) (int, Report) {
+	req, _ := http.NewRequest("GET", path, nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var report Report
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	return rr.Code, report
+}
+
+// TestLiveness tests that /healthz always reports ok, even when a readiness check fails.
+func TestLiveness(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddCheck("broken", func(ctx context.Context) error { return errors.New("down") })
+	router := setupRouterWithChecker(checker)
+
+	code, report := probe(t, router, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.NotEmpty(t, report.Uptime)
+}
+
+// TestReadiness_AllChecksPass tests that /readyz returns 200 with per-check details when every check succeeds.
+func TestReadiness_AllChecksPass(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddCheck("first", func(ctx context.Context) error { return nil })
+	checker.AddCheck("second", func(ctx context.Context) error { return nil })
+	router := setupRouterWithChecker(checker)
+
+	code, report := probe(t, router, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, StatusOK, report.Status)
+	assert.Len(t, report.Checks, 2)
+	assert.Equal(t, StatusOK, report.Checks["first"].Status)
+}
+
+// TestReadiness_FailingCheck tests that a single failing check makes /readyz return 503 with the error.
+func TestReadiness_FailingCheck(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddCheck("ok", func(ctx context.Context) error { return nil })
+	checker.AddCheck("database", func(ctx context.Context) error { return errors.New("connection refused") })
+	router := setupRouterWithChecker(checker)
+
+	code, report := probe(t, router, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["ok"].Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+}
+
+// TestReadiness_Timeout tests that a check exceeding the configured timeout, or panicking, is reported as failed.
+func TestReadiness_Timeout(t *testing.T) {
+	checker := NewChecker(20 * time.Millisecond)
+	checker.AddCheck("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+	checker.AddCheck("panics", func(ctx context.Context) error { panic("boom") })
+
+	start := time.Now()
+	report := checker.Run(context.Background())
+	assert.Less(t, time.Since(start), 500*time.Millisecond, "The slow check should be abandoned")
+	assert.Equal(t, StatusUnavailable, report.Status)
+	assert.Contains(t, report.Checks["slow"].Error, "timed out")
+	assert.Contains(t, report.Checks["panics"].Error, "boom")
+}
+
+// schemaModel is a small model used to test the schema check.
+type schemaModel struct {
+	ID uint
+}
+
+// TestDatabaseAndSchemaChecks tests the database ping and the schema check against an SQLite database,
+// before and after the table is created and after the connection pool is closed.
+func TestDatabaseAndSchemaChecks(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:health_checks?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	assert.NoError(t, DatabaseCheck(db)(context.Background()))
+	assert.ErrorContains(t, SchemaCheck(db, &schemaModel{})(context.Background()), "schema_models")
+
+	assert.NoError(t, db.AutoMigrate(&schemaModel{}))
+	assert.NoError(t, SchemaCheck(db, &schemaModel{})(context.Background()))
+
+	sqlDB, _ := db.DB()
+	sqlDB.Close()
+	assert.Error(t, DatabaseCheck(db)(context.Background()), "A closed pool should fail the ping")
+}
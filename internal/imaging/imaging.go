@@ -0,0 +1,141 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	// The GIF decoder is registered with the image package by importing it.
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrTooLarge is returned for images over the size or pixel limits.
+	ErrTooLarge = errors.New("image is too large")
+	// ErrUnsupported is returned for content that is not a JPEG, PNG or GIF image, whatever its name or declared type.
+	ErrUnsupported = errors.New("unsupported image type")
+	// ErrInvalid is returned for images of a supported type that cannot be decoded.
+	ErrInvalid = errors.New("image cannot be decoded")
+)
+
+// Limits bounds the images accepted: MaxBytes is the largest file and MaxPixels the largest width times height,
+// which bounds the memory needed to decode them. Thumbnails fit in a square of ThumbnailSize pixels.
+type Limits struct {
+	MaxBytes      int64
+	MaxPixels     int
+	ThumbnailSize int
+}
+
+// DefaultLimits are the limits of the default configuration.
+var DefaultLimits = Limits{MaxBytes: 5 << 20, MaxPixels: 25_000_000, ThumbnailSize: 320}
+
+// formats maps the media types accepted to the extension of their files.
+var formats = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// Image is an accepted image: its content and type as sniffed from the content, its dimensions and its thumbnail.
+// The thumbnail of a JPEG image is a JPEG image, and that of a PNG or GIF image a PNG image, keeping transparency.
+type Image struct {
+	Data          []byte
+	ContentType   string
+	Width         int
+	Height        int
+	Thumbnail     []byte
+	ThumbnailType string
+}
+
+// Extension returns the file extension of images of the given media type, including the dot.
+func Extension(contentType string) string {
+	return formats[contentType]
+}
+
+// Read reads an image from r and checks it against limits before making its thumbnail.
+// It returns ErrTooLarge, ErrUnsupported or an error wrapping ErrInvalid when the image is refused.
+func Read(r io.Reader, limits Limits) (*Image, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, ErrTooLarge
+	}
+	contentType := http.DetectContentType(data)
+	if formats[contentType] == "" {
+		return nil, ErrUnsupported
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalid
+	}
+	if config.Width > limits.MaxPixels/config.Height {
+		return nil, ErrTooLarge
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	var thumbnail bytes.Buffer
+	thumbnailType := "image/png"
+	if contentType == "image/jpeg" {
+		thumbnailType = contentType
+		err = jpeg.Encode(&thumbnail, Thumbnail(decoded, limits.ThumbnailSize), &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&thumbnail, Thumbnail(decoded, limits.ThumbnailSize))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:          data,
+		ContentType:   contentType,
+		Width:         config.Width,
+		Height:        config.Height,
+		Thumbnail:     thumbnail.Bytes(),
+		ThumbnailType: thumbnailType,
+	}, nil
+}
+
+// Thumbnail returns src scaled down to fit in a square of size pixels, keeping its aspect ratio. Each pixel of the
+// thumbnail averages the pixels of src it covers. Images that already fit are copied unscaled.
+func Thumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	thumbWidth, thumbHeight := width, height
+	if width > size || height > size {
+		if width >= height {
+			thumbWidth, thumbHeight = size, max(1, height*size/width)
+		} else {
+			thumbWidth, thumbHeight = max(1, width*size/height), size
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		top, bottom := bounds.Min.Y+y*height/thumbHeight, bounds.Min.Y+(y+1)*height/thumbHeight
+		for x := 0; x < thumbWidth; x++ {
+			left, right := bounds.Min.X+x*width/thumbWidth, bounds.Min.X+(x+1)*width/thumbWidth
+			var r, g, b, a, count uint64
+			for sy := top; sy < bottom; sy++ {
+				for sx := left; sx < right; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / count), G: uint16(g / count), B: uint16(b / count), A: uint16(a / count)})
+		}
+	}
+	return dst
+}
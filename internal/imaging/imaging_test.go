@@ -0,0 +1,105 @@
+package imaging
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// encode returns a width by height image filled with fill, encoded by encoder.
+func encode(t *testing.T, width, height int, fill color.Color, encoder func(*bytes.Buffer, image.Image) error) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := encoder(&buf, img); err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(buf *bytes.Buffer, img image.Image) error  { return png.Encode(buf, img) }
+func encodeJPEG(buf *bytes.Buffer, img image.Image) error { return jpeg.Encode(buf, img, nil) }
+func encodeGIF(buf *bytes.Buffer, img image.Image) error  { return gif.Encode(buf, img, nil) }
+
+// TestRead checks that images are typed by their content, measured and given a thumbnail fitting the limits
+// in the format of the image, PNG for GIF images.
+func TestRead(t *testing.T) {
+	limits := Limits{MaxBytes: 1 << 20, MaxPixels: 1_000_000, ThumbnailSize: 32}
+	for contentType, test := range map[string]struct {
+		data          []byte
+		thumbnailType string
+	}{
+		"image/png":  {encode(t, 200, 100, color.RGBA{R: 255, A: 255}, encodePNG), "image/png"},
+		"image/jpeg": {encode(t, 200, 100, color.White, encodeJPEG), "image/jpeg"},
+		"image/gif":  {encode(t, 200, 100, color.Black, encodeGIF), "image/png"},
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			img, err := Read(bytes.NewReader(test.data), limits)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, contentType, img.ContentType)
+			assert.Equal(t, []int{200, 100}, []int{img.Width, img.Height})
+			assert.Equal(t, test.data, img.Data)
+			assert.Equal(t, test.thumbnailType, img.ThumbnailType)
+			thumbnail, _, err := image.DecodeConfig(bytes.NewReader(img.Thumbnail))
+			if assert.NoError(t, err) {
+				assert.Equal(t, []int{32, 16}, []int{thumbnail.Width, thumbnail.Height})
+			}
+		})
+	}
+}
+
+// TestRead_Refused checks that content over the limits, of another type or that cannot be decoded is refused.
+func TestRead_Refused(t *testing.T) {
+	limits := Limits{MaxBytes: 4096, MaxPixels: 10_000, ThumbnailSize: 32}
+	small := encode(t, 10, 10, color.Black, encodePNG)
+	for name, test := range map[string]struct {
+		data []byte
+		err  error
+	}{
+		"too many bytes":  {data: bytes.Repeat([]byte{0}, 4097), err: ErrTooLarge},
+		"too many pixels": {data: encode(t, 101, 100, color.Black, encodePNG), err: ErrTooLarge},
+		"not an image":    {data: []byte("<html><body>hello</body></html>"), err: ErrUnsupported},
+		"truncated":       {data: small[:len(small)/2], err: ErrInvalid},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(test.data), limits)
+			assert.ErrorIs(t, err, test.err)
+		})
+	}
+	_, err := Read(strings.NewReader(string(small)), limits)
+	assert.NoError(t, err)
+}
+
+// TestThumbnail checks that thumbnails keep the aspect ratio, average the pixels they cover and leave small images
+// unscaled.
+func TestThumbnail(t *testing.T) {
+	striped := image.NewRGBA(image.Rect(0, 0, 40, 80))
+	for y := 0; y < 80; y++ {
+		for x := 0; x < 40; x++ {
+			if x%2 == 0 {
+				striped.Set(x, y, color.White)
+			} else {
+				striped.Set(x, y, color.Black)
+			}
+		}
+	}
+	thumbnail := Thumbnail(striped, 20)
+	assert.Equal(t, image.Rect(0, 0, 10, 20), thumbnail.Bounds())
+	r, _, _, a := thumbnail.At(3, 7).RGBA()
+	assert.InDelta(t, 0xffff/2, r, 0x101, "the stripes should average to grey")
+	assert.Equal(t, uint32(0xffff), a)
+
+	assert.Equal(t, image.Rect(0, 0, 40, 80), Thumbnail(striped, 100).Bounds())
+}
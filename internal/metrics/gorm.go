@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// startTimeKey is the statement setting under which the plugin stores the start time of a query.
+const startTimeKey = "metrics:start_time"
+
+// GormPlugin is a GORM plugin that observes the duration of every database operation.
+// Durations are recorded in the db_query_duration_seconds histogram, labeled by operation and table.
+type GormPlugin struct{}
+
+// Name returns the name under which the plugin is registered with GORM.
+func (p *GormPlugin) Name() string {
+	return "electromart:metrics"
+}
+
+// Initialize registers the timing callbacks around each of GORM's callback chains.
+// It returns an error if any of the callbacks could not be registered.
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	errs := []error{
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", start),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", observe("create")),
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", start),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", observe("query")),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", start),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", observe("update")),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", start),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", observe("delete")),
+		callbacks.Row().Before("gorm:row").Register("metrics:before_row", start),
+		callbacks.Row().After("gorm:row").Register("metrics:after_row", observe("row")),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", start),
+		callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", observe("raw")),
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// start stores the time at which a statement begins executing.
+func start(db *gorm.DB) {
+	db.InstanceSet(startTimeKey, time.Now())
+}
+
+// observe returns a callback recording the time elapsed since the start time stored by the before callback.
+func observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(startTimeKey)
+		if !ok {
+			return
+		}
+		startTime, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(startTime).Seconds())
+	}
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// gadget is a small model used to exercise the GORM plugin without depending on the application models.
+type gadget struct {
+	ID   uint
+	Name string
+}
+
+// sampleCount returns how many statements have been observed for the given operation and table.
+func sampleCount(t *testing.T, operation string, table string) uint64 {
+	observer, err := dbQueryDuration.GetMetricWithLabelValues(operation, table)
+	assert.NoError(t, err)
+
+	metric := &dto.Metric{}
+	assert.NoError(t, observer.(prometheus.Metric).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+// TestGormPlugin_ObservesOperations tests that create, query, update and delete statements are all timed.
+func TestGormPlugin_ObservesOperations(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:gorm_plugin?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	assert.NoError(t, db.Use(&GormPlugin{}))
+	assert.NoError(t, db.AutoMigrate(&gadget{}))
+
+	item := gadget{Name: "phone"}
+	db.Create(&item)
+	db.First(&item, item.ID)
+	db.Model(&item).Update("name", "tablet")
+	db.Delete(&item)
+
+	for _, operation := range []string{"create", "query", "update", "delete"} {
+		assert.NotZero(t, sampleCount(t, operation, "gadgets"), "operation %s should be observed", operation)
+	}
+}
+
+// TestGormPlugin_RegisterTwice tests that installing the plugin twice on the same database is reported by GORM.
+func TestGormPlugin_RegisterTwice(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file:gorm_plugin_twice?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	assert.NoError(t, db.Use(&GormPlugin{}))
+	assert.ErrorIs(t, db.Use(&GormPlugin{}), gorm.ErrRegistered)
+}
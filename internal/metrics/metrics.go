@@ -0,0 +1,121 @@
+package metrics
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gorm.io/gorm"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// namespace prefixes every metric exported by the application.
+const namespace = "electromart"
+
+// DefaultLowStockThreshold is the stock quantity at or below which a product is counted as low on stock.
+const DefaultLowStockThreshold = 5
+
+var (
+	// httpRequests counts handled HTTP requests by method, route template and status code.
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Number of HTTP requests handled, partitioned by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// httpDuration observes the latency of HTTP requests by method and route template.
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests in seconds, partitioned by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	// dbQueryDuration observes the duration of database statements issued through GORM.
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of database queries in seconds, partitioned by operation and table.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// OrdersCreated counts orders successfully created through the API.
+	OrdersCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "orders_created_total",
+		Help:      "Number of orders created.",
+	})
+
+	// Payments counts payments recorded through the API, partitioned by their status.
+	Payments = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payments_total",
+		Help:      "Number of payments created or moved to a status, partitioned by status.",
+	}, []string{"status"})
+)
+
+// Register exposes the Prometheus metrics of the application on GET /metrics.
+// It creates a registry holding the HTTP, database, business and Go runtime collectors and installs the query timing
+// plugin on the database. The request instrumentation is installed separately with Middleware.
+// lowStockThreshold sets the stock quantity at or below which a product is reported as low on stock.
+func Register(router *gin.Engine, db *gorm.DB, lowStockThreshold int) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		dbQueryDuration,
+		OrdersCreated,
+		Payments,
+		newLowStockGauge(db, lowStockThreshold),
+	)
+
+	if sqlDB, err := db.DB(); err == nil {
+		registry.MustRegister(collectors.NewDBStatsCollector(sqlDB, "main"))
+	}
+	if err := db.Use(&GormPlugin{}); err != nil && err != gorm.ErrRegistered {
+		slog.Warn("failed to install database metrics plugin", "error", err)
+	}
+
+	router.GET("/metrics", gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})))
+	return registry
+}
+
+// Middleware records the number and latency of requests for every route.
+// Requests are labeled by route template (e.g. /products/:id) rather than raw path to keep label cardinality bounded.
+// It must run outside middleware.Errors, which writes the status of the requests whose handlers failed.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := c.Request.Method
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// newLowStockGauge creates a gauge that counts the products at or below the stock threshold each time it is scraped.
+func newLowStockGauge(db *gorm.DB, threshold int) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "low_stock_products",
+		Help:        "Number of products whose stock quantity is at or below the low-stock threshold.",
+		ConstLabels: prometheus.Labels{"threshold": strconv.Itoa(threshold)},
+	}, func() float64 {
+		var count int64
+		if err := db.Model(&models.Product{}).Where("stock_quantity <= ?", threshold).Count(&count).Error; err != nil {
+			slog.Warn("failed to count low stock products", "error", err)
+			return 0
+		}
+		return float64(count)
+	})
+}
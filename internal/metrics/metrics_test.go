@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/middleware"
+	"E-Commerce_Website_Database/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// setupRouterAndDBMetrics initializes a Gin engine with the metrics middleware and endpoint, the error middleware and
+// an in-memory SQLite database, in the order of the server.
+// It returns the Gin engine, the GORM database instance, and a teardown function to clean up after tests.
+func setupRouterAndDBMetrics(t *testing.T) (*gin.Engine, *gorm.DB, func()) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Product{}); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	router.Use(Middleware(), middleware.Errors())
+	Register(router, db, 5)
+
+	teardown := func() {
+		if err := db.Migrator().DropTable(&models.Product{}); err != nil {
+			t.Fatalf("failed to drop table: %v", err)
+		}
+	}
+	return router, db, teardown
+}
+
+// scrape requests the /metrics endpoint and returns the exposition text.
+func scrape(t *testing.T, router *gin.Engine) string {
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	return rr.Body.String()
+}
+
+// TestRegister_ExposesMetrics tests that the /metrics endpoint serves HTTP, database, business and runtime metrics.
+func TestRegister_ExposesMetrics(t *testing.T) {
+	router, db, teardown := setupRouterAndDBMetrics(t)
+	defer teardown()
+
+	router.GET("/products/:id", func(c *gin.Context) {
+		var product models.Product
+		db.First(&product, c.Param("id"))
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/products/42", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	body := scrape(t, router)
+	assert.Contains(t, body, `electromart_http_requests_total{method="GET",route="/products/:id",status="200"}`)
+	assert.Contains(t, body, `electromart_http_request_duration_seconds_bucket{method="GET",route="/products/:id"`)
+	assert.Contains(t, body, `electromart_db_query_duration_seconds_count{operation="query",table="products"}`)
+	assert.Contains(t, body, "electromart_orders_created_total")
+	assert.Contains(t, body, "go_goroutines")
+}
+
+// TestMiddleware_UnmatchedRoute tests that requests to unknown paths are grouped under a single route label.
+func TestMiddleware_UnmatchedRoute(t *testing.T) {
+	router, _, teardown := setupRouterAndDBMetrics(t)
+	defer teardown()
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404"))
+	req, _ := http.NewRequest("GET", "/does/not/exist", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "unmatched", "404")))
+}
+
+// TestMiddleware_ErrorStatus tests that requests whose handlers fail are counted with the status of the problem
+// response written by the error middleware.
+func TestMiddleware_ErrorStatus(t *testing.T) {
+	router, _, teardown := setupRouterAndDBMetrics(t)
+	defer teardown()
+
+	router.GET("/brands/:id", func(c *gin.Context) {
+		c.Error(apperr.NotFound("brand not found"))
+	})
+
+	before := testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/brands/:id", "404"))
+	req, _ := http.NewRequest("GET", "/brands/7", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+	assert.Equal(t, before+1, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/brands/:id", "404")))
+	assert.Zero(t, testutil.ToFloat64(httpRequests.WithLabelValues("GET", "/brands/:id", "200")))
+}
+
+// TestLowStockGauge tests that the low stock gauge counts products at or below the threshold.
+func TestLowStockGauge(t *testing.T) {
+	router, db, teardown := setupRouterAndDBMetrics(t)
+	defer teardown()
+
+	db.Create(&models.Product{Name: "Almost gone", Stock_quantity: 2})
+	db.Create(&models.Product{Name: "At threshold", Stock_quantity: 5})
+	db.Create(&models.Product{Name: "Plenty", Stock_quantity: 50})
+
+	body := scrape(t, router)
+	assert.Contains(t, body, `electromart_low_stock_products{threshold="5"} 2`)
+}
+
+// TestBusinessCounters tests that the exported business counters can be incremented by handlers.
+func TestBusinessCounters(t *testing.T) {
+	before := testutil.ToFloat64(OrdersCreated)
+	OrdersCreated.Inc()
+	assert.Equal(t, before+1, testutil.ToFloat64(OrdersCreated))
+
+	Payments.WithLabelValues("completed").Inc()
+	assert.GreaterOrEqual(t, testutil.ToFloat64(Payments.WithLabelValues("completed")), float64(1))
+}
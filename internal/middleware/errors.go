@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"E-Commerce_Website_Database/internal/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// Errors is a middleware answering the errors handlers attach to the context with c.Error.
+// The last error is written as problem details, with the status of its apperr.Kind; errors that are not
+// an *apperr.Error are answered with HTTP 500 without exposing them. Every error stays in c.Errors for the Logger.
+// Nothing is written when the handler already responded.
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		problem.Write(c, problem.From(c.Errors.Last().Err))
+	}
+}
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/problem"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestErrors tests that attached errors are answered as problem details with the status of their kind,
+// that the causes of internal errors are not exposed, and that responses already written are left alone.
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Errors())
+	router.GET("/missing", func(c *gin.Context) {
+		c.Error(apperr.NotFound("Product not found"))
+	})
+	router.GET("/broken", func(c *gin.Context) {
+		c.Error(errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+	})
+	router.GET("/written", func(c *gin.Context) {
+		c.Error(apperr.NotFound("ignored"))
+		c.JSON(http.StatusOK, gin.H{"result": "written"})
+	})
+
+	tests := []struct {
+		path     string
+		expected int
+		code     string
+		detail   string
+	}{
+		{"/missing", http.StatusNotFound, "not_found", "Product not found"},
+		{"/broken", http.StatusInternalServerError, "internal", "Internal server error"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.path, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		assert.Equal(t, tt.expected, rr.Code, tt.path)
+		assert.Equal(t, problem.ContentType, rr.Header().Get("Content-Type"), tt.path)
+		var body map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+			t.Fatal("Failed to parse response JSON")
+		}
+		assert.Equal(t, tt.code, body["code"], tt.path)
+		assert.Equal(t, tt.detail, body["detail"], tt.path)
+		assert.Equal(t, tt.path, body["instance"], tt.path)
+	}
+
+	req, _ := http.NewRequest("GET", "/written", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"result":"written"}`, rr.Body.String())
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// RequireIfMatch is a middleware rejecting PUT, PATCH and DELETE requests without an If-Match header with
+// a precondition required error, answered with HTTP 428, so that clients cannot overwrite changes they have not seen.
+// Requests for unknown routes are left to the router's 404 and 405 answers.
+func RequireIfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			if c.FullPath() != "" && c.GetHeader("If-Match") == "" {
+				c.Error(apperr.New(apperr.KindPreconditionRequired, "Send the ETag of the record in an If-Match header"))
+				c.Abort()
+				return
+			}
+		}
+		c.Next()
+	}
+}
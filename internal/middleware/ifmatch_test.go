@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRequireIfMatch tests that modifying requests without If-Match are refused with HTTP 428,
+// while reads, requests carrying If-Match and unknown routes are let through.
+func TestRequireIfMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Errors(), RequireIfMatch())
+	router.GET("/products/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.PUT("/products/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		method   string
+		path     string
+		ifMatch  string
+		expected int
+	}{
+		{"GET", "/products/1", "", http.StatusOK},
+		{"PUT", "/products/1", "", http.StatusPreconditionRequired},
+		{"PUT", "/products/1", `"1"`, http.StatusOK},
+		{"DELETE", "/unknown", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, nil)
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, tt.expected, rr.Code, "%s %s", tt.method, tt.path)
+	}
+}
@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// RequestIDHeader is the header used to propagate the request ID between clients, proxies and this server.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the key under which the request ID is stored in the Gin context.
+const requestIDKey = "request_id"
+
+// maxLoggedBody limits how much of a request body is buffered for debug logging.
+const maxLoggedBody = 64 << 10
+
+// redactedValue replaces the value of any sensitive header or field in the logs.
+const redactedValue = "[REDACTED]"
+
+// contextKey is an unexported type for keys stored in a request's context.Context, avoiding collisions with other packages.
+type contextKey string
+
+// requestIDContextKey is the context.Context key holding the request ID.
+const requestIDContextKey contextKey = "request_id"
+
+// sensitiveHeaders lists the request headers whose values are never written to the logs.
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// NewLogger creates a JSON structured logger writing to w at the given level.
+// The level is parsed with ParseLevel, so unknown values fall back to info.
+func NewLogger(w io.Writer, level string) *slog.Logger {
+	if w == nil {
+		w = os.Stdout
+	}
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: ParseLevel(level)}))
+}
+
+// ParseLevel converts a textual log level (debug, info, warn, error) into a slog.Level.
+// It is case-insensitive and returns slog.LevelInfo for empty or unknown values.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// RequestID is a middleware that makes sure every request carries a request ID.
+// An incoming X-Request-ID header is propagated when it is well-formed, otherwise a new UUID is generated.
+// The ID is stored in the Gin context and the request context, and echoed back in the response header.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(requestIDKey, requestID)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDContextKey, requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID assigned by the RequestID middleware, or an empty string if there is none.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx by the RequestID middleware, or an empty string.
+// It is used by code that only has access to a context.Context, such as GORM callbacks.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey).(string)
+	return requestID
+}
+
+// Logger is a middleware that writes one structured log entry per request once it has been handled.
+// The entry contains the method, path, status, latency, client IP, authenticated user and request ID,
+// plus the trace ID when the request is traced.
+// Server errors are logged at error level and client errors at warn level; everything else at info level.
+// The query string is logged with the values of password parameters redacted.
+// When debug logging is enabled, the request headers and JSON body are included with secrets redacted.
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(c *gin.Context) {
+		start := time.Now()
+		debug := logger.Enabled(c.Request.Context(), slog.LevelDebug)
+
+		var body []byte
+		if debug && c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxLoggedBody))
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user", c.GetString("username")),
+			slog.String("request_id", GetRequestID(c)),
+		}
+		if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+		}
+		if c.Request.URL.RawQuery != "" {
+			attrs = append(attrs, slog.String("query", RedactQuery(c.Request.URL.RawQuery)))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		if debug {
+			attrs = append(attrs, slog.Any("headers", RedactHeaders(c.Request.Header)))
+			if len(body) > 0 {
+				attrs = append(attrs, slog.String("body", string(RedactJSON(body))))
+			}
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request handled", attrs...)
+	}
+}
+
+// RedactHeaders returns a copy of the headers with the values of sensitive headers such as Authorization replaced.
+func RedactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// RedactJSON replaces the value of every field whose name contains "password" in a JSON document, at any depth.
+// Bodies that are not valid JSON are replaced entirely, since they cannot be inspected safely.
+func RedactJSON(body []byte) []byte {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []byte(redactedValue)
+	}
+	redacted, err := json.Marshal(redactValue(document))
+	if err != nil {
+		return []byte(redactedValue)
+	}
+	return redacted
+}
+
+// RedactQuery replaces the value of every parameter whose name contains "password" in a URL query string.
+// Query strings that cannot be parsed are replaced entirely, since they cannot be inspected safely.
+func RedactQuery(rawQuery string) string {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redactedValue
+	}
+	for name, list := range values {
+		if sensitiveField(name) {
+			for i := range list {
+				list[i] = redactedValue
+			}
+		}
+	}
+	return values.Encode()
+}
+
+// sensitiveField reports whether the value of a JSON field or query parameter must not be logged.
+func sensitiveField(name string) bool {
+	return strings.Contains(strings.ToLower(name), "password")
+}
+
+// redactValue walks a decoded JSON value and redacts password fields in every nested object.
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if sensitiveField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = redactValue(nested)
+			}
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = redactValue(nested)
+		}
+	}
+	return value
+}
+
+// validRequestID reports whether a client supplied request ID is safe to propagate.
+// It must be 1-128 characters long and only contain printable ASCII without spaces.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > 128 {
+		return false
+	}
+	for _, char := range requestID {
+		if char <= ' ' || char > '~' {
+			return false
+		}
+	}
+	return true
+}
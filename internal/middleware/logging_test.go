@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// setupRouterWithLogger creates a Gin engine using the RequestID and Logger middlewares.
+// The log output is written to the returned buffer so that tests can inspect it.
+func setupRouterWithLogger(level string) (*gin.Engine, *bytes.Buffer) {
+	gin.SetMode(gin.TestMode)
+	buffer := &bytes.Buffer{}
+	router := gin.New()
+	router.Use(RequestID(), Logger(NewLogger(buffer, level)))
+	return router, buffer
+}
+
+// TestRequestID_Generated tests that a request ID is generated and echoed back when the client does not send one.
+func TestRequestID_Generated(t *testing.T) {
+	router, _ := setupRouterWithLogger("info")
+	router.GET("/ping", func(c *gin.Context) {
+		assert.NotEmpty(t, GetRequestID(c))
+		assert.Equal(t, GetRequestID(c), RequestIDFromContext(c.Request.Context()))
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("GET", "/ping", nil)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Len(t, rr.Header().Get(RequestIDHeader), 36, "A UUID request ID should be generated")
+}
+
+// TestRequestID_Propagated tests that a valid incoming request ID is kept, and an invalid one is replaced.
+func TestRequestID_Propagated(t *testing.T) {
+	router, _ := setupRouterWithLogger("info")
+	router.GET("/ping", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req, _ := http.NewRequest("GET", "/ping", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.Equal(t, "abc-123", rr.Header().Get(RequestIDHeader))
+
+	req, _ = http.NewRequest("GET", "/ping", nil)
+	req.Header.Set(RequestIDHeader, "bad id\twith spaces")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	assert.NotEqual(t, "bad id\twith spaces", rr.Header().Get(RequestIDHeader))
+}
+
+// TestLogger_StructuredEntry tests that a JSON log entry with the expected fields is written for each request.
+func TestLogger_StructuredEntry(t *testing.T) {
+	router, buffer := setupRouterWithLogger("info")
+	router.GET("/products/:id", func(c *gin.Context) {
+		c.Set("username", "alice")
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	})
+
+	req, _ := http.NewRequest("GET", "/products/7?fields=name&password=Secret123", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatalf("Failed to parse log entry: %v", err)
+	}
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/products/7", entry["path"])
+	assert.Equal(t, float64(http.StatusNotFound), entry["status"])
+	assert.Equal(t, "alice", entry["user"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "fields=name&password=%5BREDACTED%5D", entry["query"])
+	assert.Contains(t, entry, "latency")
+	assert.NotContains(t, entry, "headers", "Headers should only be logged at debug level")
+}
+
+// TestLogger_DebugRedaction tests that debug logging includes headers and body with secrets redacted,
+// and that the handler can still read the full request body.
+func TestLogger_DebugRedaction(t *testing.T) {
+	router, buffer := setupRouterWithLogger("debug")
+	router.POST("/login", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		assert.Contains(t, string(body), "Secret123")
+		c.Status(http.StatusOK)
+	})
+
+	req, _ := http.NewRequest("POST", "/login", strings.NewReader(`{"username":"bob","password":"Secret123"}`))
+	req.Header.Set("Authorization", "Bearer token-value")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	output := buffer.String()
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.NotContains(t, output, "Secret123")
+	assert.NotContains(t, output, "token-value")
+	assert.Contains(t, output, redactedValue)
+	assert.Contains(t, output, "bob")
+}
+
+// TestRedactJSON tests redaction of nested password fields and of bodies that are not JSON.
+func TestRedactJSON(t *testing.T) {
+	redacted := string(RedactJSON([]byte(`{"user":{"Password":"x","new_password":"y","name":"n"},"list":[{"password":"z"}]}`)))
+	assert.NotContains(t, redacted, `"x"`)
+	assert.NotContains(t, redacted, `"y"`)
+	assert.NotContains(t, redacted, `"z"`)
+	assert.Contains(t, redacted, `"name":"n"`)
+
+	assert.Equal(t, redactedValue, string(RedactJSON([]byte("password=secret"))))
+}
+
+// TestRedactQuery tests redaction of password parameters in query strings and of query strings that cannot be parsed.
+func TestRedactQuery(t *testing.T) {
+	redacted := RedactQuery("q=laptop&Password=x&new_password=y&new_password=z")
+	assert.NotContains(t, redacted, "=x")
+	assert.NotContains(t, redacted, "=y")
+	assert.NotContains(t, redacted, "=z")
+	assert.Contains(t, redacted, "q=laptop")
+
+	assert.Equal(t, redactedValue, RedactQuery("password=%zz"))
+}
+
+// TestParseLevel tests parsing of the supported log levels and the fallback for unknown values.
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected slog.Level
+	}{
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"error", slog.LevelError},
+		{"", slog.LevelInfo},
+		{"verbose", slog.LevelInfo},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, ParseLevel(test.input))
+		})
+	}
+}
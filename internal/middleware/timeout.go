@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// QueryTimeout is a middleware giving the context of each request a deadline timeout from its start.
+// The repositories issue every query with the request context, so queries still running past the deadline are
+// cancelled and answered with HTTP 504, and queries of a client that disconnected stop with it.
+// A zero timeout leaves requests without a deadline.
+func QueryTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestQueryTimeout tests that the request context gets a deadline only when a timeout is configured.
+func TestQueryTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, timeout := range []time.Duration{0, time.Minute} {
+		router := gin.New()
+		router.Use(QueryTimeout(timeout))
+		var deadline time.Time
+		var hasDeadline bool
+		router.GET("/brand", func(c *gin.Context) {
+			deadline, hasDeadline = c.Request.Context().Deadline()
+			c.Status(http.StatusOK)
+		})
+
+		start := time.Now()
+		req, _ := http.NewRequest("GET", "/brand", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		assert.Equal(t, timeout > 0, hasDeadline, "timeout %s", timeout)
+		if hasDeadline {
+			assert.WithinDuration(t, start.Add(timeout), deadline, time.Second)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+//go:build integration
+
+package migrations
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"os"
+	"testing"
+)
+
+// The integration tests run the migrations against real MySQL and PostgreSQL servers:
+//
+//	MYSQL_TEST_DSN='user:password@tcp(localhost:3306)/electromart_test' \
+//	POSTGRES_TEST_DSN='host=localhost user=postgres password=postgres dbname=electromart_test sslmode=disable' \
+//	go test -tags integration ./internal/migrations/
+//
+// A dialect whose variable is unset is skipped. Each DSN must name a database dedicated to the tests: its tables
+// are dropped before and after every test.
+
+// tables lists every table created by the migrations, dropped to give each test an empty database.
+var tables = []string{"schema_migrations", "audit_log", "product_images", "product_attributes", "attributes", "reviews",
+	"shipping_details", "payments", "order_items", "product_variants", "orders", "products", "categories", "brands", "users"}
+
+// integrationDatabases opens the databases named by MYSQL_TEST_DSN and POSTGRES_TEST_DSN, emptied of the tables of
+// the migrations, by dialect.
+func integrationDatabases(t *testing.T) map[string]*gorm.DB {
+	dialectors := map[string]func(dsn string) gorm.Dialector{
+		"mysql":    mysql.Open,
+		"postgres": postgres.Open,
+	}
+	variables := map[string]string{"mysql": "MYSQL_TEST_DSN", "postgres": "POSTGRES_TEST_DSN"}
+	databases := map[string]*gorm.DB{}
+	for dialect, open := range dialectors {
+		dsn := os.Getenv(variables[dialect])
+		if dsn == "" {
+			continue
+		}
+		db, err := gorm.Open(open(dsn), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("Failed to open %s database: %v", dialect, err)
+		}
+		dropTables(t, db)
+		t.Cleanup(func() {
+			dropTables(t, db)
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+		databases[dialect] = db
+	}
+	if len(databases) == 0 {
+		t.Skip("set MYSQL_TEST_DSN or POSTGRES_TEST_DSN to run the integration tests")
+	}
+	return databases
+}
+
+// dropTables drops every table of the migrations that exists in db.
+func dropTables(t *testing.T, db *gorm.DB) {
+	for _, table := range tables {
+		if err := db.Migrator().DropTable(table); err != nil {
+			t.Fatalf("Failed to drop table %s: %v", table, err)
+		}
+	}
+}
+
+// foreignKey reports whether the table of db has the foreign key constraint of the given name.
+func foreignKey(t *testing.T, db *gorm.DB, table, name string) bool {
+	schema := "current_schema()"
+	if db.Dialector.Name() == "mysql" {
+		schema = "DATABASE()"
+	}
+	var count int64
+	err := db.Raw("SELECT COUNT(*) FROM information_schema.table_constraints WHERE table_schema = "+schema+
+		" AND table_name = ? AND constraint_name = ? AND constraint_type = 'FOREIGN KEY'", table, name).Scan(&count).Error
+	if err != nil {
+		t.Fatalf("Failed to read the constraints of %s: %v", table, err)
+	}
+	return count > 0
+}
+
+// TestIntegration_UpAndDown checks that every migration applies to an empty database, reverts and applies again.
+func TestIntegration_UpAndDown(t *testing.T) {
+	for dialect, db := range integrationDatabases(t) {
+		t.Run(dialect, func(t *testing.T) {
+			ctx := context.Background()
+			migrator, err := New(db)
+			if !assert.NoError(t, err) {
+				return
+			}
+			all := migrator.Migrations()
+
+			applied, err := migrator.Up(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, applied, len(all))
+			assert.NoError(t, migrator.Check(ctx))
+			assert.True(t, foreignKey(t, db, "orders", "fk_orders_user"))
+
+			reverted, err := migrator.Down(ctx, len(all))
+			assert.NoError(t, err)
+			assert.Len(t, reverted, len(all))
+			assert.False(t, db.Migrator().HasTable("products"))
+
+			applied, err = migrator.Up(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, applied, len(all), "the migrations should apply again after a full revert")
+		})
+	}
+}
+
+// TestIntegration_RetryForeignKeys checks that 0002_add_foreign_keys can be retried once the row it failed on is
+// fixed. On PostgreSQL the failed attempt is rolled back whole; on MySQL the constraints added before the failure
+// stay, and the retry must skip them.
+func TestIntegration_RetryForeignKeys(t *testing.T) {
+	for dialect, db := range integrationDatabases(t) {
+		t.Run(dialect, func(t *testing.T) {
+			ctx := context.Background()
+			migrator, err := New(db)
+			if !assert.NoError(t, err) {
+				return
+			}
+			all := migrator.migrations
+			migrator.migrations = all[:1]
+			_, err = migrator.Up(ctx)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, statement := range []string{
+				"INSERT INTO brands (id, name) VALUES (1, 'Acme')",
+				"INSERT INTO categories (id, name) VALUES (1, 'Laptops')",
+				"INSERT INTO products (id, name, price, brand_id, category_id) VALUES (1, 'Laptop', 999, 1, 1)",
+				"INSERT INTO orders (id, user_id, status) VALUES (1, 999, 'pending')",
+			} {
+				if !assert.NoError(t, db.Exec(statement).Error) {
+					return
+				}
+			}
+
+			migrator.migrations = all[:2]
+			_, err = migrator.Up(ctx)
+			assert.ErrorContains(t, err, "0002_add_foreign_keys", "an order of a missing user should stop the migration")
+			assert.Equal(t, dialect == "mysql", foreignKey(t, db, "products", "fk_products_brand"),
+				"only MySQL keeps the constraints added before the failure")
+			assert.False(t, foreignKey(t, db, "orders", "fk_orders_user"))
+
+			assert.NoError(t, db.Exec("DELETE FROM orders WHERE id = 1").Error)
+			applied, err := migrator.Up(ctx)
+			assert.NoError(t, err)
+			if assert.Len(t, applied, 1) {
+				assert.Equal(t, int64(2), applied[0].Version)
+			}
+			for table, name := range map[string]string{"products": "fk_products_brand", "orders": "fk_orders_user", "reviews": "fk_reviews_user"} {
+				assert.True(t, foreignKey(t, db, table, name), name)
+			}
+
+			reverted, err := migrator.Down(ctx, 1)
+			assert.NoError(t, err)
+			assert.Len(t, reverted, 1)
+			assert.False(t, foreignKey(t, db, "products", "fk_products_brand"))
+		})
+	}
+}
@@ -0,0 +1,381 @@
+package migrations
+
+import (
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/tools"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// setupMigrator opens an empty SQLite database private to the test and returns a Migrator for it.
+func setupMigrator(t *testing.T) (*Migrator, *gorm.DB) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrations.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	migrator, err := New(db)
+	if err != nil {
+		t.Fatalf("Failed to create migrator: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return migrator, db
+}
+
+// TestEmbeddedMigrations checks that every dialect ships the same migration versions and names.
+func TestEmbeddedMigrations(t *testing.T) {
+	var expected []Migration
+	for _, dialect := range Dialects {
+		migrations, err := Load(os.DirFS("sql"), dialect)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, migrations)
+		if expected == nil {
+			expected = migrations
+			continue
+		}
+		if assert.Len(t, migrations, len(expected), dialect) {
+			for i := range migrations {
+				assert.Equal(t, expected[i].Version, migrations[i].Version, dialect)
+				assert.Equal(t, expected[i].Name, migrations[i].Name, dialect)
+			}
+		}
+	}
+}
+
+// TestUpCreatesModelSchema applies every migration to an empty database and checks that
+// the table and every column of each model exist, so that the migrations match the GORM models.
+func TestUpCreatesModelSchema(t *testing.T) {
+	migrator, db := setupMigrator(t)
+	ctx := context.Background()
+
+	assert.ErrorIs(t, migrator.Check(ctx), ErrPending)
+
+	applied, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, applied, len(migrator.Migrations()))
+	assert.NoError(t, migrator.Check(ctx))
+
+	for _, model := range []interface{}{&models.User{}, &models.Brands{}, &models.Category{}, &models.Product{}, &models.ProductVariant{},
+		&models.ProductImage{}, &models.Attribute{}, &models.ProductAttribute{}, &models.Order{}, &models.OrderItem{}, &models.Payment{},
+		&models.ShippingDetails{}, &models.Review{}, &audit.Entry{}} {
+		stmt := &gorm.Statement{DB: db}
+		if !assert.NoError(t, stmt.Parse(model)) {
+			continue
+		}
+		assert.True(t, db.Migrator().HasTable(model), "table %s should exist", stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" {
+				assert.True(t, db.Migrator().HasColumn(model, field.DBName), "column %s.%s should exist", stmt.Schema.Table, field.DBName)
+			}
+		}
+	}
+
+	// Running up again is a no-op.
+	applied, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, applied)
+}
+
+// TestDownAndStatus checks that status reports applied and pending migrations,
+// and that down reverts the latest migration and removes its version row.
+func TestDownAndStatus(t *testing.T) {
+	migrator, db := setupMigrator(t)
+	ctx := context.Background()
+
+	statuses, err := migrator.Status(ctx)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt)
+	}
+
+	_, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	statuses, err = migrator.Status(ctx)
+	assert.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt)
+	}
+
+	all := migrator.Migrations()
+	reverted, err := migrator.Down(ctx, len(all)+5)
+	assert.NoError(t, err)
+	assert.Len(t, reverted, len(all))
+	assert.Equal(t, all[len(all)-1].Version, reverted[0].Version, "the latest migration should be reverted first")
+	assert.False(t, db.Migrator().HasTable(&models.Product{}))
+
+	pending, err := migrator.Pending(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(all))
+}
+
+// TestUpStopsAtFailingMigration checks that a failing migration is rolled back and not recorded,
+// while the migrations before it stay applied.
+func TestUpStopsAtFailingMigration(t *testing.T) {
+	migrator, db := setupMigrator(t)
+	migrator.migrations = []Migration{
+		{Version: 1, Name: "create_widgets", Up: "CREATE TABLE widgets (id INTEGER);", Down: "DROP TABLE widgets;"},
+		{Version: 2, Name: "broken", Up: "CREATE TABLE gadgets (id INTEGER);\nNOT SQL;", Down: "DROP TABLE gadgets;"},
+	}
+
+	applied, err := migrator.Up(context.Background())
+	assert.ErrorContains(t, err, "0002_broken")
+	assert.Len(t, applied, 1)
+	assert.True(t, db.Migrator().HasTable("widgets"))
+	assert.False(t, db.Migrator().HasTable("gadgets"), "the failing migration should be rolled back")
+
+	pending, err := migrator.Pending(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, pending, 1) {
+		assert.Equal(t, int64(2), pending[0].Version)
+	}
+}
+
+// TestLoadRejectsInvalidMigrations checks that missing down files, duplicate versions and unknown dialects are reported.
+func TestLoadRejectsInvalidMigrations(t *testing.T) {
+	_, err := Load(fstest.MapFS{"sqlite/0001_init.up.sql": {Data: []byte("SELECT 1;")}}, "sqlite")
+	assert.ErrorContains(t, err, "must have non-empty up and down files")
+
+	_, err = Load(fstest.MapFS{
+		"sqlite/0001_init.up.sql":   {Data: []byte("SELECT 1;")},
+		"sqlite/0001_other.up.sql":  {Data: []byte("SELECT 1;")},
+		"sqlite/0001_init.down.sql": {Data: []byte("SELECT 1;")},
+	}, "sqlite")
+	assert.ErrorContains(t, err, "is used by both")
+
+	_, err = Load(fstest.MapFS{}, "oracle")
+	assert.ErrorContains(t, err, "no migrations for database dialect")
+}
+
+// TestCreate checks that create numbers the new migration after the latest one and writes files for every dialect.
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sqlite"), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sqlite", "0007_init.up.sql"), []byte("SELECT 1;"), 0o644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sqlite", "0007_init.down.sql"), []byte("SELECT 1;"), 0o644))
+
+	files, err := Create(dir, "Add product SKU")
+	assert.NoError(t, err)
+	assert.Len(t, files, 2*len(Dialects))
+	assert.Contains(t, files, filepath.Join(dir, "postgres", "0008_add_product_sku.up.sql"))
+
+	for _, dialect := range Dialects {
+		migrations, err := Load(os.DirFS(dir), dialect)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(8), migrations[len(migrations)-1].Version)
+	}
+
+	_, err = Create(dir, "  !! ")
+	assert.Error(t, err)
+}
+
+// TestStatements checks that scripts are split on semicolons ending a line and that comments are dropped.
+func TestStatements(t *testing.T) {
+	script := "-- a comment\nCREATE TABLE a (\n  id INTEGER\n);\n\nINSERT INTO a VALUES (1); \nSELECT 1"
+	assert.Equal(t, []string{"CREATE TABLE a (\n  id INTEGER\n);", "INSERT INTO a VALUES (1);", "SELECT 1"}, statements(script))
+	assert.Empty(t, statements("-- only a comment\n"))
+}
+
+// TestForeignKeys applies the migrations to a SQLite database with foreign keys enabled and checks the ON DELETE behaviour:
+// deleting an order deletes its items, a product or variant with order items cannot be deleted,
+// deleting a user keeps their reviews with a NULL user_id, and deleting an attribute deletes the values of products.
+func TestForeignKeys(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "foreign_keys.db")+"?_foreign_keys=on"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	migrator, err := New(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	assert.NoError(t, err)
+
+	brand := models.Brands{Name: "Acme"}
+	assert.NoError(t, db.Create(&brand).Error)
+	category := models.Category{Name: "Laptops"}
+	assert.NoError(t, db.Create(&category).Error)
+	product := models.Product{Name: "Laptop", Price: 999.99, Brand_ID: brand.ID, Category_ID: category.ID}
+	assert.NoError(t, db.Create(&product).Error)
+	buyer := models.User{Username: "buyer", Email: "buyer@example.com"}
+	assert.NoError(t, db.Create(&buyer).Error)
+	reviewer := models.User{Username: "reviewer", Email: "reviewer@example.com"}
+	assert.NoError(t, db.Create(&reviewer).Error)
+	order := models.Order{User_ID: buyer.ID, Status: "pending"}
+	assert.NoError(t, db.Create(&order).Error)
+	variant := models.ProductVariant{Product_ID: product.ID, SKU: "LAP-16GB", Options: map[string]string{"ram": "16GB"}}
+	assert.NoError(t, db.Create(&variant).Error)
+	assert.NoError(t, db.Create(&models.OrderItem{Order_ID: order.ID, Product_ID: product.ID, Variant_ID: &variant.ID, Quantity: 1}).Error)
+	assert.Error(t, db.Create(&models.ProductVariant{Product_ID: product.ID, SKU: "LAP-16GB"}).Error, "a SKU should be unique")
+	assert.Error(t, db.Unscoped().Delete(&variant).Error, "an ordered variant should not be deletable")
+	review := models.Review{Product_ID: product.ID, User_ID: reviewer.ID, Rating: 5}
+	assert.NoError(t, db.Create(&review).Error)
+
+	assert.Error(t, db.Create(&models.Order{User_ID: 999, Status: "pending"}).Error, "an order of a missing user should be rejected")
+	assert.Error(t, db.Unscoped().Delete(&product).Error, "a product with order items should not be deletable")
+	assert.Error(t, db.Unscoped().Delete(&buyer).Error, "a user with orders should not be deletable")
+
+	assert.NoError(t, db.Unscoped().Delete(&order).Error)
+	var items int64
+	db.Model(&models.OrderItem{}).Count(&items)
+	assert.Zero(t, items, "order items should be deleted with their order")
+
+	assert.NoError(t, db.Unscoped().Delete(&reviewer).Error)
+	var reloaded models.Review
+	assert.NoError(t, db.First(&reloaded, review.ID).Error)
+	assert.Zero(t, reloaded.User_ID)
+
+	attribute := models.Attribute{Category_ID: category.ID, Name: "ram_gb", Label: "RAM", Type: models.AttributeNumber}
+	assert.NoError(t, db.Create(&attribute).Error)
+	assert.Error(t, db.Create(&models.Attribute{Category_ID: category.ID, Name: "ram_gb"}).Error, "a name should be unique in a category")
+	ram := 16.0
+	assert.NoError(t, db.Create(&models.ProductAttribute{Product_ID: product.ID, Attribute_ID: attribute.ID, Number: &ram}).Error)
+	assert.Error(t, db.Create(&models.ProductAttribute{Product_ID: product.ID, Attribute_ID: attribute.ID, Number: &ram}).Error,
+		"a product should have one value per attribute")
+	assert.NoError(t, db.Unscoped().Delete(&attribute).Error)
+	var values int64
+	db.Model(&models.ProductAttribute{}).Count(&values)
+	assert.Zero(t, values, "values should be deleted with their attribute")
+
+	image := models.ProductImage{Product_ID: product.ID, Primary: true}
+	image.SetFiles("products/1/2.png", "products/1/2_thumb.png")
+	assert.NoError(t, db.Create(&image).Error)
+	assert.Error(t, db.Create(&models.ProductImage{Product_ID: 999}).Error, "an image of a missing product should be rejected")
+	logo := &models.Image{Content_Type: "image/png", Size: 42, Width: 10, Height: 10}
+	logo.SetFiles("brands/1/3.png", "brands/1/3_thumb.png")
+	brand.Logo = logo
+	assert.NoError(t, db.Save(&brand).Error)
+	var withLogo models.Brands
+	assert.NoError(t, db.First(&withLogo, brand.ID).Error)
+	assert.Equal(t, logo, withLogo.Logo)
+	assert.NoError(t, db.Unscoped().Delete(&product).Error)
+	var images int64
+	db.Model(&models.ProductImage{}).Count(&images)
+	assert.Zero(t, images, "images should be deleted with their product")
+}
+
+// TestTimeOrderedIDs checks that the IDs of existing rows, their references and their audit log entries are moved
+// into the range of generated IDs and back, whether or not foreign keys are enforced.
+func TestTimeOrderedIDs(t *testing.T) {
+	for name, dsn := range map[string]string{"foreign keys": "?_foreign_keys=on", "no foreign keys": ""} {
+		t.Run(name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "ids.db")+dsn), &gorm.Config{})
+			if err != nil {
+				t.Fatalf("Failed to open database: %v", err)
+			}
+			migrator, err := New(db)
+			assert.NoError(t, err)
+			all := migrator.Migrations()
+			migrator.migrations = all[:4]
+			ctx := context.Background()
+			_, err = migrator.Up(ctx)
+			assert.NoError(t, err)
+
+			assert.NoError(t, db.Exec(`INSERT INTO "brands" ("id", "name") VALUES (?, ?)`, 7, "Acme").Error)
+			assert.NoError(t, db.Exec(`INSERT INTO "categories" ("id", "name") VALUES (?, ?)`, 3000000000, "Laptops").Error)
+			assert.NoError(t, db.Create(&models.Product{Model: gorm.Model{ID: 42}, Name: "Laptop", Brand_ID: 7, Category_ID: 3000000000}).Error)
+			assert.NoError(t, db.Create(&audit.Entry{Entity: "brand", EntityID: 7, Action: audit.ActionCreate}).Error)
+
+			migrator.migrations = all[:5]
+			_, err = migrator.Up(ctx)
+			assert.NoError(t, err)
+			moved := func(id uint) uint { return (id + 1<<32) << 12 }
+			var product models.Product
+			assert.NoError(t, db.First(&product, moved(42)).Error)
+			assert.Equal(t, moved(7), product.Brand_ID)
+			assert.Equal(t, moved(3000000000), product.Category_ID)
+			assert.Less(t, product.Category_ID, tools.GenerateID(), "moved IDs should sort before generated ones")
+			var entry audit.Entry
+			assert.NoError(t, db.Where("entity = ?", "brand").First(&entry).Error)
+			assert.Equal(t, moved(7), entry.EntityID)
+
+			_, err = migrator.Down(ctx, 1)
+			assert.NoError(t, err)
+			var restored models.Product
+			assert.NoError(t, db.First(&restored, 42).Error)
+			assert.Equal(t, uint(7), restored.Brand_ID)
+			assert.Equal(t, uint(3000000000), restored.Category_ID)
+			var restoredEntry audit.Entry
+			assert.NoError(t, db.Where("entity = ?", "brand").First(&restoredEntry).Error)
+			assert.Equal(t, uint(7), restoredEntry.EntityID)
+		})
+	}
+}
+
+// TestTimestampDates checks that text dates become timestamps at midnight UTC, that values which are not
+// calendar dates fall back to the creation time of their row, and that reverting restores YYYY-MM-DD text.
+func TestTimestampDates(t *testing.T) {
+	migrator, db := setupMigrator(t)
+	all := migrator.Migrations()
+	migrator.migrations = all[:5]
+	ctx := context.Background()
+	_, err := migrator.Up(ctx)
+	assert.NoError(t, err)
+
+	created := "2024-03-01 12:30:00+00:00"
+	for id, date := range map[int]string{1: "2024-01-15", 2: "2024-02-30", 3: ""} {
+		assert.NoError(t, db.Exec(`INSERT INTO "orders" ("id", "created_at", "order_date", "status") VALUES (?, ?, ?, 'pending')`, id, created, date).Error)
+	}
+
+	migrator.migrations = all[:6]
+	_, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	var orders []models.Order
+	assert.NoError(t, db.Order("id").Find(&orders).Error)
+	if assert.Len(t, orders, 3) {
+		assert.True(t, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC).Equal(orders[0].Order_date))
+		assert.True(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC).Equal(orders[1].Order_date), "an invalid date should become the creation time")
+		assert.True(t, time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC).Equal(orders[2].Order_date), "an empty date should become the creation time")
+	}
+
+	_, err = migrator.Down(ctx, 1)
+	assert.NoError(t, err)
+	var dates []string
+	assert.NoError(t, db.Raw(`SELECT "order_date" FROM "orders" ORDER BY "id"`).Scan(&dates).Error)
+	assert.Equal(t, []string{"2024-01-15", "2024-03-01", "2024-03-01"}, dates)
+}
+
+// TestCategoryTree checks that existing categories become roots with a path of their own ID, that a parent with
+// children cannot be deleted, and that reverting drops the tree columns.
+func TestCategoryTree(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tree.db")+"?_foreign_keys=on"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	migrator, err := New(db)
+	assert.NoError(t, err)
+	all := migrator.Migrations()
+	migrator.migrations = all[:6]
+	ctx := context.Background()
+	_, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, db.Exec(`INSERT INTO "categories" ("id", "name") VALUES (?, ?)`, 12, "Computers").Error)
+
+	migrator.migrations = all[:7]
+	_, err = migrator.Up(ctx)
+	assert.NoError(t, err)
+	var root models.Category
+	assert.NoError(t, db.First(&root, 12).Error)
+	assert.Nil(t, root.Parent_ID)
+	assert.Equal(t, "/12/", root.Path)
+
+	parent := uint(12)
+	child := models.Category{Model: gorm.Model{ID: 34}, Name: "Laptops", Parent_ID: &parent, Path: "/12/34/"}
+	assert.NoError(t, db.Create(&child).Error)
+	assert.Error(t, db.Unscoped().Delete(&root).Error, "a category with children should not be deletable")
+	assert.Error(t, db.Create(&models.Category{Name: "Orphan", Parent_ID: new(uint)}).Error, "a missing parent should be rejected")
+
+	_, err = migrator.Down(ctx, 1)
+	assert.NoError(t, err)
+	assert.False(t, db.Migrator().HasColumn(&models.Category{}, "parent_id"))
+	assert.False(t, db.Migrator().HasColumn(&models.Category{}, "path"))
+	assert.False(t, db.Migrator().HasIndex(&models.Category{}, "idx_categories_path"))
+}
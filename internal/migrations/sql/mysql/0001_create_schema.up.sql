@@ -0,0 +1,124 @@
+-- Creates the initial ElectroMart schema. Tables are only created when missing, so databases
+-- set up from the former SQL script can be brought under version control by running this migration.
+
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `username` VARCHAR(191) UNIQUE,
+    `password` LONGTEXT,
+    `email` VARCHAR(191) UNIQUE,
+    `first_name` LONGTEXT,
+    `last_name` LONGTEXT,
+    `address` LONGTEXT,
+    `mobile` LONGTEXT,
+    `role` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_users_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `brands` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `name` LONGTEXT,
+    `description` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_brands_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `categories` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `name` LONGTEXT,
+    `description` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_categories_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `products` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `name` LONGTEXT,
+    `description` LONGTEXT,
+    `price` DOUBLE,
+    `stock_quantity` BIGINT,
+    `brand_id` INT UNSIGNED,
+    `category_id` INT UNSIGNED,
+    PRIMARY KEY (`id`),
+    INDEX `idx_products_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `orders` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `user_id` INT UNSIGNED,
+    `order_date` LONGTEXT,
+    `total_amount` DOUBLE,
+    `status` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_orders_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `order_id` INT UNSIGNED,
+    `product_id` INT UNSIGNED,
+    `quantity` BIGINT,
+    `subtotal` DOUBLE,
+    PRIMARY KEY (`id`),
+    INDEX `idx_order_items_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `payments` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `order_id` INT UNSIGNED,
+    `payment_method` LONGTEXT,
+    `amount` DOUBLE,
+    `payment_date` LONGTEXT,
+    `status` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_payments_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `shipping_details` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `order_id` INT UNSIGNED,
+    `address` LONGTEXT,
+    `shipping_date` LONGTEXT,
+    `estimated_arrival` LONGTEXT,
+    `status` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_shipping_details_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `reviews` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `product_id` INT UNSIGNED,
+    `user_id` INT UNSIGNED,
+    `rating` BIGINT,
+    `comment` LONGTEXT,
+    `review_date` LONGTEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_reviews_deleted_at` (`deleted_at`)
+);
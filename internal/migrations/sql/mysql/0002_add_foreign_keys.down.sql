@@ -0,0 +1,63 @@
+-- Like the up migration, every statement can be run again after a failure part way: each constraint is only
+-- dropped while information_schema still lists it.
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'reviews' AND CONSTRAINT_NAME = 'fk_reviews_user') > 0,
+  'ALTER TABLE `reviews` DROP FOREIGN KEY `fk_reviews_user`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'reviews' AND CONSTRAINT_NAME = 'fk_reviews_product') > 0,
+  'ALTER TABLE `reviews` DROP FOREIGN KEY `fk_reviews_product`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'shipping_details' AND CONSTRAINT_NAME = 'fk_orders_shipping') > 0,
+  'ALTER TABLE `shipping_details` DROP FOREIGN KEY `fk_orders_shipping`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'payments' AND CONSTRAINT_NAME = 'fk_orders_payments') > 0,
+  'ALTER TABLE `payments` DROP FOREIGN KEY `fk_orders_payments`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'order_items' AND CONSTRAINT_NAME = 'fk_order_items_product') > 0,
+  'ALTER TABLE `order_items` DROP FOREIGN KEY `fk_order_items_product`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'order_items' AND CONSTRAINT_NAME = 'fk_orders_items') > 0,
+  'ALTER TABLE `order_items` DROP FOREIGN KEY `fk_orders_items`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'orders' AND CONSTRAINT_NAME = 'fk_orders_user') > 0,
+  'ALTER TABLE `orders` DROP FOREIGN KEY `fk_orders_user`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'products' AND CONSTRAINT_NAME = 'fk_products_category') > 0,
+  'ALTER TABLE `products` DROP FOREIGN KEY `fk_products_category`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'products' AND CONSTRAINT_NAME = 'fk_products_brand') > 0,
+  'ALTER TABLE `products` DROP FOREIGN KEY `fk_products_brand`', 'DO 0');
+PREPARE drop_constraint FROM @ddl;
+EXECUTE drop_constraint;
+DEALLOCATE PREPARE drop_constraint;
+
+ALTER TABLE `reviews` MODIFY `product_id` INT UNSIGNED, MODIFY `user_id` INT UNSIGNED;
+ALTER TABLE `shipping_details` MODIFY `order_id` INT UNSIGNED;
+ALTER TABLE `payments` MODIFY `order_id` INT UNSIGNED;
+ALTER TABLE `order_items` MODIFY `order_id` INT UNSIGNED, MODIFY `product_id` INT UNSIGNED;
+ALTER TABLE `orders` MODIFY `user_id` INT UNSIGNED;
+ALTER TABLE `products` MODIFY `brand_id` INT UNSIGNED, MODIFY `category_id` INT UNSIGNED;
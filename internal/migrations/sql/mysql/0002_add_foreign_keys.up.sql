@@ -0,0 +1,77 @@
+-- Adds foreign keys for the relations between tables, with the ON DELETE behaviour of the GORM associations.
+-- Rows whose parent no longer exists would make the constraints fail, so the rows the delete behaviour would have
+-- handled are cleaned up first: orphaned order items, payments, shipping details and reviews are deleted, and
+-- reviews of deleted users are kept with user_id set to NULL. Products and orders referencing missing brands,
+-- categories, users or products make the migration fail and must be fixed by hand.
+-- The reference columns are widened to BIGINT UNSIGNED first, since MySQL requires them to match the primary keys.
+--
+-- MySQL commits every ALTER TABLE on its own, so a failure part way leaves the earlier statements applied. Every
+-- statement can therefore be run again: the deletes and column changes have nothing left to do, and each constraint
+-- is only added when information_schema does not list it yet. Once the offending rows are fixed, migrate up resumes.
+
+DELETE FROM `order_items` WHERE `order_id` IS NOT NULL AND `order_id` NOT IN (SELECT `id` FROM `orders`);
+DELETE FROM `payments` WHERE `order_id` IS NOT NULL AND `order_id` NOT IN (SELECT `id` FROM `orders`);
+DELETE FROM `shipping_details` WHERE `order_id` IS NOT NULL AND `order_id` NOT IN (SELECT `id` FROM `orders`);
+DELETE FROM `reviews` WHERE `product_id` IS NOT NULL AND `product_id` NOT IN (SELECT `id` FROM `products`);
+UPDATE `reviews` SET `user_id` = NULL WHERE `user_id` IS NOT NULL AND `user_id` NOT IN (SELECT `id` FROM `users`);
+
+ALTER TABLE `products` MODIFY `brand_id` BIGINT UNSIGNED NULL, MODIFY `category_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `orders` MODIFY `user_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `order_items` MODIFY `order_id` BIGINT UNSIGNED NULL, MODIFY `product_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `payments` MODIFY `order_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `shipping_details` MODIFY `order_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `reviews` MODIFY `product_id` BIGINT UNSIGNED NULL, MODIFY `user_id` BIGINT UNSIGNED NULL;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'products' AND CONSTRAINT_NAME = 'fk_products_brand') = 0,
+  'ALTER TABLE `products` ADD CONSTRAINT `fk_products_brand` FOREIGN KEY (`brand_id`) REFERENCES `brands` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'products' AND CONSTRAINT_NAME = 'fk_products_category') = 0,
+  'ALTER TABLE `products` ADD CONSTRAINT `fk_products_category` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'orders' AND CONSTRAINT_NAME = 'fk_orders_user') = 0,
+  'ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'order_items' AND CONSTRAINT_NAME = 'fk_orders_items') = 0,
+  'ALTER TABLE `order_items` ADD CONSTRAINT `fk_orders_items` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`) ON DELETE CASCADE ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'order_items' AND CONSTRAINT_NAME = 'fk_order_items_product') = 0,
+  'ALTER TABLE `order_items` ADD CONSTRAINT `fk_order_items_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'payments' AND CONSTRAINT_NAME = 'fk_orders_payments') = 0,
+  'ALTER TABLE `payments` ADD CONSTRAINT `fk_orders_payments` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`) ON DELETE CASCADE ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'shipping_details' AND CONSTRAINT_NAME = 'fk_orders_shipping') = 0,
+  'ALTER TABLE `shipping_details` ADD CONSTRAINT `fk_orders_shipping` FOREIGN KEY (`order_id`) REFERENCES `orders` (`id`) ON DELETE CASCADE ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'reviews' AND CONSTRAINT_NAME = 'fk_reviews_product') = 0,
+  'ALTER TABLE `reviews` ADD CONSTRAINT `fk_reviews_product` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE CASCADE ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
+
+SET @ddl = IF((SELECT COUNT(*) FROM information_schema.TABLE_CONSTRAINTS WHERE CONSTRAINT_SCHEMA = DATABASE() AND TABLE_NAME = 'reviews' AND CONSTRAINT_NAME = 'fk_reviews_user') = 0,
+  'ALTER TABLE `reviews` ADD CONSTRAINT `fk_reviews_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE SET NULL ON UPDATE CASCADE', 'DO 0');
+PREPARE add_constraint FROM @ddl;
+EXECUTE add_constraint;
+DEALLOCATE PREPARE add_constraint;
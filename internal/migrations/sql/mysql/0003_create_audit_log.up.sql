@@ -0,0 +1,16 @@
+-- Creates the audit log, holding one row per created, updated, deleted, restored or purged record
+-- with the user who made the change, the request it was made in and the changed columns as JSON.
+
+CREATE TABLE IF NOT EXISTS `audit_log` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `entity` VARCHAR(64),
+    `entity_id` BIGINT UNSIGNED,
+    `action` VARCHAR(16),
+    `actor` VARCHAR(191),
+    `request_id` VARCHAR(128),
+    `changes` TEXT,
+    PRIMARY KEY (`id`),
+    INDEX `idx_audit_log_entity` (`entity`, `entity_id`),
+    INDEX `idx_audit_log_created_at` (`created_at`)
+);
@@ -0,0 +1,9 @@
+ALTER TABLE `reviews` DROP COLUMN `version`;
+ALTER TABLE `shipping_details` DROP COLUMN `version`;
+ALTER TABLE `payments` DROP COLUMN `version`;
+ALTER TABLE `order_items` DROP COLUMN `version`;
+ALTER TABLE `orders` DROP COLUMN `version`;
+ALTER TABLE `products` DROP COLUMN `version`;
+ALTER TABLE `categories` DROP COLUMN `version`;
+ALTER TABLE `brands` DROP COLUMN `version`;
+ALTER TABLE `users` DROP COLUMN `version`;
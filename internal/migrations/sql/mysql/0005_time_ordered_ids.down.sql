@@ -0,0 +1,27 @@
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'user' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `users` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'brand' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `brands` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'category' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `categories` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'product' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `products` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'order' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `orders` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'order_item' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `order_items` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'payment' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `payments` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'shipping_detail' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `shipping_details` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `audit_log` SET `entity_id` = `entity_id` DIV 4096 - 4294967296 WHERE `entity` = 'review' AND `entity_id` >= 17592186044416 AND `entity_id` < 35184372088832;
+UPDATE `reviews` SET `id` = `id` DIV 4096 - 4294967296 WHERE `id` >= 17592186044416 AND `id` < 35184372088832;
+UPDATE `products` SET `brand_id` = `brand_id` DIV 4096 - 4294967296 WHERE `brand_id` >= 17592186044416 AND `brand_id` < 35184372088832;
+UPDATE `products` SET `category_id` = `category_id` DIV 4096 - 4294967296 WHERE `category_id` >= 17592186044416 AND `category_id` < 35184372088832;
+UPDATE `orders` SET `user_id` = `user_id` DIV 4096 - 4294967296 WHERE `user_id` >= 17592186044416 AND `user_id` < 35184372088832;
+UPDATE `order_items` SET `order_id` = `order_id` DIV 4096 - 4294967296 WHERE `order_id` >= 17592186044416 AND `order_id` < 35184372088832;
+UPDATE `order_items` SET `product_id` = `product_id` DIV 4096 - 4294967296 WHERE `product_id` >= 17592186044416 AND `product_id` < 35184372088832;
+UPDATE `payments` SET `order_id` = `order_id` DIV 4096 - 4294967296 WHERE `order_id` >= 17592186044416 AND `order_id` < 35184372088832;
+UPDATE `shipping_details` SET `order_id` = `order_id` DIV 4096 - 4294967296 WHERE `order_id` >= 17592186044416 AND `order_id` < 35184372088832;
+UPDATE `reviews` SET `product_id` = `product_id` DIV 4096 - 4294967296 WHERE `product_id` >= 17592186044416 AND `product_id` < 35184372088832;
+UPDATE `reviews` SET `user_id` = `user_id` DIV 4096 - 4294967296 WHERE `user_id` >= 17592186044416 AND `user_id` < 35184372088832;
@@ -0,0 +1,36 @@
+-- Moves the IDs of existing rows, random numbers below 2^32, into the range of the time-ordered 53-bit IDs
+-- generated from now on. An old ID n becomes (n + 2^32) * 2^12, the ID of node 0 and sequence 0 at 2^32 + n
+-- milliseconds after the ID epoch of 2020-01-01. These times are in early 2020, before any generated ID, so the
+-- moved rows keep their order among themselves, sort before every new row and can never collide with new IDs.
+-- Each table is moved with the columns referencing it and the audit log entries about it. The foreign keys
+-- cascade the moves when they are enforced; the references are moved explicitly as well for SQLite databases
+-- opened without foreign keys, which leaves the ones already moved by a cascade untouched.
+-- The values recorded in the changes of audit log entries keep the old IDs.
+
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'user' AND `entity_id` < 4294967296;
+UPDATE `users` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'brand' AND `entity_id` < 4294967296;
+UPDATE `brands` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'category' AND `entity_id` < 4294967296;
+UPDATE `categories` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'product' AND `entity_id` < 4294967296;
+UPDATE `products` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'order' AND `entity_id` < 4294967296;
+UPDATE `orders` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'order_item' AND `entity_id` < 4294967296;
+UPDATE `order_items` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'payment' AND `entity_id` < 4294967296;
+UPDATE `payments` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'shipping_detail' AND `entity_id` < 4294967296;
+UPDATE `shipping_details` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `audit_log` SET `entity_id` = (`entity_id` + 4294967296) * 4096 WHERE `entity` = 'review' AND `entity_id` < 4294967296;
+UPDATE `reviews` SET `id` = (`id` + 4294967296) * 4096 WHERE `id` < 4294967296;
+UPDATE `products` SET `brand_id` = (`brand_id` + 4294967296) * 4096 WHERE `brand_id` < 4294967296;
+UPDATE `products` SET `category_id` = (`category_id` + 4294967296) * 4096 WHERE `category_id` < 4294967296;
+UPDATE `orders` SET `user_id` = (`user_id` + 4294967296) * 4096 WHERE `user_id` < 4294967296;
+UPDATE `order_items` SET `order_id` = (`order_id` + 4294967296) * 4096 WHERE `order_id` < 4294967296;
+UPDATE `order_items` SET `product_id` = (`product_id` + 4294967296) * 4096 WHERE `product_id` < 4294967296;
+UPDATE `payments` SET `order_id` = (`order_id` + 4294967296) * 4096 WHERE `order_id` < 4294967296;
+UPDATE `shipping_details` SET `order_id` = (`order_id` + 4294967296) * 4096 WHERE `order_id` < 4294967296;
+UPDATE `reviews` SET `product_id` = (`product_id` + 4294967296) * 4096 WHERE `product_id` < 4294967296;
+UPDATE `reviews` SET `user_id` = (`user_id` + 4294967296) * 4096 WHERE `user_id` < 4294967296;
@@ -0,0 +1,24 @@
+ALTER TABLE `reviews` ADD COLUMN `review_date_text` LONGTEXT;
+UPDATE `reviews` SET `review_date_text` = DATE_FORMAT(`review_date`, '%Y-%m-%d');
+ALTER TABLE `reviews` DROP COLUMN `review_date`;
+ALTER TABLE `reviews` RENAME COLUMN `review_date_text` TO `review_date`;
+
+ALTER TABLE `shipping_details` ADD COLUMN `estimated_arrival_text` LONGTEXT;
+UPDATE `shipping_details` SET `estimated_arrival_text` = DATE_FORMAT(`estimated_arrival`, '%Y-%m-%d');
+ALTER TABLE `shipping_details` DROP COLUMN `estimated_arrival`;
+ALTER TABLE `shipping_details` RENAME COLUMN `estimated_arrival_text` TO `estimated_arrival`;
+
+ALTER TABLE `shipping_details` ADD COLUMN `shipping_date_text` LONGTEXT;
+UPDATE `shipping_details` SET `shipping_date_text` = DATE_FORMAT(`shipping_date`, '%Y-%m-%d');
+ALTER TABLE `shipping_details` DROP COLUMN `shipping_date`;
+ALTER TABLE `shipping_details` RENAME COLUMN `shipping_date_text` TO `shipping_date`;
+
+ALTER TABLE `payments` ADD COLUMN `payment_date_text` LONGTEXT;
+UPDATE `payments` SET `payment_date_text` = DATE_FORMAT(`payment_date`, '%Y-%m-%d');
+ALTER TABLE `payments` DROP COLUMN `payment_date`;
+ALTER TABLE `payments` RENAME COLUMN `payment_date_text` TO `payment_date`;
+
+ALTER TABLE `orders` ADD COLUMN `order_date_text` LONGTEXT;
+UPDATE `orders` SET `order_date_text` = DATE_FORMAT(`order_date`, '%Y-%m-%d');
+ALTER TABLE `orders` DROP COLUMN `order_date`;
+ALTER TABLE `orders` RENAME COLUMN `order_date_text` TO `order_date`;
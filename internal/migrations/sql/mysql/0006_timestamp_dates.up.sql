@@ -0,0 +1,58 @@
+-- Stores the order, payment, shipping and review dates as timestamps in UTC instead of text. A stored
+-- YYYY-MM-DD date becomes midnight UTC of that day; a value that is not a valid calendar date in that
+-- format, such as an empty one, becomes the creation time of its row.
+
+ALTER TABLE `orders` ADD COLUMN `order_date_utc` DATETIME(3) NULL;
+UPDATE `orders` SET `order_date_utc` = CASE
+    WHEN `order_date` REGEXP '^[0-9]{4}-[0-9]{2}-[0-9]{2}$'
+        AND SUBSTRING(`order_date`, 6, 2) BETWEEN '01' AND '12'
+        AND SUBSTRING(`order_date`, 9, 2) BETWEEN '01' AND DAY(LAST_DAY(CONCAT(LEFT(`order_date`, 7), '-01')))
+    THEN CAST(`order_date` AS DATETIME(3))
+    ELSE `created_at`
+END;
+ALTER TABLE `orders` DROP COLUMN `order_date`;
+ALTER TABLE `orders` RENAME COLUMN `order_date_utc` TO `order_date`;
+
+ALTER TABLE `payments` ADD COLUMN `payment_date_utc` DATETIME(3) NULL;
+UPDATE `payments` SET `payment_date_utc` = CASE
+    WHEN `payment_date` REGEXP '^[0-9]{4}-[0-9]{2}-[0-9]{2}$'
+        AND SUBSTRING(`payment_date`, 6, 2) BETWEEN '01' AND '12'
+        AND SUBSTRING(`payment_date`, 9, 2) BETWEEN '01' AND DAY(LAST_DAY(CONCAT(LEFT(`payment_date`, 7), '-01')))
+    THEN CAST(`payment_date` AS DATETIME(3))
+    ELSE `created_at`
+END;
+ALTER TABLE `payments` DROP COLUMN `payment_date`;
+ALTER TABLE `payments` RENAME COLUMN `payment_date_utc` TO `payment_date`;
+
+ALTER TABLE `shipping_details` ADD COLUMN `shipping_date_utc` DATETIME(3) NULL;
+UPDATE `shipping_details` SET `shipping_date_utc` = CASE
+    WHEN `shipping_date` REGEXP '^[0-9]{4}-[0-9]{2}-[0-9]{2}$'
+        AND SUBSTRING(`shipping_date`, 6, 2) BETWEEN '01' AND '12'
+        AND SUBSTRING(`shipping_date`, 9, 2) BETWEEN '01' AND DAY(LAST_DAY(CONCAT(LEFT(`shipping_date`, 7), '-01')))
+    THEN CAST(`shipping_date` AS DATETIME(3))
+    ELSE `created_at`
+END;
+ALTER TABLE `shipping_details` DROP COLUMN `shipping_date`;
+ALTER TABLE `shipping_details` RENAME COLUMN `shipping_date_utc` TO `shipping_date`;
+
+ALTER TABLE `shipping_details` ADD COLUMN `estimated_arrival_utc` DATETIME(3) NULL;
+UPDATE `shipping_details` SET `estimated_arrival_utc` = CASE
+    WHEN `estimated_arrival` REGEXP '^[0-9]{4}-[0-9]{2}-[0-9]{2}$'
+        AND SUBSTRING(`estimated_arrival`, 6, 2) BETWEEN '01' AND '12'
+        AND SUBSTRING(`estimated_arrival`, 9, 2) BETWEEN '01' AND DAY(LAST_DAY(CONCAT(LEFT(`estimated_arrival`, 7), '-01')))
+    THEN CAST(`estimated_arrival` AS DATETIME(3))
+    ELSE `created_at`
+END;
+ALTER TABLE `shipping_details` DROP COLUMN `estimated_arrival`;
+ALTER TABLE `shipping_details` RENAME COLUMN `estimated_arrival_utc` TO `estimated_arrival`;
+
+ALTER TABLE `reviews` ADD COLUMN `review_date_utc` DATETIME(3) NULL;
+UPDATE `reviews` SET `review_date_utc` = CASE
+    WHEN `review_date` REGEXP '^[0-9]{4}-[0-9]{2}-[0-9]{2}$'
+        AND SUBSTRING(`review_date`, 6, 2) BETWEEN '01' AND '12'
+        AND SUBSTRING(`review_date`, 9, 2) BETWEEN '01' AND DAY(LAST_DAY(CONCAT(LEFT(`review_date`, 7), '-01')))
+    THEN CAST(`review_date` AS DATETIME(3))
+    ELSE `created_at`
+END;
+ALTER TABLE `reviews` DROP COLUMN `review_date`;
+ALTER TABLE `reviews` RENAME COLUMN `review_date_utc` TO `review_date`;
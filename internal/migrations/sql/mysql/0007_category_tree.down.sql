@@ -0,0 +1,3 @@
+ALTER TABLE `categories` DROP FOREIGN KEY `fk_categories_parent`;
+DROP INDEX `idx_categories_path` ON `categories`;
+ALTER TABLE `categories` DROP COLUMN `path`, DROP COLUMN `parent_id`;
@@ -0,0 +1,9 @@
+-- Lets categories nest: parent_id references the parent category, NULL for a root, and path lists the IDs from the
+-- root down to the category, e.g. /12/34/56/, so that a subtree is read with one indexed prefix search.
+-- Existing categories become roots. Paths only hold digits and slashes, so they are stored as ASCII to keep the
+-- indexed column within the key size limit.
+
+ALTER TABLE `categories` ADD COLUMN `parent_id` BIGINT UNSIGNED NULL, ADD COLUMN `path` VARCHAR(2048) CHARACTER SET ascii COLLATE ascii_bin NULL;
+UPDATE `categories` SET `path` = CONCAT('/', `id`, '/');
+ALTER TABLE `categories` ADD CONSTRAINT `fk_categories_parent` FOREIGN KEY (`parent_id`) REFERENCES `categories` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX `idx_categories_path` ON `categories` (`path`);
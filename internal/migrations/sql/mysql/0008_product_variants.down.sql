@@ -0,0 +1,3 @@
+ALTER TABLE `order_items` DROP FOREIGN KEY `fk_order_items_variant`;
+ALTER TABLE `order_items` DROP COLUMN `variant_id`;
+DROP TABLE IF EXISTS `product_variants`;
@@ -0,0 +1,22 @@
+-- Adds product variants, the versions of a product sold under their own SKU with their own option values, price
+-- override and stock, and lets order items select the variant ordered. Variants are deleted with their product and
+-- cannot be deleted while they are ordered. Options are stored as a JSON object.
+
+CREATE TABLE IF NOT EXISTS `product_variants` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `version` BIGINT UNSIGNED NOT NULL DEFAULT 1,
+    `product_id` BIGINT UNSIGNED NULL,
+    `sku` VARCHAR(64),
+    `options` TEXT,
+    `price` DOUBLE NULL,
+    `stock_quantity` BIGINT,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_product_variants_sku` (`sku`),
+    INDEX `idx_product_variants_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_products_variants` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+ALTER TABLE `order_items` ADD COLUMN `variant_id` BIGINT UNSIGNED NULL;
+ALTER TABLE `order_items` ADD CONSTRAINT `fk_order_items_variant` FOREIGN KEY (`variant_id`) REFERENCES `product_variants` (`id`) ON DELETE RESTRICT ON UPDATE CASCADE;
@@ -0,0 +1,36 @@
+-- Adds typed attributes, the specifications a category defines for the products of its subtree with a type and
+-- a unit, and the values of products, stored in the column of the type of their attribute so that products can be
+-- filtered and counted by value. Attributes are deleted with their category and values with their product or attribute.
+
+CREATE TABLE IF NOT EXISTS `attributes` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `version` BIGINT UNSIGNED NOT NULL DEFAULT 1,
+    `category_id` BIGINT UNSIGNED NULL,
+    `name` VARCHAR(64),
+    `label` VARCHAR(255),
+    `type` VARCHAR(16),
+    `unit` VARCHAR(32),
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_attributes_category_name` (`category_id`, `name`),
+    INDEX `idx_attributes_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_categories_attributes` FOREIGN KEY (`category_id`) REFERENCES `categories` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE TABLE IF NOT EXISTS `product_attributes` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `product_id` BIGINT UNSIGNED NULL,
+    `attribute_id` BIGINT UNSIGNED NULL,
+    `number_value` DOUBLE NULL,
+    `text_value` VARCHAR(255) NULL,
+    `boolean_value` BOOLEAN NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_product_attributes_product_attribute` (`product_id`, `attribute_id`),
+    INDEX `idx_product_attributes_number` (`attribute_id`, `number_value`),
+    INDEX `idx_product_attributes_text` (`attribute_id`, `text_value`),
+    CONSTRAINT `fk_products_attributes` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT `fk_product_attributes_attribute` FOREIGN KEY (`attribute_id`) REFERENCES `attributes` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,31 @@
+-- Adds the galleries of products, ordered images with a single primary image deleted with their product, and the
+-- logos of brands. Files are kept in the media storage under the keys stored here, with their sniffed media type,
+-- size and dimensions; an empty logo_file means the brand has no logo.
+
+CREATE TABLE IF NOT EXISTS `product_images` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    `created_at` DATETIME(3) NULL,
+    `updated_at` DATETIME(3) NULL,
+    `deleted_at` DATETIME(3) NULL,
+    `version` BIGINT UNSIGNED NOT NULL DEFAULT 1,
+    `file` VARCHAR(255),
+    `thumbnail` VARCHAR(255),
+    `content_type` VARCHAR(64),
+    `size` BIGINT,
+    `width` BIGINT,
+    `height` BIGINT,
+    `product_id` BIGINT UNSIGNED NULL,
+    `alt` VARCHAR(255),
+    `position` BIGINT,
+    `is_primary` BOOLEAN,
+    PRIMARY KEY (`id`),
+    INDEX `idx_product_images_deleted_at` (`deleted_at`),
+    INDEX `idx_product_images_product_id` (`product_id`),
+    CONSTRAINT `fk_products_images` FOREIGN KEY (`product_id`) REFERENCES `products` (`id`) ON DELETE CASCADE ON UPDATE CASCADE
+);
+ALTER TABLE `brands` ADD COLUMN `logo_file` VARCHAR(255) NULL;
+ALTER TABLE `brands` ADD COLUMN `logo_thumbnail` VARCHAR(255) NULL;
+ALTER TABLE `brands` ADD COLUMN `logo_content_type` VARCHAR(64) NULL;
+ALTER TABLE `brands` ADD COLUMN `logo_size` BIGINT NULL;
+ALTER TABLE `brands` ADD COLUMN `logo_width` BIGINT NULL;
+ALTER TABLE `brands` ADD COLUMN `logo_height` BIGINT NULL;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "reviews";
+DROP TABLE IF EXISTS "shipping_details";
+DROP TABLE IF EXISTS "payments";
+DROP TABLE IF EXISTS "order_items";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "brands";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,115 @@
+-- Creates the initial ElectroMart schema. Tables are only created when missing, so databases
+-- set up from the former SQL script can be brought under version control by running this migration.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "username" TEXT UNIQUE,
+    "password" TEXT,
+    "email" TEXT UNIQUE,
+    "first_name" TEXT,
+    "last_name" TEXT,
+    "address" TEXT,
+    "mobile" TEXT,
+    "role" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "brands" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "name" TEXT,
+    "description" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_brands_deleted_at" ON "brands" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "name" TEXT,
+    "description" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "name" TEXT,
+    "description" TEXT,
+    "price" DOUBLE PRECISION,
+    "stock_quantity" BIGINT,
+    "brand_id" BIGINT,
+    "category_id" BIGINT
+);
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "user_id" BIGINT,
+    "order_date" TEXT,
+    "total_amount" DOUBLE PRECISION,
+    "status" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_deleted_at" ON "orders" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "order_items" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "order_id" BIGINT,
+    "product_id" BIGINT,
+    "quantity" BIGINT,
+    "subtotal" DOUBLE PRECISION
+);
+CREATE INDEX IF NOT EXISTS "idx_order_items_deleted_at" ON "order_items" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "order_id" BIGINT,
+    "payment_method" TEXT,
+    "amount" DOUBLE PRECISION,
+    "payment_date" TEXT,
+    "status" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_deleted_at" ON "payments" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "shipping_details" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "order_id" BIGINT,
+    "address" TEXT,
+    "shipping_date" TEXT,
+    "estimated_arrival" TEXT,
+    "status" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_shipping_details_deleted_at" ON "shipping_details" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "reviews" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "product_id" BIGINT,
+    "user_id" BIGINT,
+    "rating" BIGINT,
+    "comment" TEXT,
+    "review_date" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_reviews_deleted_at" ON "reviews" ("deleted_at");
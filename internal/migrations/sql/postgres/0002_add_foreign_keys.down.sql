@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS "idx_reviews_user_id";
+DROP INDEX IF EXISTS "idx_reviews_product_id";
+DROP INDEX IF EXISTS "idx_shipping_details_order_id";
+DROP INDEX IF EXISTS "idx_payments_order_id";
+DROP INDEX IF EXISTS "idx_order_items_product_id";
+DROP INDEX IF EXISTS "idx_order_items_order_id";
+DROP INDEX IF EXISTS "idx_orders_user_id";
+DROP INDEX IF EXISTS "idx_products_category_id";
+DROP INDEX IF EXISTS "idx_products_brand_id";
+ALTER TABLE "reviews" DROP CONSTRAINT IF EXISTS "fk_reviews_user";
+ALTER TABLE "reviews" DROP CONSTRAINT IF EXISTS "fk_reviews_product";
+ALTER TABLE "shipping_details" DROP CONSTRAINT IF EXISTS "fk_orders_shipping";
+ALTER TABLE "payments" DROP CONSTRAINT IF EXISTS "fk_orders_payments";
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS "fk_order_items_product";
+ALTER TABLE "order_items" DROP CONSTRAINT IF EXISTS "fk_orders_items";
+ALTER TABLE "orders" DROP CONSTRAINT IF EXISTS "fk_orders_user";
+ALTER TABLE "products" DROP CONSTRAINT IF EXISTS "fk_products_category";
+ALTER TABLE "products" DROP CONSTRAINT IF EXISTS "fk_products_brand";
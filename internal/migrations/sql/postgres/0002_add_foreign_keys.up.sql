@@ -0,0 +1,31 @@
+-- Adds foreign keys for the relations between tables, with the ON DELETE behaviour of the GORM associations.
+-- Rows whose parent no longer exists would make the constraints fail, so the rows the delete behaviour would have
+-- handled are cleaned up first: orphaned order items, payments, shipping details and reviews are deleted, and
+-- reviews of deleted users are kept with user_id set to NULL. Products and orders referencing missing brands,
+-- categories, users or products make the migration fail and must be fixed by hand.
+
+DELETE FROM "order_items" WHERE "order_id" IS NOT NULL AND "order_id" NOT IN (SELECT "id" FROM "orders");
+DELETE FROM "payments" WHERE "order_id" IS NOT NULL AND "order_id" NOT IN (SELECT "id" FROM "orders");
+DELETE FROM "shipping_details" WHERE "order_id" IS NOT NULL AND "order_id" NOT IN (SELECT "id" FROM "orders");
+DELETE FROM "reviews" WHERE "product_id" IS NOT NULL AND "product_id" NOT IN (SELECT "id" FROM "products");
+UPDATE "reviews" SET "user_id" = NULL WHERE "user_id" IS NOT NULL AND "user_id" NOT IN (SELECT "id" FROM "users");
+
+ALTER TABLE "products" ADD CONSTRAINT "fk_products_brand" FOREIGN KEY ("brand_id") REFERENCES "brands" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "products" ADD CONSTRAINT "fk_products_category" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "orders" ADD CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "order_items" ADD CONSTRAINT "fk_orders_items" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "order_items" ADD CONSTRAINT "fk_order_items_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "payments" ADD CONSTRAINT "fk_orders_payments" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "shipping_details" ADD CONSTRAINT "fk_orders_shipping" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "reviews" ADD CONSTRAINT "fk_reviews_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE;
+ALTER TABLE "reviews" ADD CONSTRAINT "fk_reviews_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX IF NOT EXISTS "idx_products_brand_id" ON "products" ("brand_id");
+CREATE INDEX IF NOT EXISTS "idx_products_category_id" ON "products" ("category_id");
+CREATE INDEX IF NOT EXISTS "idx_orders_user_id" ON "orders" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_order_items_order_id" ON "order_items" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_order_items_product_id" ON "order_items" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_payments_order_id" ON "payments" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_shipping_details_order_id" ON "shipping_details" ("order_id");
+CREATE INDEX IF NOT EXISTS "idx_reviews_product_id" ON "reviews" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_reviews_user_id" ON "reviews" ("user_id");
@@ -0,0 +1,15 @@
+-- Creates the audit log, holding one row per created, updated, deleted, restored or purged record
+-- with the user who made the change, the request it was made in and the changed columns as JSON.
+
+CREATE TABLE IF NOT EXISTS "audit_log" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "entity" VARCHAR(64),
+    "entity_id" BIGINT,
+    "action" VARCHAR(16),
+    "actor" VARCHAR(191),
+    "request_id" VARCHAR(128),
+    "changes" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_log_entity" ON "audit_log" ("entity", "entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_log_created_at" ON "audit_log" ("created_at");
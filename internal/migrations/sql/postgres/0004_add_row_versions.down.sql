@@ -0,0 +1,9 @@
+ALTER TABLE "reviews" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "shipping_details" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "order_items" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "products" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "brands" DROP COLUMN IF EXISTS "version";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "version";
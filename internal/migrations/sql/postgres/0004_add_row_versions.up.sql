@@ -0,0 +1,12 @@
+-- Adds the version column used for optimistic concurrency control: every update through the API
+-- increments it and is refused when the row no longer has the version the client read.
+
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "products" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "order_items" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "payments" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "shipping_details" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE "reviews" ADD COLUMN IF NOT EXISTS "version" BIGINT NOT NULL DEFAULT 1;
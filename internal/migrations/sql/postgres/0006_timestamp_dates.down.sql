@@ -0,0 +1,5 @@
+ALTER TABLE "reviews" ALTER COLUMN "review_date" TYPE TEXT USING to_char("review_date" AT TIME ZONE 'UTC', 'YYYY-MM-DD');
+ALTER TABLE "shipping_details" ALTER COLUMN "estimated_arrival" TYPE TEXT USING to_char("estimated_arrival" AT TIME ZONE 'UTC', 'YYYY-MM-DD');
+ALTER TABLE "shipping_details" ALTER COLUMN "shipping_date" TYPE TEXT USING to_char("shipping_date" AT TIME ZONE 'UTC', 'YYYY-MM-DD');
+ALTER TABLE "payments" ALTER COLUMN "payment_date" TYPE TEXT USING to_char("payment_date" AT TIME ZONE 'UTC', 'YYYY-MM-DD');
+ALTER TABLE "orders" ALTER COLUMN "order_date" TYPE TEXT USING to_char("order_date" AT TIME ZONE 'UTC', 'YYYY-MM-DD');
@@ -0,0 +1,12 @@
+-- Stores the order, payment, shipping and review dates as timestamps in UTC instead of text. A stored
+-- YYYY-MM-DD date becomes midnight UTC of that day; a value that is not a valid calendar date in that
+-- format, such as an empty one, becomes the creation time of its row.
+-- The conversion function is kept on one line, as statements are split at semicolons ending a line.
+
+CREATE FUNCTION pg_temp.legacy_date(value TEXT, fallback TIMESTAMPTZ) RETURNS TIMESTAMPTZ LANGUAGE plpgsql AS $$ BEGIN IF value ~ '^\d{4}-\d{2}-\d{2}$' THEN RETURN value::DATE::TIMESTAMP AT TIME ZONE 'UTC'; END IF; RETURN fallback; EXCEPTION WHEN datetime_field_overflow OR invalid_datetime_format THEN RETURN fallback; END $$;
+ALTER TABLE "orders" ALTER COLUMN "order_date" TYPE TIMESTAMPTZ USING pg_temp.legacy_date("order_date", "created_at");
+ALTER TABLE "payments" ALTER COLUMN "payment_date" TYPE TIMESTAMPTZ USING pg_temp.legacy_date("payment_date", "created_at");
+ALTER TABLE "shipping_details" ALTER COLUMN "shipping_date" TYPE TIMESTAMPTZ USING pg_temp.legacy_date("shipping_date", "created_at");
+ALTER TABLE "shipping_details" ALTER COLUMN "estimated_arrival" TYPE TIMESTAMPTZ USING pg_temp.legacy_date("estimated_arrival", "created_at");
+ALTER TABLE "reviews" ALTER COLUMN "review_date" TYPE TIMESTAMPTZ USING pg_temp.legacy_date("review_date", "created_at");
+DROP FUNCTION pg_temp.legacy_date(TEXT, TIMESTAMPTZ);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_categories_path";
+ALTER TABLE "categories" DROP CONSTRAINT IF EXISTS "fk_categories_parent";
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "path";
+ALTER TABLE "categories" DROP COLUMN IF EXISTS "parent_id";
@@ -0,0 +1,9 @@
+-- Lets categories nest: parent_id references the parent category, NULL for a root, and path lists the IDs from the
+-- root down to the category, e.g. /12/34/56/, so that a subtree is read with one indexed prefix search.
+-- Existing categories become roots. The C collation lets the index serve LIKE prefix searches.
+
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "parent_id" BIGINT;
+ALTER TABLE "categories" ADD COLUMN IF NOT EXISTS "path" VARCHAR(2048) COLLATE "C";
+UPDATE "categories" SET "path" = '/' || "id" || '/';
+ALTER TABLE "categories" ADD CONSTRAINT "fk_categories_parent" FOREIGN KEY ("parent_id") REFERENCES "categories" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+CREATE INDEX IF NOT EXISTS "idx_categories_path" ON "categories" ("path");
@@ -0,0 +1,22 @@
+-- Adds product variants, the versions of a product sold under their own SKU with their own option values, price
+-- override and stock, and lets order items select the variant ordered. Variants are deleted with their product and
+-- cannot be deleted while they are ordered. Options are stored as a JSON object.
+
+CREATE TABLE IF NOT EXISTS "product_variants" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "version" BIGINT NOT NULL DEFAULT 1,
+    "product_id" BIGINT,
+    "sku" VARCHAR(64),
+    "options" TEXT,
+    "price" DOUBLE PRECISION,
+    "stock_quantity" BIGINT,
+    CONSTRAINT "fk_products_variants" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_variants_sku" ON "product_variants" ("sku");
+CREATE INDEX IF NOT EXISTS "idx_product_variants_deleted_at" ON "product_variants" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_variants_product_id" ON "product_variants" ("product_id");
+ALTER TABLE "order_items" ADD COLUMN IF NOT EXISTS "variant_id" BIGINT;
+ALTER TABLE "order_items" ADD CONSTRAINT "fk_order_items_variant" FOREIGN KEY ("variant_id") REFERENCES "product_variants" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
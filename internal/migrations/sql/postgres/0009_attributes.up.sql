@@ -0,0 +1,34 @@
+-- Adds typed attributes, the specifications a category defines for the products of its subtree with a type and
+-- a unit, and the values of products, stored in the column of the type of their attribute so that products can be
+-- filtered and counted by value. Attributes are deleted with their category and values with their product or attribute.
+
+CREATE TABLE IF NOT EXISTS "attributes" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "version" BIGINT NOT NULL DEFAULT 1,
+    "category_id" BIGINT,
+    "name" VARCHAR(64),
+    "label" VARCHAR(255),
+    "type" VARCHAR(16),
+    "unit" VARCHAR(32),
+    CONSTRAINT "fk_categories_attributes" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_attributes_category_name" ON "attributes" ("category_id", "name");
+CREATE INDEX IF NOT EXISTS "idx_attributes_deleted_at" ON "attributes" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "product_attributes" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "product_id" BIGINT,
+    "attribute_id" BIGINT,
+    "number_value" DOUBLE PRECISION,
+    "text_value" VARCHAR(255),
+    "boolean_value" BOOLEAN,
+    CONSTRAINT "fk_products_attributes" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_product_attributes_attribute" FOREIGN KEY ("attribute_id") REFERENCES "attributes" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_attributes_product_attribute" ON "product_attributes" ("product_id", "attribute_id");
+CREATE INDEX IF NOT EXISTS "idx_product_attributes_number" ON "product_attributes" ("attribute_id", "number_value");
+CREATE INDEX IF NOT EXISTS "idx_product_attributes_text" ON "product_attributes" ("attribute_id", "text_value");
@@ -0,0 +1,30 @@
+-- Adds the galleries of products, ordered images with a single primary image deleted with their product, and the
+-- logos of brands. Files are kept in the media storage under the keys stored here, with their sniffed media type,
+-- size and dimensions; an empty logo_file means the brand has no logo.
+
+CREATE TABLE IF NOT EXISTS "product_images" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "created_at" TIMESTAMPTZ,
+    "updated_at" TIMESTAMPTZ,
+    "deleted_at" TIMESTAMPTZ,
+    "version" BIGINT NOT NULL DEFAULT 1,
+    "file" VARCHAR(255),
+    "thumbnail" VARCHAR(255),
+    "content_type" VARCHAR(64),
+    "size" BIGINT,
+    "width" BIGINT,
+    "height" BIGINT,
+    "product_id" BIGINT,
+    "alt" VARCHAR(255),
+    "position" BIGINT,
+    "is_primary" BOOLEAN,
+    CONSTRAINT "fk_products_images" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_product_images_deleted_at" ON "product_images" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_images_product_id" ON "product_images" ("product_id");
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "logo_file" VARCHAR(255);
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "logo_thumbnail" VARCHAR(255);
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "logo_content_type" VARCHAR(64);
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "logo_size" BIGINT;
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "logo_width" BIGINT;
+ALTER TABLE "brands" ADD COLUMN IF NOT EXISTS "logo_height" BIGINT;
@@ -0,0 +1,115 @@
+-- Creates the initial ElectroMart schema. Tables are only created when missing, so databases
+-- set up from the former SQL script can be brought under version control by running this migration.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "username" TEXT UNIQUE,
+    "password" TEXT,
+    "email" TEXT UNIQUE,
+    "first_name" TEXT,
+    "last_name" TEXT,
+    "address" TEXT,
+    "mobile" TEXT,
+    "role" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_users_deleted_at" ON "users" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "brands" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "name" TEXT,
+    "description" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_brands_deleted_at" ON "brands" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "name" TEXT,
+    "description" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_categories_deleted_at" ON "categories" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "name" TEXT,
+    "description" TEXT,
+    "price" REAL,
+    "stock_quantity" INTEGER,
+    "brand_id" INTEGER,
+    "category_id" INTEGER
+);
+CREATE INDEX IF NOT EXISTS "idx_products_deleted_at" ON "products" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "user_id" INTEGER,
+    "order_date" TEXT,
+    "total_amount" REAL,
+    "status" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_deleted_at" ON "orders" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "order_items" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "product_id" INTEGER,
+    "quantity" INTEGER,
+    "subtotal" REAL
+);
+CREATE INDEX IF NOT EXISTS "idx_order_items_deleted_at" ON "order_items" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "payments" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "payment_method" TEXT,
+    "amount" REAL,
+    "payment_date" TEXT,
+    "status" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_payments_deleted_at" ON "payments" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "shipping_details" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "address" TEXT,
+    "shipping_date" TEXT,
+    "estimated_arrival" TEXT,
+    "status" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_shipping_details_deleted_at" ON "shipping_details" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "reviews" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "product_id" INTEGER,
+    "user_id" INTEGER,
+    "rating" INTEGER,
+    "comment" TEXT,
+    "review_date" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_reviews_deleted_at" ON "reviews" ("deleted_at");
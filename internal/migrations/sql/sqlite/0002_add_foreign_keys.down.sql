@@ -0,0 +1,96 @@
+-- Rebuilds the referencing tables without foreign keys, children first.
+
+CREATE TABLE "reviews_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "product_id" INTEGER,
+    "user_id" INTEGER,
+    "rating" INTEGER,
+    "comment" TEXT,
+    "review_date" TEXT
+);
+INSERT INTO "reviews_new" ("id", "created_at", "updated_at", "deleted_at", "product_id", "user_id", "rating", "comment", "review_date") SELECT "id", "created_at", "updated_at", "deleted_at", "product_id", "user_id", "rating", "comment", "review_date" FROM "reviews";
+DROP TABLE "reviews";
+ALTER TABLE "reviews_new" RENAME TO "reviews";
+CREATE INDEX "idx_reviews_deleted_at" ON "reviews" ("deleted_at");
+
+CREATE TABLE "shipping_details_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "address" TEXT,
+    "shipping_date" TEXT,
+    "estimated_arrival" TEXT,
+    "status" TEXT
+);
+INSERT INTO "shipping_details_new" ("id", "created_at", "updated_at", "deleted_at", "order_id", "address", "shipping_date", "estimated_arrival", "status") SELECT "id", "created_at", "updated_at", "deleted_at", "order_id", "address", "shipping_date", "estimated_arrival", "status" FROM "shipping_details";
+DROP TABLE "shipping_details";
+ALTER TABLE "shipping_details_new" RENAME TO "shipping_details";
+CREATE INDEX "idx_shipping_details_deleted_at" ON "shipping_details" ("deleted_at");
+
+CREATE TABLE "payments_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "payment_method" TEXT,
+    "amount" REAL,
+    "payment_date" TEXT,
+    "status" TEXT
+);
+INSERT INTO "payments_new" ("id", "created_at", "updated_at", "deleted_at", "order_id", "payment_method", "amount", "payment_date", "status") SELECT "id", "created_at", "updated_at", "deleted_at", "order_id", "payment_method", "amount", "payment_date", "status" FROM "payments";
+DROP TABLE "payments";
+ALTER TABLE "payments_new" RENAME TO "payments";
+CREATE INDEX "idx_payments_deleted_at" ON "payments" ("deleted_at");
+
+CREATE TABLE "order_items_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "product_id" INTEGER,
+    "quantity" INTEGER,
+    "subtotal" REAL
+);
+INSERT INTO "order_items_new" ("id", "created_at", "updated_at", "deleted_at", "order_id", "product_id", "quantity", "subtotal") SELECT "id", "created_at", "updated_at", "deleted_at", "order_id", "product_id", "quantity", "subtotal" FROM "order_items";
+DROP TABLE "order_items";
+ALTER TABLE "order_items_new" RENAME TO "order_items";
+CREATE INDEX "idx_order_items_deleted_at" ON "order_items" ("deleted_at");
+
+CREATE TABLE "orders_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "user_id" INTEGER,
+    "order_date" TEXT,
+    "total_amount" REAL,
+    "status" TEXT
+);
+INSERT INTO "orders_new" ("id", "created_at", "updated_at", "deleted_at", "user_id", "order_date", "total_amount", "status") SELECT "id", "created_at", "updated_at", "deleted_at", "user_id", "order_date", "total_amount", "status" FROM "orders";
+DROP TABLE "orders";
+ALTER TABLE "orders_new" RENAME TO "orders";
+CREATE INDEX "idx_orders_deleted_at" ON "orders" ("deleted_at");
+
+CREATE TABLE "products_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "name" TEXT,
+    "description" TEXT,
+    "price" REAL,
+    "stock_quantity" INTEGER,
+    "brand_id" INTEGER,
+    "category_id" INTEGER
+);
+INSERT INTO "products_new" ("id", "created_at", "updated_at", "deleted_at", "name", "description", "price", "stock_quantity", "brand_id", "category_id") SELECT "id", "created_at", "updated_at", "deleted_at", "name", "description", "price", "stock_quantity", "brand_id", "category_id" FROM "products";
+DROP TABLE "products";
+ALTER TABLE "products_new" RENAME TO "products";
+CREATE INDEX "idx_products_deleted_at" ON "products" ("deleted_at");
@@ -0,0 +1,126 @@
+-- Adds foreign keys for the relations between tables, with the ON DELETE behaviour of the GORM associations.
+-- Rows whose parent no longer exists would make the constraints fail, so the rows the delete behaviour would have
+-- handled are cleaned up first: orphaned order items, payments, shipping details and reviews are deleted, and
+-- reviews of deleted users are kept with user_id set to NULL. Products and orders referencing missing brands,
+-- categories, users or products make the migration fail and must be fixed by hand.
+-- SQLite cannot add constraints to existing tables, so every referencing table is rebuilt, parents first, so that
+-- no table is dropped while another one references it.
+
+DELETE FROM "order_items" WHERE "order_id" IS NOT NULL AND "order_id" NOT IN (SELECT "id" FROM "orders");
+DELETE FROM "payments" WHERE "order_id" IS NOT NULL AND "order_id" NOT IN (SELECT "id" FROM "orders");
+DELETE FROM "shipping_details" WHERE "order_id" IS NOT NULL AND "order_id" NOT IN (SELECT "id" FROM "orders");
+DELETE FROM "reviews" WHERE "product_id" IS NOT NULL AND "product_id" NOT IN (SELECT "id" FROM "products");
+UPDATE "reviews" SET "user_id" = NULL WHERE "user_id" IS NOT NULL AND "user_id" NOT IN (SELECT "id" FROM "users");
+
+CREATE TABLE "products_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "name" TEXT,
+    "description" TEXT,
+    "price" REAL,
+    "stock_quantity" INTEGER,
+    "brand_id" INTEGER,
+    "category_id" INTEGER,
+    CONSTRAINT "fk_products_brand" FOREIGN KEY ("brand_id") REFERENCES "brands" ("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "fk_products_category" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+INSERT INTO "products_new" ("id", "created_at", "updated_at", "deleted_at", "name", "description", "price", "stock_quantity", "brand_id", "category_id") SELECT "id", "created_at", "updated_at", "deleted_at", "name", "description", "price", "stock_quantity", "brand_id", "category_id" FROM "products";
+DROP TABLE "products";
+ALTER TABLE "products_new" RENAME TO "products";
+CREATE INDEX "idx_products_deleted_at" ON "products" ("deleted_at");
+CREATE INDEX "idx_products_brand_id" ON "products" ("brand_id");
+CREATE INDEX "idx_products_category_id" ON "products" ("category_id");
+
+CREATE TABLE "orders_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "user_id" INTEGER,
+    "order_date" TEXT,
+    "total_amount" REAL,
+    "status" TEXT,
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+INSERT INTO "orders_new" ("id", "created_at", "updated_at", "deleted_at", "user_id", "order_date", "total_amount", "status") SELECT "id", "created_at", "updated_at", "deleted_at", "user_id", "order_date", "total_amount", "status" FROM "orders";
+DROP TABLE "orders";
+ALTER TABLE "orders_new" RENAME TO "orders";
+CREATE INDEX "idx_orders_deleted_at" ON "orders" ("deleted_at");
+CREATE INDEX "idx_orders_user_id" ON "orders" ("user_id");
+
+CREATE TABLE "order_items_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "product_id" INTEGER,
+    "quantity" INTEGER,
+    "subtotal" REAL,
+    CONSTRAINT "fk_orders_items" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_order_items_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+INSERT INTO "order_items_new" ("id", "created_at", "updated_at", "deleted_at", "order_id", "product_id", "quantity", "subtotal") SELECT "id", "created_at", "updated_at", "deleted_at", "order_id", "product_id", "quantity", "subtotal" FROM "order_items";
+DROP TABLE "order_items";
+ALTER TABLE "order_items_new" RENAME TO "order_items";
+CREATE INDEX "idx_order_items_deleted_at" ON "order_items" ("deleted_at");
+CREATE INDEX "idx_order_items_order_id" ON "order_items" ("order_id");
+CREATE INDEX "idx_order_items_product_id" ON "order_items" ("product_id");
+
+CREATE TABLE "payments_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "payment_method" TEXT,
+    "amount" REAL,
+    "payment_date" TEXT,
+    "status" TEXT,
+    CONSTRAINT "fk_orders_payments" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO "payments_new" ("id", "created_at", "updated_at", "deleted_at", "order_id", "payment_method", "amount", "payment_date", "status") SELECT "id", "created_at", "updated_at", "deleted_at", "order_id", "payment_method", "amount", "payment_date", "status" FROM "payments";
+DROP TABLE "payments";
+ALTER TABLE "payments_new" RENAME TO "payments";
+CREATE INDEX "idx_payments_deleted_at" ON "payments" ("deleted_at");
+CREATE INDEX "idx_payments_order_id" ON "payments" ("order_id");
+
+CREATE TABLE "shipping_details_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "order_id" INTEGER,
+    "address" TEXT,
+    "shipping_date" TEXT,
+    "estimated_arrival" TEXT,
+    "status" TEXT,
+    CONSTRAINT "fk_orders_shipping" FOREIGN KEY ("order_id") REFERENCES "orders" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO "shipping_details_new" ("id", "created_at", "updated_at", "deleted_at", "order_id", "address", "shipping_date", "estimated_arrival", "status") SELECT "id", "created_at", "updated_at", "deleted_at", "order_id", "address", "shipping_date", "estimated_arrival", "status" FROM "shipping_details";
+DROP TABLE "shipping_details";
+ALTER TABLE "shipping_details_new" RENAME TO "shipping_details";
+CREATE INDEX "idx_shipping_details_deleted_at" ON "shipping_details" ("deleted_at");
+CREATE INDEX "idx_shipping_details_order_id" ON "shipping_details" ("order_id");
+
+CREATE TABLE "reviews_new" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "product_id" INTEGER,
+    "user_id" INTEGER,
+    "rating" INTEGER,
+    "comment" TEXT,
+    "review_date" TEXT,
+    CONSTRAINT "fk_reviews_product" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_reviews_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+INSERT INTO "reviews_new" ("id", "created_at", "updated_at", "deleted_at", "product_id", "user_id", "rating", "comment", "review_date") SELECT "id", "created_at", "updated_at", "deleted_at", "product_id", "user_id", "rating", "comment", "review_date" FROM "reviews";
+DROP TABLE "reviews";
+ALTER TABLE "reviews_new" RENAME TO "reviews";
+CREATE INDEX "idx_reviews_deleted_at" ON "reviews" ("deleted_at");
+CREATE INDEX "idx_reviews_product_id" ON "reviews" ("product_id");
+CREATE INDEX "idx_reviews_user_id" ON "reviews" ("user_id");
@@ -0,0 +1,15 @@
+-- Creates the audit log, holding one row per created, updated, deleted, restored or purged record
+-- with the user who made the change, the request it was made in and the changed columns as JSON.
+
+CREATE TABLE IF NOT EXISTS "audit_log" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "entity" TEXT,
+    "entity_id" INTEGER,
+    "action" TEXT,
+    "actor" TEXT,
+    "request_id" TEXT,
+    "changes" TEXT
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_log_entity" ON "audit_log" ("entity", "entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_log_created_at" ON "audit_log" ("created_at");
@@ -0,0 +1,24 @@
+ALTER TABLE "reviews" ADD COLUMN "review_date_text" TEXT;
+UPDATE "reviews" SET "review_date_text" = date("review_date");
+ALTER TABLE "reviews" DROP COLUMN "review_date";
+ALTER TABLE "reviews" RENAME COLUMN "review_date_text" TO "review_date";
+
+ALTER TABLE "shipping_details" ADD COLUMN "estimated_arrival_text" TEXT;
+UPDATE "shipping_details" SET "estimated_arrival_text" = date("estimated_arrival");
+ALTER TABLE "shipping_details" DROP COLUMN "estimated_arrival";
+ALTER TABLE "shipping_details" RENAME COLUMN "estimated_arrival_text" TO "estimated_arrival";
+
+ALTER TABLE "shipping_details" ADD COLUMN "shipping_date_text" TEXT;
+UPDATE "shipping_details" SET "shipping_date_text" = date("shipping_date");
+ALTER TABLE "shipping_details" DROP COLUMN "shipping_date";
+ALTER TABLE "shipping_details" RENAME COLUMN "shipping_date_text" TO "shipping_date";
+
+ALTER TABLE "payments" ADD COLUMN "payment_date_text" TEXT;
+UPDATE "payments" SET "payment_date_text" = date("payment_date");
+ALTER TABLE "payments" DROP COLUMN "payment_date";
+ALTER TABLE "payments" RENAME COLUMN "payment_date_text" TO "payment_date";
+
+ALTER TABLE "orders" ADD COLUMN "order_date_text" TEXT;
+UPDATE "orders" SET "order_date_text" = date("order_date");
+ALTER TABLE "orders" DROP COLUMN "order_date";
+ALTER TABLE "orders" RENAME COLUMN "order_date_text" TO "order_date";
@@ -0,0 +1,29 @@
+-- Stores the order, payment, shipping and review dates as timestamps in UTC instead of text. A stored
+-- YYYY-MM-DD date becomes midnight UTC of that day; a value that is not a valid calendar date in that
+-- format, such as an empty one, becomes the creation time of its row.
+-- The '+0 days' modifier makes date() normalize an impossible day such as 2024-02-30, so it no longer matches.
+
+ALTER TABLE "orders" ADD COLUMN "order_date_utc" DATETIME;
+UPDATE "orders" SET "order_date_utc" = CASE WHEN date("order_date", '+0 days') IS "order_date" THEN "order_date" || ' 00:00:00+00:00' ELSE "created_at" END;
+ALTER TABLE "orders" DROP COLUMN "order_date";
+ALTER TABLE "orders" RENAME COLUMN "order_date_utc" TO "order_date";
+
+ALTER TABLE "payments" ADD COLUMN "payment_date_utc" DATETIME;
+UPDATE "payments" SET "payment_date_utc" = CASE WHEN date("payment_date", '+0 days') IS "payment_date" THEN "payment_date" || ' 00:00:00+00:00' ELSE "created_at" END;
+ALTER TABLE "payments" DROP COLUMN "payment_date";
+ALTER TABLE "payments" RENAME COLUMN "payment_date_utc" TO "payment_date";
+
+ALTER TABLE "shipping_details" ADD COLUMN "shipping_date_utc" DATETIME;
+UPDATE "shipping_details" SET "shipping_date_utc" = CASE WHEN date("shipping_date", '+0 days') IS "shipping_date" THEN "shipping_date" || ' 00:00:00+00:00' ELSE "created_at" END;
+ALTER TABLE "shipping_details" DROP COLUMN "shipping_date";
+ALTER TABLE "shipping_details" RENAME COLUMN "shipping_date_utc" TO "shipping_date";
+
+ALTER TABLE "shipping_details" ADD COLUMN "estimated_arrival_utc" DATETIME;
+UPDATE "shipping_details" SET "estimated_arrival_utc" = CASE WHEN date("estimated_arrival", '+0 days') IS "estimated_arrival" THEN "estimated_arrival" || ' 00:00:00+00:00' ELSE "created_at" END;
+ALTER TABLE "shipping_details" DROP COLUMN "estimated_arrival";
+ALTER TABLE "shipping_details" RENAME COLUMN "estimated_arrival_utc" TO "estimated_arrival";
+
+ALTER TABLE "reviews" ADD COLUMN "review_date_utc" DATETIME;
+UPDATE "reviews" SET "review_date_utc" = CASE WHEN date("review_date", '+0 days') IS "review_date" THEN "review_date" || ' 00:00:00+00:00' ELSE "created_at" END;
+ALTER TABLE "reviews" DROP COLUMN "review_date";
+ALTER TABLE "reviews" RENAME COLUMN "review_date_utc" TO "review_date";
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "idx_categories_path";
+ALTER TABLE "categories" DROP COLUMN "path";
+ALTER TABLE "categories" DROP COLUMN "parent_id";
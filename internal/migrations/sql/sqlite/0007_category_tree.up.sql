@@ -0,0 +1,9 @@
+-- Lets categories nest: parent_id references the parent category, NULL for a root, and path lists the IDs from the
+-- root down to the category, e.g. /12/34/56/, so that a subtree is read with one indexed prefix search.
+-- Existing categories become roots. SQLite only uses an index for LIKE on a column compared without case,
+-- which makes no difference for paths of digits and slashes.
+
+ALTER TABLE "categories" ADD COLUMN "parent_id" INTEGER CONSTRAINT "fk_categories_parent" REFERENCES "categories" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+ALTER TABLE "categories" ADD COLUMN "path" TEXT COLLATE NOCASE;
+UPDATE "categories" SET "path" = '/' || "id" || '/';
+CREATE INDEX "idx_categories_path" ON "categories" ("path");
@@ -0,0 +1,21 @@
+-- Adds product variants, the versions of a product sold under their own SKU with their own option values, price
+-- override and stock, and lets order items select the variant ordered. Variants are deleted with their product and
+-- cannot be deleted while they are ordered. Options are stored as a JSON object.
+
+CREATE TABLE IF NOT EXISTS "product_variants" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    "product_id" INTEGER,
+    "sku" TEXT,
+    "options" TEXT,
+    "price" REAL,
+    "stock_quantity" INTEGER,
+    CONSTRAINT "fk_products_variants" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_variants_sku" ON "product_variants" ("sku");
+CREATE INDEX IF NOT EXISTS "idx_product_variants_deleted_at" ON "product_variants" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_variants_product_id" ON "product_variants" ("product_id");
+ALTER TABLE "order_items" ADD COLUMN "variant_id" INTEGER CONSTRAINT "fk_order_items_variant" REFERENCES "product_variants" ("id") ON DELETE RESTRICT ON UPDATE CASCADE;
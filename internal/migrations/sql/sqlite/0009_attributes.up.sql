@@ -0,0 +1,34 @@
+-- Adds typed attributes, the specifications a category defines for the products of its subtree with a type and
+-- a unit, and the values of products, stored in the column of the type of their attribute so that products can be
+-- filtered and counted by value. Attributes are deleted with their category and values with their product or attribute.
+
+CREATE TABLE IF NOT EXISTS "attributes" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    "category_id" INTEGER,
+    "name" TEXT,
+    "label" TEXT,
+    "type" TEXT,
+    "unit" TEXT,
+    CONSTRAINT "fk_categories_attributes" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_attributes_category_name" ON "attributes" ("category_id", "name");
+CREATE INDEX IF NOT EXISTS "idx_attributes_deleted_at" ON "attributes" ("deleted_at");
+CREATE TABLE IF NOT EXISTS "product_attributes" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "product_id" INTEGER,
+    "attribute_id" INTEGER,
+    "number_value" REAL,
+    "text_value" TEXT,
+    "boolean_value" NUMERIC,
+    CONSTRAINT "fk_products_attributes" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_product_attributes_attribute" FOREIGN KEY ("attribute_id") REFERENCES "attributes" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_product_attributes_product_attribute" ON "product_attributes" ("product_id", "attribute_id");
+CREATE INDEX IF NOT EXISTS "idx_product_attributes_number" ON "product_attributes" ("attribute_id", "number_value");
+CREATE INDEX IF NOT EXISTS "idx_product_attributes_text" ON "product_attributes" ("attribute_id", "text_value");
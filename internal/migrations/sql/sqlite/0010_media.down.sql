@@ -0,0 +1,7 @@
+ALTER TABLE "brands" DROP COLUMN "logo_height";
+ALTER TABLE "brands" DROP COLUMN "logo_width";
+ALTER TABLE "brands" DROP COLUMN "logo_size";
+ALTER TABLE "brands" DROP COLUMN "logo_content_type";
+ALTER TABLE "brands" DROP COLUMN "logo_thumbnail";
+ALTER TABLE "brands" DROP COLUMN "logo_file";
+DROP TABLE IF EXISTS "product_images";
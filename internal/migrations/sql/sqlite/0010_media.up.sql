@@ -0,0 +1,30 @@
+-- Adds the galleries of products, ordered images with a single primary image deleted with their product, and the
+-- logos of brands. Files are kept in the media storage under the keys stored here, with their sniffed media type,
+-- size and dimensions; an empty logo_file means the brand has no logo.
+
+CREATE TABLE IF NOT EXISTS "product_images" (
+    "id" INTEGER PRIMARY KEY AUTOINCREMENT,
+    "created_at" DATETIME,
+    "updated_at" DATETIME,
+    "deleted_at" DATETIME,
+    "version" INTEGER NOT NULL DEFAULT 1,
+    "file" TEXT,
+    "thumbnail" TEXT,
+    "content_type" TEXT,
+    "size" INTEGER,
+    "width" INTEGER,
+    "height" INTEGER,
+    "product_id" INTEGER,
+    "alt" TEXT,
+    "position" INTEGER,
+    "is_primary" NUMERIC,
+    CONSTRAINT "fk_products_images" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_product_images_deleted_at" ON "product_images" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_product_images_product_id" ON "product_images" ("product_id");
+ALTER TABLE "brands" ADD COLUMN "logo_file" TEXT;
+ALTER TABLE "brands" ADD COLUMN "logo_thumbnail" TEXT;
+ALTER TABLE "brands" ADD COLUMN "logo_content_type" TEXT;
+ALTER TABLE "brands" ADD COLUMN "logo_size" INTEGER;
+ALTER TABLE "brands" ADD COLUMN "logo_width" INTEGER;
+ALTER TABLE "brands" ADD COLUMN "logo_height" INTEGER;
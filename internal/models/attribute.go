@@ -0,0 +1,294 @@
+package models
+
+import (
+	"E-Commerce_Website_Database/internal/validation"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"time"
+)
+
+// Types of attributes, which tell the column their values are stored in.
+const (
+	AttributeNumber  = "number"
+	AttributeText    = "text"
+	AttributeBoolean = "boolean"
+)
+
+// reservedAttributeNames are the query parameters of product listings, which attribute names cannot take
+// since they are filtered on by name, e.g. ?ram_gb[gte]=16.
+var reservedAttributeNames = []string{"include", "trashed"}
+
+// Attribute is a specification defined by a category for its products and the products of its descendants,
+// such as the RAM of laptops: Name identifies it in filters, e.g. "ram_gb", Label is shown to customers, e.g. "RAM",
+// Type tells whether its values are numbers, text or booleans, and Unit is the unit of numbers, e.g. "GB".
+// Attributes are deleted with their category, and the values of products are deleted with their attribute.
+type Attribute struct {
+	gorm.Model
+	Versioned
+	Category_ID uint   `gorm:"uniqueIndex:idx_attributes_category_name" json:"category_id"`
+	Name        string `gorm:"size:64;uniqueIndex:idx_attributes_category_name" json:"name"`
+	Label       string `gorm:"size:255" json:"label"`
+	Type        string `gorm:"size:16" json:"type"`
+	Unit        string `gorm:"size:32" json:"unit"`
+}
+
+// ProductAttribute is the value of an attribute for a product, stored in the column of the type of the attribute,
+// so that products can be filtered and counted by value. Value holds it as read from that column.
+// Attribute is loaded with the values of a product, e.g. with ?include=attributes.
+type ProductAttribute struct {
+	ID           uint        `gorm:"primaryKey" json:"id"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+	Product_ID   uint        `gorm:"uniqueIndex:idx_product_attributes_product_attribute" json:"product_id"`
+	Attribute_ID uint        `gorm:"uniqueIndex:idx_product_attributes_product_attribute;index:idx_product_attributes_number,priority:1;index:idx_product_attributes_text,priority:1" json:"attribute_id"`
+	Number       *float64    `gorm:"column:number_value;index:idx_product_attributes_number,priority:2" json:"-"`
+	Text         *string     `gorm:"column:text_value;size:255;index:idx_product_attributes_text,priority:2" json:"-"`
+	Boolean      *bool       `gorm:"column:boolean_value" json:"-"`
+	Value        interface{} `gorm:"-" json:"value"`
+	Attribute    *Attribute  `gorm:"foreignKey:Attribute_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"attribute,omitempty"`
+}
+
+// SetCategoryID sets the category defining the attribute, verifying with categoryExists that the category exists.
+// inUse tells whether products have values of the attribute, which keep it in its category.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (a *Attribute) SetCategoryID(category_id uint, categoryExists ExistsFunc, inUse bool) error {
+	if err := validation.Unchanged(inUse && category_id != a.Category_ID, "products have values of the attribute"); err != nil {
+		return err
+	}
+	if err := checkReference(category_id, categoryExists, "category"); err != nil {
+		return err
+	}
+	a.Category_ID = category_id
+	return nil
+}
+
+// SetName sets the name of the attribute, which must be usable as a query parameter. related are the attributes
+// of the category, its ancestors and its descendants, which products may have together: their names must differ.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (a *Attribute) SetName(name string, related []Attribute) error {
+	if err := validation.Identifier(name, 64, reservedAttributeNames); err != nil {
+		return err
+	}
+	duplicate := false
+	for _, attribute := range related {
+		duplicate = duplicate || attribute.ID != a.ID && attribute.Name == name
+	}
+	if err := validation.Distinct(duplicate, "attribute of the category, its ancestors and its descendants"); err != nil {
+		return err
+	}
+	a.Name = name
+	return nil
+}
+
+// SetLabel sets the label of the attribute shown to customers after validating its length.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (a *Attribute) SetLabel(label string) error {
+	if err := validation.String(label, 255); err != nil {
+		return err
+	}
+	a.Label = label
+	return nil
+}
+
+// SetType sets the type of the values of the attribute. namesakes are the attributes with the same name in other
+// categories, which must have the same type so that a filter on the name compares values of one type.
+// inUse tells whether products have values of the attribute, which keep their type.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (a *Attribute) SetType(attributeType string, namesakes []Attribute, inUse bool) error {
+	if err := validation.AttributeType(attributeType); err != nil {
+		return err
+	}
+	if err := validation.Unchanged(inUse && attributeType != a.Type, "products have values of the attribute"); err != nil {
+		return err
+	}
+	for _, namesake := range namesakes {
+		if namesake.ID == a.ID {
+			continue
+		}
+		if err := validation.SameAs(namesake.Type == attributeType, fmt.Sprintf("the type of the other %s attributes, %s", namesake.Name, namesake.Type)); err != nil {
+			return err
+		}
+	}
+	a.Type = attributeType
+	return nil
+}
+
+// SetUnit sets the unit of the values of the attribute, which may be empty, after validating its length.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (a *Attribute) SetUnit(unit string) error {
+	if err := validation.Optional(unit, 32); err != nil {
+		return err
+	}
+	a.Unit = unit
+	return nil
+}
+
+// AttributeSchema returns the attributes products of a category have: those of the categories of lineage, the IDs
+// from the root down to the category. When several of them have the same name, that of the nearest category wins.
+// The attributes are ordered from the root down, then by ID.
+func AttributeSchema(attributes []Attribute, lineage []uint) []Attribute {
+	depth := map[uint]int{}
+	for i, id := range lineage {
+		depth[id] = i + 1
+	}
+	byName := map[string]Attribute{}
+	for _, attribute := range attributes {
+		if depth[attribute.Category_ID] == 0 {
+			continue
+		}
+		if current, found := byName[attribute.Name]; !found || depth[attribute.Category_ID] > depth[current.Category_ID] {
+			byName[attribute.Name] = attribute
+		}
+	}
+	schema := make([]Attribute, 0, len(byName))
+	for _, attribute := range byName {
+		schema = append(schema, attribute)
+	}
+	sort.Slice(schema, func(i, j int) bool {
+		if depth[schema[i].Category_ID] != depth[schema[j].Category_ID] {
+			return depth[schema[i].Category_ID] < depth[schema[j].Category_ID]
+		}
+		return schema[i].ID < schema[j].ID
+	})
+	return schema
+}
+
+// AfterFind reads the value of the attribute from the column it is stored in.
+func (pa *ProductAttribute) AfterFind(tx *gorm.DB) error {
+	pa.Value = pa.value()
+	return nil
+}
+
+// SetValue sets the value of attribute for the product after validating it against the type of the attribute:
+// a number, a string of at most 255 characters or a boolean, as decoded from JSON.
+// It returns a *validation.FieldError, leaving the value unchanged, if the value is invalid.
+func (pa *ProductAttribute) SetValue(attribute *Attribute, value interface{}) error {
+	if err := validation.AttributeValue(value, attribute.Type, 255); err != nil {
+		return err
+	}
+	pa.Attribute_ID = attribute.ID
+	pa.Number, pa.Text, pa.Boolean = nil, nil, nil
+	switch value := value.(type) {
+	case float64:
+		pa.Number = &value
+	case string:
+		pa.Text = &value
+	case bool:
+		pa.Boolean = &value
+	}
+	pa.Value = value
+	return nil
+}
+
+// value returns the value stored in whichever column is set, or nil.
+func (pa *ProductAttribute) value() interface{} {
+	switch {
+	case pa.Number != nil:
+		return *pa.Number
+	case pa.Text != nil:
+		return *pa.Text
+	case pa.Boolean != nil:
+		return *pa.Boolean
+	}
+	return nil
+}
+
+// GetAttributes returns the attributes of the categories with the given IDs, or every attribute when categoryIDs
+// is nil, ordered by ID.
+func GetAttributes(db *gorm.DB, categoryIDs []uint) ([]Attribute, error) {
+	attributes := []Attribute{}
+	query := db.Model(&Attribute{})
+	if categoryIDs != nil {
+		if len(categoryIDs) == 0 {
+			return attributes, nil
+		}
+		query = query.Where("attributes.category_id IN ?", categoryIDs)
+	}
+	if err := query.Order("attributes.id").Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+// GetProductAttributes returns the values of the product with the given ID with their attribute, leaving out
+// the values of attributes in the trash, ordered by attribute ID.
+func GetProductAttributes(db *gorm.DB, productID uint) ([]ProductAttribute, error) {
+	values := []ProductAttribute{}
+	err := db.Model(&ProductAttribute{}).InnerJoins("Attribute").Where("product_attributes.product_id = ?", productID).
+		Order("product_attributes.attribute_id").Find(&values).Error
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ReplaceProductAttributes replaces the values of the product with the given ID by values, updating the values that
+// changed, creating the new ones and deleting those left out, so that only actual changes reach the audit log.
+// values hold one value per attribute; their IDs are set for the created values.
+func ReplaceProductAttributes(tx *gorm.DB, productID uint, values []ProductAttribute) error {
+	var current []ProductAttribute
+	if err := tx.Where("product_id = ?", productID).Find(&current).Error; err != nil {
+		return err
+	}
+	byAttribute := map[uint]ProductAttribute{}
+	for _, value := range current {
+		byAttribute[value.Attribute_ID] = value
+	}
+	for i := range values {
+		value := &values[i]
+		value.Product_ID = productID
+		stored, found := byAttribute[value.Attribute_ID]
+		delete(byAttribute, value.Attribute_ID)
+		if !found {
+			if err := tx.Omit("Attribute").Create(value).Error; err != nil {
+				return err
+			}
+			continue
+		}
+		value.ID, value.CreatedAt, value.UpdatedAt = stored.ID, stored.CreatedAt, stored.UpdatedAt
+		if stored.value() == value.value() {
+			continue
+		}
+		if err := tx.Model(value).Select("number_value", "text_value", "boolean_value", "updated_at").
+			Updates(map[string]interface{}{"number_value": value.Number, "text_value": value.Text, "boolean_value": value.Boolean}).Error; err != nil {
+			return err
+		}
+	}
+	for _, value := range byAttribute {
+		if err := tx.Delete(&ProductAttribute{}, value.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchAttribute performs a search for attributes based on the provided search parameters:
+// name and label match a substring, category_id and type are compared exactly.
+// It returns the matching attributes, an empty slice if none match.
+func SearchAttribute(db *gorm.DB, searchParams map[string]interface{}) ([]Attribute, error) {
+	var attributes []Attribute
+	query := db.Model(&Attribute{})
+
+	for key, value := range searchParams {
+		switch key {
+		case "name", "label":
+			if strVal, ok := value.(string); ok {
+				query = query.Where("attributes."+key+" LIKE ?", "%"+strVal+"%")
+			}
+		case "type":
+			if strVal, ok := value.(string); ok {
+				query = query.Where("attributes.type = ?", strVal)
+			}
+		case "category_id":
+			if numVal, ok := value.(int); ok {
+				query = query.Where("attributes.category_id = ?", numVal)
+			}
+		}
+	}
+
+	if err := query.Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"E-Commerce_Website_Database/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// TestAttribute_Setters checks that valid values are set and that invalid ones are refused,
+// leaving the attribute unchanged.
+func TestAttribute_Setters(t *testing.T) {
+	a := Attribute{Model: gorm.Model{ID: 1}}
+	categoryExists := func(id uint) (bool, error) { return id == 1 || id == 2, nil }
+
+	assert.NoError(t, a.SetCategoryID(1, categoryExists, false))
+	assert.Equal(t, uint(1), a.Category_ID)
+	assert.Error(t, a.SetCategoryID(3, categoryExists, false))
+	assert.Error(t, a.SetCategoryID(2, categoryExists, true), "an attribute in use should stay in its category")
+	assert.Equal(t, uint(1), a.Category_ID)
+
+	assert.NoError(t, a.SetName("ram_gb", []Attribute{{Model: gorm.Model{ID: 1}, Name: "ram_gb"}}))
+	assert.Equal(t, "ram_gb", a.Name)
+	assert.Error(t, a.SetName("RAM", nil))
+	assert.Error(t, a.SetName("include", nil))
+	assert.Error(t, a.SetName("wifi", []Attribute{{Model: gorm.Model{ID: 2}, Name: "wifi"}}))
+	assert.Equal(t, "ram_gb", a.Name)
+
+	assert.NoError(t, a.SetLabel("RAM"))
+	assert.Equal(t, "RAM", a.Label)
+	assert.Error(t, a.SetLabel(""))
+
+	assert.NoError(t, a.SetUnit("GB"))
+	assert.NoError(t, a.SetUnit(""))
+	assert.Equal(t, "", a.Unit)
+}
+
+// TestAttribute_SetType checks that an attribute has the type of the attributes sharing its name, and keeps its type
+// while products have values of it.
+func TestAttribute_SetType(t *testing.T) {
+	namesakes := []Attribute{{Model: gorm.Model{ID: 2}, Name: "ram_gb", Type: AttributeNumber}}
+
+	a := Attribute{Model: gorm.Model{ID: 1}}
+	assert.NoError(t, a.SetType(AttributeNumber, namesakes, false))
+	assert.Equal(t, AttributeNumber, a.Type)
+
+	for name, test := range map[string]struct {
+		attributeType string
+		namesakes     []Attribute
+		inUse         bool
+		code          string
+	}{
+		"unknown":        {attributeType: "date", code: validation.CodeNotAllowed},
+		"other namesake": {attributeType: AttributeText, namesakes: namesakes, code: validation.CodeInvalid},
+		"in use":         {attributeType: AttributeText, inUse: true, code: validation.CodeNotAllowed},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := a.SetType(test.attributeType, test.namesakes, test.inUse)
+			if fieldErr, ok := err.(*validation.FieldError); assert.True(t, ok) {
+				assert.Equal(t, test.code, fieldErr.Code)
+			}
+			assert.Equal(t, AttributeNumber, a.Type)
+		})
+	}
+}
+
+// TestAttributeSchema checks that a category has the attributes of its lineage, the nearest one winning
+// when several share a name.
+func TestAttributeSchema(t *testing.T) {
+	attributes := []Attribute{
+		{Model: gorm.Model{ID: 1}, Category_ID: 12, Name: "weight_kg"},
+		{Model: gorm.Model{ID: 2}, Category_ID: 34, Name: "ram_gb"},
+		{Model: gorm.Model{ID: 3}, Category_ID: 34, Name: "weight_kg"},
+		{Model: gorm.Model{ID: 4}, Category_ID: 56, Name: "screen_in"},
+	}
+	schema := AttributeSchema(attributes, []uint{12, 34})
+	ids := []uint{}
+	for _, attribute := range schema {
+		ids = append(ids, attribute.ID)
+	}
+	assert.Equal(t, []uint{2, 3}, ids)
+	assert.Len(t, AttributeSchema(attributes, []uint{12}), 1)
+}
+
+// TestProductAttribute_SetValue checks that a value is stored in the column of the type of its attribute.
+func TestProductAttribute_SetValue(t *testing.T) {
+	ram := &Attribute{Model: gorm.Model{ID: 1}, Type: AttributeNumber}
+	value := ProductAttribute{}
+	assert.NoError(t, value.SetValue(ram, 16.0))
+	assert.Equal(t, uint(1), value.Attribute_ID)
+	if assert.NotNil(t, value.Number) {
+		assert.Equal(t, 16.0, *value.Number)
+	}
+	assert.Nil(t, value.Text)
+	assert.Equal(t, 16.0, value.Value)
+
+	assert.Error(t, value.SetValue(ram, "16"))
+	assert.Error(t, value.SetValue(&Attribute{Type: AttributeBoolean}, 1.0))
+	assert.Equal(t, 16.0, value.Value)
+}
+
+// TestNewAttributeFilter checks that filters are parsed according to the type of their attribute.
+func TestNewAttributeFilter(t *testing.T) {
+	ram := Attribute{Name: "ram_gb", Type: AttributeNumber}
+	filter, err := NewAttributeFilter(ram, "gte", []string{" 16 "})
+	assert.NoError(t, err)
+	assert.Equal(t, AttributeFilter{Name: "ram_gb", Operator: "gte", Values: []interface{}{16.0}}, filter)
+	filter, err = NewAttributeFilter(Attribute{Name: "wifi", Type: AttributeBoolean}, "eq", []string{"true"})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{true}, filter.Values)
+
+	for name, test := range map[string]struct {
+		attribute Attribute
+		operator  string
+		values    []string
+	}{
+		"not a number":   {attribute: ram, operator: "eq", values: []string{"lots"}},
+		"NaN":            {attribute: ram, operator: "eq", values: []string{"NaN"}},
+		"infinity":       {attribute: ram, operator: "gte", values: []string{"-Inf"}},
+		"overflow":       {attribute: ram, operator: "lte", values: []string{"1e400"}},
+		"text range":     {attribute: Attribute{Type: AttributeText}, operator: "gt", values: []string{"a"}},
+		"repeated range": {attribute: ram, operator: "lt", values: []string{"8", "16"}},
+		"unknown":        {attribute: ram, operator: "like", values: []string{"8"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAttributeFilter(test.attribute, test.operator, test.values)
+			_, ok := err.(*validation.FieldError)
+			assert.True(t, ok)
+		})
+	}
+}
+
+// TestParseFilterKey checks that query parameters are split into an attribute name and an operator.
+func TestParseFilterKey(t *testing.T) {
+	for key, expected := range map[string]struct {
+		name, operator string
+		ok             bool
+	}{
+		"ram_gb":      {name: "ram_gb", operator: "eq", ok: true},
+		"ram_gb[gte]": {name: "ram_gb", operator: "gte", ok: true},
+		"ram_gb[gte":  {ok: false},
+		"Page":        {name: "Page", operator: "eq", ok: false},
+	} {
+		name, operator, ok := ParseFilterKey(key)
+		assert.Equal(t, expected.ok, ok, key)
+		if expected.ok {
+			assert.Equal(t, expected.name, name, key)
+			assert.Equal(t, expected.operator, operator, key)
+		}
+	}
+}
+
+// TestAttributeFilter_Matches checks the comparison of values by each operator.
+func TestAttributeFilter_Matches(t *testing.T) {
+	in := AttributeFilter{Operator: "eq", Values: []interface{}{"black", "white"}}
+	assert.True(t, in.Matches("white"))
+	assert.False(t, in.Matches("red"))
+	notIn := AttributeFilter{Operator: "ne", Values: []interface{}{"black"}}
+	assert.True(t, notIn.Matches("red"))
+	assert.False(t, notIn.Matches("black"))
+	atLeast := AttributeFilter{Operator: "gte", Values: []interface{}{16.0}}
+	assert.True(t, atLeast.Matches(16.0))
+	assert.False(t, atLeast.Matches(8.0))
+	assert.False(t, atLeast.Matches("16"))
+	below := AttributeFilter{Operator: "lt", Values: []interface{}{16.0}}
+	assert.True(t, below.Matches(8.0))
+}
+
+// TestBuildFacets checks that counts are grouped by attribute, values in ascending order, leaving out attributes
+// without values.
+func TestBuildFacets(t *testing.T) {
+	attributes := []Attribute{
+		{Name: "ram_gb", Label: "RAM", Type: AttributeNumber, Unit: "GB"},
+		{Name: "wifi", Label: "Wi-Fi", Type: AttributeBoolean},
+		{Name: "color", Label: "Color", Type: AttributeText},
+	}
+	facets := BuildFacets(attributes, []FacetCount{
+		{Name: "wifi", Value: true, Count: 2},
+		{Name: "ram_gb", Value: 32.0, Count: 1},
+		{Name: "wifi", Value: false, Count: 1},
+		{Name: "ram_gb", Value: 16.0, Count: 3},
+	})
+	assert.Equal(t, []Facet{
+		{Name: "ram_gb", Label: "RAM", Type: AttributeNumber, Unit: "GB", Values: []FacetValue{{16.0, 3}, {32.0, 1}}},
+		{Name: "wifi", Label: "Wi-Fi", Type: AttributeBoolean, Values: []FacetValue{{false, 1}, {true, 2}}},
+	}, facets)
+}
@@ -1,16 +1,30 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
 )
 
 // Brands represents the brand model that holds details about a brand.
-// It includes the default gorm.Model fields along with Name and Description for the brand.
+// It includes the default gorm.Model fields along with Name and Description for the brand,
+// and Logo, the uploaded logo of the brand, nil until one is uploaded.
 type Brands struct {
 	gorm.Model
+	Versioned
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	Logo        *Image `gorm:"embedded;embeddedPrefix:logo_" json:"logo,omitempty"`
+}
+
+// AfterFind derives the URLs of the files of the logo from their keys. A removed logo, whose columns are empty,
+// is read as no logo.
+func (b *Brands) AfterFind(tx *gorm.DB) error {
+	if b.Logo == nil || b.Logo.File == "" {
+		b.Logo = nil
+		return nil
+	}
+	b.Logo.SetFiles(b.Logo.File, b.Logo.Thumbnail)
+	return nil
 }
 
 // GetAllBrands retrieves all brands from the database.
@@ -25,33 +39,31 @@ func GetAllBrands(db *gorm.DB) ([]Brands, error) {
 }
 
 // SetName sets the name of the brand with validation.
-// It ensures the name does not exceed 255 characters. Returns true if set successfully, otherwise false.
-// It returns true if the name is within the allowed length, otherwise false.
-func (b *Brands) SetName(name string) bool {
-	if !tools.CheckString(name, 255) {
-		return false
-	} else {
-		b.Name = name
-		return true
+// It ensures the name does not exceed 255 characters.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (b *Brands) SetName(name string) error {
+	if err := validation.String(name, 255); err != nil {
+		return err
 	}
+	b.Name = name
+	return nil
 }
 
 // SetDescription sets the description of the brand with validation.
-// It ensures the description does not exceed 1000 characters. Returns true if set successfully, otherwise false.
-// It returns true if the description is within the allowed length, otherwise false.
-func (b *Brands) SetDescription(description string) bool {
-	if !tools.CheckString(description, 1000) {
-		return false
-	} else {
-		b.Description = description
-		return true
+// It ensures the description does not exceed 1000 characters.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (b *Brands) SetDescription(description string) error {
+	if err := validation.String(description, 1000); err != nil {
+		return err
 	}
+	b.Description = description
+	return nil
 }
 
 // BrandExists checks if a brand exists in the database by its ID.
 // It queries the database for the brand by the given ID and returns true if found, otherwise false.
 // It returns true if the brand exists, otherwise false.
-func BrandExists(db *gorm.DB, id uint32) bool {
+func BrandExists(db *gorm.DB, id uint) bool {
 	var brand Brands
 	if err := db.Where("id = ?", id).First(&brand).Error; err != nil {
 		return false
@@ -60,11 +72,9 @@ func BrandExists(db *gorm.DB, id uint32) bool {
 }
 
 // SearchBrand performs a search on brands based on provided query parameters.
-// It constructs a search query dynamically and returns the matching brand or an appropriate error message.
-// If no brand is found, it responds with an HTTP 404 Not Found status.
-// If the search is successful, it responds with an HTTP 200 OK status and the brand details in JSON format.
-func SearchBrand(db *gorm.DB, searchParams map[string]interface{}) (Brands, error) {
-	var brands Brands
+// It constructs a search query dynamically and returns the matching brands, an empty slice if none match.
+func SearchBrand(db *gorm.DB, searchParams map[string]interface{}) ([]Brands, error) {
+	var brands []Brands
 	query := db.Model(&Brands{})
 
 	for key, value := range searchParams {
@@ -77,8 +87,8 @@ func SearchBrand(db *gorm.DB, searchParams map[string]interface{}) (Brands, erro
 		}
 	}
 
-	if err := query.First(&brands).Debug().Error; err != nil {
-		return brands, err
+	if err := query.Find(&brands).Error; err != nil {
+		return nil, err
 	}
 	return brands, nil
 }
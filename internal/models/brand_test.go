@@ -40,26 +40,26 @@ func TestGetAllBrands(t *testing.T) {
 
 // TestBrands_SetName check if this function sets the name of brand correctly
 // It creates a new instance of the Brands struct and calls the SetName function with a valid name.
-// It then checks if the name was set correctly and if the function returned true.
-// It repeats the process with an invalid name and checks if the name was not set and the function returned false.
+// It then checks if the name was set correctly and if the function returned no error.
+// It repeats the process with an invalid name and checks if the name was not set and the function returned an error.
 func TestBrands_SetName(t *testing.T) {
 	b := Brands{}
-	assert.True(t, b.SetName("Valid Brand Name"))
+	assert.NoError(t, b.SetName("Valid Brand Name"))
 	assert.Equal(t, "Valid Brand Name", b.Name)
 	// Test wth invalid name
-	assert.False(t, b.SetName(""))
+	assert.Error(t, b.SetName(""))
 }
 
 // TestBrands_SetDescription check if this function sets the description of brand correctly
 // It creates a new instance of the Brands struct and calls the SetDescription function with a valid description.
-// It then checks if the description was set correctly and if the function returned true.
-// It repeats the process with an invalid description and checks if the description was not set and the function returned false.
+// It then checks if the description was set correctly and if the function returned no error.
+// It repeats the process with an invalid description and checks if the description was not set and the function returned an error.
 func TestBrands_SetDescription(t *testing.T) {
 	b := Brands{}
-	assert.True(t, b.SetDescription("Valid Brand Description"))
+	assert.NoError(t, b.SetDescription("Valid Brand Description"))
 	assert.Equal(t, "Valid Brand Description", b.Description)
 	// Test wth invalid description
-	assert.False(t, b.SetDescription(""))
+	assert.Error(t, b.SetDescription(""))
 }
 
 // TestBrandExists checks at this function ensure at a specific brand exists.
@@ -108,13 +108,15 @@ func TestSearchBrand(t *testing.T) {
 	// Setup expectations
 	rows := sqlmock.NewRows([]string{"id", "name", "description"}).
 		AddRow(1, "Search Brand", "Matches Criteria")
-	mock.ExpectQuery("^SELECT \\* FROM \"brands\" WHERE").WithArgs("Search Brand", 1).WillReturnRows(rows)
+	mock.ExpectQuery("^SELECT \\* FROM \"brands\" WHERE").WithArgs("Search Brand").WillReturnRows(rows)
 
 	// Call the function now
 	brands, err := SearchBrand(gormDB, map[string]interface{}{"name": "Search Brand"})
 	assert.NoError(t, err)
 	assert.NotNil(t, brands)
-	assert.Equal(t, "Search Brand", brands.Name)
+	if assert.Len(t, brands, 1) {
+		assert.Equal(t, "Search Brand", brands[0].Name)
+	}
 
 	// Check all expectations
 	assert.NoError(t, mock.ExpectationsWereMet())
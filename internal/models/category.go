@@ -1,16 +1,43 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// MaxCategoryDepth is how many levels deep categories can be nested, so that their paths fit the indexed path column.
+const MaxCategoryDepth = 100
+
+// ErrParentMoved is returned by CheckCategoryMove when the parent a category is moved below was itself moved or
+// deleted after the path of the category was derived from it, or is now below the category.
+var ErrParentMoved = errors.New("the parent category was moved or deleted meanwhile")
+
 // Category represents the category model for products.
 // It extends gorm.Model, adding Name and Description fields with JSON tags to aid in serialization.
+// Categories form a tree: Parent_ID is null for a root category, and Path lists the IDs from the root down to
+// the category itself, e.g. "/12/34/56/", so that a subtree is every category whose path starts with its root's path.
+// Path is derived from the parent by SetParent; a category cannot be deleted while it has children.
+// Attributes are those the category defines for its products, deleted with it.
 type Category struct {
 	gorm.Model
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Versioned
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parent_ID   *uint       `json:"parent_id"`
+	Path        string      `gorm:"size:2048;index" json:"path"`
+	Parent      *Category   `gorm:"foreignKey:Parent_ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+	Attributes  []Attribute `gorm:"foreignKey:Category_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// CategoryTree is a category with its subtree, as answered by the tree endpoints.
+type CategoryTree struct {
+	Category
+	Children []CategoryTree `json:"children"`
 }
 
 // GetAllCategories retrieves all categories from the database.
@@ -24,30 +51,89 @@ func GetAllCategories(db *gorm.DB) ([]Category, error) {
 }
 
 // SetName attempts to set the category's name while enforcing a maximum length of 255 characters.
-// It returns true if the name is valid and set successfully, otherwise returns false.
-func (c *Category) SetName(name string) bool {
-	if !tools.CheckString(name, 255) {
-		return false
-	} else {
-		c.Name = name
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (c *Category) SetName(name string) error {
+	if err := validation.String(name, 255); err != nil {
+		return err
 	}
+	c.Name = name
+	return nil
 }
 
 // SetDescription attempts to set the category's description while enforcing a maximum length of 1000 characters.
-// It returns true if the description is valid and set successfully, otherwise returns false.
-func (c *Category) SetDescription(description string) bool {
-	if !tools.CheckString(description, 1000) {
-		return false
-	} else {
-		c.Description = description
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (c *Category) SetDescription(description string) error {
+	if err := validation.String(description, 1000); err != nil {
+		return err
 	}
+	c.Description = description
+	return nil
+}
+
+// SetParent places the category below the category with the given ID, found with parentOf, or at the root when
+// parent_id is nil, and derives its path. height is how many levels of descendants the category has, which move with it.
+// parentOf returns nil without error when the parent does not exist.
+// It returns a *validation.FieldError, leaving the category unchanged, if the parent does not exist, is the category
+// itself or one of its descendants, or if the subtree would be nested deeper than MaxCategoryDepth, and a
+// *validation.Failure if the parent could not be looked up.
+func (c *Category) SetParent(parent_id *uint, parentOf func(id uint) (*Category, error), height int) error {
+	var parent *Category
+	if parent_id != nil {
+		var err error
+		if parent, err = parentOf(*parent_id); err != nil {
+			return validation.Failed(err)
+		}
+		if err := validation.Exists(parent != nil, "category"); err != nil {
+			return err
+		}
+		if err := validation.NotDescendant(c.Path != "" && strings.HasPrefix(parent.Path, c.Path), "category"); err != nil {
+			return err
+		}
+	}
+	path := CategoryPath(parent, c.ID)
+	if err := validation.Depth(pathDepth(path)+height, MaxCategoryDepth); err != nil {
+		return err
+	}
+	c.Parent_ID = parent_id
+	c.Path = path
+	return nil
+}
+
+// Depth returns the level of the category in its tree, 1 for a root category.
+func (c *Category) Depth() int {
+	return pathDepth(c.Path)
+}
+
+// AncestorIDs returns the IDs of the ancestors of the category listed by its path, from the root down to its parent.
+func (c *Category) AncestorIDs() []uint {
+	segments := strings.Split(strings.Trim(c.Path, "/"), "/")
+	var ids []uint
+	for _, segment := range segments[:len(segments)-1] {
+		id, err := strconv.ParseUint(segment, 10, 64)
+		if err == nil {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// CategoryPath returns the path of the category with the given ID below parent, or at the root when parent is nil.
+func CategoryPath(parent *Category, id uint) string {
+	path := "/"
+	if parent != nil {
+		path = parent.Path
+	}
+	return path + strconv.FormatUint(uint64(id), 10) + "/"
+}
+
+// pathDepth returns the number of IDs in a category path.
+func pathDepth(path string) int {
+	return strings.Count(path, "/") - 1
 }
 
 // CategoryExists checks the existence of a category by its ID in the database.
 // It returns true if the category is found, otherwise false if the category does not exist or there is an error.
-func CategoryExists(db *gorm.DB, id uint32) bool {
+func CategoryExists(db *gorm.DB, id uint) bool {
 	var category Category
 	if err := db.Where("id = ?", id).First(&category).Error; err != nil {
 		return false
@@ -56,9 +142,9 @@ func CategoryExists(db *gorm.DB, id uint32) bool {
 }
 
 // SearchCategory performs a search for a category based on the provided search parameters.
-// It constructs a search query dynamically and returns the matching category or an error if not found.
-func SearchCategory(db *gorm.DB, searchParams map[string]interface{}) (Category, error) {
-	var category Category
+// It constructs a search query dynamically and returns the matching categories, an empty slice if none match.
+func SearchCategory(db *gorm.DB, searchParams map[string]interface{}) ([]Category, error) {
+	var categories []Category
 	query := db.Model(&Category{})
 
 	for key, value := range searchParams {
@@ -71,8 +157,128 @@ func SearchCategory(db *gorm.DB, searchParams map[string]interface{}) (Category,
 		}
 	}
 
-	if err := query.First(&category).Debug().Error; err != nil {
-		return category, err
+	if err := query.Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// CategoryDescendants retrieves the descendants of the category with the given path, parents before their children.
+// The path prefix is matched with LIKE on the indexed path column, so the whole subtree is read in one query.
+func CategoryDescendants(db *gorm.DB, path string) ([]Category, error) {
+	categories := []Category{}
+	if err := db.Where("categories.path LIKE ? AND categories.path <> ?", path+"%", path).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	SortCategories(categories)
+	return categories, nil
+}
+
+// CategoryAncestors retrieves the ancestors of category, from the root down to its parent.
+func CategoryAncestors(db *gorm.DB, category *Category) ([]Category, error) {
+	categories := []Category{}
+	ids := category.AncestorIDs()
+	if len(ids) == 0 {
+		return categories, nil
+	}
+	if err := db.Where("categories.id IN ?", ids).Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	SortCategories(categories)
+	return categories, nil
+}
+
+// CheckCategoryMove checks that category, stored with storedPath, can still be saved with its path, derived by
+// SetParent from parent, the current row of its parent or nil for a root category or a missing parent. The parent
+// must still exist with the path the one of category was derived from, and must not be category or below it.
+// It returns ErrParentMoved otherwise, since a concurrent move invalidated the checks of SetParent.
+func CheckCategoryMove(category *Category, storedPath string, parent *Category) error {
+	if category.Parent_ID == nil {
+		return nil
+	}
+	if parent == nil || parent.DeletedAt.Valid || CategoryPath(parent, category.ID) != category.Path ||
+		strings.HasPrefix(parent.Path, storedPath) {
+		return ErrParentMoved
+	}
+	return nil
+}
+
+// LockCategoryMove locks the row of category and that of its parent for the rest of tx, in the order of their IDs so
+// that concurrent moves cannot deadlock. When the stored path of category differs from its path, the move is checked
+// by CheckCategoryMove against the locked parent, so that two categories moved below each other at the same time
+// cannot form a cycle, and the stored path is returned; otherwise, or when category does not exist, it returns "".
+func LockCategoryMove(tx *gorm.DB, category *Category) (string, error) {
+	ids := []uint{category.ID}
+	if category.Parent_ID != nil {
+		ids = append(ids, *category.Parent_ID)
+	}
+	var rows []Category
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "path", "deleted_at").
+		Where("id IN ?", ids).Order("id").Find(&rows).Error; err != nil {
+		return "", err
+	}
+	var stored, parent *Category
+	for i := range rows {
+		if rows[i].ID == category.ID {
+			stored = &rows[i]
+		}
+		if category.Parent_ID != nil && rows[i].ID == *category.Parent_ID {
+			parent = &rows[i]
+		}
+	}
+	if stored == nil || stored.Path == category.Path {
+		return "", nil
+	}
+	if err := CheckCategoryMove(category, stored.Path, parent); err != nil {
+		return "", err
+	}
+	return stored.Path, nil
+}
+
+// MoveCategorySubtree rewrites the paths of the descendants of the category whose path changed from oldPath to
+// newPath, in one statement whatever the size of the subtree. Descendants in the trash move as well.
+func MoveCategorySubtree(db *gorm.DB, oldPath, newPath string) error {
+	concat := "? || SUBSTR(path, ?)"
+	if db.Dialector.Name() == "mysql" {
+		concat = "CONCAT(?, SUBSTR(path, ?))"
+	}
+	return db.Unscoped().Model(&Category{}).Where("path LIKE ? AND path <> ?", oldPath+"%", oldPath).
+		UpdateColumn("path", gorm.Expr(concat, newPath, len(oldPath)+1)).Error
+}
+
+// SortCategories orders categories by depth, then by ID, so that parents come before their children.
+func SortCategories(categories []Category) {
+	sort.Slice(categories, func(i, j int) bool {
+		if categories[i].Depth() != categories[j].Depth() {
+			return categories[i].Depth() < categories[j].Depth()
+		}
+		return categories[i].ID < categories[j].ID
+	})
+}
+
+// BuildCategoryTrees nests categories, sorted by SortCategories, below their parents. Categories whose parent
+// is not among them are returned as the roots of the trees.
+func BuildCategoryTrees(categories []Category) []CategoryTree {
+	children := map[uint][]Category{}
+	present := map[uint]bool{}
+	for _, category := range categories {
+		present[category.ID] = true
+	}
+	var roots []Category
+	for _, category := range categories {
+		if category.Parent_ID != nil && present[*category.Parent_ID] {
+			children[*category.Parent_ID] = append(children[*category.Parent_ID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+	var build func(categories []Category) []CategoryTree
+	build = func(categories []Category) []CategoryTree {
+		trees := make([]CategoryTree, 0, len(categories))
+		for _, category := range categories {
+			trees = append(trees, CategoryTree{Category: category, Children: build(children[category.ID])})
+		}
+		return trees
 	}
-	return category, nil
+	return build(roots)
 }
@@ -1,10 +1,13 @@
 package models
 
 import (
+	"E-Commerce_Website_Database/internal/validation"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"path/filepath"
 	"testing"
 )
 
@@ -40,26 +43,26 @@ func TestGetAllCategories(t *testing.T) {
 
 // TestCategory_SetName check if this function sets the name of category correctly
 // It creates a new instance of the Category struct and calls the SetName function with a valid name.
-// It then checks if the name was set correctly and if the function returned true.
-// It repeats the process with an invalid name and checks if the name was not set and the function returned false.
+// It then checks if the name was set correctly and if the function returned no error.
+// It repeats the process with an invalid name and checks if the name was not set and the function returned an error.
 func TestCategory_SetName(t *testing.T) {
 	c := Category{}
-	assert.True(t, c.SetName("Valid Category Name"))
+	assert.NoError(t, c.SetName("Valid Category Name"))
 	assert.Equal(t, "Valid Category Name", c.Name)
 	// Test with invalid name
-	assert.False(t, c.SetName(""))
+	assert.Error(t, c.SetName(""))
 }
 
 // TestCategory_SetDescription check if this function sets the description of category correctly
 // It creates a new instance of the Category struct and calls the SetDescription function with a valid description.
-// It then checks if the description was set correctly and if the function returned true.
-// It repeats the process with an invalid description and checks if the description was not set and the function returned false.
+// It then checks if the description was set correctly and if the function returned no error.
+// It repeats the process with an invalid description and checks if the description was not set and the function returned an error.
 func TestCategory_SetDescription(t *testing.T) {
 	c := Category{}
-	assert.True(t, c.SetDescription("Valid Category Description"))
+	assert.NoError(t, c.SetDescription("Valid Category Description"))
 	assert.Equal(t, "Valid Category Description", c.Description)
 	// Test with invalid description
-	assert.False(t, c.SetDescription(""))
+	assert.Error(t, c.SetDescription(""))
 }
 
 // TestCategoryExists checks at this function ensure at a specific category exists.
@@ -106,14 +109,153 @@ func TestSearchCategory(t *testing.T) {
 	// Setup expectations
 	rows := sqlmock.NewRows([]string{"id", "name", "description"}).
 		AddRow(1, "Search Category", "Matches Criteria")
-	mock.ExpectQuery("^SELECT \\* FROM \"categories\" WHERE").WithArgs("Search Category", 1).WillReturnRows(rows)
+	mock.ExpectQuery("^SELECT \\* FROM \"categories\" WHERE").WithArgs("Search Category").WillReturnRows(rows)
 
 	// Call the function now
 	brands, err := SearchCategory(gormDB, map[string]interface{}{"name": "Search Category"})
 	assert.NoError(t, err)
 	assert.NotNil(t, brands)
-	assert.Equal(t, "Search Category", brands.Name)
+	if assert.Len(t, brands, 1) {
+		assert.Equal(t, "Search Category", brands[0].Name)
+	}
 
 	// Check all expectations
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestCategory_SetParent checks that a category is placed below an existing parent with a path derived from it,
+// and that a missing parent, the category itself, one of its descendants or a too deep subtree are refused
+// with the code of the rule they break, leaving the category unchanged.
+func TestCategory_SetParent(t *testing.T) {
+	root := Category{Model: gorm.Model{ID: 1}, Path: "/1/"}
+	child := Category{Model: gorm.Model{ID: 2}, Path: "/1/2/"}
+	deep := Category{Model: gorm.Model{ID: 3}, Path: "/1/2/3/"}
+	parentOf := func(id uint) (*Category, error) {
+		for _, category := range []*Category{&root, &child, &deep} {
+			if category.ID == id {
+				return category, nil
+			}
+		}
+		return nil, nil
+	}
+	id := func(id uint) *uint { return &id }
+
+	c := Category{Model: gorm.Model{ID: 9}}
+	assert.NoError(t, c.SetParent(nil, parentOf, 0))
+	assert.Nil(t, c.Parent_ID)
+	assert.Equal(t, "/9/", c.Path)
+	assert.NoError(t, c.SetParent(id(2), parentOf, 0))
+	assert.Equal(t, uint(2), *c.Parent_ID)
+	assert.Equal(t, "/1/2/9/", c.Path)
+	assert.Equal(t, 3, c.Depth())
+	assert.Equal(t, []uint{1, 2}, c.AncestorIDs())
+
+	for name, test := range map[string]struct {
+		category Category
+		parentID uint
+		height   int
+		code     string
+	}{
+		"missing parent": {category: Category{Model: gorm.Model{ID: 9}, Path: "/9/"}, parentID: 99, code: validation.CodeNotFound},
+		"itself":         {category: child, parentID: 2, code: validation.CodeCycle},
+		"descendant":     {category: root, parentID: 3, code: validation.CodeCycle},
+		"too deep":       {category: Category{Model: gorm.Model{ID: 9}, Path: "/9/"}, parentID: 3, height: MaxCategoryDepth - 3, code: validation.CodeOutOfRange},
+	} {
+		t.Run(name, func(t *testing.T) {
+			category := test.category
+			err := category.SetParent(id(test.parentID), parentOf, test.height)
+			if fieldErr, ok := err.(*validation.FieldError); assert.True(t, ok) {
+				assert.Equal(t, test.code, fieldErr.Code)
+			}
+			assert.Equal(t, test.category, category)
+		})
+	}
+}
+
+// TestBuildCategoryTrees checks that sorted categories are nested below their parents, and that a category whose
+// parent is missing becomes a root.
+func TestBuildCategoryTrees(t *testing.T) {
+	id := func(id uint) *uint { return &id }
+	categories := []Category{
+		{Model: gorm.Model{ID: 5}, Parent_ID: id(1), Path: "/1/5/"},
+		{Model: gorm.Model{ID: 7}, Parent_ID: id(5), Path: "/1/5/7/"},
+		{Model: gorm.Model{ID: 1}, Path: "/1/"},
+		{Model: gorm.Model{ID: 3}, Parent_ID: id(1), Path: "/1/3/"},
+		{Model: gorm.Model{ID: 8}, Parent_ID: id(6), Path: "/6/8/"},
+	}
+	SortCategories(categories)
+	trees := BuildCategoryTrees(categories)
+	if assert.Len(t, trees, 2) {
+		assert.Equal(t, uint(1), trees[0].ID)
+		if assert.Len(t, trees[0].Children, 2) {
+			assert.Equal(t, uint(3), trees[0].Children[0].ID)
+			assert.Empty(t, trees[0].Children[0].Children)
+			assert.Equal(t, uint(5), trees[0].Children[1].ID)
+			assert.Len(t, trees[0].Children[1].Children, 1)
+		}
+		assert.Equal(t, uint(8), trees[1].ID)
+	}
+}
+
+// TestMoveCategorySubtree checks that the descendants of a moved category, including those in the trash, get paths
+// below its new path, that its descendants and ancestors are read back in order, and that a root category can be
+// restored from the trash.
+func TestMoveCategorySubtree(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tree.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	assert.NoError(t, db.AutoMigrate(&Category{}, &Product{}, &Attribute{}))
+	id := func(id uint) *uint { return &id }
+	for _, category := range []Category{
+		{Model: gorm.Model{ID: 1}, Name: "Computers", Path: "/1/"},
+		{Model: gorm.Model{ID: 2}, Name: "Laptops", Parent_ID: id(1), Path: "/1/2/"},
+		{Model: gorm.Model{ID: 3}, Name: "Gaming laptops", Parent_ID: id(2), Path: "/1/2/3/"},
+		{Model: gorm.Model{ID: 4}, Name: "Ultrabooks", Parent_ID: id(2), Path: "/1/2/4/"},
+		{Model: gorm.Model{ID: 10}, Name: "Portable", Path: "/10/"},
+		{Model: gorm.Model{ID: 12}, Name: "Laptop bags", Path: "/12/"},
+	} {
+		assert.NoError(t, db.Create(&category).Error)
+	}
+	assert.NoError(t, db.Delete(&Category{}, 4).Error)
+
+	assert.NoError(t, db.Model(&Category{}).Where("id = ?", 2).Updates(map[string]interface{}{"parent_id": 10, "path": "/10/2/"}).Error)
+	assert.NoError(t, MoveCategorySubtree(db, "/1/2/", "/10/2/"))
+	var paths []string
+	assert.NoError(t, db.Unscoped().Model(&Category{}).Order("id").Pluck("path", &paths).Error)
+	assert.Equal(t, []string{"/1/", "/10/2/", "/10/2/3/", "/10/2/4/", "/10/", "/12/"}, paths)
+
+	descendants, err := CategoryDescendants(db, "/10/")
+	assert.NoError(t, err)
+	if assert.Len(t, descendants, 2) {
+		assert.Equal(t, uint(2), descendants[0].ID)
+		assert.Equal(t, uint(3), descendants[1].ID)
+	}
+	ancestors, err := CategoryAncestors(db, &Category{Path: "/10/2/3/"})
+	assert.NoError(t, err)
+	if assert.Len(t, ancestors, 2) {
+		assert.Equal(t, uint(10), ancestors[0].ID)
+		assert.Equal(t, uint(2), ancestors[1].ID)
+	}
+
+	assert.NoError(t, Delete(db, &Category{}, 12))
+	assert.NoError(t, Restore(db, &Category{}, 12))
+}
+
+// TestCheckCategoryMove checks that a move is refused when the parent no longer has the path the one of the category
+// was derived from, was deleted, is missing or is now below the category.
+func TestCheckCategoryMove(t *testing.T) {
+	parent := &Category{Model: gorm.Model{ID: 2}, Path: "/2/"}
+	parentID := uint(2)
+	category := &Category{Model: gorm.Model{ID: 1}, Parent_ID: &parentID, Path: "/2/1/"}
+
+	assert.NoError(t, CheckCategoryMove(category, "/1/", parent))
+	assert.NoError(t, CheckCategoryMove(&Category{Model: gorm.Model{ID: 1}, Path: "/1/"}, "/2/1/", nil), "a root has no parent to check")
+	assert.ErrorIs(t, CheckCategoryMove(category, "/1/", nil), ErrParentMoved)
+	assert.ErrorIs(t, CheckCategoryMove(category, "/1/", &Category{Model: gorm.Model{ID: 2}, Path: "/3/2/"}), ErrParentMoved)
+	assert.ErrorIs(t, CheckCategoryMove(&Category{Model: gorm.Model{ID: 1}, Parent_ID: &parentID, Path: "/1/2/1/"}, "/1/",
+		&Category{Model: gorm.Model{ID: 2}, Path: "/1/2/"}), ErrParentMoved)
+	deleted := *parent
+	deleted.DeletedAt = gorm.DeletedAt{Valid: true}
+	assert.ErrorIs(t, CheckCategoryMove(category, "/1/", &deleted), ErrParentMoved)
+}
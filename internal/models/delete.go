@@ -0,0 +1,345 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DeleteAction tells what happens to the rows referencing a row that is deleted.
+type DeleteAction int
+
+const (
+	// Restrict refuses the deletion while referencing rows exist.
+	Restrict DeleteAction = iota
+	// Cascade deletes the referencing rows with it, applying their own policy first.
+	Cascade
+	// Nullify keeps the referencing rows and sets their reference to NULL once the referenced row is purged.
+	Nullify
+)
+
+// Dependent is a table referencing another one through Column, with the action applied when the referenced row is deleted.
+type Dependent struct {
+	Model  interface{}
+	Column string
+	Action DeleteAction
+}
+
+// DeletePolicies lists, by table, the tables referencing it. It mirrors the ON DELETE clauses of the foreign keys,
+// so that deletes behave the same on databases that do not enforce them, and restricted deletes can name their dependents.
+var DeletePolicies = map[string][]Dependent{
+	"brands": {{Model: &Product{}, Column: "brand_id", Action: Restrict}},
+	"categories": {
+		{Model: &Product{}, Column: "category_id", Action: Restrict},
+		{Model: &Category{}, Column: "parent_id", Action: Restrict},
+		{Model: &Attribute{}, Column: "category_id", Action: Cascade},
+	},
+	"users": {
+		{Model: &Order{}, Column: "user_id", Action: Restrict},
+		{Model: &Review{}, Column: "user_id", Action: Nullify},
+	},
+	"products": {
+		{Model: &OrderItem{}, Column: "product_id", Action: Restrict},
+		{Model: &Review{}, Column: "product_id", Action: Cascade},
+		{Model: &ProductVariant{}, Column: "product_id", Action: Cascade},
+		{Model: &ProductAttribute{}, Column: "product_id", Action: Cascade},
+		{Model: &ProductImage{}, Column: "product_id", Action: Cascade},
+	},
+	"attributes":       {{Model: &ProductAttribute{}, Column: "attribute_id", Action: Cascade}},
+	"product_variants": {{Model: &OrderItem{}, Column: "variant_id", Action: Restrict}},
+	"orders": {
+		{Model: &OrderItem{}, Column: "order_id", Action: Cascade},
+		{Model: &Payment{}, Column: "order_id", Action: Cascade},
+		{Model: &ShippingDetails{}, Column: "order_id", Action: Cascade},
+	},
+}
+
+// DependentsError is returned when a row cannot be deleted because other rows still reference it.
+// Dependents counts the blocking rows by table.
+type DependentsError struct {
+	Table      string
+	Dependents map[string]int64
+}
+
+func (e *DependentsError) Error() string {
+	tables := make([]string, 0, len(e.Dependents))
+	for table := range e.Dependents {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	blocking := make([]string, len(tables))
+	for i, table := range tables {
+		blocking[i] = fmt.Sprintf("%d %s", e.Dependents[table], table)
+	}
+	return fmt.Sprintf("%s row is still referenced by %s", e.Table, strings.Join(blocking, ", "))
+}
+
+// Delete moves the row of model with the given ID to the trash in a transaction, after applying DeletePolicies
+// to the rows referencing it: restricted rows still in use block the deletion and cascaded rows are trashed with it,
+// sharing its deletion time. It returns a *DependentsError if restricted rows reference it,
+// and gorm.ErrRecordNotFound if the row does not exist or is already in the trash.
+func Delete(db *gorm.DB, model interface{}, id uint) error {
+	return DeleteVersion(db, model, id, 0)
+}
+
+// DeleteVersion is Delete for a row that must still have the given version, unless it is 0, when it is moved to the
+// trash: a row changed since that version was read returns ErrVersionConflict and nothing is deleted.
+func DeleteVersion(db *gorm.DB, model interface{}, id, version uint) error {
+	deletedAt := time.Now().Truncate(time.Millisecond)
+	return db.Transaction(func(tx *gorm.DB) error {
+		return deleteRow(tx, model, id, version, &deletedAt)
+	})
+}
+
+// Purge permanently deletes the row of model with the given ID, whether it is in the trash or not, in a transaction.
+// Restricted rows block it even when they are in the trash, cascaded rows are deleted with it
+// and nullified references are set to NULL. It returns the same errors as Delete.
+func Purge(db *gorm.DB, model interface{}, id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		return deleteRow(tx, model, id, 0, nil)
+	})
+}
+
+// deleteRow applies the policies of the table of model to the rows referencing id, then deletes the row:
+// into the trash at deletedAt, or permanently when deletedAt is nil. A version other than 0 is the version the row
+// must still have when it is deleted.
+func deleteRow(tx *gorm.DB, model interface{}, id, version uint, deletedAt *time.Time) error {
+	table, err := tableName(tx, model)
+	if err != nil {
+		return err
+	}
+	// Rows in the trash only count when deleting permanently.
+	scope := func() *gorm.DB {
+		if deletedAt == nil {
+			return tx.Unscoped()
+		}
+		return tx
+	}
+
+	// A missing row is reported before the rows referencing it are looked at.
+	var found int64
+	if err := scope().Model(model).Where("id = ?", id).Count(&found).Error; err != nil {
+		return err
+	}
+	if found == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	blocking := map[string]int64{}
+	for _, dependent := range DeletePolicies[table] {
+		if dependent.Action != Restrict {
+			continue
+		}
+		var count int64
+		if err := scope().Model(dependent.Model).Where(dependent.Column+" = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			dependentTable, err := tableName(tx, dependent.Model)
+			if err != nil {
+				return err
+			}
+			blocking[dependentTable] = count
+		}
+	}
+	if len(blocking) > 0 {
+		return &DependentsError{Table: table, Dependents: blocking}
+	}
+
+	for _, dependent := range DeletePolicies[table] {
+		switch dependent.Action {
+		case Nullify:
+			// A row in the trash can still be restored, so its references are only cleared when it is purged.
+			if deletedAt != nil {
+				continue
+			}
+			if err := tx.Unscoped().Model(dependent.Model).Where(dependent.Column+" = ?", id).UpdateColumn(dependent.Column, nil).Error; err != nil {
+				return err
+			}
+		case Cascade:
+			// Rows without a trash, such as the values of products, stay with their row in the trash
+			// and are only deleted when it is purged.
+			trashable, err := hasTrash(tx, dependent.Model)
+			if err != nil {
+				return err
+			}
+			if !trashable && deletedAt != nil {
+				continue
+			}
+			var ids []uint
+			if err := scope().Model(dependent.Model).Where(dependent.Column+" = ?", id).Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, dependentID := range ids {
+				if err := deleteRow(tx, dependent.Model, dependentID, 0, deletedAt); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	row := tx.Model(model).Where("id = ?", id)
+	if version != 0 {
+		row = row.Where("version = ?", version)
+	}
+	var result *gorm.DB
+	if deletedAt == nil {
+		result = row.Unscoped().Delete(model)
+	} else {
+		result = row.UpdateColumn("deleted_at", *deletedAt)
+	}
+	switch {
+	case result.Error != nil:
+		return result.Error
+	case result.RowsAffected == 0 && version != 0:
+		return ErrVersionConflict
+	case result.RowsAffected == 0:
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeletedParentError is returned when a row cannot be restored because a row it references is in the trash.
+type DeletedParentError struct {
+	Table  string
+	Parent string
+	ID     uint
+}
+
+func (e *DeletedParentError) Error() string {
+	return fmt.Sprintf("%s row references %s row %d, which is deleted and must be restored first", e.Table, e.Parent, e.ID)
+}
+
+// Restore takes the row of model with the given ID out of the trash in a transaction, together with the rows that were
+// cascaded into the trash with it. It returns a *DeletedParentError if a row it references is in the trash,
+// and gorm.ErrRecordNotFound if the row is not in the trash.
+func Restore(db *gorm.DB, model interface{}, id uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		table, err := tableName(tx, model)
+		if err != nil {
+			return err
+		}
+		for parent, dependents := range DeletePolicies {
+			for _, dependent := range dependents {
+				if dependent.Action == Nullify {
+					continue
+				}
+				if dependentTable, err := tableName(tx, dependent.Model); err != nil || dependentTable != table {
+					continue
+				}
+				// The reference is NULL for a row without a parent, such as a root category.
+				var parentIDs []uint
+				if err := tx.Unscoped().Model(model).Where("id = ? AND "+dependent.Column+" IS NOT NULL", id).Pluck(dependent.Column, &parentIDs).Error; err != nil {
+					return err
+				}
+				if len(parentIDs) == 0 || parentIDs[0] == 0 {
+					continue
+				}
+				var live int64
+				if err := tx.Table(parent).Where("id = ? AND deleted_at IS NULL", parentIDs[0]).Count(&live).Error; err != nil {
+					return err
+				}
+				if live == 0 {
+					return &DeletedParentError{Table: table, Parent: parent, ID: parentIDs[0]}
+				}
+			}
+		}
+		return restoreRow(tx, table, model, id)
+	})
+}
+
+// restoreRow takes the row out of the trash, then the cascaded rows deleted at the same time as it.
+func restoreRow(tx *gorm.DB, table string, model interface{}, id uint) error {
+	var deletedAt []time.Time
+	if err := tx.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).Pluck("deleted_at", &deletedAt).Error; err != nil {
+		return err
+	}
+	if len(deletedAt) == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	if err := tx.Unscoped().Model(model).Where("id = ?", id).UpdateColumn("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	for _, dependent := range DeletePolicies[table] {
+		if dependent.Action != Cascade {
+			continue
+		}
+		trashable, err := hasTrash(tx, dependent.Model)
+		if err != nil {
+			return err
+		}
+		if !trashable {
+			continue
+		}
+		dependentTable, err := tableName(tx, dependent.Model)
+		if err != nil {
+			return err
+		}
+		var ids []uint
+		if err := tx.Unscoped().Model(dependent.Model).Where(dependent.Column+" = ? AND deleted_at = ?", id, deletedAt[0]).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		for _, dependentID := range ids {
+			if err := restoreRow(tx, dependentTable, dependent.Model, dependentID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// purgeOrder lists the models in the order the trash is emptied, referencing tables before the tables they reference.
+var purgeOrder = []interface{}{&Review{}, &ShippingDetails{}, &Payment{}, &OrderItem{}, &ProductVariant{}, &ProductImage{},
+	&Order{}, &Product{}, &User{}, &Attribute{}, &Category{}, &Brands{}}
+
+// PurgeDeleted permanently deletes the rows moved to the trash before the given time, and returns how many rows
+// were purged by table. Rows still referenced by restricted rows, e.g. a brand of a product deleted later,
+// are kept until those rows are purged too.
+func PurgeDeleted(ctx context.Context, db *gorm.DB, before time.Time) (map[string]int64, error) {
+	db = db.WithContext(ctx)
+	purged := map[string]int64{}
+	for _, model := range purgeOrder {
+		table, err := tableName(db, model)
+		if err != nil {
+			return purged, err
+		}
+		var ids []uint
+		if err := db.Unscoped().Model(model).Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Pluck("id", &ids).Error; err != nil {
+			return purged, err
+		}
+		for _, id := range ids {
+			err := Purge(db, model, id)
+			var dependentsErr *DependentsError
+			switch {
+			case err == nil:
+				purged[table]++
+			case errors.As(err, &dependentsErr), errors.Is(err, gorm.ErrRecordNotFound):
+			default:
+				return purged, err
+			}
+		}
+	}
+	return purged, nil
+}
+
+// tableName returns the name of the table of model.
+func tableName(db *gorm.DB, model interface{}) (string, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return "", err
+	}
+	return stmt.Schema.Table, nil
+}
+
+// hasTrash reports whether the table of model has a deleted_at column, so that its rows are moved to the trash.
+func hasTrash(db *gorm.DB, model interface{}) (bool, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return false, err
+	}
+	return stmt.Schema.LookUpField("deleted_at") != nil, nil
+}
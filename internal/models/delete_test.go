@@ -0,0 +1,259 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupDeleteDB opens a SQLite database private to the test, without foreign key enforcement so that only the
+// delete policies are tested, and creates a product ordered once and reviewed once.
+func setupDeleteDB(t *testing.T) (*gorm.DB, Product, Order, Review) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "delete.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&Brands{}, &Category{}, &Product{}, &User{}, &Order{}, &OrderItem{}, &Payment{},
+		&ShippingDetails{}, &Review{}, &Attribute{}, &ProductAttribute{}, &ProductImage{}); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	brand := Brands{Name: "Acme"}
+	db.Create(&brand)
+	category := Category{Name: "Laptops"}
+	db.Create(&category)
+	product := Product{Name: "Laptop", Brand_ID: brand.ID, Category_ID: category.ID}
+	db.Create(&product)
+	buyer := User{Username: "buyer", Email: "buyer@example.com"}
+	db.Create(&buyer)
+	order := Order{User_ID: buyer.ID, Status: "pending"}
+	db.Create(&order)
+	db.Create(&OrderItem{Order_ID: order.ID, Product_ID: product.ID, Quantity: 1})
+	db.Create(&Payment{Order_ID: order.ID, Status: "pending"})
+	db.Create(&ShippingDetails{Order_ID: order.ID, Status: "pending"})
+	reviewer := User{Username: "reviewer", Email: "reviewer@example.com"}
+	db.Create(&reviewer)
+	review := Review{Product_ID: product.ID, User_ID: reviewer.ID, Rating: 4}
+	db.Create(&review)
+	return db, product, order, review
+}
+
+// count returns the number of rows of model matching the conditions of db.
+func count(db *gorm.DB, model interface{}) int64 {
+	var n int64
+	db.Model(model).Count(&n)
+	return n
+}
+
+// TestDeleteRestrict checks that brands, categories, ordered products and users with orders are not deleted,
+// and that the error counts the rows blocking the deletion.
+func TestDeleteRestrict(t *testing.T) {
+	db, product, order, _ := setupDeleteDB(t)
+
+	cases := []struct {
+		model    interface{}
+		id       uint
+		table    string
+		blocking map[string]int64
+	}{
+		{&Brands{}, uint(product.Brand_ID), "brands", map[string]int64{"products": 1}},
+		{&Category{}, uint(product.Category_ID), "categories", map[string]int64{"products": 1}},
+		{&Product{}, product.ID, "products", map[string]int64{"order_items": 1}},
+		{&User{}, uint(order.User_ID), "users", map[string]int64{"orders": 1}},
+	}
+	for _, tc := range cases {
+		err := Delete(db, tc.model, tc.id)
+		var dependentsErr *DependentsError
+		if assert.True(t, errors.As(err, &dependentsErr), tc.table) {
+			assert.Equal(t, tc.table, dependentsErr.Table)
+			assert.Equal(t, tc.blocking, dependentsErr.Dependents)
+		}
+		assert.Equal(t, int64(1), count(db.Where("id = ?", tc.id), tc.model), "%s row should be kept", tc.table)
+	}
+	assert.EqualError(t, &DependentsError{Table: "users", Dependents: map[string]int64{"reviews": 2, "orders": 1}},
+		"users row is still referenced by 1 orders, 2 reviews")
+
+	// Rows in the trash do not block a delete, but they still block purging.
+	assert.NoError(t, Delete(db, &Order{}, order.ID))
+	assert.IsType(t, &DependentsError{}, Purge(db, &Product{}, product.ID))
+	assert.NoError(t, Delete(db, &Product{}, product.ID))
+}
+
+// TestDeleteCascade checks that deleting an order moves it to the trash with its items, payments and shipping details,
+// and that purging it deletes them permanently.
+func TestDeleteCascade(t *testing.T) {
+	db, product, order, _ := setupDeleteDB(t)
+
+	assert.NoError(t, Delete(db, &Order{}, order.ID))
+	assert.ErrorIs(t, Delete(db, &Order{}, order.ID), gorm.ErrRecordNotFound, "an order in the trash is not found")
+	for _, model := range []interface{}{&Order{}, &OrderItem{}, &Payment{}, &ShippingDetails{}} {
+		assert.Zero(t, count(db, model))
+		assert.Equal(t, int64(1), count(db.Unscoped(), model), "the row should be in the trash")
+	}
+	var orderRow, itemRow OrderItem
+	db.Unscoped().Table("orders").First(&orderRow)
+	db.Unscoped().First(&itemRow)
+	assert.Equal(t, orderRow.DeletedAt, itemRow.DeletedAt, "cascaded rows share the deletion time")
+
+	assert.NoError(t, Purge(db, &Order{}, order.ID))
+	for _, model := range []interface{}{&Order{}, &OrderItem{}, &Payment{}, &ShippingDetails{}} {
+		assert.Zero(t, count(db.Unscoped(), model))
+	}
+
+	assert.NoError(t, Delete(db, &Product{}, product.ID))
+	assert.Zero(t, count(db, &Review{}))
+	assert.Equal(t, int64(1), count(db, &Brands{}), "the brand of a deleted product is kept")
+}
+
+// TestDeleteKeepsValuesOfTrashedRows checks that the values of products, which have no trash, stay with a product
+// or attribute in the trash so that they come back when it is restored, and are deleted when it is purged.
+func TestDeleteKeepsValuesOfTrashedRows(t *testing.T) {
+	db, product, _, _ := setupDeleteDB(t)
+	attribute := Attribute{Category_ID: product.Category_ID, Name: "ram_gb", Type: AttributeNumber}
+	db.Create(&attribute)
+	tablet := Product{Name: "Tablet", Brand_ID: product.Brand_ID, Category_ID: product.Category_ID}
+	db.Create(&tablet)
+	ram := 8.0
+	db.Create(&ProductAttribute{Product_ID: tablet.ID, Attribute_ID: attribute.ID, Number: &ram})
+	db.Create(&ProductAttribute{Product_ID: product.ID, Attribute_ID: attribute.ID, Number: &ram})
+
+	assert.NoError(t, Delete(db, &Product{}, tablet.ID))
+	assert.Equal(t, int64(2), count(db, &ProductAttribute{}))
+	assert.NoError(t, Restore(db, &Product{}, tablet.ID))
+	assert.NoError(t, Delete(db, &Product{}, tablet.ID))
+	assert.NoError(t, Purge(db, &Product{}, tablet.ID))
+	assert.Equal(t, int64(1), count(db, &ProductAttribute{}))
+
+	assert.NoError(t, Delete(db, &Attribute{}, attribute.ID))
+	assert.Equal(t, int64(1), count(db, &ProductAttribute{}))
+	assert.NoError(t, Purge(db, &Attribute{}, attribute.ID))
+	assert.Zero(t, count(db, &ProductAttribute{}))
+}
+
+// TestDeleteNullify checks that the reviews of a deleted user keep their author while the user is in the trash,
+// and are kept with a NULL user_id once the user is purged.
+func TestDeleteNullify(t *testing.T) {
+	db, _, _, review := setupDeleteDB(t)
+
+	assert.NoError(t, Delete(db, &User{}, uint(review.User_ID)))
+	var reloaded Review
+	assert.NoError(t, db.First(&reloaded, review.ID).Error)
+	assert.Equal(t, review.User_ID, reloaded.User_ID)
+
+	assert.NoError(t, Purge(db, &User{}, uint(review.User_ID)))
+	var orphan Review
+	assert.NoError(t, db.First(&orphan, review.ID).Error)
+	assert.Zero(t, orphan.User_ID)
+	assert.Equal(t, int64(1), count(db.Where("user_id IS NULL"), &Review{}))
+}
+
+// TestDeleteNotFound checks that deleting a missing row reports gorm.ErrRecordNotFound.
+func TestDeleteNotFound(t *testing.T) {
+	db, _, _, _ := setupDeleteDB(t)
+
+	assert.ErrorIs(t, Delete(db, &Payment{}, 999), gorm.ErrRecordNotFound)
+	assert.ErrorIs(t, Purge(db, &Payment{}, 999), gorm.ErrRecordNotFound)
+}
+
+// TestRestore checks that restoring an order also restores the rows deleted with it, but not the rows deleted before,
+// and that a row cannot be restored while a row it references is in the trash.
+func TestRestore(t *testing.T) {
+	db, _, order, _ := setupDeleteDB(t)
+	var payment Payment
+	db.First(&payment)
+
+	assert.NoError(t, Delete(db, &Payment{}, payment.ID))
+	time.Sleep(2 * time.Millisecond)
+	assert.NoError(t, Delete(db, &Order{}, order.ID))
+
+	var item OrderItem
+	db.Unscoped().First(&item)
+	err := Restore(db, &OrderItem{}, item.ID)
+	var parentErr *DeletedParentError
+	if assert.True(t, errors.As(err, &parentErr)) {
+		assert.Equal(t, "orders", parentErr.Parent)
+		assert.Equal(t, order.ID, parentErr.ID)
+	}
+
+	assert.NoError(t, Restore(db, &Order{}, order.ID))
+	assert.Equal(t, int64(1), count(db, &Order{}))
+	assert.Equal(t, int64(1), count(db, &OrderItem{}))
+	assert.Equal(t, int64(1), count(db, &ShippingDetails{}))
+	assert.Zero(t, count(db, &Payment{}), "the payment deleted before the order stays in the trash")
+
+	assert.ErrorIs(t, Restore(db, &Order{}, order.ID), gorm.ErrRecordNotFound, "a live row cannot be restored")
+	assert.NoError(t, Restore(db, &Payment{}, payment.ID))
+}
+
+// TestPurgeDeleted checks that only rows deleted before the retention limit are purged,
+// and that a row still referenced by a more recently deleted row is kept until that row is purged.
+func TestPurgeDeleted(t *testing.T) {
+	db, product, order, _ := setupDeleteDB(t)
+	ctx := context.Background()
+
+	assert.NoError(t, Delete(db, &Order{}, order.ID))
+	assert.NoError(t, Delete(db, &Product{}, product.ID))
+	assert.NoError(t, Delete(db, &Brands{}, uint(product.Brand_ID)))
+
+	purged, err := PurgeDeleted(ctx, db, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Empty(t, purged)
+
+	// The product and brand expired, but the order referencing the product did not.
+	old := time.Now().Add(-48 * time.Hour)
+	db.Unscoped().Model(&Product{}).Where("id = ?", product.ID).UpdateColumn("deleted_at", old)
+	db.Unscoped().Model(&Brands{}).Where("id = ?", product.Brand_ID).UpdateColumn("deleted_at", old)
+	db.Unscoped().Model(&Review{}).Where("product_id = ?", product.ID).UpdateColumn("deleted_at", old)
+	purged, err = PurgeDeleted(ctx, db, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"reviews": 1}, purged)
+	assert.Equal(t, int64(1), count(db.Unscoped(), &Product{}))
+
+	purged, err = PurgeDeleted(ctx, db, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int64{"shipping_details": 1, "payments": 1, "order_items": 1, "orders": 1, "products": 1, "brands": 1}, purged)
+	for _, model := range []interface{}{&Order{}, &OrderItem{}, &Payment{}, &ShippingDetails{}, &Product{}, &Brands{}} {
+		assert.Zero(t, count(db.Unscoped(), model))
+	}
+	assert.Equal(t, int64(1), count(db, &Category{}))
+}
+
+// TestDeletePoliciesMatchForeignKeys checks that every policy matches the OnDelete constraint declared
+// on a GORM association, so that the policies and the foreign keys created by the migrations agree.
+func TestDeletePoliciesMatchForeignKeys(t *testing.T) {
+	db, _, _, _ := setupDeleteDB(t)
+	actions := map[string]DeleteAction{"RESTRICT": Restrict, "CASCADE": Cascade, "SET NULL": Nullify}
+
+	// Constraints are keyed by referenced table, then by referencing table and column.
+	constraints := map[string]map[string]DeleteAction{}
+	for _, model := range []interface{}{&Category{}, &Product{}, &Order{}, &OrderItem{}, &Review{}, &ProductAttribute{}} {
+		stmt := &gorm.Statement{DB: db}
+		if !assert.NoError(t, stmt.Parse(model)) {
+			continue
+		}
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			if constraint := rel.ParseConstraint(); constraint != nil {
+				if constraints[constraint.ReferenceSchema.Table] == nil {
+					constraints[constraint.ReferenceSchema.Table] = map[string]DeleteAction{}
+				}
+				constraints[constraint.ReferenceSchema.Table][constraint.Schema.Table+"."+constraint.ForeignKeys[0].DBName] = actions[constraint.OnDelete]
+			}
+		}
+	}
+
+	expected := map[string]map[string]DeleteAction{}
+	for table, dependents := range DeletePolicies {
+		expected[table] = map[string]DeleteAction{}
+		for _, dependent := range dependents {
+			dependentTable, err := tableName(db, dependent.Model)
+			assert.NoError(t, err)
+			expected[table][dependentTable+"."+dependent.Column] = dependent.Action
+		}
+	}
+	assert.Equal(t, constraints, expected)
+}
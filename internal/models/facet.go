@@ -0,0 +1,219 @@
+package models
+
+import (
+	"E-Commerce_Website_Database/internal/validation"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttributeFilter keeps the products whose value of the attribute named Name compares with Values by Operator,
+// one of validation.FilterOperators: eq keeps a value equal to any of Values, ne a value equal to none of them,
+// and the ranges compare numbers with the first value. Values hold float64, string or bool values by attribute type.
+type AttributeFilter struct {
+	Name     string
+	Operator string
+	Values   []interface{}
+}
+
+// FacetCount is the number of products having Value as the value of the attribute named Name.
+type FacetCount struct {
+	Name  string
+	Value interface{}
+	Count int64
+}
+
+// Facet lists the values an attribute takes among products with the number of products having each,
+// so that clients can offer them as filters.
+type Facet struct {
+	Name   string       `json:"name"`
+	Label  string       `json:"label"`
+	Type   string       `json:"type"`
+	Unit   string       `json:"unit,omitempty"`
+	Values []FacetValue `json:"values"`
+}
+
+// FacetValue is a value of the attribute of a facet and the number of products having it.
+type FacetValue struct {
+	Value interface{} `json:"value"`
+	Count int64       `json:"count"`
+}
+
+// ParseFilterKey splits a query string key such as ram_gb[gte] into the attribute name and the operator, eq when
+// the key has none. ok is false for keys that cannot name an attribute, such as _ or a[b]c.
+func ParseFilterKey(key string) (name, operator string, ok bool) {
+	name, operator = key, "eq"
+	if open := strings.IndexByte(key, '['); open >= 0 {
+		if !strings.HasSuffix(key, "]") {
+			return "", "", false
+		}
+		name, operator = key[:open], key[open+1:len(key)-1]
+	}
+	return name, operator, validation.Identifier(name, 64, nil) == nil
+}
+
+// NewAttributeFilter returns the filter comparing the values of attribute by operator with values, as given in
+// a query string, parsed according to the type of the attribute.
+// It returns a *validation.FieldError if the operator does not apply to the type or a value is not of the type.
+func NewAttributeFilter(attribute Attribute, operator string, values []string) (AttributeFilter, error) {
+	if err := validation.FilterOperator(operator, attribute.Type); err != nil {
+		return AttributeFilter{}, err
+	}
+	if err := validation.Repeated(len(values) > 1 && operator != "eq" && operator != "ne"); err != nil {
+		return AttributeFilter{}, err
+	}
+	filter := AttributeFilter{Name: attribute.Name, Operator: operator}
+	for _, raw := range values {
+		var value interface{} = strings.TrimSpace(raw)
+		switch attribute.Type {
+		case AttributeNumber:
+			if number, err := strconv.ParseFloat(value.(string), 64); err == nil {
+				value = number
+			}
+		case AttributeBoolean:
+			if boolean, err := strconv.ParseBool(value.(string)); err == nil {
+				value = boolean
+			}
+		}
+		if err := validation.AttributeValue(value, attribute.Type, 255); err != nil {
+			return AttributeFilter{}, err
+		}
+		filter.Values = append(filter.Values, value)
+	}
+	return filter, nil
+}
+
+// Matches reports whether value, the value of the attribute of the filter for a product, is kept by the filter.
+func (f AttributeFilter) Matches(value interface{}) bool {
+	switch f.Operator {
+	case "eq", "ne":
+		for _, candidate := range f.Values {
+			if candidate == value {
+				return f.Operator == "eq"
+			}
+		}
+		return f.Operator == "ne"
+	}
+	number, ok := value.(float64)
+	if !ok || len(f.Values) == 0 {
+		return false
+	}
+	limit := f.Values[0].(float64)
+	switch f.Operator {
+	case "gt":
+		return number > limit
+	case "gte":
+		return number >= limit
+	case "lt":
+		return number < limit
+	}
+	return number <= limit
+}
+
+// column returns the column of product_attributes holding the values the filter compares.
+func (f AttributeFilter) column() string {
+	if len(f.Values) > 0 {
+		switch f.Values[0].(type) {
+		case float64:
+			return "product_attributes.number_value"
+		case bool:
+			return "product_attributes.boolean_value"
+		}
+	}
+	return "product_attributes.text_value"
+}
+
+// sqlOperators maps the operators of filters to SQL, for a single value.
+var sqlOperators = map[string]string{"eq": "IN", "ne": "NOT IN", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+
+// FilterProducts scopes db, a query of products, to those whose values of live attributes match every filter.
+func FilterProducts(db *gorm.DB, filters []AttributeFilter) *gorm.DB {
+	for _, filter := range filters {
+		value := interface{}(filter.Values)
+		if filter.Operator != "eq" && filter.Operator != "ne" {
+			value = filter.Values[0]
+		}
+		matching := db.Session(&gorm.Session{NewDB: true}).Table("product_attributes").
+			Select("product_attributes.product_id").
+			Joins("JOIN attributes ON attributes.id = product_attributes.attribute_id AND attributes.deleted_at IS NULL").
+			Where("attributes.name = ?", filter.Name).
+			Where(fmt.Sprintf("%s %s ?", filter.column(), sqlOperators[filter.Operator]), value)
+		db = db.Where("products.id IN (?)", matching)
+	}
+	return db
+}
+
+// CountProductFacets counts the products in db, a query of products, having each value of the live attributes
+// named names.
+func CountProductFacets(db *gorm.DB, names []string) ([]FacetCount, error) {
+	counts := []FacetCount{}
+	if len(names) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		Name         string
+		NumberValue  *float64
+		TextValue    *string
+		BooleanValue *bool
+		Count        int64
+	}
+	err := db.Model(&Product{}).
+		Select("attributes.name, product_attributes.number_value, product_attributes.text_value, product_attributes.boolean_value, COUNT(DISTINCT products.id) AS count").
+		Joins("JOIN product_attributes ON product_attributes.product_id = products.id").
+		Joins("JOIN attributes ON attributes.id = product_attributes.attribute_id AND attributes.deleted_at IS NULL").
+		Where("attributes.name IN ?", names).
+		Group("attributes.name, product_attributes.number_value, product_attributes.text_value, product_attributes.boolean_value").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		stored := ProductAttribute{Number: row.NumberValue, Text: row.TextValue, Boolean: row.BooleanValue}
+		counts = append(counts, FacetCount{Name: row.Name, Value: stored.value(), Count: row.Count})
+	}
+	return counts, nil
+}
+
+// BuildFacets returns the facets of attributes from counts, ordered by name, with their values in ascending order.
+// Attributes sharing a name make one facet, labelled after the first of them; facets without values are left out.
+func BuildFacets(attributes []Attribute, counts []FacetCount) []Facet {
+	byName := map[string]*Facet{}
+	for _, attribute := range attributes {
+		if byName[attribute.Name] == nil {
+			byName[attribute.Name] = &Facet{Name: attribute.Name, Label: attribute.Label, Type: attribute.Type, Unit: attribute.Unit}
+		}
+	}
+	for _, count := range counts {
+		if facet := byName[count.Name]; facet != nil && count.Value != nil {
+			facet.Values = append(facet.Values, FacetValue{Value: count.Value, Count: count.Count})
+		}
+	}
+	facets := []Facet{}
+	for _, facet := range byName {
+		if len(facet.Values) == 0 {
+			continue
+		}
+		sort.Slice(facet.Values, func(i, j int) bool { return less(facet.Values[i].Value, facet.Values[j].Value) })
+		facets = append(facets, *facet)
+	}
+	sort.Slice(facets, func(i, j int) bool { return facets[i].Name < facets[j].Name })
+	return facets
+}
+
+// less orders values of attributes: numbers and strings ascending, false before true.
+func less(a, b interface{}) bool {
+	switch a := a.(type) {
+	case float64:
+		b, ok := b.(float64)
+		return ok && a < b
+	case string:
+		b, ok := b.(string)
+		return ok && a < b
+	case bool:
+		b, ok := b.(bool)
+		return ok && !a && b
+	}
+	return false
+}
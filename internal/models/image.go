@@ -0,0 +1,202 @@
+package models
+
+import (
+	"E-Commerce_Website_Database/internal/validation"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"sort"
+)
+
+// MediaPath is the path below which the files of images are served, followed by their storage key.
+const MediaPath = "/media/"
+
+// MediaURL returns the URL path of the file stored under key, empty when there is no file.
+func MediaURL(key string) string {
+	if key == "" {
+		return ""
+	}
+	return MediaPath + key
+}
+
+// Image describes an uploaded image: the storage keys of its file and of its thumbnail, the media type sniffed from
+// its content, its size in bytes and its dimensions in pixels. Files are never overwritten, a new upload being
+// stored under new keys, so that they can be cached forever. URL and Thumbnail_URL are the paths serving the files,
+// derived from the keys by SetFiles and when the image is read.
+type Image struct {
+	File          string `gorm:"size:255" json:"-"`
+	Thumbnail     string `gorm:"size:255" json:"-"`
+	URL           string `gorm:"-" json:"url"`
+	Thumbnail_URL string `gorm:"-" json:"thumbnail_url"`
+	Content_Type  string `gorm:"size:64" json:"content_type"`
+	Size          int64  `json:"size"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+}
+
+// SetFiles sets the storage keys of the file and thumbnail of the image, and the URLs serving them.
+func (i *Image) SetFiles(file, thumbnail string) {
+	i.File, i.Thumbnail = file, thumbnail
+	i.URL, i.Thumbnail_URL = MediaURL(file), MediaURL(thumbnail)
+}
+
+// Files returns the storage keys of the file and thumbnail of the image.
+func (i *Image) Files() []string {
+	return []string{i.File, i.Thumbnail}
+}
+
+// ProductImage is an image of the gallery of a product. Images are shown in ascending Position, and a product
+// with images has exactly one primary image, the one shown in listings. Images are deleted with their product.
+type ProductImage struct {
+	gorm.Model
+	Versioned
+	Image
+	Product_ID uint   `gorm:"index" json:"product_id"`
+	Alt        string `gorm:"size:255" json:"alt"`
+	Position   int    `json:"position"`
+	Primary    bool   `gorm:"column:is_primary" json:"primary"`
+}
+
+// AfterFind derives the URLs of the files of the image from their keys.
+func (pi *ProductImage) AfterFind(tx *gorm.DB) error {
+	pi.SetFiles(pi.File, pi.Thumbnail)
+	return nil
+}
+
+// SetAlt sets the text describing the image to those who cannot see it, of at most 255 characters.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (pi *ProductImage) SetAlt(alt string) error {
+	if err := validation.Optional(alt, 255); err != nil {
+		return err
+	}
+	pi.Alt = alt
+	return nil
+}
+
+// SetPrimary makes the image the primary image of its product when primary is true. The primary image changes by
+// making another one primary, so an image cannot stop being primary otherwise.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (pi *ProductImage) SetPrimary(primary bool) error {
+	if err := validation.Kept(pi.Primary && !primary, "making another image primary"); err != nil {
+		return err
+	}
+	pi.Primary = primary
+	return nil
+}
+
+// SortProductImages sorts images by position, then by ID for images sharing a position.
+func SortProductImages(images []ProductImage) {
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].Position != images[j].Position {
+			return images[i].Position < images[j].Position
+		}
+		return images[i].ID < images[j].ID
+	})
+}
+
+// GetProductImages returns the images of the product with the given ID in their order.
+func GetProductImages(db *gorm.DB, productID uint) ([]ProductImage, error) {
+	images := []ProductImage{}
+	if err := db.Where("product_images.product_id = ?", productID).
+		Order("product_images.position, product_images.id").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
+
+// AddProductImage creates image at the end of the gallery of its product, locking the product row so that concurrent
+// additions queue up: its position follows the last image of the product. It is primary when the product has no
+// image yet, and when it was made primary the other images of the product are not any more.
+// It returns gorm.ErrRecordNotFound if the product does not exist.
+func AddProductImage(tx *gorm.DB, image *ProductImage) error {
+	var product Product
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", image.Product_ID).First(&product).Error; err != nil {
+		return err
+	}
+	var gallery struct {
+		Count int64
+		Last  *int
+	}
+	if err := tx.Model(&ProductImage{}).Select("COUNT(*) AS count, MAX(position) AS last").
+		Where("product_id = ?", image.Product_ID).Scan(&gallery).Error; err != nil {
+		return err
+	}
+	image.Position = 0
+	if gallery.Last != nil {
+		image.Position = *gallery.Last + 1
+	}
+	image.Primary = image.Primary || gallery.Count == 0
+	if err := tx.Create(image).Error; err != nil {
+		return err
+	}
+	if !image.Primary || gallery.Count == 0 {
+		return nil
+	}
+	return SetPrimaryProductImage(tx, image.Product_ID, image.ID)
+}
+
+// ReorderProductImages moves the images of the product with the given ID to their index in ids.
+// Images whose position changes get a new version.
+func ReorderProductImages(tx *gorm.DB, productID uint, ids []uint) error {
+	for position, id := range ids {
+		if err := tx.Model(&ProductImage{}).Where("id = ? AND product_id = ? AND position <> ?", id, productID, position).
+			Updates(map[string]interface{}{"position": position, "version": gorm.Expr("version + 1")}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetPrimaryProductImage makes the image with the given ID the primary image of the product with the given ID, and
+// its other images not. Images whose flag changes get a new version.
+func SetPrimaryProductImage(tx *gorm.DB, productID, id uint) error {
+	if err := tx.Model(&ProductImage{}).Where("product_id = ? AND id <> ? AND is_primary = ?", productID, id, true).
+		Updates(map[string]interface{}{"is_primary": false, "version": gorm.Expr("version + 1")}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&ProductImage{}).Where("product_id = ? AND id = ? AND is_primary = ?", productID, id, false).
+		Updates(map[string]interface{}{"is_primary": true, "version": gorm.Expr("version + 1")}).Error
+}
+
+// ImageFiles returns the storage keys of the files of the images of model found in the columns file and thumbnail,
+// including those of rows in the trash, whose files are kept until they are purged.
+func ImageFiles(db *gorm.DB, model interface{}, file, thumbnail string) ([]string, error) {
+	var rows []struct {
+		File      string
+		Thumbnail string
+	}
+	if err := db.Unscoped().Model(model).Select(file + " AS file, " + thumbnail + " AS thumbnail").
+		Where(file + " IS NOT NULL AND " + file + " <> ''").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, row := range rows {
+		files = append(files, row.File, row.Thumbnail)
+	}
+	return files, nil
+}
+
+// SearchProductImage performs a search for product images based on the provided search parameters:
+// alt matches a substring, product_id is compared exactly. It returns the matching images in their order,
+// an empty slice if none match.
+func SearchProductImage(db *gorm.DB, searchParams map[string]interface{}) ([]ProductImage, error) {
+	var images []ProductImage
+	query := db.Model(&ProductImage{})
+
+	for key, value := range searchParams {
+		switch key {
+		case "alt":
+			if strVal, ok := value.(string); ok {
+				query = query.Where("alt LIKE ?", "%"+strVal+"%")
+			}
+		case "product_id":
+			if numVal, ok := value.(int); ok {
+				query = query.Where(key+" = ?", numVal)
+			}
+		}
+	}
+
+	if err := query.Order("product_images.product_id, product_images.position, product_images.id").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	return images, nil
+}
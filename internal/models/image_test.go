@@ -0,0 +1,73 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestProductImage_Setters checks that valid values are set and that invalid ones are refused,
+// leaving the image unchanged, and that the primary image can only change by making another one primary.
+func TestProductImage_Setters(t *testing.T) {
+	pi := ProductImage{}
+
+	assert.NoError(t, pi.SetAlt("Front view"))
+	assert.Equal(t, "Front view", pi.Alt)
+	assert.Error(t, pi.SetAlt(strings.Repeat("a", 256)))
+	assert.Equal(t, "Front view", pi.Alt)
+
+	assert.NoError(t, pi.SetPrimary(false))
+	assert.NoError(t, pi.SetPrimary(true))
+	assert.True(t, pi.Primary)
+	assert.Error(t, pi.SetPrimary(false))
+	assert.True(t, pi.Primary)
+
+	pi.SetFiles("products/1/2.png", "products/1/2_thumb.png")
+	assert.Equal(t, "/media/products/1/2.png", pi.URL)
+	assert.Equal(t, "/media/products/1/2_thumb.png", pi.Thumbnail_URL)
+	assert.Equal(t, []string{"products/1/2.png", "products/1/2_thumb.png"}, pi.Files())
+}
+
+// TestProductImages checks that images are reordered and given a single primary image, each change of an image
+// giving it a new version, and that the files of images and logos in the trash are still listed.
+func TestProductImages(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "images.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&Brands{}, &Category{}, &Product{}, &ProductImage{}); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	images := make([]ProductImage, 3)
+	for i, name := range []string{"a", "b", "c"} {
+		images[i] = ProductImage{Product_ID: 1, Position: i, Primary: i == 0}
+		images[i].SetFiles("products/1/"+name+".png", "products/1/"+name+"_thumb.png")
+		db.Create(&images[i])
+	}
+
+	assert.NoError(t, ReorderProductImages(db, 1, []uint{images[2].ID, images[0].ID, images[1].ID}))
+	assert.NoError(t, SetPrimaryProductImage(db, 1, images[2].ID))
+	reloaded, err := GetProductImages(db, 1)
+	assert.NoError(t, err)
+	if assert.Len(t, reloaded, 3) {
+		assert.Equal(t, []uint{images[2].ID, images[0].ID, images[1].ID}, []uint{reloaded[0].ID, reloaded[1].ID, reloaded[2].ID})
+		assert.Equal(t, []bool{true, false, false}, []bool{reloaded[0].Primary, reloaded[1].Primary, reloaded[2].Primary})
+		assert.Equal(t, []uint{3, 3, 2}, []uint{reloaded[0].Version, reloaded[1].Version, reloaded[2].Version})
+		assert.Equal(t, "/media/products/1/c.png", reloaded[0].URL)
+	}
+
+	logo := &Image{}
+	logo.SetFiles("brands/1/logo.png", "brands/1/logo_thumb.png")
+	db.Create(&Brands{Name: "Acme", Logo: logo})
+	db.Create(&Brands{Name: "Plain"})
+	db.Delete(&ProductImage{}, images[1].ID)
+	files, err := ImageFiles(db, &ProductImage{}, "file", "thumbnail")
+	assert.NoError(t, err)
+	assert.Len(t, files, 6)
+	files, err = ImageFiles(db, &Brands{}, "logo_file", "logo_thumbnail")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"brands/1/logo.png", "brands/1/logo_thumb.png"}, files)
+}
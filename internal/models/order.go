@@ -1,19 +1,30 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"math"
 	"strings"
+	"time"
 )
 
 // Order represents the order model for transactions.
 // It includes fields like User_ID, Order_date, Total_amount, and Status, which are tagged for JSON serialization.
+// Total_amount is the sum of the subtotals of its items, kept up to date by UpdateOrderTotal.
+// Items, Payments and Shipping belong to the order and are deleted with it; they are only loaded when requested,
+// e.g. with ?include=items,payments,shipping. A user cannot be deleted while they still have orders.
 type Order struct {
 	gorm.Model
-	User_ID      uint32  `json:"user_id"`
-	Order_date   string  `json:"order_date"`
-	Total_amount float64 `json:"total_amount"`
-	Status       string  `json:"status"`
+	Versioned
+	User_ID      uint              `json:"user_id"`
+	Order_date   time.Time         `json:"order_date"`
+	Total_amount float64           `json:"total_amount"`
+	Status       string            `json:"status"`
+	User         *User             `gorm:"foreignKey:User_ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"-"`
+	Items        []OrderItem       `gorm:"foreignKey:Order_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
+	Payments     []Payment         `gorm:"foreignKey:Order_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"payments,omitempty"`
+	Shipping     []ShippingDetails `gorm:"foreignKey:Order_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"shipping,omitempty"`
 }
 
 // GetAllOrders retrieves all orders from the database.
@@ -26,52 +37,81 @@ func GetAllOrders(db *gorm.DB) ([]Order, error) {
 	return orders, nil
 }
 
-// SetUserID validates and sets the user ID of an order.
-// It returns true if the user ID is valid and set successfully, otherwise returns false.
+// SetUserID validates and sets the user ID of an order, checking with userExists that the user exists.
 // The order's user ID is updated if the check is successful.
-func (o *Order) SetUserID(user_id uint32, db *gorm.DB) bool {
-	if !UserExists(db, user_id) {
-		return false
-	} else {
-		o.User_ID = user_id
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (o *Order) SetUserID(user_id uint, userExists ExistsFunc) error {
+	if err := checkReference(user_id, userExists, "user"); err != nil {
+		return err
 	}
+	o.User_ID = user_id
+	return nil
 }
 
-// SetOrderDate validates and sets the order date.
-// It returns true if the date is valid according to predefined date checking logic, otherwise returns false.
-func (o *Order) SetOrderDate(order_date string) bool {
-	if !tools.CheckDate(order_date) {
-		return false
+// SetOrderDate validates and sets the order date, stored in UTC. It must not be in the future.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (o *Order) SetOrderDate(order_date time.Time) error {
+	if err := validation.Past(order_date); err != nil {
+		return err
 	}
-	return true
+	o.Order_date = order_date.UTC()
+	return nil
 }
 
 // SetTotalAmount validates and sets the total amount of an order.
-// It returns true if the amount is valid, otherwise returns false.
 // The order's total amount is updated if the check is successful.
-func (o *Order) SetTotalAmount(total_amount float64) bool {
-	if !tools.CheckFloat(total_amount) {
-		return false
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (o *Order) SetTotalAmount(total_amount float64) error {
+	if err := validation.NonNegativeFloat(total_amount); err != nil {
+		return err
 	}
 	o.Total_amount = total_amount
-	return true
+	return nil
+}
+
+// UpdateOrderTotal sets the total amount of the order with the given ID to the sum of the subtotals of its items not
+// in the trash, rounded to the cent, giving the order a new version when its total changes, even if the order is in
+// the trash. tx should be a transaction: the order is locked while its items are summed, so that concurrent changes
+// to its items cannot leave a stale total.
+func UpdateOrderTotal(tx *gorm.DB, id uint) error {
+	var order Order
+	if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "total_amount").Where("id = ?", id).First(&order).Error; err != nil {
+		return err
+	}
+	var subtotals []float64
+	if err := tx.Model(&OrderItem{}).Where("order_id = ?", id).Pluck("subtotal", &subtotals).Error; err != nil {
+		return err
+	}
+	total := 0.0
+	for _, subtotal := range subtotals {
+		total += subtotal
+	}
+	if total = RoundCents(total); total == order.Total_amount {
+		return nil
+	}
+	return tx.Unscoped().Model(&Order{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"total_amount": total, "version": gorm.Expr("version + 1")}).Error
+}
+
+// RoundCents rounds an amount of money to the cent.
+func RoundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
 }
 
 // SetStatus validates and sets the status of an order.
-// It returns true if the status is valid according to predefined status checking logic, otherwise returns false.
 // The order's status is updated if the check is successful.
-func (o *Order) SetStatus(status string) bool {
-	if !tools.CheckStatus(status, 255) {
-		return false
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (o *Order) SetStatus(status string) error {
+	if err := validation.Status(status); err != nil {
+		return err
 	}
 	o.Status = status
-	return true
+	return nil
 }
 
 // OrderExists checks if an order exists in the database by its ID.
 // It returns true if the order is found, otherwise returns false.
-func OrderExists(db *gorm.DB, id uint32) bool {
+func OrderExists(db *gorm.DB, id uint) bool {
 	var order Order
 	if db.Where("id = ?", id).First(&order).Error != nil {
 		return false
@@ -94,8 +134,9 @@ func SearchOrder(db *gorm.DB, searchParams map[string]interface{}) ([]Order, err
 				query = query.Where(key+" = ?", numVal)
 			}
 		case "order_date":
-			if isString {
-				query = query.Where(key+" = ?", valueStr)
+			// A date matches every time of that day in UTC
+			if day, ok := value.(time.Time); ok {
+				query = query.Where(key+" >= ? AND "+key+" < ?", day, day.AddDate(0, 0, 1))
 			}
 		case "total_amount":
 			// For numeric fields
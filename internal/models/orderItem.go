@@ -1,18 +1,26 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
 )
 
 // OrderItem represents the order item model for an e-commerce transaction.
 // It includes foreign keys to Order and Product, as well as Quantity and Subtotal to detail the item specifics.
+// Subtotal is the unit price of the product or variant ordered times the quantity, set by SetPrice.
+// Variant_ID selects the variant ordered, and is required for a product that has variants.
+// Product and Variant are only loaded when requested, e.g. with ?include=product,variant; a product or a variant
+// cannot be deleted while it is ordered.
 type OrderItem struct {
 	gorm.Model
-	Order_ID   uint32  `json:"order_id"`
-	Product_ID uint32  `json:"product_id"`
-	Quantity   int     `json:"quantity"`
-	Subtotal   float64 `json:"subtotal"`
+	Versioned
+	Order_ID   uint            `json:"order_id"`
+	Product_ID uint            `json:"product_id"`
+	Variant_ID *uint           `json:"variant_id"`
+	Quantity   int             `json:"quantity"`
+	Subtotal   float64         `json:"subtotal"`
+	Product    *Product        `gorm:"foreignKey:Product_ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"product,omitempty"`
+	Variant    *ProductVariant `gorm:"foreignKey:Variant_ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"variant,omitempty"`
 }
 
 // GetAllOrderItems retrieves all order items from the database.
@@ -27,55 +35,96 @@ func GetAllOrderItems(db *gorm.DB) ([]OrderItem, error) {
 }
 
 // SetOrderID validates and sets the Order_ID for an order item, ensuring the order exists.
-// It returns true if the order exists and the ID is successfully set; otherwise, it returns false.
-// The order ID is validated by checking if the order exists in the database.
-func (oi *OrderItem) SetOrderID(order_id uint32, db *gorm.DB) bool {
-	if !OrderExists(db, order_id) {
-		return false
-	} else {
-		oi.Order_ID = order_id
-		return true
+// The order ID is validated by checking with orderExists that the order exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (oi *OrderItem) SetOrderID(order_id uint, orderExists ExistsFunc) error {
+	if err := checkReference(order_id, orderExists, "order"); err != nil {
+		return err
 	}
+	oi.Order_ID = order_id
+	return nil
 }
 
 // SetProductID validates and sets the Product_ID for an order item, ensuring the product exists.
-// It returns true if the product exists and the ID is successfully set; otherwise, it returns false.
-// The product ID is validated by checking if the product exists in the database.
-func (oi *OrderItem) SetProductID(product_id uint32, db *gorm.DB) bool {
-	if !ProductExists(db, product_id) {
-		return false
-	} else {
-		oi.Product_ID = product_id
-		return true
+// The product ID is validated by checking with productExists that the product exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (oi *OrderItem) SetProductID(product_id uint, productExists ExistsFunc) error {
+	if err := checkReference(product_id, productExists, "product"); err != nil {
+		return err
 	}
+	oi.Product_ID = product_id
+	return nil
+}
+
+// SetVariantID validates and sets the variant ordered, found with variantOf, which must be a variant of the ordered
+// product. variants are the variants of the ordered product: when it has some, the variant is required.
+// variantOf returns nil without error when the variant does not exist.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid, and a
+// *validation.Failure if the variant could not be looked up.
+func (oi *OrderItem) SetVariantID(variant_id *uint, variantOf func(id uint) (*ProductVariant, error), variants []ProductVariant) error {
+	if err := validation.Selected(variant_id != nil, len(variants) > 0, "variant of the product"); err != nil {
+		return err
+	}
+	if variant_id == nil {
+		oi.Variant_ID = nil
+		return nil
+	}
+	variant, err := variantOf(*variant_id)
+	if err != nil {
+		return validation.Failed(err)
+	}
+	if err := validation.Exists(variant != nil, "variant"); err != nil {
+		return err
+	}
+	if err := validation.BelongsTo(variant.Product_ID == oi.Product_ID, "ordered product"); err != nil {
+		return err
+	}
+	oi.Variant_ID = variant_id
+	return nil
 }
 
 // SetQuantity validates and sets the quantity of an order item.
-// It ensures the quantity is a positive integer before setting. Returns true if valid; otherwise false.
+// It ensures the quantity is a positive integer before setting.
 // The quantity is set if it is a positive integer.
-func (oi *OrderItem) SetQuantity(quantity int) bool {
-	if !tools.CheckInt(quantity) {
-		return false
-	} else {
-		oi.Quantity = quantity
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (oi *OrderItem) SetQuantity(quantity int) error {
+	if err := validation.NonNegativeInt(quantity); err != nil {
+		return err
 	}
+	oi.Quantity = quantity
+	return nil
+}
+
+// SetPrice prices the item from product, and from variant when one is ordered: its subtotal becomes the unit price
+// times the quantity, rounded to the cent. The quantity must not exceed the stock of the variant, or of the product
+// when no variant is ordered.
+// It returns a *validation.FieldError of the quantity, leaving the subtotal unchanged, if more is ordered than in stock.
+func (oi *OrderItem) SetPrice(product *Product, variant *ProductVariant) error {
+	price, stock := product.Price, product.Stock_quantity
+	if variant != nil {
+		price, stock = variant.UnitPrice(product), variant.Stock_quantity
+	}
+	if err := validation.InStock(oi.Quantity, stock); err != nil {
+		return err
+	}
+	oi.Subtotal = RoundCents(price * float64(oi.Quantity))
+	return nil
 }
 
 // SetSubtotal validates and sets the subtotal for an order item.
-// It ensures the subtotal is a positive float before setting. Returns true if valid; otherwise false.
-func (oi *OrderItem) SetSubtotal(subtotal float64) bool {
-	if !tools.CheckFloat(subtotal) {
-		return false
-	} else {
-		oi.Subtotal = subtotal
-		return true
+// It ensures the subtotal is a positive float before setting.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (oi *OrderItem) SetSubtotal(subtotal float64) error {
+	if err := validation.NonNegativeFloat(subtotal); err != nil {
+		return err
 	}
+	oi.Subtotal = subtotal
+	return nil
 }
 
 // OrderItemExists checks if an order item exists in the database by its ID.
 // It returns true if the order item is found, otherwise returns false.
-func OrderItemExists(db *gorm.DB, id uint32) bool {
+func OrderItemExists(db *gorm.DB, id uint) bool {
 	var orderItem OrderItem
 	if db.Where("id = ?", id).First(&orderItem).Error != nil {
 		return false
@@ -93,7 +142,7 @@ func SearchOrderItem(db *gorm.DB, searchParams map[string]interface{}) ([]OrderI
 
 	for key, value := range searchParams {
 		switch key {
-		case "order_id", "product_id":
+		case "order_id", "product_id", "variant_id":
 			if numVal, ok := value.(int); ok {
 				query = query.Where(key+" = ?", numVal)
 			}
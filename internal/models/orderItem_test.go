@@ -32,8 +32,8 @@ func TestGetAllOrderItems(t *testing.T) {
 	orderItem, err := GetAllOrderItems(gormDB)
 	assert.NoError(t, err)
 	assert.Len(t, orderItem, 2, "Should fetch two order item")
-	assert.Equal(t, uint32(1), orderItem[0].Order_ID, "Check Order ID of the first order item")
-	assert.Equal(t, uint32(1), orderItem[0].Product_ID, "Check Product ID of the first order item")
+	assert.Equal(t, uint(1), orderItem[0].Order_ID, "Check Order ID of the first order item")
+	assert.Equal(t, uint(1), orderItem[0].Product_ID, "Check Product ID of the first order item")
 	assert.Equal(t, 5, orderItem[0].Quantity, "Check quantity of the first order item")
 	assert.Equal(t, float64(100), orderItem[0].Subtotal, "Check subtotal of the first order item")
 }
@@ -54,13 +54,13 @@ func TestOrderItem_SetOrderID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	orderItem := OrderItem{}
-	result := orderItem.SetOrderID(1, gormDB)
-	assert.True(t, result)
+	result := orderItem.SetOrderID(1, func(id uint) (bool, error) { return OrderExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\" WHERE").WithArgs(99, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = orderItem.SetOrderID(99, gormDB)
-	assert.False(t, result)
+	result = orderItem.SetOrderID(99, func(id uint) (bool, error) { return OrderExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestOrderItem_SetProductID ensures that the product id are set only if the referenced entity exits.
@@ -79,33 +79,52 @@ func TestOrderItem_SetProductID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"products\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	orderItem := OrderItem{}
-	result := orderItem.SetProductID(1, gormDB)
-	assert.True(t, result)
+	result := orderItem.SetProductID(1, func(id uint) (bool, error) { return ProductExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"products\" WHERE").WithArgs(99, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = orderItem.SetProductID(99, gormDB)
-	assert.False(t, result)
+	result = orderItem.SetProductID(99, func(id uint) (bool, error) { return ProductExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestOrderItem_SetQuantity validate and set quantity
 // It creates a new instance of the OrderItem struct and calls the SetQuantity function with a valid quantity.
-// It then checks if the quantity was set correctly and if the function returned true.
-// It repeats the process with an invalid quantity and checks if the quantity was not set and the function returned false.
+// It then checks if the quantity was set correctly and if the function returned no error.
+// It repeats the process with an invalid quantity and checks if the quantity was not set and the function returned an error.
 func TestOrderItem_SetQuantity(t *testing.T) {
 	orderItem := OrderItem{}
-	assert.False(t, orderItem.SetQuantity(-1))
-	assert.True(t, orderItem.SetQuantity(10))
+	assert.Error(t, orderItem.SetQuantity(-1))
+	assert.NoError(t, orderItem.SetQuantity(10))
 }
 
 // TestOrderItem_SetSubtotal validate and set subtotal
 // It creates a new instance of the OrderItem struct and calls the SetSubtotal function with a valid subtotal.
-// It then checks if the subtotal was set correctly and if the function returned true.
-// It repeats the process with an invalid subtotal and checks if the subtotal was not set and the function returned false.
+// It then checks if the subtotal was set correctly and if the function returned no error.
+// It repeats the process with an invalid subtotal and checks if the subtotal was not set and the function returned an error.
 func TestOrderItem_SetSubtotal(t *testing.T) {
 	orderItem := OrderItem{}
-	assert.False(t, orderItem.SetSubtotal(-100.0))
-	assert.True(t, orderItem.SetSubtotal(200.0))
+	assert.Error(t, orderItem.SetSubtotal(-100.0))
+	assert.NoError(t, orderItem.SetSubtotal(200.0))
+}
+
+// TestOrderItem_SetPrice checks that the subtotal is the price of the product, or of the variant when one is ordered,
+// times the quantity, and that ordering more than the stock of the product or variant is an error.
+func TestOrderItem_SetPrice(t *testing.T) {
+	product := &Product{Price: 19.99, Stock_quantity: 3}
+	price := 24.5
+	variant := &ProductVariant{Price: &price, Stock_quantity: 1}
+
+	orderItem := OrderItem{Quantity: 3}
+	assert.NoError(t, orderItem.SetPrice(product, nil))
+	assert.Equal(t, 59.97, orderItem.Subtotal)
+	assert.Error(t, orderItem.SetPrice(product, variant))
+	assert.Equal(t, 59.97, orderItem.Subtotal, "the subtotal is unchanged")
+	orderItem.Quantity = 1
+	assert.NoError(t, orderItem.SetPrice(product, variant))
+	assert.Equal(t, 24.5, orderItem.Subtotal)
+	assert.NoError(t, orderItem.SetPrice(product, &ProductVariant{Stock_quantity: 1}))
+	assert.Equal(t, 19.99, orderItem.Subtotal, "a variant without a price is sold at the price of the product")
 }
 
 // TestOrderItemExists checks if an order item exists by its ID
@@ -153,8 +172,8 @@ func TestSearchOrderItem(t *testing.T) {
 	orderItems, err := SearchOrderItem(gormDB, searchParams)
 	assert.NoError(t, err)
 	assert.Len(t, orderItems, 1, "Should find one order item")
-	assert.Equal(t, uint32(1), orderItems[0].Order_ID)
-	assert.Equal(t, uint32(1), orderItems[0].Product_ID)
+	assert.Equal(t, uint(1), orderItems[0].Order_ID)
+	assert.Equal(t, uint(1), orderItems[0].Product_ID)
 	assert.Equal(t, 5, orderItems[0].Quantity)
 	assert.Equal(t, float64(100), orderItems[0].Subtotal)
 }
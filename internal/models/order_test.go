@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 // TestGetAllOrders checks at this function returns all records correctly.
@@ -23,16 +24,16 @@ func TestGetAllOrders(t *testing.T) {
 
 	// Setup expectations
 	rows := sqlmock.NewRows([]string{"id", "user_id", "order_date", "total_amount", "status"}).
-		AddRow(1, 1, "2023-01-01", 100.0, "pending").
-		AddRow(2, 2, "2023-01-02", 200.0, "completed")
+		AddRow(1, 1, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 100.0, "pending").
+		AddRow(2, 2, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), 200.0, "completed")
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\"").WillReturnRows(rows)
 
 	// Call the function now
 	orders, err := GetAllOrders(gormDB)
 	assert.NoError(t, err)
 	assert.Len(t, orders, 2, "Should fetch two orders")
-	assert.Equal(t, uint32(1), orders[0].User_ID, "Check user ID of the first order")
-	assert.Equal(t, "2023-01-01", orders[0].Order_date, "Check Order date of the first order")
+	assert.Equal(t, uint(1), orders[0].User_ID, "Check user ID of the first order")
+	assert.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), orders[0].Order_date, "Check Order date of the first order")
 	assert.Equal(t, float64(100), orders[0].Total_amount, "Check total amount of the first order")
 	assert.Equal(t, "pending", orders[0].Status, "Check status of the first order")
 }
@@ -57,44 +58,45 @@ func TestOrder_SetUserID(t *testing.T) {
 
 	// Call the function now
 	order := Order{}
-	result := order.SetUserID(1, gormDB)
-	assert.True(t, result, "User ID should be set when user exists")
+	result := order.SetUserID(1, func(id uint) (bool, error) { return UserExists(gormDB, id), nil })
+	assert.NoError(t, result, "User ID should be set when user exists")
 
 	// Not exist user
 	mock.ExpectQuery("^SELECT \\* FROM \"users\" WHERE").WithArgs(50, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = order.SetUserID(50, gormDB)
-	assert.False(t, result, "User ID should not be set when user dose not exist")
+	result = order.SetUserID(50, func(id uint) (bool, error) { return UserExists(gormDB, id), nil })
+	assert.Error(t, result, "User ID should not be set when user dose not exist")
 }
 
 // TestOrder_SetOrderDate checks if this function works correctly.
 // It creates a new instance of the Order struct and calls the SetOrderDate function with a valid date.
-// It then checks if the date was set correctly and if the function returned true.
-// It repeats the process with an invalid date and checks if the date was not set and the function returned false.
+// It then checks if the date was set correctly and if the function returned no error.
+// It repeats the process with an invalid date and checks if the date was not set and the function returned an error.
 func TestOrder_SetOrderDate(t *testing.T) {
 	order := Order{}
-	assert.True(t, order.SetOrderDate("2023-01-01"), "Order date should be valid")
-	assert.False(t, order.SetOrderDate("23-01-2023"), "Order date should be invalid")
+	assert.NoError(t, order.SetOrderDate(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)), "Order date should be valid")
+	assert.Error(t, order.SetOrderDate(time.Now().AddDate(0, 0, 1)), "Order date should not be in the future")
+	assert.Error(t, order.SetOrderDate(time.Time{}), "Order date should be set")
 }
 
 // TestOrder_SetTotalAmount checks if this function works correctly.
 // It creates a new instance of the Order struct and calls the SetTotalAmount function with a valid amount.
-// It then checks if the amount was set correctly and if the function returned true.
-// It repeats the process with an invalid amount and checks if the amount was not set and the function returned false.
+// It then checks if the amount was set correctly and if the function returned no error.
+// It repeats the process with an invalid amount and checks if the amount was not set and the function returned an error.
 func TestOrder_SetTotalAmount(t *testing.T) {
 	order := Order{}
-	assert.True(t, order.SetTotalAmount(100.0), "Total amount should be valid")
-	assert.False(t, order.SetTotalAmount(-1.0), "Total amount should not be set if negative")
+	assert.NoError(t, order.SetTotalAmount(100.0), "Total amount should be valid")
+	assert.Error(t, order.SetTotalAmount(-1.0), "Total amount should not be set if negative")
 }
 
 // TestOrder_SetStatus checks if this function works correctly.
 // It creates a new instance of the Order struct and calls the SetStatus function with a valid status.
-// It then checks if the status was set correctly and if the function returned true.
-// It repeats the process with an invalid status and checks if the status was not set and the function returned false.
+// It then checks if the status was set correctly and if the function returned no error.
+// It repeats the process with an invalid status and checks if the status was not set and the function returned an error.
 func TestOrder_SetStatus(t *testing.T) {
 	order := Order{}
-	assert.True(t, order.SetStatus("pending"), "Status date should be valid")
-	assert.False(t, order.SetStatus("unknown"), "Status date should be invalid")
+	assert.NoError(t, order.SetStatus("pending"), "Status date should be valid")
+	assert.Error(t, order.SetStatus("unknown"), "Status date should be invalid")
 }
 
 // TestOrderExists Checks if the function works correctly.
@@ -141,15 +143,15 @@ func TestSearchOrder(t *testing.T) {
 
 	// Setup expectations
 	rows := sqlmock.NewRows([]string{"id", "user_id", "order_date", "total_amount", "status"}).
-		AddRow(1, 1, "2023-01-01", 100.0, "pending").
-		AddRow(2, 2, "2023-01-02", 200.0, "completed")
+		AddRow(1, 1, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 100.0, "pending").
+		AddRow(2, 2, time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), 200.0, "completed")
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\"").WithArgs(1).WillReturnRows(rows)
 
 	// Call the function now
 	orders, err := SearchOrder(gormDB, map[string]interface{}{"user_id": 1})
 	assert.NoError(t, err)
 	assert.Len(t, orders, 2, "Should find tow order")
-	assert.Equal(t, uint32(1), orders[0].User_ID)
+	assert.Equal(t, uint(1), orders[0].User_ID)
 
 	// Check all expectations
 	assert.NoError(t, mock.ExpectationsWereMet())
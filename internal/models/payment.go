@@ -1,20 +1,22 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
 	"strings"
+	"time"
 )
 
 // Payment represents the payment model associated with an order.
 // It includes fields for the order ID, payment method, amount, payment date, and status, all of which include JSON serialization tags.
 type Payment struct {
 	gorm.Model
-	Order_ID       uint32  `json:"order_id"`
-	Payment_method string  `json:"payment_method"`
-	Amount         float64 `json:"amount"`
-	Payment_date   string  `json:"payment_date"`
-	Status         string  `json:"status"`
+	Versioned
+	Order_ID       uint      `json:"order_id"`
+	Payment_method string    `json:"payment_method"`
+	Amount         float64   `json:"amount"`
+	Payment_date   time.Time `json:"payment_date"`
+	Status         string    `json:"status"`
 }
 
 // GetAllPayments retrieves all payments from the database.
@@ -27,64 +29,59 @@ func GetAllPayments(db *gorm.DB) ([]Payment, error) {
 	return payments, nil
 }
 
-// SetOrderID sets the order ID for the payment after verifying the existence of the order.
-// Returns true if the order exists and the ID is set; otherwise, it returns false.
-func (p *Payment) SetOrderID(order_id uint32, db *gorm.DB) bool {
-	if !OrderExists(db, order_id) {
-		return false
-	} else {
-		p.Order_ID = order_id
-		return true
+// SetOrderID sets the order ID for the payment after verifying with orderExists that the order exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Payment) SetOrderID(order_id uint, orderExists ExistsFunc) error {
+	if err := checkReference(order_id, orderExists, "order"); err != nil {
+		return err
 	}
+	p.Order_ID = order_id
+	return nil
 }
 
 // SetPaymentMethod sets the payment method for the payment.
-// It validates the payment method to ensure it meets certain criteria (not implemented here) and returns true if valid.
-func (p *Payment) SetPaymentMethod(payment_method string) bool {
-	if !tools.CheckPaymentMethod(payment_method) {
-		return false
-	} else {
-		p.Payment_method = payment_method
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Payment) SetPaymentMethod(payment_method string) error {
+	if err := validation.PaymentMethod(payment_method); err != nil {
+		return err
 	}
+	p.Payment_method = payment_method
+	return nil
 }
 
 // SetAmount sets the amount of the payment.
-// It ensures the amount is valid as a float value and returns true if so; otherwise, it returns false.
-func (p *Payment) SetAmount(amount float64) bool {
-	if !tools.CheckFloat(amount) {
-		return false
-	} else {
-		p.Amount = amount
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Payment) SetAmount(amount float64) error {
+	if err := validation.NonNegativeFloat(amount); err != nil {
+		return err
 	}
+	p.Amount = amount
+	return nil
 }
 
-// SetPaymentDate sets the date of the payment.
-// It validates the date format and returns true if the date is valid; otherwise, it returns false.
-func (p *Payment) SetPaymentDate(payment_date string) bool {
-	if !tools.CheckDate(payment_date) {
-		return false
-	} else {
-		p.Payment_date = payment_date
-		return true
+// SetPaymentDate sets the date of the payment, stored in UTC. It must not be in the future.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Payment) SetPaymentDate(payment_date time.Time) error {
+	if err := validation.Past(payment_date); err != nil {
+		return err
 	}
+	p.Payment_date = payment_date.UTC()
+	return nil
 }
 
 // SetStatus sets the status of the payment.
-// It validates the status based on predefined criteria and returns true if the status is valid; otherwise, it returns false.
-func (p *Payment) SetStatus(status string) bool {
-	if !tools.CheckStatus(status, 255) {
-		return false
-	} else {
-		p.Status = status
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Payment) SetStatus(status string) error {
+	if err := validation.Status(status); err != nil {
+		return err
 	}
+	p.Status = status
+	return nil
 }
 
 // PaymentExists checks if a payment exists in the database by its ID.
 // It returns true if the payment is found, otherwise returns false.
-func PaymentExists(db *gorm.DB, id uint32) bool {
+func PaymentExists(db *gorm.DB, id uint) bool {
 	var payment Payment
 	if db.Where("id = ?", id).First(&payment).Error != nil {
 		return false
@@ -120,8 +117,9 @@ func SearchPayment(db *gorm.DB, searchParams map[string]interface{}) ([]Payment,
 			}
 
 		case "payment_date":
-			if isString {
-				query = query.Where(key+" = ?", valueStr)
+			// A date matches every time of that day in UTC
+			if day, ok := value.(time.Time); ok {
+				query = query.Where(key+" >= ? AND "+key+" < ?", day, day.AddDate(0, 0, 1))
 			}
 		}
 	}
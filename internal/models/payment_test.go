@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 // TestGetAllPayments ensures that all payments are retrieved correctly.
@@ -21,8 +22,8 @@ func TestGetAllPayments(t *testing.T) {
 	assert.NoError(t, err)
 
 	rows := sqlmock.NewRows([]string{"id", "order_id", "payment_method", "amount", "payment_date", "status"}).
-		AddRow(1, 1, "Credit Card", 100.0, "2021-04-21", "Completed").
-		AddRow(2, 2, "PayPal", 200.0, "2021-04-22", "Pending")
+		AddRow(1, 1, "Credit Card", 100.0, time.Date(2021, 4, 21, 0, 0, 0, 0, time.UTC), "Completed").
+		AddRow(2, 2, "PayPal", 200.0, time.Date(2021, 4, 22, 0, 0, 0, 0, time.UTC), "Pending")
 	mock.ExpectQuery("^SELECT \\* FROM \"payments\"").WillReturnRows(rows)
 
 	payments, err := GetAllPayments(gormDB)
@@ -45,53 +46,54 @@ func TestPayment_SetOrderID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	payment := Payment{}
-	result := payment.SetOrderID(1, gormDB)
-	assert.True(t, result)
+	result := payment.SetOrderID(1, func(id uint) (bool, error) { return OrderExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\" WHERE").WithArgs(99, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = payment.SetOrderID(99, gormDB)
-	assert.False(t, result)
+	result = payment.SetOrderID(99, func(id uint) (bool, error) { return OrderExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestPayment_SetPaymentMethod checks that the payment method is set only if it is valid.
 // It creates a new instance of the Payment struct and calls the SetPaymentMethod function with a valid method.
-// It then checks if the method was set correctly and if the function returned true.
-// It repeats the process with an invalid method and checks if the method was not set and the function returned false.
+// It then checks if the method was set correctly and if the function returned no error.
+// It repeats the process with an invalid method and checks if the method was not set and the function returned an error.
 func TestPayment_SetPaymentMethod(t *testing.T) {
 	payment := Payment{}
-	assert.False(t, payment.SetPaymentMethod("Invalid Method"))
-	assert.True(t, payment.SetPaymentMethod("Credit Card"))
+	assert.Error(t, payment.SetPaymentMethod("Invalid Method"))
+	assert.NoError(t, payment.SetPaymentMethod("Credit Card"))
 }
 
 // TestPayment_SetAmount checks the validity and setting of the payment amount.
 // It creates a new instance of the Payment struct and calls the SetAmount function with a valid amount.
-// It then checks if the amount was set correctly and if the function returned true.
-// It repeats the process with an invalid amount and checks if the amount was not set and the function returned false.
+// It then checks if the amount was set correctly and if the function returned no error.
+// It repeats the process with an invalid amount and checks if the amount was not set and the function returned an error.
 func TestPayment_SetAmount(t *testing.T) {
 	payment := Payment{}
-	assert.False(t, payment.SetAmount(-100.0))
-	assert.True(t, payment.SetAmount(100.0))
+	assert.Error(t, payment.SetAmount(-100.0))
+	assert.NoError(t, payment.SetAmount(100.0))
 }
 
 // TestPayment_SetPaymentDate checks the date setting and validation logic.
 // It creates a new instance of the Payment struct and calls the SetPaymentDate function with a valid date.
-// It then checks if the date was set correctly and if the function returned true.
-// It repeats the process with an invalid date and checks if the date was not set and the function returned false.
+// It then checks if the date was set correctly and if the function returned no error.
+// It repeats the process with an invalid date and checks if the date was not set and the function returned an error.
 func TestPayment_SetPaymentDate(t *testing.T) {
 	payment := Payment{}
-	assert.False(t, payment.SetPaymentDate("not-a-date"))
-	assert.True(t, payment.SetPaymentDate("2021-04-21"))
+	assert.Error(t, payment.SetPaymentDate(time.Time{}))
+	assert.Error(t, payment.SetPaymentDate(time.Now().Add(time.Hour)))
+	assert.NoError(t, payment.SetPaymentDate(time.Date(2021, 4, 21, 0, 0, 0, 0, time.UTC)))
 }
 
 // TestPayment_SetStatus checks the status setting and validation logic.
 // It creates a new instance of the Payment struct and calls the SetStatus function with a valid status.
-// It then checks if the status was set correctly and if the function returned true.
-// It repeats the process with an invalid status and checks if the status was not set and the function returned false.
+// It then checks if the status was set correctly and if the function returned no error.
+// It repeats the process with an invalid status and checks if the status was not set and the function returned an error.
 func TestPayment_SetStatus(t *testing.T) {
 	payment := Payment{}
-	assert.False(t, payment.SetStatus("unknown"), "Status should be invalid")
-	assert.True(t, payment.SetStatus("completed"), "Expected 'completed' to be a valid status")
+	assert.Error(t, payment.SetStatus("unknown"), "Status should be invalid")
+	assert.NoError(t, payment.SetStatus("completed"), "Expected 'completed' to be a valid status")
 
 }
 
@@ -129,7 +131,7 @@ func TestSearchPayment(t *testing.T) {
 	assert.NoError(t, err)
 
 	rows := sqlmock.NewRows([]string{"id", "order_id", "payment_method", "amount", "payment_date", "status"}).
-		AddRow(1, 1, "Credit Card", 100.0, "2021-04-21", "Completed")
+		AddRow(1, 1, "Credit Card", 100.0, time.Date(2021, 4, 21, 0, 0, 0, 0, time.UTC), "Completed")
 	mock.ExpectQuery("^SELECT \\* FROM \"payments\" WHERE").
 		WithArgs("%credit card%").
 		WillReturnRows(rows)
@@ -1,21 +1,50 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"fmt"
 	"gorm.io/gorm"
 )
 
 // Product represents the product entity with properties such as name, description,
 // price, stock quantity, and associations with brand and category.
+// Brand, Category, Variants, Attributes and Images are only loaded when requested, e.g. with ?include=brand,variants,attributes;
+// loading the variants also fills Options with the values of each of their options, the variant matrix,
+// Attributes is the specification sheet of the product, the values of its attributes, and Images is its gallery
+// in order.
+// A brand or category cannot be deleted while products still reference it, and variants, values and images are
+// deleted with their product.
 type Product struct {
 	gorm.Model
-	Name           string  `json:"name"`
-	Description    string  `json:"description"`
-	Price          float64 `json:"price"`
-	Stock_quantity int     `json:"stock_quantity"`
-	Brand_ID       uint32  `json:"brand_id"`
-	Category_ID    uint32  `json:"category_id"`
+	Versioned
+	Name           string              `json:"name"`
+	Description    string              `json:"description"`
+	Price          float64             `json:"price"`
+	Stock_quantity int                 `json:"stock_quantity"`
+	Brand_ID       uint                `json:"brand_id"`
+	Category_ID    uint                `json:"category_id"`
+	Brand          *Brands             `gorm:"foreignKey:Brand_ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"brand,omitempty"`
+	Category       *Category           `gorm:"foreignKey:Category_ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT" json:"category,omitempty"`
+	Variants       []ProductVariant    `gorm:"foreignKey:Product_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"variants,omitempty"`
+	Options        map[string][]string `gorm:"-" json:"options,omitempty"`
+	Attributes     []ProductAttribute  `gorm:"foreignKey:Product_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"attributes,omitempty"`
+	Images         []ProductImage      `gorm:"foreignKey:Product_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"images,omitempty"`
+}
+
+// AfterFind derives the variant matrix of the product from its variants, once they were loaded,
+// leaves out the values of attributes in the trash, which are loaded without their attribute,
+// and puts the images of the gallery in order.
+func (p *Product) AfterFind(tx *gorm.DB) error {
+	p.Options = VariantOptions(p.Variants)
+	SortProductImages(p.Images)
+	live := p.Attributes[:0]
+	for _, value := range p.Attributes {
+		if value.Attribute != nil {
+			live = append(live, value)
+		}
+	}
+	p.Attributes = live
+	return nil
 }
 
 // GetAllProducts retrieves all products from the database.
@@ -29,74 +58,68 @@ func GetAllProducts(db *gorm.DB) ([]Product, error) {
 }
 
 // SetName sets the name of the product after validating its length.
-// Returns true if the name is within the allowed length, otherwise false.
-func (p *Product) SetName(name string) bool {
-	if !tools.CheckString(name, 255) {
-		return false
-	} else {
-		p.Name = name
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Product) SetName(name string) error {
+	if err := validation.String(name, 255); err != nil {
+		return err
 	}
+	p.Name = name
+	return nil
 }
 
 // SetDescription sets the product's description after validating its length.
-// Returns true if the description is within the allowed length, otherwise false.
-func (p *Product) SetDescription(description string) bool {
-	if !tools.CheckString(description, 1000) {
-		return false
-	} else {
-		p.Description = description
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Product) SetDescription(description string) error {
+	if err := validation.String(description, 1000); err != nil {
+		return err
 	}
+	p.Description = description
+	return nil
 }
 
 // SetPrice sets the price of the product after validating it as a positive float.
-// Returns true if the price is valid, otherwise false.
-func (p *Product) SetPrice(price float64) bool {
-	if !tools.CheckFloat(price) {
-		return false
-	} else {
-		p.Price = price
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Product) SetPrice(price float64) error {
+	if err := validation.NonNegativeFloat(price); err != nil {
+		return err
 	}
+	p.Price = price
+	return nil
 }
 
 // SetStockQuantity sets the stock quantity of the product after validating it as a non-negative integer.
-// Returns true if the stock quantity is valid, otherwise false.
-func (p *Product) SetStockQuantity(stock_quantity int) bool {
-	if !tools.CheckInt(stock_quantity) {
-		return false
-	} else {
-		p.Stock_quantity = stock_quantity
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Product) SetStockQuantity(stock_quantity int) error {
+	if err := validation.NonNegativeInt(stock_quantity); err != nil {
+		return err
 	}
+	p.Stock_quantity = stock_quantity
+	return nil
 }
 
-// SetBrandID sets the brand ID of the product, verifying the existence of the brand.
-// Returns true if the brand exists, otherwise false.
-func (p *Product) SetBrandID(brand_id uint32, db *gorm.DB) bool {
-	if !BrandExists(db, brand_id) {
-		return false
-	} else {
-		p.Brand_ID = brand_id
-		return true
+// SetBrandID sets the brand ID of the product, verifying with brandExists that the brand exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Product) SetBrandID(brand_id uint, brandExists ExistsFunc) error {
+	if err := checkReference(brand_id, brandExists, "brand"); err != nil {
+		return err
 	}
+	p.Brand_ID = brand_id
+	return nil
 }
 
-// SetCategoryID sets the category ID of the product, verifying the existence of the category.
-// Returns true if the category exists, otherwise false.
-func (p *Product) SetCategoryID(category_id uint32, db *gorm.DB) bool {
-	if !CategoryExists(db, category_id) {
-		return false
-	} else {
-		p.Category_ID = category_id
-		return true
+// SetCategoryID sets the category ID of the product, verifying with categoryExists that the category exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (p *Product) SetCategoryID(category_id uint, categoryExists ExistsFunc) error {
+	if err := checkReference(category_id, categoryExists, "category"); err != nil {
+		return err
 	}
+	p.Category_ID = category_id
+	return nil
 }
 
 // ProductExists checks if a specific product exists in the database by its ID.
 // Returns true if the product exists, otherwise false.
-func ProductExists(db *gorm.DB, id uint32) bool {
+func ProductExists(db *gorm.DB, id uint) bool {
 	var product Product
 	if db.First(&product, id).Error != nil {
 		return false
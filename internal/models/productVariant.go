@@ -0,0 +1,159 @@
+package models
+
+import (
+	"E-Commerce_Website_Database/internal/validation"
+	"gorm.io/gorm"
+	"reflect"
+	"sort"
+)
+
+// ProductVariant represents a version of a product sold under its own SKU, such as the black phone with 256GB of
+// storage. Options holds its option values by option name, e.g. {"color": "black", "storage": "256GB"}, and every
+// variant of a product has the same option names with a different combination of values.
+// Price overrides the price of the product when set, and each variant counts its own stock.
+// Variants are deleted with their product, and a variant cannot be deleted while it is ordered.
+type ProductVariant struct {
+	gorm.Model
+	Versioned
+	Product_ID     uint              `json:"product_id"`
+	SKU            string            `gorm:"column:sku;size:64;uniqueIndex" json:"sku"`
+	Options        map[string]string `gorm:"type:text;serializer:json" json:"options"`
+	Price          *float64          `json:"price"`
+	Stock_quantity int               `json:"stock_quantity"`
+}
+
+// SetProductID sets the product of the variant, verifying with productExists that the product exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (v *ProductVariant) SetProductID(product_id uint, productExists ExistsFunc) error {
+	if err := checkReference(product_id, productExists, "product"); err != nil {
+		return err
+	}
+	v.Product_ID = product_id
+	return nil
+}
+
+// SetSKU sets the stock keeping unit of the variant after validating its format and length of at most 64 characters.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (v *ProductVariant) SetSKU(sku string) error {
+	if err := validation.SKU(sku, 64); err != nil {
+		return err
+	}
+	v.SKU = sku
+	return nil
+}
+
+// SetOptions sets the option values of the variant. siblings are the other variants of its product: the options must
+// have the same names as theirs and a combination of values none of them has.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (v *ProductVariant) SetOptions(options map[string]string, siblings []ProductVariant) error {
+	var names []string
+	duplicate := false
+	for _, sibling := range siblings {
+		if sibling.ID == v.ID {
+			continue
+		}
+		if names == nil {
+			names = optionNames(sibling.Options)
+		}
+		duplicate = duplicate || reflect.DeepEqual(sibling.Options, options)
+	}
+	if err := validation.Options(options, names, 64); err != nil {
+		return err
+	}
+	if err := validation.Distinct(duplicate, "variant of the product"); err != nil {
+		return err
+	}
+	v.Options = options
+	return nil
+}
+
+// SetPrice sets the price of the variant, or makes it use the price of its product when price is nil.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is negative.
+func (v *ProductVariant) SetPrice(price *float64) error {
+	if price != nil {
+		if err := validation.NonNegativeFloat(*price); err != nil {
+			return err
+		}
+	}
+	v.Price = price
+	return nil
+}
+
+// SetStockQuantity sets the stock quantity of the variant after validating it as a non-negative integer.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (v *ProductVariant) SetStockQuantity(stock_quantity int) error {
+	if err := validation.NonNegativeInt(stock_quantity); err != nil {
+		return err
+	}
+	v.Stock_quantity = stock_quantity
+	return nil
+}
+
+// UnitPrice returns the price the variant is sold at: its own price if it has one, otherwise that of product.
+func (v *ProductVariant) UnitPrice(product *Product) float64 {
+	if v.Price != nil {
+		return *v.Price
+	}
+	return product.Price
+}
+
+// VariantOptions returns the values of each option of variants, sorted, e.g. {"color": ["black", "white"]}.
+// It returns nil when there are no variants.
+func VariantOptions(variants []ProductVariant) map[string][]string {
+	if len(variants) == 0 {
+		return nil
+	}
+	matrix := map[string][]string{}
+	seen := map[string]map[string]bool{}
+	for _, variant := range variants {
+		for name, value := range variant.Options {
+			if seen[name] == nil {
+				seen[name] = map[string]bool{}
+			}
+			if !seen[name][value] {
+				seen[name][value] = true
+				matrix[name] = append(matrix[name], value)
+			}
+		}
+	}
+	for _, values := range matrix {
+		sort.Strings(values)
+	}
+	return matrix
+}
+
+// optionNames returns the sorted names of options.
+func optionNames(options map[string]string) []string {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SearchProductVariant performs a search for product variants based on the provided search parameters:
+// sku matches a substring, product_id and stock_quantity are compared exactly.
+// It returns the matching variants, an empty slice if none match.
+func SearchProductVariant(db *gorm.DB, searchParams map[string]interface{}) ([]ProductVariant, error) {
+	var variants []ProductVariant
+	query := db.Model(&ProductVariant{})
+
+	for key, value := range searchParams {
+		switch key {
+		case "sku":
+			if strVal, ok := value.(string); ok {
+				query = query.Where("sku LIKE ?", "%"+strVal+"%")
+			}
+		case "product_id", "stock_quantity":
+			if numVal, ok := value.(int); ok {
+				query = query.Where(key+" = ?", numVal)
+			}
+		}
+	}
+
+	if err := query.Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"E-Commerce_Website_Database/internal/validation"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// TestProductVariant_Setters checks that valid values are set and that invalid ones are refused,
+// leaving the variant unchanged.
+func TestProductVariant_Setters(t *testing.T) {
+	v := ProductVariant{}
+	productExists := func(id uint) (bool, error) { return id == 1, nil }
+
+	assert.NoError(t, v.SetProductID(1, productExists))
+	assert.Equal(t, uint(1), v.Product_ID)
+	assert.Error(t, v.SetProductID(2, productExists))
+	assert.Equal(t, uint(1), v.Product_ID)
+	var failure *validation.Failure
+	assert.ErrorAs(t, v.SetProductID(2, func(uint) (bool, error) { return false, gorm.ErrInvalidDB }), &failure,
+		"a failed lookup is not an invalid value")
+	assert.Equal(t, uint(1), v.Product_ID)
+
+	assert.NoError(t, v.SetSKU("PHN-256.BLK_2"))
+	assert.Equal(t, "PHN-256.BLK_2", v.SKU)
+	assert.Error(t, v.SetSKU(""))
+	assert.Error(t, v.SetSKU("PHN 256"))
+	assert.Equal(t, "PHN-256.BLK_2", v.SKU)
+
+	price := 12.5
+	assert.NoError(t, v.SetPrice(&price))
+	assert.Equal(t, &price, v.Price)
+	negative := -1.0
+	assert.Error(t, v.SetPrice(&negative))
+	assert.NoError(t, v.SetPrice(nil))
+	assert.Nil(t, v.Price)
+
+	assert.NoError(t, v.SetStockQuantity(3))
+	assert.Equal(t, 3, v.Stock_quantity)
+	assert.Error(t, v.SetStockQuantity(-1))
+	assert.Equal(t, 3, v.Stock_quantity)
+}
+
+// TestProductVariant_SetOptions checks that the options of a variant must have the names of those of its siblings
+// and a combination of values none of them has, the variant itself excluded.
+func TestProductVariant_SetOptions(t *testing.T) {
+	siblings := []ProductVariant{
+		{Model: gorm.Model{ID: 1}, Options: map[string]string{"color": "black", "storage": "128GB"}},
+		{Model: gorm.Model{ID: 2}, Options: map[string]string{"color": "black", "storage": "256GB"}},
+	}
+
+	v := ProductVariant{}
+	assert.NoError(t, v.SetOptions(map[string]string{"color": "white"}, nil))
+	assert.NoError(t, v.SetOptions(map[string]string{"color": "white", "storage": "128GB"}, siblings))
+	assert.Equal(t, map[string]string{"color": "white", "storage": "128GB"}, v.Options)
+
+	existing := ProductVariant{Model: gorm.Model{ID: 2}, Options: siblings[1].Options}
+	assert.NoError(t, existing.SetOptions(map[string]string{"color": "black", "storage": "256GB"}, siblings))
+
+	for name, test := range map[string]struct {
+		options map[string]string
+		code    string
+	}{
+		"empty":        {options: map[string]string{}, code: validation.CodeRequired},
+		"empty value":  {options: map[string]string{"color": "", "storage": "128GB"}, code: validation.CodeRequired},
+		"missing name": {options: map[string]string{"color": "white"}, code: validation.CodeInvalid},
+		"extra name":   {options: map[string]string{"color": "white", "storage": "128GB", "size": "L"}, code: validation.CodeInvalid},
+		"duplicate":    {options: map[string]string{"color": "black", "storage": "128GB"}, code: validation.CodeDuplicate},
+	} {
+		t.Run(name, func(t *testing.T) {
+			v := ProductVariant{}
+			err := v.SetOptions(test.options, siblings)
+			if fieldErr, ok := err.(*validation.FieldError); assert.True(t, ok) {
+				assert.Equal(t, test.code, fieldErr.Code)
+			}
+			assert.Nil(t, v.Options)
+		})
+	}
+}
+
+// TestVariantOptions checks that the values of each option are collected once and sorted.
+func TestVariantOptions(t *testing.T) {
+	assert.Nil(t, VariantOptions(nil))
+	assert.Equal(t, map[string][]string{
+		"color":   {"black", "white"},
+		"storage": {"128GB", "256GB"},
+	}, VariantOptions([]ProductVariant{
+		{Options: map[string]string{"color": "white", "storage": "256GB"}},
+		{Options: map[string]string{"color": "black", "storage": "256GB"}},
+		{Options: map[string]string{"color": "black", "storage": "128GB"}},
+	}))
+}
+
+// TestProductVariant_UnitPrice checks that a variant is sold at its own price if it has one, otherwise at that of
+// its product.
+func TestProductVariant_UnitPrice(t *testing.T) {
+	product := &Product{Price: 999}
+	v := ProductVariant{}
+	assert.Equal(t, 999.0, v.UnitPrice(product))
+	price := 1099.0
+	v.Price = &price
+	assert.Equal(t, 1099.0, v.UnitPrice(product))
+}
+
+// TestOrderItem_SetVariantID checks that an order item must select a variant of the ordered product when it has
+// variants, and none otherwise.
+func TestOrderItem_SetVariantID(t *testing.T) {
+	variants := []ProductVariant{
+		{Model: gorm.Model{ID: 10}, Product_ID: 1},
+		{Model: gorm.Model{ID: 20}, Product_ID: 2},
+	}
+	variantOf := func(id uint) (*ProductVariant, error) {
+		for i := range variants {
+			if variants[i].ID == id {
+				return &variants[i], nil
+			}
+		}
+		return nil, nil
+	}
+	id := func(id uint) *uint { return &id }
+
+	oi := OrderItem{Product_ID: 1}
+	assert.NoError(t, oi.SetVariantID(id(10), variantOf, variants[:1]))
+	assert.Equal(t, uint(10), *oi.Variant_ID)
+
+	plain := OrderItem{Product_ID: 3, Variant_ID: id(10)}
+	assert.NoError(t, plain.SetVariantID(nil, variantOf, nil))
+	assert.Nil(t, plain.Variant_ID)
+
+	for name, test := range map[string]struct {
+		variantID *uint
+		code      string
+	}{
+		"none selected":   {variantID: nil, code: validation.CodeRequired},
+		"missing":         {variantID: id(99), code: validation.CodeNotFound},
+		"another product": {variantID: id(20), code: validation.CodeNotAllowed},
+	} {
+		t.Run(name, func(t *testing.T) {
+			oi := OrderItem{Product_ID: 1}
+			err := oi.SetVariantID(test.variantID, variantOf, variants[:1])
+			if fieldErr, ok := err.(*validation.FieldError); assert.True(t, ok) {
+				assert.Equal(t, test.code, fieldErr.Code)
+			}
+			assert.Nil(t, oi.Variant_ID)
+		})
+	}
+}
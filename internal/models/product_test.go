@@ -32,42 +32,42 @@ func TestGetAllProducts(t *testing.T) {
 
 // TestProduct_SetName tests setting a product's name after validating its length.
 // It creates a new instance of the Product struct and calls the SetName function with a valid name.
-// It then checks if the name was set correctly and if the function returned true.
-// It repeats the process with an invalid name and checks if the name was not set and the function returned false.
+// It then checks if the name was set correctly and if the function returned no error.
+// It repeats the process with an invalid name and checks if the name was not set and the function returned an error.
 func TestProduct_SetName(t *testing.T) {
 	product := Product{}
-	assert.False(t, product.SetName(string(make([]byte, 256))), "Name should be invalid due to length")
-	assert.True(t, product.SetName("Valid Name"), "Name should be valid")
+	assert.Error(t, product.SetName(string(make([]byte, 256))), "Name should be invalid due to length")
+	assert.NoError(t, product.SetName("Valid Name"), "Name should be valid")
 }
 
 // TestProduct_SetDescription tests setting a product's description after validating its length.
 // It creates a new instance of the Product struct and calls the SetDescription function with a valid description.
-// It then checks if the description was set correctly and if the function returned true.
-// It repeats the process with an invalid description and checks if the description was not set and the function returned false.
+// It then checks if the description was set correctly and if the function returned no error.
+// It repeats the process with an invalid description and checks if the description was not set and the function returned an error.
 func TestProduct_SetDescription(t *testing.T) {
 	product := Product{}
-	assert.False(t, product.SetDescription(string(make([]byte, 1001))), "Description should be invalid due to length")
-	assert.True(t, product.SetDescription("Valid Description"), "Description should be valid")
+	assert.Error(t, product.SetDescription(string(make([]byte, 1001))), "Description should be invalid due to length")
+	assert.NoError(t, product.SetDescription("Valid Description"), "Description should be valid")
 }
 
 // TestProduct_SetPrice tests setting a product's price after validating it as a positive float.
 // It creates a new instance of the Product struct and calls the SetPrice function with a valid price.
-// It then checks if the price was set correctly and if the function returned true.
-// It repeats the process with an invalid price and checks if the price was not set and the function returned false.
+// It then checks if the price was set correctly and if the function returned no error.
+// It repeats the process with an invalid price and checks if the price was not set and the function returned an error.
 func TestProduct_SetPrice(t *testing.T) {
 	product := Product{}
-	assert.False(t, product.SetPrice(-10.0), "Price should be invalid because it is negative")
-	assert.True(t, product.SetPrice(100.0), "Price should be valid")
+	assert.Error(t, product.SetPrice(-10.0), "Price should be invalid because it is negative")
+	assert.NoError(t, product.SetPrice(100.0), "Price should be valid")
 }
 
 // TestProduct_SetStockQuantity tests setting a product's stock quantity after validating it as a non-negative integer.
 // It creates a new instance of the Product struct and calls the SetStockQuantity function with a valid quantity.
-// It then checks if the quantity was set correctly and if the function returned true.
-// It repeats the process with an invalid quantity and checks if the quantity was not set and the function returned false.
+// It then checks if the quantity was set correctly and if the function returned no error.
+// It repeats the process with an invalid quantity and checks if the quantity was not set and the function returned an error.
 func TestProduct_SetStockQuantity(t *testing.T) {
 	product := Product{}
-	assert.False(t, product.SetStockQuantity(-1), "Stock quantity should be invalid because it is negative")
-	assert.True(t, product.SetStockQuantity(50), "Stock quantity should be valid")
+	assert.Error(t, product.SetStockQuantity(-1), "Stock quantity should be invalid because it is negative")
+	assert.NoError(t, product.SetStockQuantity(50), "Stock quantity should be valid")
 }
 
 // TestProduct_SetBrandID tests setting a product's brand ID after verifying the existence of the brand.
@@ -85,13 +85,13 @@ func TestProduct_SetBrandID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"brands\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	product := Product{}
-	result := product.SetBrandID(1, gormDB)
-	assert.True(t, result)
+	result := product.SetBrandID(1, func(id uint) (bool, error) { return BrandExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"brands\" WHERE").WithArgs(2, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = product.SetBrandID(2, gormDB)
-	assert.False(t, result)
+	result = product.SetBrandID(2, func(id uint) (bool, error) { return BrandExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestProduct_SetCategoryID tests setting a product's category ID after verifying the existence of the category.
@@ -109,13 +109,13 @@ func TestProduct_SetCategoryID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"categories\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	product := Product{}
-	result := product.SetCategoryID(1, gormDB)
-	assert.True(t, result)
+	result := product.SetCategoryID(1, func(id uint) (bool, error) { return CategoryExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"categories\" WHERE").WithArgs(2, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = product.SetCategoryID(2, gormDB)
-	assert.False(t, result)
+	result = product.SetCategoryID(2, func(id uint) (bool, error) { return CategoryExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestProductExists tests checking if a specific product exists in the database by its ID.
@@ -155,7 +155,7 @@ func TestSearchProduct(t *testing.T) {
 		AddRow(1, "Searchable Product", "Description", 10.0, 5, 1, 1)
 
 	// match the actual query that is being executed
-	mock.ExpectQuery(`^SELECT "products"."id","products"."created_at","products"."updated_at","products"."deleted_at","products"."name","products"."description","products"."price","products"."stock_quantity","products"."brand_id","products"."category_id" FROM "products" JOIN brands ON brands.id = products.brand_id JOIN categories ON categories.id = products.category_id WHERE products.name LIKE \$1 AND "products"."deleted_at" IS NULL$`).
+	mock.ExpectQuery(`^SELECT "products"."id","products"."created_at","products"."updated_at","products"."deleted_at","products"."version","products"."name","products"."description","products"."price","products"."stock_quantity","products"."brand_id","products"."category_id" FROM "products" JOIN brands ON brands.id = products.brand_id JOIN categories ON categories.id = products.category_id WHERE products.name LIKE \$1 AND "products"."deleted_at" IS NULL$`).
 		WithArgs("%searchable%").
 		WillReturnRows(rows)
 
@@ -0,0 +1,18 @@
+package models
+
+import "E-Commerce_Website_Database/internal/validation"
+
+// ExistsFunc reports whether the row with the given ID of a referenced table exists and is not in the trash.
+// The setters of references take one, so that they check the reference however the rows are stored.
+// An error means the row could not be looked up, which says nothing about the value checked.
+type ExistsFunc func(id uint) (bool, error)
+
+// checkReference requires the row with the given ID, described by what, to exist according to exists.
+// A failed lookup is returned as a *validation.Failure rather than as an invalid value.
+func checkReference(id uint, exists ExistsFunc, what string) error {
+	found, err := exists(id)
+	if err != nil {
+		return validation.Failed(err)
+	}
+	return validation.Exists(found, what)
+}
@@ -1,19 +1,25 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
+	"time"
 )
 
 // Review represents the review model for products.
 // It includes fields for Product_ID, User_ID, Rating, Comment, and Review_Date.
+// Reviews are deleted with their product, while deleting a user keeps their reviews with User_ID set to NULL,
+// which reads back as 0. Product is only loaded when requested, e.g. with ?include=product.
 type Review struct {
 	gorm.Model
-	Product_ID  uint32 `json:"product_id"`
-	User_ID     uint32 `json:"user_id"`
-	Rating      int    `json:"rating"`
-	Comment     string `json:"comment"`
-	Review_Date string `json:"review_date"`
+	Versioned
+	Product_ID  uint      `json:"product_id"`
+	User_ID     uint      `json:"user_id"`
+	Rating      int       `json:"rating"`
+	Comment     string    `json:"comment"`
+	Review_Date time.Time `json:"review_date"`
+	Product     *Product  `gorm:"foreignKey:Product_ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"product,omitempty"`
+	User        *User     `gorm:"foreignKey:User_ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
 }
 
 // GetAllReviews retrieves all reviews from the database.
@@ -26,64 +32,59 @@ func GetAllReviews(db *gorm.DB) ([]Review, error) {
 	return reviews, nil
 }
 
-// SetProductID sets the product ID for the review after verifying the existence of the product.
-// Returns true if the product exists and the ID is set; otherwise, it returns false.
-func (r *Review) SetProductID(product_id uint32, db *gorm.DB) bool {
-	if !ProductExists(db, product_id) {
-		return false
-	} else {
-		r.Product_ID = product_id
-		return true
+// SetProductID sets the product ID for the review after verifying with productExists that the product exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (r *Review) SetProductID(product_id uint, productExists ExistsFunc) error {
+	if err := checkReference(product_id, productExists, "product"); err != nil {
+		return err
 	}
+	r.Product_ID = product_id
+	return nil
 }
 
-// SetUserID sets the user ID for the review after verifying the existence of the user.
-// Returns true if the user exists and the ID is set; otherwise, it returns false.
-func (r *Review) SetUserID(user_id uint32, db *gorm.DB) bool {
-	if !UserExists(db, user_id) {
-		return false
-	} else {
-		r.User_ID = user_id
-		return true
+// SetUserID sets the user ID for the review after verifying with userExists that the user exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (r *Review) SetUserID(user_id uint, userExists ExistsFunc) error {
+	if err := checkReference(user_id, userExists, "user"); err != nil {
+		return err
 	}
+	r.User_ID = user_id
+	return nil
 }
 
 // SetRating sets the rating for the review.
-// It validates the rating to ensure it meets certain criteria and returns true if valid.
-func (r *Review) SetRating(rating int) bool {
-	if !tools.CheckRating(rating) {
-		return false
-	} else {
-		r.Rating = rating
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (r *Review) SetRating(rating int) error {
+	if err := validation.Rating(rating); err != nil {
+		return err
 	}
+	r.Rating = rating
+	return nil
 }
 
 // SetComment sets the comment for the review.
-// It validates the comment to ensure it meets certain criteria and returns true if valid.
-func (r *Review) SetComment(comment string) bool {
-	if !tools.CheckString(comment, 255) {
-		return false
-	} else {
-		r.Comment = comment
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (r *Review) SetComment(comment string) error {
+	if err := validation.String(comment, 255); err != nil {
+		return err
 	}
+	r.Comment = comment
+	return nil
 }
 
-// SetReviewDate sets the review date for the review.
-// It validates the date format and returns true if the date is valid; otherwise, it returns false.
-func (r *Review) SetReviewDate(review_date string) bool {
-	if !tools.CheckDate(review_date) {
-		return false
-	} else {
-		r.Review_Date = review_date
-		return true
+// SetReviewDate sets the review date for the review, stored in UTC. It must not be in the future.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (r *Review) SetReviewDate(review_date time.Time) error {
+	if err := validation.Past(review_date); err != nil {
+		return err
 	}
+	r.Review_Date = review_date.UTC()
+	return nil
 }
 
 // ReviewExists checks if a review exists in the database by its ID.
 // It returns true if the review is found, otherwise returns false.
-func ReviewExists(db *gorm.DB, id uint32) bool {
+func ReviewExists(db *gorm.DB, id uint) bool {
 	var review Review
 	if db.Where("id = ?", id).First(&review).Error != nil {
 		return false
@@ -114,8 +115,9 @@ func SearchReview(db *gorm.DB, searchParams map[string]interface{}) ([]Review, e
 				query = query.Where(key+" LIKE ?", "%"+valueStr+"%")
 			}
 		case "review_date":
-			if isString {
-				query = query.Where(key+" = ?", valueStr)
+			// A date matches every time of that day in UTC
+			if day, ok := value.(time.Time); ok {
+				query = query.Where(key+" >= ? AND "+key+" < ?", day, day.AddDate(0, 0, 1))
 			}
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 // TestGetAllReviews tests the retrieval of all reviews from the database.
@@ -21,8 +22,8 @@ func TestGetAllReviews(t *testing.T) {
 	assert.NoError(t, err)
 
 	rows := sqlmock.NewRows([]string{"id", "product_id", "user_id", "rating", "comment", "review_date"}).
-		AddRow(1, 1, 1, 5, "Great product", "2021-04-21").
-		AddRow(2, 1, 2, 4, "Good, but expensive", "2021-04-22")
+		AddRow(1, 1, 1, 5, "Great product", time.Date(2021, 4, 21, 0, 0, 0, 0, time.UTC)).
+		AddRow(2, 1, 2, 4, "Good, but expensive", time.Date(2021, 4, 22, 0, 0, 0, 0, time.UTC))
 	mock.ExpectQuery("^SELECT \\* FROM \"reviews\"").WillReturnRows(rows)
 
 	reviews, err := GetAllReviews(gormDB)
@@ -45,13 +46,13 @@ func TestReview_SetProductID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"products\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	review := Review{}
-	result := review.SetProductID(1, gormDB)
-	assert.True(t, result)
+	result := review.SetProductID(1, func(id uint) (bool, error) { return ProductExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"products\" WHERE").WithArgs(99, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = review.SetProductID(99, gormDB)
-	assert.False(t, result)
+	result = review.SetProductID(99, func(id uint) (bool, error) { return ProductExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestReview_SetUserID tests setting the user ID after verifying the user exists
@@ -69,13 +70,13 @@ func TestReview_SetUserID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"users\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	review := Review{}
-	result := review.SetUserID(1, gormDB)
-	assert.True(t, result)
+	result := review.SetUserID(1, func(id uint) (bool, error) { return UserExists(gormDB, id), nil })
+	assert.NoError(t, result)
 
 	mock.ExpectQuery("^SELECT \\* FROM \"users\" WHERE").WithArgs(99, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = review.SetUserID(99, gormDB)
-	assert.False(t, result)
+	result = review.SetUserID(99, func(id uint) (bool, error) { return UserExists(gormDB, id), nil })
+	assert.Error(t, result)
 }
 
 // TestReview_SetRating tests setting the rating after validating it with predefined rules.
@@ -84,8 +85,8 @@ func TestReview_SetUserID(t *testing.T) {
 // Finally, it checks if all the expectations were met.
 func TestReview_SetRating(t *testing.T) {
 	review := Review{}
-	assert.False(t, review.SetRating(-1), "Rating should be invalid because it is negative")
-	assert.True(t, review.SetRating(5), "Rating should be valid")
+	assert.Error(t, review.SetRating(-1), "Rating should be invalid because it is negative")
+	assert.NoError(t, review.SetRating(5), "Rating should be valid")
 }
 
 // TestReview_SetComment tests setting the comment after validating its length.
@@ -94,18 +95,18 @@ func TestReview_SetRating(t *testing.T) {
 // Finally, it checks if all the expectations were met.
 func TestReview_SetComment(t *testing.T) {
 	review := Review{}
-	assert.False(t, review.SetComment(string(make([]byte, 256))), "Comment should be invalid due to length")
-	assert.True(t, review.SetComment("Great product!"), "Comment should be valid")
+	assert.Error(t, review.SetComment(string(make([]byte, 256))), "Comment should be invalid due to length")
+	assert.NoError(t, review.SetComment("Great product!"), "Comment should be valid")
 }
 
-// TestReview_SetReviewDate tests setting the review date after validating it as a valid date string.
+// TestReview_SetReviewDate tests setting the review date after validating that it is not in the future.
 // It creates a new instance of sql mock and sets up expectations for the query.
 // It then calls the function and checks if the returned data matches the expected data.
 // Finally, it checks if all the expectations were met.
 func TestReview_SetReviewDate(t *testing.T) {
 	review := Review{}
-	assert.False(t, review.SetReviewDate("20210421"), "Review date should be invalid due to format")
-	assert.True(t, review.SetReviewDate("2021-04-21"), "Review date should be valid")
+	assert.Error(t, review.SetReviewDate(time.Now().AddDate(1, 0, 0)), "Review date should not be in the future")
+	assert.NoError(t, review.SetReviewDate(time.Date(2021, 4, 21, 0, 0, 0, 0, time.UTC)), "Review date should be valid")
 }
 
 // TestReviewExists tests checking if a specific review exists in the database by its ID.
@@ -142,7 +143,7 @@ func TestSearchReview(t *testing.T) {
 	assert.NoError(t, err)
 
 	rows := sqlmock.NewRows([]string{"id", "product_id", "user_id", "rating", "comment", "review_date"}).
-		AddRow(1, 1, 1, 5, "Great product", "2021-04-21")
+		AddRow(1, 1, 1, 5, "Great product", time.Date(2021, 4, 21, 0, 0, 0, 0, time.UTC))
 	mock.ExpectQuery("^SELECT \\* FROM \"reviews\" WHERE").
 		WithArgs(5).
 		WillReturnRows(rows)
@@ -1,20 +1,22 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
 	"strings"
+	"time"
 )
 
 // ShippingDetails represents the shipping details model for an e-commerce transaction.
 // It includes fields for Order_ID, Address, Shipping_Date, Estimated_Arrival, and Status.
 type ShippingDetails struct {
 	gorm.Model
-	Order_ID          uint32 `json:"order_id"`
-	Address           string `json:"address"`
-	Shipping_Date     string `json:"shipping_date"`
-	Estimated_Arrival string `json:"estimated_arrival"`
-	Status            string `json:"status"`
+	Versioned
+	Order_ID          uint      `json:"order_id"`
+	Address           string    `json:"address"`
+	Shipping_Date     time.Time `json:"shipping_date"`
+	Estimated_Arrival time.Time `json:"estimated_arrival"`
+	Status            string    `json:"status"`
 }
 
 // GetAllShippingDetails retrieves all shipping details from the database.
@@ -27,64 +29,60 @@ func GetAllShippingDetails(db *gorm.DB) ([]ShippingDetails, error) {
 	return shippingDetails, nil
 }
 
-// SetOrderID sets the order ID for the shipping details after verifying the existence of the order.
-// Returns true if the order exists and the ID is set; otherwise, it returns false.
-func (s *ShippingDetails) SetOrderID(order_id uint32, db *gorm.DB) bool {
-	if !OrderExists(db, order_id) {
-		return false
-	} else {
-		s.Order_ID = order_id
-		return true
+// SetOrderID sets the order ID for the shipping details after verifying with orderExists that the order exists.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (s *ShippingDetails) SetOrderID(order_id uint, orderExists ExistsFunc) error {
+	if err := checkReference(order_id, orderExists, "order"); err != nil {
+		return err
 	}
+	s.Order_ID = order_id
+	return nil
 }
 
 // SetAddress sets the address for the shipping details after validating its length.
-// Returns true if the address is within the allowed length, otherwise false.
-func (s *ShippingDetails) SetAddress(address string) bool {
-	if !tools.CheckString(address, 255) {
-		return false
-	} else {
-		s.Address = address
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (s *ShippingDetails) SetAddress(address string) error {
+	if err := validation.String(address, 255); err != nil {
+		return err
 	}
+	s.Address = address
+	return nil
 }
 
-// SetShippingDate sets the shipping date for the shipping details after validating the date format.
-// Returns true if the date is valid, otherwise false.
-func (s *ShippingDetails) SetShippingDate(shipping_date string) bool {
-	if !tools.CheckDate(shipping_date) {
-		return false
-	} else {
-		s.Shipping_Date = shipping_date
-		return true
+// SetShippingDate sets the shipping date for the shipping details, stored in UTC, after checking that it is set.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (s *ShippingDetails) SetShippingDate(shipping_date time.Time) error {
+	if err := validation.Time(shipping_date); err != nil {
+		return err
 	}
+	s.Shipping_Date = shipping_date.UTC()
+	return nil
 }
 
-// SetEstimatedArrival sets the estimated arrival date for the shipping details after validating the date format.
-// Returns true if the date is valid, otherwise false.
-func (s *ShippingDetails) SetEstimatedArrival(estimated_arrival string) bool {
-	if !tools.CheckDate(estimated_arrival) {
-		return false
-	} else {
-		s.Estimated_Arrival = estimated_arrival
-		return true
+// SetEstimatedArrival sets the estimated arrival date for the shipping details, stored in UTC, after checking that
+// it is set.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (s *ShippingDetails) SetEstimatedArrival(estimated_arrival time.Time) error {
+	if err := validation.Time(estimated_arrival); err != nil {
+		return err
 	}
+	s.Estimated_Arrival = estimated_arrival.UTC()
+	return nil
 }
 
 // SetStatus sets the status for the shipping details after validating its length.
-// Returns true if the status is within the allowed length, otherwise false.
-func (s *ShippingDetails) SetStatus(status string) bool {
-	if !tools.CheckStatus(status, 255) {
-		return false
-	} else {
-		s.Status = status
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (s *ShippingDetails) SetStatus(status string) error {
+	if err := validation.Status(status); err != nil {
+		return err
 	}
+	s.Status = status
+	return nil
 }
 
 // ShippingDetailsExists checks if a shipping details record exists in the database by its ID.
 // It returns true if the shipping details record is found, otherwise returns false.
-func ShippingDetailsExists(db *gorm.DB, id uint32) bool {
+func ShippingDetailsExists(db *gorm.DB, id uint) bool {
 	var shippingDetails ShippingDetails
 	if db.Where("id = ?", id).First(&shippingDetails).Error != nil {
 		return false
@@ -111,8 +109,9 @@ func SearchShippingDetails(db *gorm.DB, searchParams map[string]interface{}) ([]
 				query = query.Where(key+" LIKE ?", "%"+valueStr+"%")
 			}
 		case "shipping_date", "estimated_arrival":
-			if isString {
-				query = query.Where(key+" = ?", valueStr)
+			// A date matches every time of that day in UTC
+			if day, ok := value.(time.Time); ok {
+				query = query.Where(key+" >= ? AND "+key+" < ?", day, day.AddDate(0, 0, 1))
 			}
 		case "status":
 			if isString {
@@ -6,6 +6,7 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 // TestGetAllShippingDetails tests retrieving all shipping details from the database.
@@ -21,8 +22,8 @@ func TestGetAllShippingDetails(t *testing.T) {
 	assert.NoError(t, err)
 
 	rows := sqlmock.NewRows([]string{"id", "order_id", "address", "shipping_date", "estimated_arrival", "status"}).
-		AddRow(1, 1, "123 Elm St", "2021-06-01", "2021-06-15", "Shipped").
-		AddRow(2, 2, "456 Oak St", "2021-07-01", "2021-07-15", "Delivered")
+		AddRow(1, 1, "123 Elm St", time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC), "Shipped").
+		AddRow(2, 2, "456 Oak St", time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 7, 15, 0, 0, 0, 0, time.UTC), "Delivered")
 	mock.ExpectQuery("^SELECT \\* FROM \"shipping_details\"").WillReturnRows(rows)
 
 	shippingDetails, err := GetAllShippingDetails(gormDB)
@@ -45,53 +46,53 @@ func TestShippingDetails_SetOrderID(t *testing.T) {
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\" WHERE").WithArgs(1, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	details := ShippingDetails{}
-	result := details.SetOrderID(1, gormDB)
-	assert.True(t, result, "Order exists, should return true")
+	result := details.SetOrderID(1, func(id uint) (bool, error) { return OrderExists(gormDB, id), nil })
+	assert.NoError(t, result, "Order exists, should return no error")
 
 	mock.ExpectQuery("^SELECT \\* FROM \"orders\" WHERE").WithArgs(99, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}))
-	result = details.SetOrderID(99, gormDB)
-	assert.False(t, result, "Order does not exist, should return false")
+	result = details.SetOrderID(99, func(id uint) (bool, error) { return OrderExists(gormDB, id), nil })
+	assert.Error(t, result, "Order does not exist, should return an error")
 }
 
 // TestShippingDetails_SetAddress tests setting the shipping address after validating its length.
 // It creates a new instance of the ShippingDetails struct and calls the SetAddress function with a valid address.
-// It then checks if the address was set correctly and if the function returned true.
-// It repeats the process with an invalid address and checks if the address was not set and the function returned false.
+// It then checks if the address was set correctly and if the function returned no error.
+// It repeats the process with an invalid address and checks if the address was not set and the function returned an error.
 func TestShippingDetails_SetAddress(t *testing.T) {
 	details := ShippingDetails{}
-	assert.False(t, details.SetAddress(string(make([]byte, 256))), "Address should be invalid due to length")
-	assert.True(t, details.SetAddress("123 Elm St"), "Address should be valid")
+	assert.Error(t, details.SetAddress(string(make([]byte, 256))), "Address should be invalid due to length")
+	assert.NoError(t, details.SetAddress("123 Elm St"), "Address should be valid")
 }
 
-// TestShippingDetails_SetShippingDate tests setting the shipping date after validating its format.
+// TestShippingDetails_SetShippingDate tests setting the shipping date after validating that it is set.
 // It creates a new instance of the ShippingDetails struct and calls the SetShippingDate function with a valid date.
-// It then checks if the date was set correctly and if the function returned true.
-// It repeats the process with an invalid date and checks if the date was not set and the function returned false.
+// It then checks if the date was set correctly and if the function returned no error.
+// It repeats the process with an invalid date and checks if the date was not set and the function returned an error.
 func TestShippingDetails_SetShippingDate(t *testing.T) {
 	details := ShippingDetails{}
-	assert.False(t, details.SetShippingDate("20210601"), "Shipping date should be invalid due to format")
-	assert.True(t, details.SetShippingDate("2021-06-01"), "Shipping date should be valid")
+	assert.Error(t, details.SetShippingDate(time.Time{}), "Shipping date should be set")
+	assert.NoError(t, details.SetShippingDate(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)), "Shipping date should be valid")
 }
 
-// TestShippingDetails_SetEstimatedArrival tests setting the estimated arrival date after validating its format.
+// TestShippingDetails_SetEstimatedArrival tests setting the estimated arrival date after validating that it is set.
 // It creates a new instance of the ShippingDetails struct and calls the SetEstimatedArrival function with a valid date.
-// It then checks if the date was set correctly and if the function returned true.
-// It repeats the process with an invalid date and checks if the date was not set and the function returned false.
+// It then checks if the date was set correctly and if the function returned no error.
+// It repeats the process with an invalid date and checks if the date was not set and the function returned an error.
 func TestShippingDetails_SetEstimatedArrival(t *testing.T) {
 	details := ShippingDetails{}
-	assert.False(t, details.SetEstimatedArrival("20210701"), "Estimated arrival should be invalid due to format")
-	assert.True(t, details.SetEstimatedArrival("2021-07-15"), "Estimated arrival should be valid")
+	assert.Error(t, details.SetEstimatedArrival(time.Time{}), "Estimated arrival should be set")
+	assert.NoError(t, details.SetEstimatedArrival(time.Date(2021, 7, 15, 0, 0, 0, 0, time.UTC)), "Estimated arrival should be valid")
 }
 
 // TestShippingDetails_SetStatus tests setting the status after validating its length and contents.
 // It creates a new instance of the ShippingDetails struct and calls the SetStatus function with a valid status.
-// It then checks if the status was set correctly and if the function returned true.
-// It repeats the process with an invalid status and checks if the status was not set and the function returned false.
+// It then checks if the status was set correctly and if the function returned no error.
+// It repeats the process with an invalid status and checks if the status was not set and the function returned an error.
 func TestShippingDetails_SetStatus(t *testing.T) {
 	details := ShippingDetails{}
-	assert.False(t, details.SetStatus(""), "Status should be invalid due to being empty")
-	assert.True(t, details.SetStatus("delivered"), "Status should be valid")
+	assert.Error(t, details.SetStatus(""), "Status should be invalid due to being empty")
+	assert.NoError(t, details.SetStatus("delivered"), "Status should be valid")
 }
 
 // TestShippingDetailsExists tests checking if a specific shipping detail exists in the database by its ID.
@@ -128,7 +129,7 @@ func TestSearchShippingDetails(t *testing.T) {
 	assert.NoError(t, err)
 
 	rows := sqlmock.NewRows([]string{"id", "order_id", "address", "shipping_date", "estimated_arrival", "status"}).
-		AddRow(1, 2, "Bunt St", "2021-06-01", "2021-06-15", "Delivered")
+		AddRow(1, 2, "Bunt St", time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC), "Delivered")
 
 	mock.ExpectQuery("^SELECT \\* FROM \"shipping_details\" WHERE").
 		WithArgs("%bunt st%").
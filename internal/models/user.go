@@ -1,7 +1,7 @@
 package models
 
 import (
-	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
 	"gorm.io/gorm"
 )
 
@@ -9,6 +9,7 @@ import (
 // It includes essential fields like Username, Password, Email, along with personal details such as First Name, Last Name, and Address.
 type User struct {
 	gorm.Model
+	Versioned
 	Username   string `gorm:"unique" json:"username"`
 	Password   string `json:"password"`
 	Email      string `gorm:"unique" json:"email"`
@@ -29,96 +30,89 @@ func GetAllUsers(db *gorm.DB) ([]User, error) {
 	return users, nil
 }
 
-func (u *User) SetRole(role string) bool {
-	if !tools.CheckRole(role) {
-		return false
-	} else {
-		u.Role = role
-		return true
+// SetRole sets the role of the user after checking it is one of validation.Roles.
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetRole(role string) error {
+	if err := validation.Role(role); err != nil {
+		return err
 	}
-
+	u.Role = role
+	return nil
 }
 
 // SetUsername sets the username for the user after validating its uniqueness and length.
-// Returns true if the username is within the allowed length and unique, otherwise false.
-func (u *User) SetUsername(username string) bool {
-	if !tools.CheckString(username, 255) {
-		return false
-	} else {
-		u.Username = username
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetUsername(username string) error {
+	if err := validation.String(username, 255); err != nil {
+		return err
 	}
+	u.Username = username
+	return nil
 }
 
 // SetPhone validates a phone number by ensuring it contains only digits and does not exceed the specified length.
-// Returns true if the phone number is within the valid range and contains only digits, otherwise false.
-func (u *User) SetPhone(phone string) bool {
-	if !tools.CheckPhone(phone, 11) {
-		return false
-	} else {
-		u.Mobile = phone
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetPhone(phone string) error {
+	if err := validation.Phone(phone, 11); err != nil {
+		return err
 	}
+	u.Mobile = phone
+	return nil
 }
 
 // SetPassword sets the password for the user after ensuring it meets security standards.
-// Returns true if the password is considered secure, otherwise false.
-func (u *User) SetPassword(password string) bool {
-	if !tools.CheckPassword(password) {
-		return false
-	} else {
-		u.Password = password
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetPassword(password string) error {
+	if err := validation.Password(password); err != nil {
+		return err
 	}
+	u.Password = password
+	return nil
 }
 
 // SetEmail sets the email for the user after validating its format and uniqueness.
-// Returns true if the email is valid and unique, otherwise false.
-func (u *User) SetEmail(email string) bool {
-	if !tools.CheckEmail(email) {
-		return false
-	} else {
-		u.Email = email
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetEmail(email string) error {
+	if err := validation.Email(email); err != nil {
+		return err
 	}
+	u.Email = email
+	return nil
 }
 
 // SetFirstName sets the first name of the user after validating its length.
-// Returns true if the first name is within the allowed length, otherwise false.
-func (u *User) SetFirstName(first_name string) bool {
-	if !tools.CheckString(first_name, 255) {
-		return false
-	} else {
-		u.First_Name = first_name
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetFirstName(first_name string) error {
+	if err := validation.String(first_name, 255); err != nil {
+		return err
 	}
+	u.First_Name = first_name
+	return nil
 }
 
 // SetLastName sets the last name of the user after validating its length.
-// Returns true if the last name is within the allowed length, otherwise false.
-func (u *User) SetLastName(last_name string) bool {
-	if !tools.CheckString(last_name, 255) {
-		return false
-	} else {
-		u.Last_Name = last_name
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetLastName(last_name string) error {
+	if err := validation.String(last_name, 255); err != nil {
+		return err
 	}
+	u.Last_Name = last_name
+	return nil
 }
 
 // SetAddress sets the address for the user after validating its length.
-// Returns true if the address is within the allowed length, otherwise false.
-func (u *User) SetAddress(address string) bool {
-	if !tools.CheckString(address, 255) {
-		return false
-	} else {
-		u.Address = address
-		return true
+// It returns a *validation.FieldError, leaving the field unchanged, if the value is invalid.
+func (u *User) SetAddress(address string) error {
+	if err := validation.String(address, 255); err != nil {
+		return err
 	}
+	u.Address = address
+	return nil
 }
 
 // UserExists checks if a specific user exists in the database by their ID.
 // Returns true if the user exists, otherwise false.
-func UserExists(db *gorm.DB, id uint32) bool {
+func UserExists(db *gorm.DB, id uint) bool {
 	var user User
 	if db.First(&user, id).Error != nil {
 		return false
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict is returned by SaveVersioned when the row was changed since it was read.
+var ErrVersionConflict = errors.New("record was modified since it was read")
+
+// Versioned is embedded in the models edited through the API. Version starts at 1 and is incremented
+// by every SaveVersioned, so that concurrent edits of the same row can be detected instead of overwriting each other.
+type Versioned struct {
+	Version uint `gorm:"not null;default:1" json:"version"`
+}
+
+// Versioner is implemented by the models embedding Versioned.
+type Versioner interface {
+	CurrentVersion() uint
+	setVersion(version uint)
+}
+
+// CurrentVersion returns the version of the row as it was read or last saved.
+func (v *Versioned) CurrentVersion() uint {
+	return v.Version
+}
+
+// setVersion changes the version held by the model.
+func (v *Versioned) setVersion(version uint) {
+	v.Version = version
+}
+
+// BeforeCreate starts new rows at version 1.
+func (v *Versioned) BeforeCreate(*gorm.DB) error {
+	if v.Version == 0 {
+		v.Version = 1
+	}
+	return nil
+}
+
+// SaveVersioned writes the given columns of model, or every column like db.Save when none are given, provided the row
+// still has the version model was read with, and increments the version. It returns ErrVersionConflict,
+// leaving model unchanged, when the row was saved by someone else in the meantime or deleted.
+func SaveVersioned(db *gorm.DB, model Versioner, columns ...string) error {
+	read := model.CurrentVersion()
+	model.setVersion(read + 1)
+	query := db.Model(model).Where("version = ?", read)
+	if len(columns) == 0 {
+		query = query.Select("*")
+	} else {
+		query = query.Select(append([]string{"version", "updated_at"}, columns...))
+	}
+	result := query.Updates(model)
+	err := result.Error
+	if err == nil && result.RowsAffected == 0 {
+		err = ErrVersionConflict
+	}
+	if err != nil {
+		model.setVersion(read)
+	}
+	return err
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+// TestSaveVersioned tests that saving increments the version, and that saving a copy read before
+// is refused with ErrVersionConflict without changing the row or the copy.
+func TestSaveVersioned(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "version.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&Brands{}); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	brand := Brands{Name: "Acme"}
+	assert.NoError(t, db.Create(&brand).Error)
+	assert.Equal(t, uint(1), brand.Version)
+
+	var stale Brands
+	db.First(&stale, brand.ID)
+
+	brand.Name = "Acme Corp"
+	assert.NoError(t, SaveVersioned(db, &brand))
+	assert.Equal(t, uint(2), brand.Version)
+
+	stale.Name = "Lost update"
+	assert.ErrorIs(t, SaveVersioned(db, &stale), ErrVersionConflict)
+	assert.Equal(t, uint(1), stale.Version)
+
+	var saved Brands
+	db.First(&saved, brand.ID)
+	assert.Equal(t, "Acme Corp", saved.Name)
+	assert.Equal(t, uint(2), saved.Version)
+}
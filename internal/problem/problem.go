@@ -0,0 +1,94 @@
+package problem
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/validation"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// ContentType is the media type of problem details, as defined by RFC 7807.
+const ContentType = "application/problem+json"
+
+// TypeValidation identifies problems caused by invalid fields of a request body.
+const TypeValidation = "/problems/validation"
+
+// Problem is an RFC 7807 problem details object, the body of every error response. Code is the machine-readable
+// kind of the error and Errors, for validation problems, holds the failures of each invalid field.
+// Extensions are written as additional members.
+type Problem struct {
+	Type       string                              `json:"type"`
+	Title      string                              `json:"title"`
+	Status     int                                 `json:"status"`
+	Detail     string                              `json:"detail,omitempty"`
+	Instance   string                              `json:"instance,omitempty"`
+	Code       string                              `json:"code,omitempty"`
+	Errors     map[string][]*validation.FieldError `json:"errors,omitempty"`
+	Extensions map[string]interface{}              `json:"-"`
+}
+
+// MarshalJSON writes the members of p followed by its extensions.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	type members Problem
+	data, err := json.Marshal((*members)(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+	document := map[string]interface{}{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	for name, value := range p.Extensions {
+		if _, taken := document[name]; !taken {
+			document[name] = value
+		}
+	}
+	return json.Marshal(document)
+}
+
+// From returns the problem answering err. An *apperr.Error gives its kind and message; any other error is
+// answered as an internal error without exposing it. Field errors of validation errors are listed per field.
+func From(err error) *Problem {
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) {
+		appErr = apperr.Internal("Internal server error", err)
+	}
+	status := appErr.Kind.Status()
+	p := &Problem{
+		Type:       "/problems/" + strings.ReplaceAll(string(appErr.Kind), "_", "-"),
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     appErr.Message,
+		Code:       string(appErr.Kind),
+		Extensions: appErr.Extensions,
+	}
+	if appErr.Kind == apperr.KindValidation {
+		p.Title = "Validation error"
+		var fieldErrors validation.Errors
+		var fieldErr *validation.FieldError
+		switch {
+		case errors.As(appErr.Err, &fieldErrors):
+			p.Errors = fieldErrors.ByField()
+			p.Detail = "invalid fields: " + strings.Join(fieldErrors.Fields(), ", ")
+		case errors.As(appErr.Err, &fieldErr):
+			p.Errors = validation.Errors{fieldErr}.ByField()
+			p.Detail = "invalid fields: " + fieldErr.Field
+		case appErr.Err != nil:
+			p.Detail = appErr.Err.Error()
+		}
+	}
+	return p
+}
+
+// Write writes p as the response with its status and the problem+json content type.
+// The request path is used as the instance when p does not name one.
+func Write(c *gin.Context, p *Problem) {
+	if p.Instance == "" {
+		p.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
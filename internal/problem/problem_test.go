@@ -0,0 +1,90 @@
+package problem
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/validation"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestFrom_Validation checks the problem of field errors collected by a validator and of a plain error.
+func TestFrom_Validation(t *testing.T) {
+	v := validation.New()
+	v.Check("name", validation.String("", 255))
+	v.Check("email", validation.Email("nope"))
+
+	p := From(apperr.Validation(v.Err()))
+	assert.Equal(t, http.StatusBadRequest, p.Status)
+	assert.Equal(t, TypeValidation, p.Type)
+	assert.Equal(t, "validation", p.Code)
+	assert.Equal(t, "invalid fields: email, name", p.Detail)
+	assert.Equal(t, validation.CodeRequired, p.Errors["name"][0].Code)
+	assert.Equal(t, validation.CodeInvalidFormat, p.Errors["email"][0].Code)
+
+	p = From(apperr.Validation(errors.New("bad input")))
+	assert.Equal(t, "bad input", p.Detail)
+	assert.Nil(t, p.Errors)
+}
+
+// TestFrom checks the status, type and code given by the kind of an error, and that errors
+// which are not application errors, as well as the causes of internal errors, are not exposed.
+func TestFrom(t *testing.T) {
+	p := From(apperr.NotFound("Brand not found"))
+	assert.Equal(t, http.StatusNotFound, p.Status)
+	assert.Equal(t, "/problems/not-found", p.Type)
+	assert.Equal(t, "Not Found", p.Title)
+	assert.Equal(t, "not_found", p.Code)
+	assert.Equal(t, "Brand not found", p.Detail)
+
+	p = From(apperr.Internal("Failed to create brand", errors.New("dial tcp: connection refused")))
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+	assert.Equal(t, "Failed to create brand", p.Detail)
+
+	p = From(errors.New("dial tcp: connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, p.Status)
+	assert.Equal(t, "internal", p.Code)
+	assert.Equal(t, "Internal server error", p.Detail)
+}
+
+// TestProblem_MarshalJSON checks that extensions are written as members without replacing the standard ones.
+func TestProblem_MarshalJSON(t *testing.T) {
+	p := From(apperr.New(apperr.KindConflict, "Still referenced").With("dependents", map[string]int64{"orders": 2}).With("status", 200))
+
+	data, err := json.Marshal(p)
+	assert.NoError(t, err)
+	var body map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &body))
+	assert.Equal(t, float64(http.StatusConflict), body["status"])
+	assert.Equal(t, "conflict", body["code"])
+	assert.Equal(t, map[string]interface{}{"orders": float64(2)}, body["dependents"])
+}
+
+// TestWrite checks that a problem is written with its status, the problem+json content type
+// and the request path as instance.
+func TestWrite(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/brands", func(c *gin.Context) {
+		Write(c, From(apperr.Validation(validation.Errors{{Field: "name", Code: validation.CodeRequired, Message: "must not be empty"}})))
+	})
+
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodPost, "/brands", nil)
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+	assert.Equal(t, ContentType, rr.Header().Get("Content-Type"))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal("Failed to parse response JSON")
+	}
+	assert.Equal(t, "/brands", body["instance"])
+	assert.Equal(t, map[string]interface{}{
+		"name": []interface{}{map[string]interface{}{"code": "required", "message": "must not be empty"}},
+	}, body["errors"])
+}
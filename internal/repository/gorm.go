@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+	"gorm.io/gorm"
+	"slices"
+)
+
+// NewGORM returns the repositories of every entity backed by db.
+// Every query runs in a session bound to the context it is given, so that it is traced as a child of the request span,
+// records the actor of the request in the audit log and stops when the request is cancelled.
+func NewGORM(db *gorm.DB) *Repositories {
+	repos := &Repositories{
+		Users:           &gormUsers{gormRepository[models.User, *models.User]{db: db, table: "users", search: models.SearchUsers}},
+		Brands:          &gormBrands{gormRepository[models.Brands, *models.Brands]{db: db, table: "brands", search: models.SearchBrand}},
+		Categories:      &gormCategories{gormRepository[models.Category, *models.Category]{db: db, table: "categories", search: models.SearchCategory}},
+		Products:        &gormProducts{gormRepository[models.Product, *models.Product]{db: db, table: "products", search: models.SearchProduct}},
+		ProductVariants: &gormProductVariants{gormRepository[models.ProductVariant, *models.ProductVariant]{db: db, table: "product_variants", search: models.SearchProductVariant}},
+		ProductImages:   &gormProductImages{gormRepository[models.ProductImage, *models.ProductImage]{db: db, table: "product_images", search: models.SearchProductImage}},
+		Attributes:      &gormAttributes{gormRepository[models.Attribute, *models.Attribute]{db: db, table: "attributes", search: models.SearchAttribute}},
+		Orders:          &gormOrders{gormRepository[models.Order, *models.Order]{db: db, table: "orders", search: models.SearchOrder}},
+		OrderItems:      &gormRepository[models.OrderItem, *models.OrderItem]{db: db, table: "order_items", search: models.SearchOrderItem},
+		Payments:        &gormRepository[models.Payment, *models.Payment]{db: db, table: "payments", search: models.SearchPayment},
+		ShippingDetails: &gormRepository[models.ShippingDetails, *models.ShippingDetails]{db: db, table: "shipping_details", search: models.SearchShippingDetails},
+		Reviews:         &gormRepository[models.Review, *models.Review]{db: db, table: "reviews", search: models.SearchReview},
+		Audit:           &gormAuditLog{db: db},
+	}
+	repos.transaction = func(ctx context.Context, fn func(tx *Repositories) error) error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(NewGORM(tx))
+		})
+	}
+	return repos
+}
+
+// gormRepository stores the rows of the model T, whose pointer type P is versioned, in the table of db.
+// Searches are delegated to the search function of the model.
+type gormRepository[T any, P interface {
+	*T
+	models.Versioner
+}] struct {
+	db     *gorm.DB
+	table  string
+	search func(db *gorm.DB, params map[string]interface{}) ([]T, error)
+}
+
+// query returns a session bound to ctx, scoped by q.
+func (r *gormRepository[T, P]) query(ctx context.Context, q Query) *gorm.DB {
+	db := r.db.WithContext(ctx)
+	switch q.Trashed {
+	case WithTrashed:
+		db = db.Unscoped()
+	case OnlyTrashed:
+		db = db.Unscoped().Where(r.table + ".deleted_at IS NOT NULL")
+	}
+	for _, path := range q.Includes {
+		db = db.Preload(path)
+	}
+	return db
+}
+
+func (r *gormRepository[T, P]) Get(ctx context.Context, id uint, q Query) (*T, error) {
+	var row T
+	if err := r.query(ctx, q).Where(r.table+".id = ?", id).First(&row).Error; err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+func (r *gormRepository[T, P]) List(ctx context.Context, q Query) ([]T, error) {
+	rows := []T{}
+	if err := r.query(ctx, q).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *gormRepository[T, P]) Search(ctx context.Context, params map[string]interface{}, q Query) ([]T, error) {
+	rows, err := r.search(r.query(ctx, q), params)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	return rows, nil
+}
+
+func (r *gormRepository[T, P]) Exists(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(new(T)).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *gormRepository[T, P]) Version(ctx context.Context, id uint) (uint, error) {
+	var versions []uint
+	if err := r.db.WithContext(ctx).Model(new(T)).Where("id = ?", id).Pluck("version", &versions).Error; err != nil {
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return versions[0], nil
+}
+
+func (r *gormRepository[T, P]) Create(ctx context.Context, row *T) error {
+	return r.db.WithContext(ctx).Create(row).Error
+}
+
+func (r *gormRepository[T, P]) Save(ctx context.Context, row *T, columns ...string) error {
+	return models.SaveVersioned(r.db.WithContext(ctx), P(row), columns...)
+}
+
+func (r *gormRepository[T, P]) Delete(ctx context.Context, id, version uint) error {
+	return models.DeleteVersion(r.db.WithContext(ctx), new(T), id, version)
+}
+
+func (r *gormRepository[T, P]) Restore(ctx context.Context, id uint) (*T, error) {
+	if err := models.Restore(r.db.WithContext(ctx), new(T), id); err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, id, Query{})
+}
+
+// gormUsers adds the lookup by username to the repository of users.
+type gormUsers struct {
+	gormRepository[models.User, *models.User]
+}
+
+func (r *gormUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// gormBrands adds the listing of the files of logos to the repository of brands.
+type gormBrands struct {
+	gormRepository[models.Brands, *models.Brands]
+}
+
+func (r *gormBrands) Files(ctx context.Context) ([]string, error) {
+	return models.ImageFiles(r.db.WithContext(ctx), &models.Brands{}, "logo_file", "logo_thumbnail")
+}
+
+// gormCategories adds the tree queries to the repository of categories.
+type gormCategories struct {
+	gormRepository[models.Category, *models.Category]
+}
+
+// Save writes row and, when its path is written and changed, rewrites the paths of its descendants in the same
+// transaction. The row and its parent are locked first and the move checked again, since the parent may have been
+// moved since the path of row was derived from it.
+func (r *gormCategories) Save(ctx context.Context, row *models.Category, columns ...string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		oldPath := ""
+		if len(columns) == 0 || slices.Contains(columns, "path") {
+			var err error
+			if oldPath, err = models.LockCategoryMove(tx, row); err != nil {
+				return err
+			}
+		}
+		if err := models.SaveVersioned(tx, row, columns...); err != nil {
+			return err
+		}
+		if oldPath == "" {
+			return nil
+		}
+		return models.MoveCategorySubtree(tx, oldPath, row.Path)
+	})
+}
+
+func (r *gormCategories) Descendants(ctx context.Context, path string, q Query) ([]models.Category, error) {
+	return models.CategoryDescendants(r.query(ctx, q), path)
+}
+
+func (r *gormCategories) Ancestors(ctx context.Context, category *models.Category) ([]models.Category, error) {
+	return models.CategoryAncestors(r.db.WithContext(ctx), category)
+}
+
+// gormProducts adds the filtering by categories and attributes and the specification sheets to the repository
+// of products.
+type gormProducts struct {
+	gormRepository[models.Product, *models.Product]
+}
+
+// filter scopes db, a query of products, to those selected by filter. It reports false when none can be.
+func (r *gormProducts) filter(db *gorm.DB, filter ProductFilter) (*gorm.DB, bool) {
+	if filter.CategoryIDs != nil {
+		if len(filter.CategoryIDs) == 0 {
+			return db, false
+		}
+		db = db.Where("products.category_id IN ?", filter.CategoryIDs)
+	}
+	return models.FilterProducts(db, filter.Attributes), true
+}
+
+func (r *gormProducts) Filter(ctx context.Context, filter ProductFilter, q Query) ([]models.Product, error) {
+	products := []models.Product{}
+	db, ok := r.filter(r.query(ctx, q), filter)
+	if !ok {
+		return products, nil
+	}
+	if err := db.Find(&products).Error; err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *gormProducts) Facets(ctx context.Context, filter ProductFilter, names []string) ([]models.FacetCount, error) {
+	db, ok := r.filter(r.db.WithContext(ctx), filter)
+	if !ok {
+		return []models.FacetCount{}, nil
+	}
+	return models.CountProductFacets(db, names)
+}
+
+func (r *gormProducts) Attributes(ctx context.Context, productID uint) ([]models.ProductAttribute, error) {
+	return models.GetProductAttributes(r.db.WithContext(ctx), productID)
+}
+
+func (r *gormProducts) SetAttributes(ctx context.Context, product *models.Product, values []models.ProductAttribute) error {
+	read := product.Version
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := models.SaveVersioned(tx, product, "updated_at"); err != nil {
+			return err
+		}
+		return models.ReplaceProductAttributes(tx, product.ID, values)
+	})
+	if err != nil {
+		product.Version = read
+	}
+	return err
+}
+
+// gormProductVariants adds the listing by product to the repository of product variants.
+type gormProductVariants struct {
+	gormRepository[models.ProductVariant, *models.ProductVariant]
+}
+
+func (r *gormProductVariants) ListByProduct(ctx context.Context, productID uint, q Query) ([]models.ProductVariant, error) {
+	variants := []models.ProductVariant{}
+	if err := r.query(ctx, q).Where("product_variants.product_id = ?", productID).Order("product_variants.id").Find(&variants).Error; err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// gormProductImages adds the listing by product, the ordering and the choice of the primary image to the repository
+// of product images.
+type gormProductImages struct {
+	gormRepository[models.ProductImage, *models.ProductImage]
+}
+
+func (r *gormProductImages) ListByProduct(ctx context.Context, productID uint) ([]models.ProductImage, error) {
+	return models.GetProductImages(r.db.WithContext(ctx), productID)
+}
+
+func (r *gormProductImages) Add(ctx context.Context, image *models.ProductImage) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return models.AddProductImage(tx, image)
+	})
+}
+
+func (r *gormProductImages) Reorder(ctx context.Context, productID uint, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return models.ReorderProductImages(tx, productID, ids)
+	})
+}
+
+func (r *gormProductImages) SetPrimary(ctx context.Context, productID, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return models.SetPrimaryProductImage(tx, productID, id)
+	})
+}
+
+func (r *gormProductImages) Files(ctx context.Context) ([]string, error) {
+	return models.ImageFiles(r.db.WithContext(ctx), &models.ProductImage{}, "file", "thumbnail")
+}
+
+// gormOrders adds the computation of the totals to the repository of orders.
+type gormOrders struct {
+	gormRepository[models.Order, *models.Order]
+}
+
+func (r *gormOrders) UpdateTotal(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return models.UpdateOrderTotal(tx, id)
+	})
+}
+
+// gormAttributes adds the listings by category and name and the lookup of values to the repository of attributes.
+type gormAttributes struct {
+	gormRepository[models.Attribute, *models.Attribute]
+}
+
+func (r *gormAttributes) ListByCategories(ctx context.Context, categoryIDs []uint) ([]models.Attribute, error) {
+	return models.GetAttributes(r.db.WithContext(ctx), categoryIDs)
+}
+
+func (r *gormAttributes) ListByName(ctx context.Context, name string) ([]models.Attribute, error) {
+	attributes := []models.Attribute{}
+	if err := r.db.WithContext(ctx).Where("attributes.name = ?", name).Order("attributes.id").Find(&attributes).Error; err != nil {
+		return nil, err
+	}
+	return attributes, nil
+}
+
+func (r *gormAttributes) InUse(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&models.ProductAttribute{}).Where("attribute_id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// gormAuditLog reads the audit log from the audit_log table.
+type gormAuditLog struct {
+	db *gorm.DB
+}
+
+func (l *gormAuditLog) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	return audit.Find(l.db.WithContext(ctx), filter)
+}
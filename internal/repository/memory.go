@@ -0,0 +1,719 @@
+package repository
+
+import (
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewMemory returns the repositories of every entity backed by maps, for unit tests of the services that
+// should not need a database. Searches follow the GORM repositories: string columns searched with LIKE match
+// substrings, dates searched with a day match every time of that day and other columns must be equal.
+// Unlike them, associations are not loaded, unique indexes other than the ID are not enforced and deleting a row
+// does not apply models.DeletePolicies to the rows referencing it.
+func NewMemory() *Repositories {
+	brands := &memoryBrands{newMemory[models.Brands]()}
+	categories := newMemory[models.Category]()
+	products := newMemory[models.Product]("name", "description")
+	products.matchers = map[string]func(*models.Product, interface{}) bool{
+		"brand_name": func(product *models.Product, value interface{}) bool {
+			brand, err := brands.Get(context.Background(), product.Brand_ID, Query{})
+			return err == nil && like(brand.Name, value)
+		},
+		"category_name": func(product *models.Product, value interface{}) bool {
+			category, err := categories.Get(context.Background(), product.Category_ID, Query{})
+			return err == nil && like(category.Name, value)
+		},
+	}
+	attributes := &memoryAttributes{memoryRepository: newMemory[models.Attribute]("name", "label")}
+	items := newMemory[models.OrderItem]()
+	catalog := &memoryProducts{memoryRepository: products, attributes: attributes, values: map[uint][]models.ProductAttribute{}}
+	attributes.products = catalog
+	return &Repositories{
+		Users:           &memoryUsers{newMemory[models.User]("username", "email", "first_name", "last_name", "address")},
+		Brands:          brands,
+		Categories:      &memoryCategories{categories},
+		Products:        catalog,
+		ProductVariants: &memoryProductVariants{newMemory[models.ProductVariant]("sku")},
+		ProductImages:   &memoryProductImages{newMemory[models.ProductImage]("alt")},
+		Attributes:      attributes,
+		Orders:          &memoryOrders{memoryRepository: newMemory[models.Order]("status"), items: items},
+		OrderItems:      items,
+		Payments:        newMemory[models.Payment]("payment_method", "status"),
+		ShippingDetails: newMemory[models.ShippingDetails]("address", "status"),
+		Reviews:         newMemory[models.Review]("comment"),
+		Audit:           &MemoryAuditLog{},
+	}
+}
+
+// memoryRepository stores rows of the model T, which embeds gorm.Model and models.Versioned, by ID.
+// Their fields are reached by reflection, and their columns are the JSON names of the fields.
+type memoryRepository[T any] struct {
+	mu   sync.Mutex
+	rows map[uint]*T
+	next uint
+	// like holds the string columns searched by substring.
+	like map[string]bool
+	// matchers search keys that are not columns of T, such as the brand name of a product.
+	matchers map[string]func(row *T, value interface{}) bool
+}
+
+// newMemory returns an empty repository of T searching the like columns by substring.
+func newMemory[T any](like ...string) *memoryRepository[T] {
+	r := &memoryRepository[T]{rows: map[uint]*T{}, like: map[string]bool{}}
+	for _, column := range like {
+		r.like[column] = true
+	}
+	return r
+}
+
+func (r *memoryRepository[T]) Get(ctx context.Context, id uint, q Query) (*T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, found := r.rows[id]
+	if !found || !visible(row, q.Trashed) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *row
+	return &copied, nil
+}
+
+func (r *memoryRepository[T]) List(ctx context.Context, q Query) ([]T, error) {
+	return r.Search(ctx, nil, q)
+}
+
+func (r *memoryRepository[T]) Search(ctx context.Context, params map[string]interface{}, q Query) ([]T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]uint, 0, len(r.rows))
+	for id := range r.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	rows := []T{}
+	for _, id := range ids {
+		row := r.rows[id]
+		if visible(row, q.Trashed) && r.matches(row, params) {
+			rows = append(rows, *row)
+		}
+	}
+	return rows, nil
+}
+
+func (r *memoryRepository[T]) Exists(ctx context.Context, id uint) (bool, error) {
+	_, err := r.Get(ctx, id, Query{})
+	return err == nil, nil
+}
+
+func (r *memoryRepository[T]) Version(ctx context.Context, id uint) (uint, error) {
+	row, err := r.Get(ctx, id, Query{})
+	if err != nil {
+		return 0, err
+	}
+	return uint(field(row, "Version").Uint()), nil
+}
+
+func (r *memoryRepository[T]) Create(ctx context.Context, row *T) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(row)
+}
+
+// create is Create for a caller holding the lock.
+func (r *memoryRepository[T]) create(row *T) error {
+	id := uint(field(row, "ID").Uint())
+	if id == 0 {
+		r.next++
+		id = r.next
+		field(row, "ID").SetUint(uint64(id))
+	}
+	if _, taken := r.rows[id]; taken {
+		return fmt.Errorf("%w: id %d", gorm.ErrDuplicatedKey, id)
+	}
+	if id > r.next {
+		r.next = id
+	}
+	now := time.Now()
+	field(row, "CreatedAt").Set(reflect.ValueOf(now))
+	field(row, "UpdatedAt").Set(reflect.ValueOf(now))
+	if field(row, "Version").Uint() == 0 {
+		field(row, "Version").SetUint(1)
+	}
+	copied := *row
+	r.rows[id] = &copied
+	return nil
+}
+
+func (r *memoryRepository[T]) Save(ctx context.Context, row *T, columns ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, found := r.rows[uint(field(row, "ID").Uint())]
+	if !found || !visible(stored, WithoutTrashed) || field(stored, "Version").Uint() != field(row, "Version").Uint() {
+		return models.ErrVersionConflict
+	}
+
+	field(row, "Version").SetUint(field(row, "Version").Uint() + 1)
+	field(row, "UpdatedAt").Set(reflect.ValueOf(time.Now()))
+	if len(columns) == 0 {
+		copied := *row
+		r.rows[uint(field(row, "ID").Uint())] = &copied
+		return nil
+	}
+	for _, name := range append([]string{"Version", "UpdatedAt"}, fieldNames(row, columns)...) {
+		field(stored, name).Set(field(row, name))
+	}
+	return nil
+}
+
+func (r *memoryRepository[T]) Delete(ctx context.Context, id, version uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	row, found := r.rows[id]
+	if !found || !visible(row, WithoutTrashed) {
+		return gorm.ErrRecordNotFound
+	}
+	if version != 0 && field(row, "Version").Uint() != uint64(version) {
+		return models.ErrVersionConflict
+	}
+	field(row, "DeletedAt").Set(reflect.ValueOf(gorm.DeletedAt{Time: time.Now(), Valid: true}))
+	return nil
+}
+
+func (r *memoryRepository[T]) Restore(ctx context.Context, id uint) (*T, error) {
+	r.mu.Lock()
+	row, found := r.rows[id]
+	if !found || !visible(row, OnlyTrashed) {
+		r.mu.Unlock()
+		return nil, gorm.ErrRecordNotFound
+	}
+	field(row, "DeletedAt").Set(reflect.ValueOf(gorm.DeletedAt{}))
+	r.mu.Unlock()
+	return r.Get(ctx, id, Query{})
+}
+
+// matches reports whether row matches every search parameter. Unknown keys are ignored, as by the GORM repositories.
+func (r *memoryRepository[T]) matches(row *T, params map[string]interface{}) bool {
+	for key, value := range params {
+		if matcher, found := r.matchers[key]; found {
+			if !matcher(row, value) {
+				return false
+			}
+			continue
+		}
+		names := fieldNames(row, []string{key})
+		if len(names) == 0 {
+			continue
+		}
+		current := field(row, names[0])
+		if current.Kind() == reflect.String && r.like[key] {
+			if !like(current.String(), value) {
+				return false
+			}
+		} else if !equal(current, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryUsers adds the lookup by username to the repository of users.
+type memoryUsers struct {
+	*memoryRepository[models.User]
+}
+
+func (r *memoryUsers) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	users, err := r.List(ctx, Query{})
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// memoryBrands adds the listing of the files of logos to the repository of brands.
+type memoryBrands struct {
+	*memoryRepository[models.Brands]
+}
+
+func (r *memoryBrands) Files(ctx context.Context) ([]string, error) {
+	brands, err := r.List(ctx, Query{Trashed: WithTrashed})
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, brand := range brands {
+		if brand.Logo != nil && brand.Logo.File != "" {
+			files = append(files, brand.Logo.Files()...)
+		}
+	}
+	return files, nil
+}
+
+// memoryCategories adds the tree queries to the repository of categories.
+type memoryCategories struct {
+	*memoryRepository[models.Category]
+}
+
+// Save writes row and, when its path is written and changed, rewrites the paths of its descendants. The move is
+// checked again against the parent, like by the GORM repository.
+func (r *memoryCategories) Save(ctx context.Context, row *models.Category, columns ...string) error {
+	stored, err := r.Get(ctx, row.ID, Query{Trashed: WithTrashed})
+	if err != nil {
+		return models.ErrVersionConflict
+	}
+	moved := (len(columns) == 0 || slices.Contains(columns, "path")) && stored.Path != "" && stored.Path != row.Path
+	if moved {
+		var parent *models.Category
+		if row.Parent_ID != nil {
+			parent, _ = r.Get(ctx, *row.Parent_ID, Query{Trashed: WithTrashed})
+		}
+		if err := models.CheckCategoryMove(row, stored.Path, parent); err != nil {
+			return err
+		}
+	}
+	if err := r.memoryRepository.Save(ctx, row, columns...); err != nil {
+		return err
+	}
+	if !moved {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, category := range r.rows {
+		if category.Path != stored.Path && strings.HasPrefix(category.Path, stored.Path) {
+			category.Path = row.Path + strings.TrimPrefix(category.Path, stored.Path)
+		}
+	}
+	return nil
+}
+
+func (r *memoryCategories) Descendants(ctx context.Context, path string, q Query) ([]models.Category, error) {
+	categories, err := r.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	descendants := []models.Category{}
+	for _, category := range categories {
+		if category.Path != path && strings.HasPrefix(category.Path, path) {
+			descendants = append(descendants, category)
+		}
+	}
+	models.SortCategories(descendants)
+	return descendants, nil
+}
+
+func (r *memoryCategories) Ancestors(ctx context.Context, category *models.Category) ([]models.Category, error) {
+	ancestors := []models.Category{}
+	for _, id := range category.AncestorIDs() {
+		if ancestor, err := r.Get(ctx, id, Query{}); err == nil {
+			ancestors = append(ancestors, *ancestor)
+		}
+	}
+	return ancestors, nil
+}
+
+// memoryProducts adds the filtering by categories and attributes and the specification sheets to the repository
+// of products. values holds the values of each product by product ID.
+type memoryProducts struct {
+	*memoryRepository[models.Product]
+	attributes *memoryAttributes
+	valuesMu   sync.Mutex
+	values     map[uint][]models.ProductAttribute
+}
+
+func (r *memoryProducts) Filter(ctx context.Context, filter ProductFilter, q Query) ([]models.Product, error) {
+	products, err := r.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	listed := []models.Product{}
+	for _, product := range products {
+		if r.selected(ctx, product, filter) {
+			listed = append(listed, product)
+		}
+	}
+	return listed, nil
+}
+
+func (r *memoryProducts) Facets(ctx context.Context, filter ProductFilter, names []string) ([]models.FacetCount, error) {
+	products, err := r.Filter(ctx, filter, Query{})
+	if err != nil {
+		return nil, err
+	}
+	counts := []models.FacetCount{}
+	for _, product := range products {
+		values, err := r.Attributes(ctx, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			if !slices.Contains(names, value.Attribute.Name) {
+				continue
+			}
+			counted := false
+			for i := range counts {
+				if counts[i].Name == value.Attribute.Name && counts[i].Value == value.Value {
+					counts[i].Count++
+					counted = true
+				}
+			}
+			if !counted {
+				counts = append(counts, models.FacetCount{Name: value.Attribute.Name, Value: value.Value, Count: 1})
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (r *memoryProducts) Attributes(ctx context.Context, productID uint) ([]models.ProductAttribute, error) {
+	r.valuesMu.Lock()
+	stored := r.values[productID]
+	r.valuesMu.Unlock()
+	values := []models.ProductAttribute{}
+	for _, value := range stored {
+		attribute, err := r.attributes.Get(ctx, value.Attribute_ID, Query{})
+		if err != nil {
+			continue
+		}
+		// As GORM does once a value is read, its Value is read from the column it is stored in.
+		value.Attribute = attribute
+		value.AfterFind(nil)
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i].Attribute_ID < values[j].Attribute_ID })
+	return values, nil
+}
+
+func (r *memoryProducts) SetAttributes(ctx context.Context, product *models.Product, values []models.ProductAttribute) error {
+	r.valuesMu.Lock()
+	defer r.valuesMu.Unlock()
+	if err := r.Save(ctx, product, "updated_at"); err != nil {
+		return err
+	}
+	stored := make([]models.ProductAttribute, len(values))
+	for i, value := range values {
+		value.Product_ID = product.ID
+		value.Attribute = nil
+		stored[i] = value
+	}
+	r.values[product.ID] = stored
+	return nil
+}
+
+// selected reports whether filter selects product, comparing the values of live attributes.
+func (r *memoryProducts) selected(ctx context.Context, product models.Product, filter ProductFilter) bool {
+	if filter.CategoryIDs != nil && !slices.Contains(filter.CategoryIDs, product.Category_ID) {
+		return false
+	}
+	if len(filter.Attributes) == 0 {
+		return true
+	}
+	values, err := r.Attributes(ctx, product.ID)
+	if err != nil {
+		return false
+	}
+	for _, attributeFilter := range filter.Attributes {
+		matched := false
+		for _, value := range values {
+			matched = matched || value.Attribute.Name == attributeFilter.Name && attributeFilter.Matches(value.Value)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryProductVariants adds the listing by product to the repository of product variants.
+type memoryProductVariants struct {
+	*memoryRepository[models.ProductVariant]
+}
+
+func (r *memoryProductVariants) ListByProduct(ctx context.Context, productID uint, q Query) ([]models.ProductVariant, error) {
+	variants, err := r.List(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	listed := []models.ProductVariant{}
+	for _, variant := range variants {
+		if variant.Product_ID == productID {
+			listed = append(listed, variant)
+		}
+	}
+	return listed, nil
+}
+
+// memoryProductImages adds the listing by product, the ordering and the choice of the primary image to the repository
+// of product images.
+type memoryProductImages struct {
+	*memoryRepository[models.ProductImage]
+}
+
+func (r *memoryProductImages) ListByProduct(ctx context.Context, productID uint) ([]models.ProductImage, error) {
+	images, err := r.List(ctx, Query{})
+	if err != nil {
+		return nil, err
+	}
+	listed := []models.ProductImage{}
+	for _, image := range images {
+		if image.Product_ID == productID {
+			listed = append(listed, image)
+		}
+	}
+	models.SortProductImages(listed)
+	return listed, nil
+}
+
+func (r *memoryProductImages) Add(ctx context.Context, image *models.ProductImage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var gallery []*models.ProductImage
+	image.Position = 0
+	for _, stored := range r.rows {
+		if stored.Product_ID == image.Product_ID && !stored.DeletedAt.Valid {
+			gallery = append(gallery, stored)
+			image.Position = max(image.Position, stored.Position+1)
+		}
+	}
+	image.Primary = image.Primary || len(gallery) == 0
+	if err := r.create(image); err != nil {
+		return err
+	}
+	for _, stored := range gallery {
+		if image.Primary && stored.Primary {
+			stored.Primary = false
+			stored.Version++
+		}
+	}
+	return nil
+}
+
+func (r *memoryProductImages) Reorder(ctx context.Context, productID uint, ids []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for position, id := range ids {
+		if image, found := r.rows[id]; found && image.Product_ID == productID && !image.DeletedAt.Valid && image.Position != position {
+			image.Position = position
+			image.Version++
+		}
+	}
+	return nil
+}
+
+func (r *memoryProductImages) SetPrimary(ctx context.Context, productID, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, image := range r.rows {
+		if image.Product_ID == productID && !image.DeletedAt.Valid && image.Primary != (image.ID == id) {
+			image.Primary = image.ID == id
+			image.Version++
+		}
+	}
+	return nil
+}
+
+func (r *memoryProductImages) Files(ctx context.Context) ([]string, error) {
+	images, err := r.List(ctx, Query{Trashed: WithTrashed})
+	if err != nil {
+		return nil, err
+	}
+	files := []string{}
+	for _, image := range images {
+		files = append(files, image.Files()...)
+	}
+	return files, nil
+}
+
+// memoryOrders adds the computation of the totals to the repository of orders, summing the rows of items.
+type memoryOrders struct {
+	*memoryRepository[models.Order]
+	items *memoryRepository[models.OrderItem]
+}
+
+func (r *memoryOrders) UpdateTotal(ctx context.Context, id uint) error {
+	items, err := r.items.List(ctx, Query{})
+	if err != nil {
+		return err
+	}
+	total := 0.0
+	for _, item := range items {
+		if item.Order_ID == id {
+			total += item.Subtotal
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	order, found := r.rows[id]
+	if !found {
+		return gorm.ErrRecordNotFound
+	}
+	if total = models.RoundCents(total); order.Total_amount != total {
+		order.Total_amount = total
+		order.Version++
+	}
+	return nil
+}
+
+// memoryAttributes adds the listings by category and name and the lookup of values to the repository of attributes.
+type memoryAttributes struct {
+	*memoryRepository[models.Attribute]
+	products *memoryProducts
+}
+
+func (r *memoryAttributes) ListByCategories(ctx context.Context, categoryIDs []uint) ([]models.Attribute, error) {
+	attributes, err := r.List(ctx, Query{})
+	if err != nil || categoryIDs == nil {
+		return attributes, err
+	}
+	listed := []models.Attribute{}
+	for _, attribute := range attributes {
+		if slices.Contains(categoryIDs, attribute.Category_ID) {
+			listed = append(listed, attribute)
+		}
+	}
+	return listed, nil
+}
+
+func (r *memoryAttributes) ListByName(ctx context.Context, name string) ([]models.Attribute, error) {
+	attributes, err := r.List(ctx, Query{})
+	if err != nil {
+		return nil, err
+	}
+	listed := []models.Attribute{}
+	for _, attribute := range attributes {
+		if attribute.Name == name {
+			listed = append(listed, attribute)
+		}
+	}
+	return listed, nil
+}
+
+func (r *memoryAttributes) InUse(ctx context.Context, id uint) (bool, error) {
+	r.products.valuesMu.Lock()
+	defer r.products.valuesMu.Unlock()
+	for _, values := range r.products.values {
+		for _, value := range values {
+			if value.Attribute_ID == id {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// MemoryAuditLog is an audit log held in memory. Its entries are not recorded by the memory repositories;
+// tests add the entries they need.
+type MemoryAuditLog struct {
+	mu      sync.Mutex
+	entries []audit.Entry
+}
+
+// Add appends entries to the log.
+func (l *MemoryAuditLog) Add(entries ...audit.Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entries...)
+}
+
+func (l *MemoryAuditLog) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := []audit.Entry{}
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		entry := l.entries[i]
+		if (filter.Entity != "" && entry.Entity != filter.Entity) || (filter.EntityID != 0 && entry.EntityID != filter.EntityID) ||
+			(filter.Actor != "" && entry.Actor != filter.Actor) {
+			continue
+		}
+		if filter.Limit > 0 && len(entries) == filter.Limit {
+			break
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// visible reports whether row is returned by reads selecting trashed rows.
+func visible(row interface{}, trashed Trashed) bool {
+	deleted := field(row, "DeletedAt").Interface().(gorm.DeletedAt).Valid
+	switch trashed {
+	case WithTrashed:
+		return true
+	case OnlyTrashed:
+		return deleted
+	}
+	return !deleted
+}
+
+// field returns the settable field of row, a pointer to a model, with the given Go name.
+func field(row interface{}, name string) reflect.Value {
+	return reflect.ValueOf(row).Elem().FieldByName(name)
+}
+
+// fieldNames returns the Go names of the fields of row whose JSON names are listed in columns.
+func fieldNames(row interface{}, columns []string) []string {
+	var names []string
+	rowType := reflect.TypeOf(row).Elem()
+	for i := 0; i < rowType.NumField(); i++ {
+		structField := rowType.Field(i)
+		jsonName := strings.Split(structField.Tag.Get("json"), ",")[0]
+		for _, column := range columns {
+			if jsonName == column {
+				names = append(names, structField.Name)
+			}
+		}
+	}
+	return names
+}
+
+// like reports whether value is a string contained in s, ignoring case as LIKE does for ASCII letters.
+func like(s string, value interface{}) bool {
+	pattern, ok := value.(string)
+	return ok && strings.Contains(strings.ToLower(s), strings.ToLower(pattern))
+}
+
+// equal reports whether the field current holds value, comparing numbers whatever their type. A time.Time value is
+// the start of a day, held by the times of that day.
+func equal(current reflect.Value, value interface{}) bool {
+	switch current.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return toFloat(value) == float64(current.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return toFloat(value) == float64(current.Uint())
+	case reflect.Float32, reflect.Float64:
+		return toFloat(value) == current.Float()
+	}
+	if day, ok := value.(time.Time); ok {
+		date, ok := current.Interface().(time.Time)
+		return ok && !date.Before(day) && date.Before(day.AddDate(0, 0, 1))
+	}
+	return reflect.DeepEqual(current.Interface(), value)
+}
+
+// toFloat converts a numeric search parameter to float64, or NaN when it is not a number.
+func toFloat(value interface{}) float64 {
+	number := reflect.ValueOf(value)
+	switch number.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(number.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(number.Uint())
+	case reflect.Float32, reflect.Float64:
+		return number.Float()
+	}
+	return math.NaN()
+}
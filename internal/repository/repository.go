@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+)
+
+// Trashed selects the rows returned by reads according to the trash.
+type Trashed int
+
+const (
+	// WithoutTrashed hides deleted rows. It is the default.
+	WithoutTrashed Trashed = iota
+	// WithTrashed returns deleted rows along with the others.
+	WithTrashed
+	// OnlyTrashed returns nothing but deleted rows.
+	OnlyTrashed
+)
+
+// Query holds the options of reads: the rows returned according to the trash, and the associations
+// loaded with each row, as GORM preload paths such as "Items.Product".
+type Query struct {
+	Trashed  Trashed
+	Includes []string
+}
+
+// Repository stores the rows of a model. A missing row is reported as gorm.ErrRecordNotFound by every
+// implementation, so that callers classify errors the same way whatever stores the rows.
+type Repository[T any] interface {
+	// Get returns the row with the given ID.
+	Get(ctx context.Context, id uint, q Query) (*T, error)
+	// List returns every row.
+	List(ctx context.Context, q Query) ([]T, error)
+	// Search returns the rows matching params, keyed by column as parsed from the query string.
+	Search(ctx context.Context, params map[string]interface{}, q Query) ([]T, error)
+	// Exists reports whether the row with the given ID exists and is not in the trash.
+	Exists(ctx context.Context, id uint) (bool, error)
+	// Version returns the version of the row with the given ID.
+	Version(ctx context.Context, id uint) (uint, error)
+	// Create inserts row.
+	Create(ctx context.Context, row *T) error
+	// Save writes the given columns of row, or all of them, provided the stored row still has the version of row,
+	// and increments it. It returns models.ErrVersionConflict, leaving row unchanged, otherwise.
+	Save(ctx context.Context, row *T, columns ...string) error
+	// Delete moves the row with the given ID to the trash, provided it still has the given version unless it is 0.
+	// It returns a *models.DependentsError when rows still reference it, and models.ErrVersionConflict, deleting
+	// nothing, when the row was changed since that version.
+	Delete(ctx context.Context, id, version uint) error
+	// Restore takes the row with the given ID out of the trash and returns it. It returns
+	// a *models.DeletedParentError when a row it references is in the trash.
+	Restore(ctx context.Context, id uint) (*T, error)
+}
+
+// UserRepository stores users.
+type UserRepository interface {
+	Repository[models.User]
+	// GetByUsername returns the user with the given username.
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+}
+
+// BrandRepository stores brands.
+type BrandRepository interface {
+	Repository[models.Brands]
+	// Files returns the storage keys of the files of the logos of brands, including those in the trash.
+	Files(ctx context.Context) ([]string, error)
+}
+
+// CategoryRepository stores categories. Saving a category whose path changed moves its descendants with it.
+type CategoryRepository interface {
+	Repository[models.Category]
+	// Descendants returns the categories below the category with the given path, parents before their children.
+	Descendants(ctx context.Context, path string, q Query) ([]models.Category, error)
+	// Ancestors returns the ancestors of category, from the root down to its parent.
+	Ancestors(ctx context.Context, category *models.Category) ([]models.Category, error)
+}
+
+// ProductFilter selects the products of any of the categories with the IDs in CategoryIDs, or of every category
+// when it is nil, whose values match every filter of Attributes.
+type ProductFilter struct {
+	CategoryIDs []uint
+	Attributes  []models.AttributeFilter
+}
+
+// ProductRepository stores products and their values of attributes, their specification sheets.
+type ProductRepository interface {
+	Repository[models.Product]
+	// Filter returns the products selected by filter.
+	Filter(ctx context.Context, filter ProductFilter, q Query) ([]models.Product, error)
+	// Facets counts the products selected by filter having each value of the live attributes named names.
+	Facets(ctx context.Context, filter ProductFilter, names []string) ([]models.FacetCount, error)
+	// Attributes returns the values of live attributes of the product with the given ID with their attribute,
+	// ordered by attribute ID.
+	Attributes(ctx context.Context, productID uint) ([]models.ProductAttribute, error)
+	// SetAttributes replaces the values of product by values, one per attribute, provided it still has the version
+	// it was read with, and increments its version. It returns models.ErrVersionConflict, leaving product and its
+	// values unchanged, otherwise.
+	SetAttributes(ctx context.Context, product *models.Product, values []models.ProductAttribute) error
+}
+
+// AttributeRepository stores the attributes categories define for their products.
+type AttributeRepository interface {
+	Repository[models.Attribute]
+	// ListByCategories returns the attributes of the categories with the given IDs, or every attribute when
+	// categoryIDs is nil, ordered by ID.
+	ListByCategories(ctx context.Context, categoryIDs []uint) ([]models.Attribute, error)
+	// ListByName returns the attributes with the given name, whatever their category.
+	ListByName(ctx context.Context, name string) ([]models.Attribute, error)
+	// InUse reports whether products have values of the attribute with the given ID.
+	InUse(ctx context.Context, id uint) (bool, error)
+}
+
+// ProductVariantRepository stores the variants of products.
+type ProductVariantRepository interface {
+	Repository[models.ProductVariant]
+	// ListByProduct returns the variants of the product with the given ID.
+	ListByProduct(ctx context.Context, productID uint, q Query) ([]models.ProductVariant, error)
+}
+
+// ProductImageRepository stores the images of the galleries of products.
+type ProductImageRepository interface {
+	Repository[models.ProductImage]
+	// ListByProduct returns the images of the product with the given ID in their order.
+	ListByProduct(ctx context.Context, productID uint) ([]models.ProductImage, error)
+	// Add creates image after the last image of its product, in a transaction. It is made primary when the product
+	// has no image, and the other images of the product lose the flag when it is primary.
+	Add(ctx context.Context, image *models.ProductImage) error
+	// Reorder moves the images of the product with the given ID to their index in ids, in a transaction.
+	Reorder(ctx context.Context, productID uint, ids []uint) error
+	// SetPrimary makes the image with the given ID the only primary image of the product with the given ID,
+	// in a transaction.
+	SetPrimary(ctx context.Context, productID, id uint) error
+	// Files returns the storage keys of the files of images, including those in the trash.
+	Files(ctx context.Context) ([]string, error)
+}
+
+// OrderRepository stores orders.
+type OrderRepository interface {
+	Repository[models.Order]
+	// UpdateTotal sets the total amount of the order with the given ID to the sum of the subtotals of its items
+	// not in the trash, in a transaction.
+	UpdateTotal(ctx context.Context, id uint) error
+}
+
+// OrderItemRepository stores order items.
+type OrderItemRepository interface {
+	Repository[models.OrderItem]
+}
+
+// PaymentRepository stores payments.
+type PaymentRepository interface {
+	Repository[models.Payment]
+}
+
+// ShippingDetailsRepository stores shipping details.
+type ShippingDetailsRepository interface {
+	Repository[models.ShippingDetails]
+}
+
+// ReviewRepository stores reviews.
+type ReviewRepository interface {
+	Repository[models.Review]
+}
+
+// AuditLog reads the audit log.
+type AuditLog interface {
+	// Find returns the entries matching filter, most recent first.
+	Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error)
+}
+
+// Repositories holds the repository of every entity, all backed by the same store.
+type Repositories struct {
+	Users           UserRepository
+	Brands          BrandRepository
+	Categories      CategoryRepository
+	Products        ProductRepository
+	ProductVariants ProductVariantRepository
+	ProductImages   ProductImageRepository
+	Attributes      AttributeRepository
+	Orders          OrderRepository
+	OrderItems      OrderItemRepository
+	Payments        PaymentRepository
+	ShippingDetails ShippingDetailsRepository
+	Reviews         ReviewRepository
+	Audit           AuditLog
+	// transaction runs fn with repositories bound to a transaction of the store, nil when the store has none.
+	transaction func(ctx context.Context, fn func(tx *Repositories) error) error
+}
+
+// Transaction runs fn with repositories whose writes are committed together when fn returns nil and rolled back
+// when it returns an error, which Transaction returns. The methods that run their own transaction run a nested one.
+// Stores without transactions, such as the memory repositories, run fn on r and keep the writes made before an error.
+func (r *Repositories) Transaction(ctx context.Context, fn func(tx *Repositories) error) error {
+	if r.transaction == nil {
+		return fn(r)
+	}
+	return r.transaction(ctx, fn)
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+)
+
+// implementations returns the GORM repositories over a SQLite database private to the test and the memory repositories,
+// so that the same expectations are checked against both.
+func implementations(t *testing.T) map[string]*Repositories {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repository.db")), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Brands{}, &models.Category{}, &models.Product{}, &models.ProductVariant{}, &models.User{},
+		&models.Order{}, &models.OrderItem{}, &models.Payment{}, &models.ShippingDetails{}, &models.Review{},
+		&models.Attribute{}, &models.ProductAttribute{}, &models.ProductImage{}); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	return map[string]*Repositories{"gorm": NewGORM(db), "memory": NewMemory()}
+}
+
+// TestRepository_CRUD checks reads, versioned saves and deletes and the trash of both implementations.
+func TestRepository_CRUD(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			brand := models.Brands{Name: "Acme", Description: "Gadgets"}
+			assert.NoError(t, repos.Brands.Create(ctx, &brand))
+			assert.NotZero(t, brand.ID)
+			assert.Equal(t, uint(1), brand.Version)
+
+			_, err := repos.Brands.Get(ctx, 999, Query{})
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+			stale := brand
+			brand.Name = "Acme Corp"
+			assert.NoError(t, repos.Brands.Save(ctx, &brand, "name"))
+			assert.Equal(t, uint(2), brand.Version)
+			stale.Name = "Stale"
+			assert.ErrorIs(t, repos.Brands.Save(ctx, &stale), models.ErrVersionConflict)
+
+			stored, err := repos.Brands.Get(ctx, brand.ID, Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, "Acme Corp", stored.Name)
+			version, err := repos.Brands.Version(ctx, brand.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, uint(2), version)
+
+			assert.ErrorIs(t, repos.Brands.Delete(ctx, brand.ID, 1), models.ErrVersionConflict)
+			found, err := repos.Brands.Exists(ctx, brand.ID)
+			assert.NoError(t, err)
+			assert.True(t, found, "a delete of a stale version deletes nothing")
+			assert.NoError(t, repos.Brands.Delete(ctx, brand.ID, 2))
+			found, err = repos.Brands.Exists(ctx, brand.ID)
+			assert.NoError(t, err)
+			assert.False(t, found)
+			trashed, err := repos.Brands.List(ctx, Query{Trashed: OnlyTrashed})
+			assert.NoError(t, err)
+			assert.Len(t, trashed, 1)
+
+			restored, err := repos.Brands.Restore(ctx, brand.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, "Acme Corp", restored.Name)
+			_, err = repos.Brands.Restore(ctx, brand.ID)
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		})
+	}
+}
+
+// TestRepository_Search checks that both implementations match strings by substring and numbers by value,
+// return an empty list rather than nil, and find users by username.
+func TestRepository_Search(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			acme := models.Brands{Name: "Acme"}
+			assert.NoError(t, repos.Brands.Create(ctx, &acme))
+			other := models.Brands{Name: "Other"}
+			assert.NoError(t, repos.Brands.Create(ctx, &other))
+			category := models.Category{Name: "Computers"}
+			assert.NoError(t, repos.Categories.Create(ctx, &category))
+			for _, product := range []models.Product{
+				{Name: "Laptop Pro", Price: 1500, Brand_ID: acme.ID, Category_ID: category.ID},
+				{Name: "Laptop Air", Price: 900, Brand_ID: acme.ID, Category_ID: category.ID},
+				{Name: "Phone", Price: 900, Brand_ID: other.ID, Category_ID: category.ID},
+			} {
+				assert.NoError(t, repos.Products.Create(ctx, &product))
+			}
+
+			products, err := repos.Products.Search(ctx, map[string]interface{}{"name": "laptop"}, Query{})
+			assert.NoError(t, err)
+			assert.Len(t, products, 2)
+			products, err = repos.Products.Search(ctx, map[string]interface{}{"price": float64(900), "brand_name": "acme"}, Query{})
+			assert.NoError(t, err)
+			if assert.Len(t, products, 1) {
+				assert.Equal(t, "Laptop Air", products[0].Name)
+			}
+			products, err = repos.Products.Search(ctx, map[string]interface{}{"name": "tablet"}, Query{})
+			assert.NoError(t, err)
+			assert.NotNil(t, products)
+			assert.Empty(t, products)
+
+			user := models.User{Username: "alice", Email: "alice@example.com"}
+			assert.NoError(t, repos.Users.Create(ctx, &user))
+			found, err := repos.Users.GetByUsername(ctx, "alice")
+			assert.NoError(t, err)
+			assert.Equal(t, user.ID, found.ID)
+			_, err = repos.Users.GetByUsername(ctx, "bob")
+			assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		})
+	}
+}
+
+// TestRepository_ProductVariants checks that the options of variants are stored and read back, and that variants
+// are listed by product and searched by SKU in both implementations.
+func TestRepository_ProductVariants(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			price := 1099.0
+			for _, variant := range []models.ProductVariant{
+				{Product_ID: 1, SKU: "PHN-128-BLK", Options: map[string]string{"color": "black", "storage": "128GB"}},
+				{Product_ID: 1, SKU: "PHN-256-BLK", Options: map[string]string{"color": "black", "storage": "256GB"}, Price: &price},
+				{Product_ID: 2, SKU: "TAB-64-WHT", Options: map[string]string{"color": "white"}},
+			} {
+				assert.NoError(t, repos.ProductVariants.Create(ctx, &variant))
+			}
+
+			variants, err := repos.ProductVariants.ListByProduct(ctx, 1, Query{})
+			assert.NoError(t, err)
+			if assert.Len(t, variants, 2) {
+				assert.Equal(t, map[string]string{"color": "black", "storage": "128GB"}, variants[0].Options)
+				assert.Nil(t, variants[0].Price)
+				if assert.NotNil(t, variants[1].Price) {
+					assert.Equal(t, price, *variants[1].Price)
+				}
+			}
+			found, err := repos.ProductVariants.Search(ctx, map[string]interface{}{"sku": "BLK"}, Query{})
+			assert.NoError(t, err)
+			assert.Len(t, found, 2)
+		})
+	}
+}
+
+// TestRepository_CategoryMoves checks that moving a category rewrites the paths of its descendants, and that a move
+// checked against a parent read before another move is refused rather than forming a cycle, in both implementations.
+func TestRepository_CategoryMoves(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			x := models.Category{Model: gorm.Model{ID: 1}, Name: "X", Path: models.CategoryPath(nil, 1)}
+			y := models.Category{Model: gorm.Model{ID: 2}, Name: "Y", Path: models.CategoryPath(nil, 2)}
+			child := models.Category{Model: gorm.Model{ID: 3}, Name: "Child", Parent_ID: &x.ID, Path: models.CategoryPath(&x, 3)}
+			for _, category := range []*models.Category{&x, &y, &child} {
+				assert.NoError(t, repos.Categories.Create(ctx, category))
+			}
+			staleX, staleY := x, y
+
+			// Both moves were validated against the paths read above: X below Y, and Y below X.
+			x.Parent_ID, x.Path = &y.ID, models.CategoryPath(&y, x.ID)
+			assert.NoError(t, repos.Categories.Save(ctx, &x, "parent_id", "path"))
+			staleY.Parent_ID, staleY.Path = &staleX.ID, models.CategoryPath(&staleX, staleY.ID)
+			assert.ErrorIs(t, repos.Categories.Save(ctx, &staleY, "parent_id", "path"), models.ErrParentMoved)
+
+			stored, err := repos.Categories.Get(ctx, y.ID, Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, "/2/", stored.Path, "the refused move should leave the category in place")
+			stored, err = repos.Categories.Get(ctx, child.ID, Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, "/2/1/3/", stored.Path, "descendants should move with their ancestor")
+
+			// A patch not writing the path keeps the descendants where they are, even with a stale path.
+			staleX.Name = "Renamed"
+			staleX.Version = x.Version
+			assert.NoError(t, repos.Categories.Save(ctx, &staleX, "name"))
+			stored, err = repos.Categories.Get(ctx, child.ID, Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, "/2/1/3/", stored.Path)
+		})
+	}
+}
+
+// TestRepository_OrderTotals checks that the total amount of an order becomes the sum of the subtotals of its items
+// not in the trash, rounded to the cent, with a new version only when it changes, in both implementations.
+func TestRepository_OrderTotals(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			order := models.Order{Total_amount: 500}
+			assert.NoError(t, repos.Orders.Create(ctx, &order))
+			items := []models.OrderItem{
+				{Order_ID: order.ID, Product_ID: 1, Quantity: 1, Subtotal: 0.1},
+				{Order_ID: order.ID, Product_ID: 2, Quantity: 1, Subtotal: 0.2},
+				{Order_ID: order.ID, Product_ID: 3, Quantity: 1, Subtotal: 10},
+				{Order_ID: order.ID + 1, Product_ID: 1, Quantity: 1, Subtotal: 99},
+			}
+			for i := range items {
+				assert.NoError(t, repos.OrderItems.Create(ctx, &items[i]))
+			}
+			assert.NoError(t, repos.OrderItems.Delete(ctx, items[2].ID, 0))
+
+			assert.NoError(t, repos.Orders.UpdateTotal(ctx, order.ID))
+			stored, err := repos.Orders.Get(ctx, order.ID, Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, 0.3, stored.Total_amount)
+			assert.Equal(t, order.Version+1, stored.Version)
+			assert.NoError(t, repos.Orders.UpdateTotal(ctx, order.ID))
+			version, err := repos.Orders.Version(ctx, order.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, stored.Version, version, "an unchanged total keeps the version")
+		})
+	}
+}
+
+// TestRepository_ProductImages checks that images are listed by product in their order, reordered and given a single
+// primary image, and that the files of images and logos are listed with those in the trash, in both implementations.
+func TestRepository_ProductImages(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			images := make([]models.ProductImage, 3)
+			for i, productID := range []uint{1, 1, 2} {
+				images[i] = models.ProductImage{Product_ID: productID, Position: i, Primary: i != 1}
+				images[i].SetFiles(fmt.Sprintf("products/%d/%d.png", productID, i), fmt.Sprintf("products/%d/%d_thumb.png", productID, i))
+				assert.NoError(t, repos.ProductImages.Create(ctx, &images[i]))
+			}
+
+			assert.NoError(t, repos.ProductImages.Reorder(ctx, 1, []uint{images[1].ID, images[0].ID}))
+			assert.NoError(t, repos.ProductImages.SetPrimary(ctx, 1, images[1].ID))
+			listed, err := repos.ProductImages.ListByProduct(ctx, 1)
+			assert.NoError(t, err)
+			if assert.Len(t, listed, 2) {
+				assert.Equal(t, []uint{images[1].ID, images[0].ID}, []uint{listed[0].ID, listed[1].ID})
+				assert.Equal(t, []bool{true, false}, []bool{listed[0].Primary, listed[1].Primary})
+				assert.Equal(t, "/media/products/1/1.png", listed[0].URL)
+			}
+			other, err := repos.ProductImages.Get(ctx, images[2].ID, Query{})
+			assert.NoError(t, err)
+			assert.True(t, other.Primary, "the images of other products are left alone")
+
+			logo := &models.Image{}
+			logo.SetFiles("brands/1/logo.png", "brands/1/logo_thumb.png")
+			assert.NoError(t, repos.Brands.Create(ctx, &models.Brands{Name: "Acme", Logo: logo}))
+			assert.NoError(t, repos.Brands.Create(ctx, &models.Brands{Name: "Plain"}))
+			assert.NoError(t, repos.ProductImages.Delete(ctx, images[2].ID, 0))
+			files, err := repos.ProductImages.Files(ctx)
+			assert.NoError(t, err)
+			assert.Len(t, files, 6)
+			files, err = repos.Brands.Files(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"brands/1/logo.png", "brands/1/logo_thumb.png"}, files)
+		})
+	}
+}
+
+// TestRepository_AddProductImages checks that added images go after the last image of their product, whatever the
+// size of the gallery, and that the first image and the images added as primary are the only primary image, in both
+// implementations.
+func TestRepository_AddProductImages(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			product := models.Product{Name: "Phone", Category_ID: 1}
+			assert.NoError(t, repos.Products.Create(ctx, &product))
+			added := make([]models.ProductImage, 4)
+			for i := range added {
+				added[i] = models.ProductImage{Product_ID: product.ID, Primary: i == 2}
+				assert.NoError(t, repos.ProductImages.Add(ctx, &added[i]))
+				if i == 1 {
+					assert.NoError(t, repos.ProductImages.Reorder(ctx, product.ID, []uint{added[1].ID, added[0].ID}))
+					assert.NoError(t, repos.ProductImages.Delete(ctx, added[0].ID, 0))
+				}
+			}
+			assert.Equal(t, []int{0, 1, 1, 2}, []int{added[0].Position, added[1].Position, added[2].Position, added[3].Position},
+				"positions follow the last image of the gallery")
+			assert.True(t, added[0].Primary, "the first image is primary")
+			listed, err := repos.ProductImages.ListByProduct(ctx, product.ID)
+			assert.NoError(t, err)
+			if assert.Len(t, listed, 3) {
+				assert.Equal(t, []uint{added[1].ID, added[2].ID, added[3].ID}, []uint{listed[0].ID, listed[1].ID, listed[2].ID})
+				assert.Equal(t, []bool{false, true, false}, []bool{listed[0].Primary, listed[1].Primary, listed[2].Primary})
+			}
+		})
+	}
+}
+
+// TestRepository_ProductAttributes checks that the values of products are replaced and read back with their attribute,
+// incrementing the version of the product and refusing stale versions, and that products are filtered and counted by
+// value, leaving out attributes in the trash, in both implementations.
+func TestRepository_ProductAttributes(t *testing.T) {
+	for name, repos := range implementations(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			ram := models.Attribute{Category_ID: 1, Name: "ram_gb", Label: "RAM", Type: models.AttributeNumber, Unit: "GB"}
+			wifi := models.Attribute{Category_ID: 1, Name: "wifi", Label: "Wi-Fi", Type: models.AttributeBoolean}
+			assert.NoError(t, repos.Attributes.Create(ctx, &ram))
+			assert.NoError(t, repos.Attributes.Create(ctx, &wifi))
+			number := func(n float64) *float64 { return &n }
+			yes := true
+			products := map[uint]*models.Product{}
+			for id, gigabytes := range map[uint]float64{1: 8, 2: 16, 3: 32} {
+				products[id] = &models.Product{Model: gorm.Model{ID: id}, Name: "Laptop", Category_ID: 1}
+				assert.NoError(t, repos.Products.Create(ctx, products[id]))
+				assert.NoError(t, repos.Products.SetAttributes(ctx, products[id], []models.ProductAttribute{{Attribute_ID: ram.ID, Number: number(gigabytes)}}))
+			}
+			stale := *products[3]
+			assert.NoError(t, repos.Products.SetAttributes(ctx, products[3], []models.ProductAttribute{
+				{Attribute_ID: ram.ID, Number: number(16)},
+				{Attribute_ID: wifi.ID, Boolean: &yes},
+			}))
+			assert.Equal(t, uint(3), products[3].Version)
+			stale.Version--
+			assert.ErrorIs(t, repos.Products.SetAttributes(ctx, &stale, nil), models.ErrVersionConflict)
+			assert.Equal(t, uint(1), stale.Version)
+			stored, err := repos.Products.Get(ctx, 3, Query{})
+			assert.NoError(t, err)
+			assert.Equal(t, uint(3), stored.Version)
+
+			values, err := repos.Products.Attributes(ctx, 3)
+			assert.NoError(t, err)
+			if assert.Len(t, values, 2) {
+				assert.Equal(t, 16.0, values[0].Value)
+				assert.Equal(t, "ram_gb", values[0].Attribute.Name)
+				assert.Equal(t, true, values[1].Value)
+			}
+			inUse, err := repos.Attributes.InUse(ctx, wifi.ID)
+			assert.NoError(t, err)
+			assert.True(t, inUse)
+
+			filtered, err := repos.Products.Filter(ctx, ProductFilter{
+				CategoryIDs: []uint{1},
+				Attributes:  []models.AttributeFilter{{Name: "ram_gb", Operator: "gte", Values: []interface{}{16.0}}},
+			}, Query{})
+			assert.NoError(t, err)
+			assert.Len(t, filtered, 2)
+			filtered, err = repos.Products.Filter(ctx, ProductFilter{CategoryIDs: []uint{}}, Query{})
+			assert.NoError(t, err)
+			assert.Empty(t, filtered)
+
+			counts, err := repos.Products.Facets(ctx, ProductFilter{}, []string{"ram_gb", "wifi"})
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, []models.FacetCount{
+				{Name: "ram_gb", Value: 8.0, Count: 1},
+				{Name: "ram_gb", Value: 16.0, Count: 2},
+				{Name: "wifi", Value: true, Count: 1},
+			}, counts)
+
+			assert.NoError(t, repos.Attributes.Delete(ctx, wifi.ID, 0))
+			values, err = repos.Products.Attributes(ctx, 3)
+			assert.NoError(t, err)
+			assert.Len(t, values, 1)
+			counts, err = repos.Products.Facets(ctx, ProductFilter{}, []string{"wifi"})
+			assert.NoError(t, err)
+			assert.Empty(t, counts)
+		})
+	}
+}
@@ -0,0 +1,350 @@
+package seed
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/tools"
+	"context"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPassword is the password of every generated user, so that demo accounts can log in.
+// It satisfies tools.CheckPassword.
+const DefaultPassword = "Electromart1!"
+
+// DefaultSeed is the RNG seed used when none is given, so that repeated runs generate the same data.
+const DefaultSeed = 42
+
+// defaultBatchSize is the number of rows inserted per statement.
+const defaultBatchSize = 500
+
+// referenceDate anchors every generated date, so that the output does not depend on the day it is generated.
+var referenceDate = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Preset sets how many rows of each kind are generated.
+type Preset struct {
+	Brands            int
+	Categories        int
+	Products          int
+	Users             int
+	Orders            int
+	MaxItemsPerOrder  int
+	ReviewsPerProduct int
+}
+
+// Presets holds the named dataset sizes: small for demos, medium for development and large for load tests.
+var Presets = map[string]Preset{
+	"small":  {Brands: 5, Categories: 5, Products: 50, Users: 20, Orders: 50, MaxItemsPerOrder: 3, ReviewsPerProduct: 1},
+	"medium": {Brands: 20, Categories: 12, Products: 500, Users: 200, Orders: 1000, MaxItemsPerOrder: 5, ReviewsPerProduct: 3},
+	"large":  {Brands: 60, Categories: 30, Products: 10000, Users: 5000, Orders: 50000, MaxItemsPerOrder: 8, ReviewsPerProduct: 5},
+}
+
+// Options controls the generated dataset.
+type Options struct {
+	// Seed makes the generated data reproducible: the same seed and preset always give the same rows.
+	Seed int64
+	// Preset sets the number of rows of each kind.
+	Preset Preset
+	// Password is the plain text password of every user, DefaultPassword when empty.
+	Password string
+	// BcryptCost is the cost used to hash the password, bcrypt.DefaultCost when zero.
+	BcryptCost int
+}
+
+// Dataset is a generated, internally consistent set of rows: every reference points at a generated row,
+// order totals match their items and payments match their orders.
+type Dataset struct {
+	Brands          []models.Brands
+	Categories      []models.Category
+	Products        []models.Product
+	Users           []models.User
+	Orders          []models.Order
+	OrderItems      []models.OrderItem
+	Payments        []models.Payment
+	ShippingDetails []models.ShippingDetails
+	Reviews         []models.Review
+}
+
+// Counts reports how many rows of each table a dataset holds.
+type Counts map[string]int
+
+// Counts returns the number of rows per table, keyed by table name.
+func (d *Dataset) Counts() Counts {
+	return Counts{
+		"brands":           len(d.Brands),
+		"categories":       len(d.Categories),
+		"products":         len(d.Products),
+		"users":            len(d.Users),
+		"orders":           len(d.Orders),
+		"order_items":      len(d.OrderItems),
+		"payments":         len(d.Payments),
+		"shipping_details": len(d.ShippingDetails),
+		"reviews":          len(d.Reviews),
+	}
+}
+
+// String lists the counts sorted by table name, e.g. "brands=5 categories=5 ...".
+func (c Counts) String() string {
+	tables := make([]string, 0, len(c))
+	for table := range c {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	parts := make([]string, 0, len(tables))
+	for _, table := range tables {
+		parts = append(parts, fmt.Sprintf("%s=%d", table, c[table]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Generate builds a dataset from the seeded RNG.
+// Everything but the password hashes is deterministic; bcrypt salts every hash, so the password is hashed once
+// per dataset and shared by all users, which also keeps large presets fast to generate.
+func Generate(opts Options) (*Dataset, error) {
+	if opts.Password == "" {
+		opts.Password = DefaultPassword
+	}
+	if opts.BcryptCost == 0 {
+		opts.BcryptCost = bcrypt.DefaultCost
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(opts.Password), opts.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	g := &generator{rng: rand.New(rand.NewSource(opts.Seed)), ids: map[uint]bool{}}
+	preset := opts.Preset
+	d := &Dataset{}
+
+	for i := 0; i < preset.Brands; i++ {
+		name := brandNames[i%len(brandNames)]
+		if i >= len(brandNames) {
+			name = fmt.Sprintf("%s %d", name, i/len(brandNames)+1)
+		}
+		d.Brands = append(d.Brands, models.Brands{Model: g.model(), Name: name,
+			Description: fmt.Sprintf("%s makes %s electronics.", name, g.pick(adjectives))})
+	}
+	for i := 0; i < preset.Categories; i++ {
+		name := categoryNames[i%len(categoryNames)]
+		if i >= len(categoryNames) {
+			name = fmt.Sprintf("%s %d", name, i/len(categoryNames)+1)
+		}
+		category := models.Category{Model: g.model(), Name: name,
+			Description: fmt.Sprintf("All kinds of %s.", strings.ToLower(name))}
+		// The categories beyond the first round of names are nested below the category of the same name.
+		var parent *models.Category
+		if i >= len(categoryNames) {
+			root := d.Categories[i%len(categoryNames)]
+			parent = &root
+			category.Parent_ID = &root.ID
+		}
+		category.Path = models.CategoryPath(parent, category.ID)
+		d.Categories = append(d.Categories, category)
+	}
+	if len(d.Brands) > 0 && len(d.Categories) > 0 {
+		for i := 0; i < preset.Products; i++ {
+			brand := d.Brands[g.rng.Intn(len(d.Brands))]
+			category := d.Categories[g.rng.Intn(len(d.Categories))]
+			name := fmt.Sprintf("%s %s %s %d", brand.Name, g.pick(adjectives), g.pick(productNouns), 100+g.rng.Intn(900))
+			d.Products = append(d.Products, models.Product{
+				Model:          g.model(),
+				Name:           name,
+				Description:    fmt.Sprintf("The %s, a %s pick in %s.", name, g.pick(adjectives), strings.ToLower(category.Name)),
+				Price:          g.price(),
+				Stock_quantity: g.rng.Intn(200),
+				Brand_ID:       brand.ID,
+				Category_ID:    category.ID,
+			})
+		}
+	}
+
+	for i := 0; i < preset.Users; i++ {
+		first, last := g.pick(firstNames), g.pick(lastNames)
+		username := fmt.Sprintf("%s.%s%d", strings.ToLower(first), strings.ToLower(last), i+1)
+		role := "regular"
+		if i == 0 {
+			role = "admin"
+		}
+		d.Users = append(d.Users, models.User{
+			Model:      g.model(),
+			Username:   username,
+			Password:   string(hashedPassword),
+			Email:      username + "@example.com",
+			First_Name: first,
+			Last_Name:  last,
+			Address:    g.address(),
+			Mobile:     fmt.Sprintf("4%07d", g.rng.Intn(10000000)),
+			Role:       role,
+		})
+	}
+
+	if len(d.Users) > 0 && len(d.Products) > 0 {
+		for i := 0; i < preset.Orders; i++ {
+			g.order(d, preset)
+		}
+		for _, product := range d.Products {
+			for i := 0; i < preset.ReviewsPerProduct; i++ {
+				user := d.Users[g.rng.Intn(len(d.Users))]
+				d.Reviews = append(d.Reviews, models.Review{
+					Model:       g.model(),
+					Product_ID:  product.ID,
+					User_ID:     user.ID,
+					Rating:      1 + g.rng.Intn(5),
+					Comment:     g.pick(reviewComments),
+					Review_Date: g.date(365),
+				})
+			}
+		}
+	}
+	return d, nil
+}
+
+// Insert writes the dataset in a single transaction, parents before children, in batches of batchSize rows.
+// A zero batchSize uses a default suited to every supported database.
+func Insert(ctx context.Context, db *gorm.DB, d *Dataset, batchSize int) error {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, rows := range []interface{}{&d.Brands, &d.Categories, &d.Products, &d.Users, &d.Orders,
+			&d.OrderItems, &d.Payments, &d.ShippingDetails, &d.Reviews} {
+			if reflect.ValueOf(rows).Elem().Len() == 0 {
+				continue
+			}
+			if err := tx.CreateInBatches(rows, batchSize).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Reset permanently deletes every row of the seeded tables, children before parents.
+func Reset(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Categories reference their parents, so they are detached before being deleted in any order.
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Model(&models.Category{}).
+			UpdateColumn("parent_id", nil).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.Review{}, &models.ShippingDetails{}, &models.Payment{},
+			&models.OrderItem{}, &models.ProductVariant{}, &models.ProductAttribute{}, &models.ProductImage{}, &models.Order{},
+			&models.User{}, &models.Product{}, &models.Attribute{}, &models.Category{}, &models.Brands{}} {
+			if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// generator wraps the seeded RNG with helpers producing realistic values.
+type generator struct {
+	rng *rand.Rand
+	ids map[uint]bool
+}
+
+// model returns a gorm.Model created at a time drawn from the RNG, with a unique ID of that time like the IDs
+// assigned by the services.
+func (g *generator) model() gorm.Model {
+	for {
+		created := referenceDate.Add(-time.Duration(g.rng.Int63n(int64(365*24*time.Hour/time.Millisecond))) * time.Millisecond)
+		id := tools.ComposeID(created, 0, 0)
+		if !g.ids[id] {
+			g.ids[id] = true
+			return gorm.Model{ID: id, CreatedAt: created, UpdatedAt: created}
+		}
+	}
+}
+
+// order generates an order with its items, its payment and, once it has shipped, its shipping details.
+func (g *generator) order(d *Dataset, preset Preset) {
+	user := d.Users[g.rng.Intn(len(d.Users))]
+	order := models.Order{Model: g.model(), User_ID: user.ID, Order_date: g.date(365), Status: g.pick(orderStatuses)}
+
+	items := 1
+	if preset.MaxItemsPerOrder > 1 {
+		items += g.rng.Intn(preset.MaxItemsPerOrder)
+	}
+	used := map[uint]bool{}
+	for i := 0; i < items; i++ {
+		product := d.Products[g.rng.Intn(len(d.Products))]
+		if used[product.ID] {
+			continue
+		}
+		used[product.ID] = true
+		quantity := 1 + g.rng.Intn(3)
+		subtotal := round(product.Price * float64(quantity))
+		order.Total_amount = round(order.Total_amount + subtotal)
+		d.OrderItems = append(d.OrderItems, models.OrderItem{Model: g.model(), Order_ID: order.ID,
+			Product_ID: product.ID, Quantity: quantity, Subtotal: subtotal})
+	}
+	d.Orders = append(d.Orders, order)
+
+	paymentStatus := "completed"
+	switch order.Status {
+	case "pending", "processing":
+		paymentStatus = "pending"
+	case "cancelled", "returned", "refunded":
+		paymentStatus = "refunded"
+	}
+	d.Payments = append(d.Payments, models.Payment{Model: g.model(), Order_ID: order.ID,
+		Payment_method: g.pick(paymentMethods), Amount: order.Total_amount, Payment_date: order.Order_date, Status: paymentStatus})
+
+	if order.Status == "shipped" || order.Status == "delivered" || order.Status == "returned" {
+		shipped := order.Order_date.AddDate(0, 0, 1+g.rng.Intn(3))
+		d.ShippingDetails = append(d.ShippingDetails, models.ShippingDetails{Model: g.model(), Order_ID: order.ID,
+			Address: user.Address, Shipping_Date: shipped, Estimated_Arrival: shipped.AddDate(0, 0, 2+g.rng.Intn(5)),
+			Status: order.Status})
+	}
+}
+
+// pick returns a random element of values.
+func (g *generator) pick(values []string) string {
+	return values[g.rng.Intn(len(values))]
+}
+
+// price returns a price between 9.99 and roughly 2500, skewed towards cheaper products and ending in .99.
+func (g *generator) price() float64 {
+	return math.Floor(10+math.Pow(g.rng.Float64(), 3)*2490) - 0.01
+}
+
+// date returns a time, to the second, within maxDaysAgo days before the reference date.
+func (g *generator) date(maxDaysAgo int) time.Time {
+	return referenceDate.Add(-time.Duration(g.rng.Int63n(int64(maxDaysAgo)*24*60*60)) * time.Second)
+}
+
+// address returns a street address.
+func (g *generator) address() string {
+	return fmt.Sprintf("%s %d, %04d %s", g.pick(streets), 1+g.rng.Intn(150), g.rng.Intn(10000), g.pick(cities))
+}
+
+// round rounds an amount to cents.
+func round(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+var (
+	brandNames = []string{"Voltix", "Nordwave", "Lumina", "Kestrel", "Arcadia", "Quanta", "Helix", "Orbitron",
+		"Pulsar", "Zenith", "Corvid", "Stratus"}
+	categoryNames = []string{"Laptops", "Smartphones", "Tablets", "Headphones", "Televisions", "Cameras",
+		"Smartwatches", "Gaming", "Speakers", "Accessories", "Monitors", "Networking"}
+	adjectives     = []string{"Pro", "Ultra", "Lite", "Max", "Air", "Neo", "Prime", "Edge", "Plus", "Mini"}
+	productNouns   = []string{"Book", "Phone", "Tab", "Buds", "Vision", "Shot", "Watch", "Console", "Boom", "Hub"}
+	firstNames     = []string{"Emma", "Noah", "Olivia", "Liam", "Sara", "Jakob", "Nora", "Lucas", "Ingrid", "Ali", "Maja", "Filip"}
+	lastNames      = []string{"Hansen", "Johansen", "Olsen", "Larsen", "Andersen", "Pedersen", "Nilsen", "Berg", "Haugen", "Dahl"}
+	streets        = []string{"Storgata", "Kongens gate", "Elvegata", "Parkveien", "Skogveien", "Strandgata", "Bakkegata"}
+	cities         = []string{"Oslo", "Bergen", "Trondheim", "Stavanger", "Tromsø", "Gjøvik", "Drammen"}
+	orderStatuses  = []string{"pending", "processing", "shipped", "delivered", "delivered", "completed", "cancelled", "returned"}
+	paymentMethods = []string{"credit card", "debit card", "paypal", "cash"}
+	reviewComments = []string{"Great value for money.", "Works as advertised.", "Battery life could be better.",
+		"Exceeded my expectations!", "Arrived quickly and well packaged.", "Stopped working after a month.",
+		"Solid build quality.", "Would buy again."}
+)
@@ -0,0 +1,187 @@
+package seed
+
+import (
+	"E-Commerce_Website_Database/internal/migrations"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/tools"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testOptions returns generation options for the small preset with the cheapest bcrypt cost, to keep the tests fast.
+func testOptions(seed int64) Options {
+	return Options{Seed: seed, Preset: Presets["small"], BcryptCost: bcrypt.MinCost}
+}
+
+// TestGenerateIsDeterministic checks that the same seed generates the same rows, apart from the salted password hash,
+// and that a different seed generates different rows.
+func TestGenerateIsDeterministic(t *testing.T) {
+	first, err := Generate(testOptions(7))
+	assert.NoError(t, err)
+	second, err := Generate(testOptions(7))
+	assert.NoError(t, err)
+	other, err := Generate(testOptions(8))
+	assert.NoError(t, err)
+
+	for i := range first.Users {
+		first.Users[i].Password = ""
+		second.Users[i].Password = ""
+	}
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first.Products[0].ID, other.Products[0].ID)
+}
+
+// TestGenerateIsConsistent checks the generated data against the presets and the validation rules of the API:
+// every reference points at a generated row, order totals equal the sum of their items,
+// payments match their orders and passwords are bcrypt hashes of the chosen password.
+func TestGenerateIsConsistent(t *testing.T) {
+	d, err := Generate(testOptions(DefaultSeed))
+	assert.NoError(t, err)
+	preset := Presets["small"]
+	assert.Len(t, d.Brands, preset.Brands)
+	assert.Len(t, d.Products, preset.Products)
+	assert.Len(t, d.Users, preset.Users)
+	assert.Len(t, d.Orders, preset.Orders)
+	assert.Len(t, d.Payments, preset.Orders)
+	assert.Len(t, d.Reviews, preset.Products*preset.ReviewsPerProduct)
+
+	ids := map[uint]bool{}
+	addID := func(model gorm.Model) {
+		assert.False(t, ids[model.ID], "ID %d should be unique", model.ID)
+		ids[model.ID] = true
+	}
+	for _, brand := range d.Brands {
+		addID(brand.Model)
+	}
+	for _, category := range d.Categories {
+		addID(category.Model)
+	}
+	for _, product := range d.Products {
+		addID(product.Model)
+	}
+	for _, user := range d.Users {
+		addID(user.Model)
+	}
+	for _, order := range d.Orders {
+		addID(order.Model)
+	}
+
+	for _, product := range d.Products {
+		assert.True(t, ids[product.Brand_ID] && ids[product.Category_ID])
+		assert.True(t, tools.CheckFloat(product.Price) && product.Price > 0)
+	}
+	totals := map[uint]float64{}
+	for _, item := range d.OrderItems {
+		assert.True(t, ids[item.Order_ID] && ids[item.Product_ID])
+		totals[item.Order_ID] += item.Subtotal
+	}
+	orders := map[uint]models.Order{}
+	for _, order := range d.Orders {
+		orders[order.ID] = order
+		assert.True(t, ids[order.User_ID])
+		assert.False(t, order.Order_date.IsZero() || order.Order_date.After(time.Now()))
+		assert.True(t, tools.CheckStatus(order.Status, 0))
+		assert.InDelta(t, totals[order.ID], order.Total_amount, 0.001)
+	}
+	for _, payment := range d.Payments {
+		assert.Equal(t, orders[payment.Order_ID].Total_amount, payment.Amount)
+		assert.True(t, tools.CheckPaymentMethod(payment.Payment_method))
+		assert.True(t, tools.CheckStatus(payment.Status, 0))
+	}
+	for _, shipping := range d.ShippingDetails {
+		assert.Contains(t, []string{"shipped", "delivered", "returned"}, orders[shipping.Order_ID].Status)
+		assert.True(t, shipping.Shipping_Date.After(orders[shipping.Order_ID].Order_date))
+		assert.True(t, shipping.Estimated_Arrival.After(shipping.Shipping_Date))
+	}
+	for _, review := range d.Reviews {
+		assert.True(t, tools.CheckRating(review.Rating) && review.Rating > 0)
+	}
+
+	assert.Equal(t, "admin", d.Users[0].Role)
+	for _, user := range d.Users {
+		assert.True(t, tools.CheckEmail(user.Email))
+		assert.True(t, tools.CheckPhone(user.Mobile, 8))
+	}
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(d.Users[0].Password), []byte(DefaultPassword)))
+	assert.True(t, tools.CheckPassword(DefaultPassword))
+}
+
+// TestInsertAndReset seeds a migrated SQLite database with foreign keys enabled, checks the row counts, and resets it.
+func TestInsertAndReset(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "seed.db")+"?_foreign_keys=on"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	migrator, err := migrations.New(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	assert.NoError(t, err)
+
+	d, err := Generate(testOptions(DefaultSeed))
+	assert.NoError(t, err)
+	assert.NoError(t, Insert(context.Background(), db, d, 16))
+
+	for table, expected := range d.Counts() {
+		var count int64
+		assert.NoError(t, db.Table(table).Count(&count).Error)
+		assert.Equal(t, int64(expected), count, table)
+	}
+
+	// Inserting the same dataset again fails on the primary keys and leaves nothing half written.
+	assert.Error(t, Insert(context.Background(), db, d, 16))
+	var orders int64
+	db.Model(&models.Order{}).Count(&orders)
+	assert.Equal(t, int64(len(d.Orders)), orders)
+
+	assert.NoError(t, Reset(context.Background(), db))
+	for table := range d.Counts() {
+		var count int64
+		assert.NoError(t, db.Table(table).Count(&count).Error)
+		assert.Zero(t, count, table)
+	}
+}
+
+// TestNestedCategories checks that the categories beyond the first round of names are nested below a root with
+// a path derived from it, and that they are inserted and reset with foreign keys enabled.
+func TestNestedCategories(t *testing.T) {
+	d, err := Generate(Options{Seed: DefaultSeed, Preset: Preset{Categories: 2*len(categoryNames) + 1}, BcryptCost: bcrypt.MinCost})
+	assert.NoError(t, err)
+	for i, category := range d.Categories {
+		if i < len(categoryNames) {
+			assert.Nil(t, category.Parent_ID)
+			assert.Equal(t, models.CategoryPath(nil, category.ID), category.Path)
+			continue
+		}
+		root := d.Categories[i%len(categoryNames)]
+		if assert.NotNil(t, category.Parent_ID) {
+			assert.Equal(t, root.ID, *category.Parent_ID)
+		}
+		assert.Equal(t, models.CategoryPath(&root, category.ID), category.Path)
+	}
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "seed.db")+"?_foreign_keys=on"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	migrator, err := migrations.New(db)
+	assert.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, Insert(context.Background(), db, d, 4))
+	assert.NoError(t, Reset(context.Background(), db))
+	var count int64
+	assert.NoError(t, db.Model(&models.Category{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+// TestCountsString checks that counts are listed sorted by table name.
+func TestCountsString(t *testing.T) {
+	assert.Equal(t, "brands=2 orders=1", Counts{"orders": 1, "brands": 2}.String())
+	assert.Equal(t, 12.35, round(12.345000001))
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default values used for any Options field left at its zero value.
+const (
+	DefaultReadTimeout       = 15 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 60 * time.Second
+	DefaultShutdownTimeout   = 30 * time.Second
+	DefaultHookTimeout       = 10 * time.Second
+	DefaultMaxHeaderBytes    = 1 << 20
+)
+
+// Options configures the HTTP server, its timeouts and optional TLS.
+type Options struct {
+	// Addr is the TCP address to listen on, e.g. ":8081".
+	Addr string
+	// ReadTimeout bounds reading an entire request, including the body.
+	ReadTimeout time.Duration
+	// ReadHeaderTimeout bounds reading the request headers.
+	ReadHeaderTimeout time.Duration
+	// WriteTimeout bounds writing the response.
+	WriteTimeout time.Duration
+	// IdleTimeout bounds how long keep-alive connections wait for the next request.
+	IdleTimeout time.Duration
+	// MaxHeaderBytes limits the size of the request headers.
+	MaxHeaderBytes int
+	// ShutdownTimeout bounds how long in-flight requests may drain once shutdown starts, and then, separately,
+	// how long the background workers may take to stop.
+	ShutdownTimeout time.Duration
+	// HookTimeout bounds each shutdown hook, so that a slow hook cannot use up the time of the others.
+	HookTimeout time.Duration
+	// TLSCertFile and TLSKeyFile enable HTTPS when both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+}
+
+// Server wraps an http.Server with graceful shutdown, background workers and cleanup hooks.
+type Server struct {
+	httpServer    *http.Server
+	opts          Options
+	workerCtx     context.Context
+	stopWorkers   context.CancelFunc
+	workers       sync.WaitGroup
+	mu            sync.Mutex
+	shutdownHooks []func(context.Context) error
+}
+
+// New creates a Server serving handler with the given options, applying defaults to unset timeouts and limits.
+func New(handler http.Handler, opts Options) *Server {
+	opts = withDefaults(opts)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	return &Server{
+		httpServer: &http.Server{
+			Addr:              opts.Addr,
+			Handler:           handler,
+			ReadTimeout:       opts.ReadTimeout,
+			ReadHeaderTimeout: opts.ReadHeaderTimeout,
+			WriteTimeout:      opts.WriteTimeout,
+			IdleTimeout:       opts.IdleTimeout,
+			MaxHeaderBytes:    opts.MaxHeaderBytes,
+		},
+		opts:        opts,
+		workerCtx:   workerCtx,
+		stopWorkers: stopWorkers,
+	}
+}
+
+// HTTPServer exposes the underlying http.Server, e.g. to inspect its configuration.
+func (s *Server) HTTPServer() *http.Server {
+	return s.httpServer
+}
+
+// Go starts a background worker. The worker's context is cancelled when the server shuts down,
+// and shutdown waits for the worker to return before running the shutdown hooks.
+func (s *Server) Go(worker func(ctx context.Context)) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		worker(s.workerCtx)
+	}()
+}
+
+// OnShutdown registers a hook run after the HTTP server and background workers have stopped.
+// Hooks run in reverse registration order, so resources opened first (like the database pool) are closed last.
+func (s *Server) OnShutdown(hook func(context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, hook)
+}
+
+// Run listens on the configured address and serves requests until ctx is cancelled, then shuts down gracefully.
+// It returns an error if the server could not start or did not shut down cleanly.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.opts.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.opts.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve accepts connections on listener until ctx is cancelled, then shuts down gracefully.
+// HTTPS is used when a certificate and key are configured.
+// Shutdown stops accepting connections, drains in-flight requests within the shutdown timeout,
+// stops the background workers and finally runs the shutdown hooks.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		var err error
+		if s.TLSEnabled() {
+			slog.Info("HTTPS server listening", "addr", listener.Addr().String())
+			err = s.httpServer.ServeTLS(listener, s.opts.TLSCertFile, s.opts.TLSKeyFile)
+		} else {
+			slog.Info("HTTP server listening", "addr", listener.Addr().String())
+			err = s.httpServer.Serve(listener)
+		}
+		serveErr <- err
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return errors.Join(fmt.Errorf("server stopped unexpectedly: %w", err), s.shutdown())
+		}
+		return s.shutdown()
+	case <-ctx.Done():
+		slog.Info("shutdown signal received, draining in-flight requests", "timeout", s.opts.ShutdownTimeout.String())
+		return s.shutdown()
+	}
+}
+
+// TLSEnabled reports whether both a certificate and a key file are configured.
+func (s *Server) TLSEnabled() bool {
+	return s.opts.TLSCertFile != "" && s.opts.TLSKeyFile != ""
+}
+
+// shutdown drains the HTTP server, stops the workers and runs the shutdown hooks. Draining and stopping the workers
+// each get the shutdown timeout and every hook gets the hook timeout, so that a step running out of time does not
+// leave the next ones an expired context. Connections still open when draining fails are closed.
+// It keeps going after a failing step so that every resource gets a chance to be released, and returns the errors joined.
+func (s *Server) shutdown() error {
+	var errs []error
+	drainCtx, cancelDrain := context.WithTimeout(context.Background(), s.opts.ShutdownTimeout)
+	defer cancelDrain()
+	if err := s.httpServer.Shutdown(drainCtx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain HTTP server: %w", err))
+		if err := s.httpServer.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close HTTP server: %w", err))
+		}
+	}
+
+	s.stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(workersDone)
+	}()
+	workersTimer := time.NewTimer(s.opts.ShutdownTimeout)
+	defer workersTimer.Stop()
+	select {
+	case <-workersDone:
+	case <-workersTimer.C:
+		errs = append(errs, fmt.Errorf("background workers did not stop: %w", context.DeadlineExceeded))
+	}
+
+	s.mu.Lock()
+	hooks := s.shutdownHooks
+	s.mu.Unlock()
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := s.runHook(hooks[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	slog.Info("server stopped gracefully")
+	return nil
+}
+
+// runHook runs a shutdown hook with a context of its own, expiring after the hook timeout.
+func (s *Server) runHook(hook func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.HookTimeout)
+	defer cancel()
+	return hook(ctx)
+}
+
+// withDefaults fills every unset option with its default value.
+func withDefaults(opts Options) Options {
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = DefaultReadTimeout
+	}
+	if opts.ReadHeaderTimeout <= 0 {
+		opts.ReadHeaderTimeout = DefaultReadHeaderTimeout
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultWriteTimeout
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if opts.HookTimeout <= 0 {
+		opts.HookTimeout = DefaultHookTimeout
+	}
+	if opts.MaxHeaderBytes <= 0 {
+		opts.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+	return opts
+}
@@ -0,0 +1,233 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startServer serves handler on a random local port and returns its base address and a channel receiving Serve's result.
+func startServer(t *testing.T, ctx context.Context, srv *Server) (string, chan error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	result := make(chan error, 1)
+	go func() { result <- srv.Serve(ctx, listener) }()
+	return listener.Addr().String(), result
+}
+
+// TestNew_AppliesDefaults tests that unset options fall back to the defaults and explicit ones are kept.
+func TestNew_AppliesDefaults(t *testing.T) {
+	srv := New(http.NewServeMux(), Options{Addr: ":9999", WriteTimeout: 5 * time.Second})
+	httpServer := srv.HTTPServer()
+
+	assert.Equal(t, ":9999", httpServer.Addr)
+	assert.Equal(t, DefaultReadTimeout, httpServer.ReadTimeout)
+	assert.Equal(t, DefaultReadHeaderTimeout, httpServer.ReadHeaderTimeout)
+	assert.Equal(t, 5*time.Second, httpServer.WriteTimeout)
+	assert.Equal(t, DefaultIdleTimeout, httpServer.IdleTimeout)
+	assert.Equal(t, DefaultMaxHeaderBytes, httpServer.MaxHeaderBytes)
+	assert.Equal(t, DefaultHookTimeout, srv.opts.HookTimeout)
+	assert.False(t, srv.TLSEnabled())
+}
+
+// TestServe_GracefulShutdown tests that cancelling the context lets an in-flight request finish,
+// stops the background workers and runs the shutdown hooks in reverse order.
+func TestServe_GracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+	srv := New(handler, Options{ShutdownTimeout: 5 * time.Second})
+
+	workerStopped := false
+	srv.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		workerStopped = true
+	})
+	var mu sync.Mutex
+	var order []string
+	srv.OnShutdown(func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, "database")
+		return nil
+	})
+	srv.OnShutdown(func(context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, "tracing")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, srv)
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr + "/slow")
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+
+	<-started
+	cancel()
+
+	assert.Equal(t, "done", <-response, "The in-flight request should be drained")
+	assert.NoError(t, <-result)
+	assert.True(t, workerStopped)
+	assert.Equal(t, []string{"tracing", "database"}, order)
+
+	_, err := http.Get("http://" + addr + "/slow")
+	assert.Error(t, err, "No new connections should be accepted after shutdown")
+}
+
+// TestServe_ShutdownTimeout tests that shutdown gives up on requests that outlive the shutdown timeout.
+func TestServe_ShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	srv := New(handler, Options{ShutdownTimeout: 50 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, srv)
+	go http.Get("http://" + addr + "/stuck")
+
+	<-started
+	cancel()
+	assert.ErrorContains(t, <-result, "failed to drain HTTP server")
+}
+
+// TestServe_ShutdownAfterDrainTimeout tests that connections outliving the shutdown timeout are closed, and that
+// the workers and hooks still get time of their own once draining timed out.
+func TestServe_ShutdownAfterDrainTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+	srv := New(handler, Options{ShutdownTimeout: 50 * time.Millisecond, HookTimeout: time.Second})
+
+	srv.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(20 * time.Millisecond)
+	})
+	var hookErrs []error
+	for i := 0; i < 2; i++ {
+		srv.OnShutdown(func(ctx context.Context) error {
+			hookErrs = append(hookErrs, ctx.Err())
+			select {
+			case <-ctx.Done():
+			case <-time.After(30 * time.Millisecond):
+			}
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, srv)
+	requestErr := make(chan error, 1)
+	go func() {
+		_, err := http.Get("http://" + addr + "/stuck")
+		requestErr <- err
+	}()
+
+	<-started
+	cancel()
+	err := <-result
+	assert.ErrorContains(t, err, "failed to drain HTTP server")
+	assert.NotContains(t, err.Error(), "background workers did not stop")
+	assert.Equal(t, []error{nil, nil}, hookErrs, "every hook should start with a live context")
+	select {
+	case err := <-requestErr:
+		assert.Error(t, err, "the stuck connection should be closed")
+	case <-time.After(time.Second):
+		t.Fatal("the stuck connection was left open")
+	}
+}
+
+// TestServe_TLS tests that the server speaks HTTPS when a certificate and key are configured.
+func TestServe_TLS(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCertificate(t)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secure"))
+	})
+	srv := New(handler, Options{TLSCertFile: certFile, TLSKeyFile: keyFile})
+	assert.True(t, srv.TLSEnabled())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, srv)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	resp, err := client.Get("https://" + addr + "/")
+	if assert.NoError(t, err) {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Equal(t, "secure", string(body))
+		assert.NotNil(t, resp.TLS)
+	}
+
+	cancel()
+	assert.NoError(t, <-result)
+}
+
+// writeSelfSignedCertificate creates a self-signed certificate for 127.0.0.1 and returns the certificate and key paths.
+func writeSelfSignedCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600)
+	return certFile, keyFile
+}
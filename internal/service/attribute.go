@@ -0,0 +1,245 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Attributes applies the rules of attributes, which categories define for the products of their subtree:
+// their names differ along every branch of the tree, and attributes sharing a name have the same type.
+type Attributes struct {
+	records[models.Attribute]
+	attributes repository.AttributeRepository
+	categories repository.CategoryRepository
+}
+
+// Create validates input and inserts it as a new attribute with a generated ID.
+func (s *Attributes) Create(ctx context.Context, input models.Attribute) (*models.Attribute, error) {
+	attribute := models.Attribute{
+		Category_ID: input.Category_ID,
+		Name:        input.Name,
+		Label:       input.Label,
+		Type:        input.Type,
+		Unit:        input.Unit,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	rules, err := s.rules(ctx, &attribute, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.create(ctx, &attribute, s.check(ctx, &attribute, input, rules)); err != nil {
+		return nil, err
+	}
+	return &attribute, nil
+}
+
+// Update replaces the fields of attribute with those of input once validated, and saves it.
+// The category and type of an attribute products have values of cannot change, so they are compared with those
+// of attribute before being replaced.
+func (s *Attributes) Update(ctx context.Context, attribute *models.Attribute, input models.Attribute) error {
+	rules, err := s.rules(ctx, attribute, input)
+	if err != nil {
+		return err
+	}
+	attribute.Name = input.Name
+	attribute.Label = input.Label
+	attribute.Unit = input.Unit
+	return s.save(ctx, attribute, s.check(ctx, attribute, input, rules))
+}
+
+// Patch validates and saves the fields of attribute changed by a merge patch. Without fields nothing is saved.
+// An attribute moved to another category has its name checked against the attributes of that category.
+func (s *Attributes) Patch(ctx context.Context, attribute *models.Attribute, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	if slices.Contains(fields, "category_id") && !slices.Contains(fields, "name") {
+		fields = append(fields, "name")
+	}
+	// The patch was applied to attribute: its category and type are compared with the stored ones.
+	stored, err := s.Get(ctx, attribute.ID, repository.Query{})
+	if err != nil {
+		return err
+	}
+	input := *attribute
+	attribute.Category_ID, attribute.Type = stored.Category_ID, stored.Type
+	rules, err := s.rules(ctx, attribute, input)
+	if err != nil {
+		return err
+	}
+	return s.save(ctx, attribute, s.check(ctx, attribute, input, rules, fields...), fields...)
+}
+
+// OfCategories returns the attributes of the categories with the given IDs, or every attribute when categoryIDs
+// is nil.
+func (s *Attributes) OfCategories(ctx context.Context, categoryIDs []uint) ([]models.Attribute, error) {
+	attributes, err := s.attributes.ListByCategories(ctx, categoryIDs)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving attributes")
+	}
+	return attributes, nil
+}
+
+// Schema returns the attributes the products of category have, those of the category and of its ancestors,
+// from the root down.
+func (s *Attributes) Schema(ctx context.Context, category *models.Category) ([]models.Attribute, error) {
+	lineage := append(category.AncestorIDs(), category.ID)
+	attributes, err := s.OfCategories(ctx, lineage)
+	if err != nil {
+		return nil, err
+	}
+	return models.AttributeSchema(attributes, lineage), nil
+}
+
+// Related returns the attributes products of category may have together with those of its own: the attributes of
+// its ancestors, of the category itself and of its descendants. It returns none for a missing category.
+func (s *Attributes) Related(ctx context.Context, categoryID uint) ([]models.Attribute, error) {
+	category, err := s.categories.Get(ctx, categoryID, repository.Query{})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving categories")
+	}
+	descendants, err := s.categories.Descendants(ctx, category.Path, repository.Query{})
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving categories")
+	}
+	ids := append(category.AncestorIDs(), category.ID)
+	for _, descendant := range descendants {
+		ids = append(ids, descendant.ID)
+	}
+	return s.OfCategories(ctx, ids)
+}
+
+// attributeRules holds what an attribute is checked against: whether products have values of it, the related
+// attributes of its category and the attributes with its name.
+type attributeRules struct {
+	inUse     bool
+	related   []models.Attribute
+	namesakes []models.Attribute
+}
+
+// rules reads what attribute is checked against once given the category and name of newAttribute.
+func (s *Attributes) rules(ctx context.Context, attribute *models.Attribute, newAttribute models.Attribute) (attributeRules, error) {
+	var rules attributeRules
+	var err error
+	if attribute.ID != 0 {
+		if rules.inUse, err = s.attributes.InUse(ctx, attribute.ID); err != nil {
+			return rules, apperr.FromDB(err, "Error retrieving attributes")
+		}
+	}
+	if rules.related, err = s.Related(ctx, newAttribute.Category_ID); err != nil {
+		return rules, err
+	}
+	if rules.namesakes, err = s.attributes.ListByName(ctx, newAttribute.Name); err != nil {
+		return rules, apperr.FromDB(err, "Error retrieving attributes")
+	}
+	return rules, nil
+}
+
+// check validates the input data for an attribute against rules: that its category exists, that its name is not
+// taken along the branch of the category and that its type is that of the attributes with the same name.
+// The category and type of an attribute in use are kept.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Attributes) check(ctx context.Context, attribute *models.Attribute, newAttribute models.Attribute, rules attributeRules, only ...string) error {
+	v := validation.New(only...)
+	v.Check("category_id", attribute.SetCategoryID(newAttribute.Category_ID, exists[models.Category](ctx, s.categories), rules.inUse))
+	v.Check("name", attribute.SetName(newAttribute.Name, rules.related))
+	v.Check("label", attribute.SetLabel(newAttribute.Label))
+	v.Check("type", attribute.SetType(newAttribute.Type, rules.namesakes, rules.inUse))
+	v.Check("unit", attribute.SetUnit(newAttribute.Unit))
+	return v.Err()
+}
+
+// attributeFilters parses the attribute filters of params, the query string of a product listing, comparing the
+// values of attributes. A key is a filter when it names one of attributes or has an operator, e.g. ram_gb[gte];
+// other keys, such as page or a cache buster, are ignored as by listings without filters. A filter must name one of
+// attributes, with an operator applying to its type and values of its type.
+// The errors of all invalid filters are returned together as a validation error, keyed by query parameter.
+func attributeFilters(params map[string][]string, attributes []models.Attribute) ([]models.AttributeFilter, error) {
+	byName := map[string]models.Attribute{}
+	for _, attribute := range attributes {
+		if _, found := byName[attribute.Name]; !found {
+			byName[attribute.Name] = attribute
+		}
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	v := validation.New()
+	filters := []models.AttributeFilter{}
+	for _, key := range keys {
+		name, operator, ok := models.ParseFilterKey(key)
+		if !ok {
+			continue
+		}
+		attribute, found := byName[name]
+		if !found && !strings.HasSuffix(key, "]") {
+			continue
+		}
+		if err := validation.Exists(found, "attribute"); err != nil {
+			v.Check(key, err)
+			continue
+		}
+		filter, err := models.NewAttributeFilter(attribute, operator, params[key])
+		v.Check(key, err)
+		if err == nil {
+			filters = append(filters, filter)
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, apperr.Validation(err)
+	}
+	return filters, nil
+}
+
+// facets returns the facets of attributes among the products of the categories with the given IDs, or of every
+// category when categoryIDs is nil, selected by filters. The values of a facet are counted without the filters on
+// its own attribute, so that clients can offer the other values of an attribute already filtered on.
+func facets(ctx context.Context, products repository.ProductRepository, categoryIDs []uint, attributes []models.Attribute, filters []models.AttributeFilter) ([]models.Facet, error) {
+	filtered := map[string]bool{}
+	for _, filter := range filters {
+		filtered[filter.Name] = true
+	}
+	var unfiltered []string
+	for _, attribute := range attributes {
+		if !filtered[attribute.Name] && !slices.Contains(unfiltered, attribute.Name) {
+			unfiltered = append(unfiltered, attribute.Name)
+		}
+	}
+
+	counts, err := products.Facets(ctx, repository.ProductFilter{CategoryIDs: categoryIDs, Attributes: filters}, unfiltered)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving facets")
+	}
+	for name := range filtered {
+		others := []models.AttributeFilter{}
+		for _, filter := range filters {
+			if filter.Name != name {
+				others = append(others, filter)
+			}
+		}
+		own, err := products.Facets(ctx, repository.ProductFilter{CategoryIDs: categoryIDs, Attributes: others}, []string{name})
+		if err != nil {
+			return nil, apperr.FromDB(err, "Error retrieving facets")
+		}
+		counts = append(counts, own...)
+	}
+	return models.BuildFacets(attributes, counts), nil
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/audit"
+	"E-Commerce_Website_Database/internal/repository"
+	"context"
+)
+
+// Audit reads the audit log of every mutation.
+type Audit struct {
+	log repository.AuditLog
+}
+
+// Find returns the entries matching filter, most recent first.
+func (s *Audit) Find(ctx context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	entries, err := s.log.Find(ctx, filter)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving the audit log")
+	}
+	return entries, nil
+}
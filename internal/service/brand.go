@@ -0,0 +1,93 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+)
+
+// Brands applies the rules of brands, whose logo is an uploaded image.
+type Brands struct {
+	records[models.Brands]
+	media *Media
+}
+
+// Create validates input and inserts it as a new brand with a generated ID.
+func (s *Brands) Create(ctx context.Context, input models.Brands) (*models.Brands, error) {
+	brand := models.Brands{
+		Name:        input.Name,
+		Description: input.Description,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &brand, s.check(brand, input)); err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// Update replaces the name and description of brand with those of input once validated, and saves it.
+func (s *Brands) Update(ctx context.Context, brand *models.Brands, input models.Brands) error {
+	brand.Name = input.Name
+	brand.Description = input.Description
+	return s.save(ctx, brand, s.check(*brand, input))
+}
+
+// Patch validates and saves the fields of brand changed by a merge patch. Without fields nothing is saved.
+func (s *Brands) Patch(ctx context.Context, brand *models.Brands, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return s.save(ctx, brand, s.check(*brand, *brand, fields...), fields...)
+}
+
+// SetLogo reads an image from r and makes it the logo of brand, replacing the files of its previous logo.
+func (s *Brands) SetLogo(ctx context.Context, brand *models.Brands, r io.Reader) error {
+	logo, err := s.media.store(ctx, fmt.Sprintf("%s/%d", brandMediaDir, brand.ID), r)
+	if err != nil {
+		return err
+	}
+	previous := brand.Logo
+	brand.Logo = logo
+	if err := s.save(ctx, brand, nil); err != nil {
+		brand.Logo = previous
+		s.media.remove(ctx, logo)
+		return err
+	}
+	if previous != nil {
+		s.media.remove(ctx, previous)
+	}
+	return nil
+}
+
+// RemoveLogo removes the logo of brand and its files. A brand without a logo is left unchanged.
+func (s *Brands) RemoveLogo(ctx context.Context, brand *models.Brands) error {
+	if brand.Logo == nil {
+		return nil
+	}
+	previous := brand.Logo
+	brand.Logo = nil
+	if err := s.save(ctx, brand, nil); err != nil {
+		brand.Logo = previous
+		return err
+	}
+	// Saving allocates the embedded logo to write its empty columns.
+	brand.Logo = nil
+	s.media.remove(ctx, previous)
+	return nil
+}
+
+// check validates the input data for a brand, its name and description.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Brands) check(brand models.Brands, newBrand models.Brands, only ...string) error {
+	v := validation.New(only...)
+	v.Check("name", brand.SetName(newBrand.Name))
+	v.Check("description", brand.SetDescription(newBrand.Description))
+	return v.Err()
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+)
+
+// Categories applies the rules of categories, which form a tree through their parents.
+type Categories struct {
+	records[models.Category]
+	categories repository.CategoryRepository
+	products   repository.ProductRepository
+	attributes *Attributes
+}
+
+// Create validates input and inserts it as a new category with a generated ID, below its parent or at the root.
+func (s *Categories) Create(ctx context.Context, input models.Category) (*models.Category, error) {
+	category := models.Category{
+		Name:        input.Name,
+		Description: input.Description,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &category, s.check(ctx, &category, input, 0)); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Update replaces the name, description and parent of category with those of input once validated, and saves it.
+// A new parent moves the category together with its subtree.
+func (s *Categories) Update(ctx context.Context, category *models.Category, input models.Category) error {
+	height, err := s.height(ctx, category)
+	if err != nil {
+		return err
+	}
+	category.Name = input.Name
+	category.Description = input.Description
+	return s.saveTree(ctx, category, s.check(ctx, category, input, height))
+}
+
+// Patch validates and saves the fields of category changed by a merge patch. Without fields nothing is saved.
+// A patched parent moves the category together with its subtree.
+func (s *Categories) Patch(ctx context.Context, category *models.Category, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	height := 0
+	for _, field := range fields {
+		if field == "parent_id" {
+			var err error
+			if height, err = s.height(ctx, category); err != nil {
+				return err
+			}
+			fields = append(fields, "path")
+			break
+		}
+	}
+	return s.saveTree(ctx, category, s.check(ctx, category, *category, height, fields...), fields...)
+}
+
+// Move places category and its subtree below the category with the given ID, or at the root when parentID is nil.
+func (s *Categories) Move(ctx context.Context, category *models.Category, parentID *uint) error {
+	category.Parent_ID = parentID
+	return s.Patch(ctx, category, []string{"parent_id"})
+}
+
+// Tree returns every category nested below its parent, the root categories first.
+func (s *Categories) Tree(ctx context.Context) ([]models.CategoryTree, error) {
+	categories, err := s.List(ctx, repository.Query{})
+	if err != nil {
+		return nil, err
+	}
+	models.SortCategories(categories)
+	return models.BuildCategoryTrees(categories), nil
+}
+
+// Subtree returns the category with the given ID with its descendants nested below it, or a not found error.
+func (s *Categories) Subtree(ctx context.Context, id uint) (*models.CategoryTree, error) {
+	category, err := s.Get(ctx, id, repository.Query{})
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := s.descendants(ctx, category, repository.Query{})
+	if err != nil {
+		return nil, err
+	}
+	tree := models.BuildCategoryTrees(append([]models.Category{*category}, descendants...))[0]
+	return &tree, nil
+}
+
+// Breadcrumbs returns the categories from the root down to the category with the given ID, or a not found error.
+func (s *Categories) Breadcrumbs(ctx context.Context, id uint) ([]models.Category, error) {
+	category, err := s.Get(ctx, id, repository.Query{})
+	if err != nil {
+		return nil, err
+	}
+	ancestors, err := s.categories.Ancestors(ctx, category)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving categories")
+	}
+	return append(ancestors, *category), nil
+}
+
+// Products returns the products of the category with the given ID and of its descendants whose values match the
+// attribute filters of params, the query string of the listing, or a not found error. Filters name the attributes
+// of the category, of its ancestors and of its descendants.
+func (s *Categories) Products(ctx context.Context, id uint, params map[string][]string, q repository.Query) ([]models.Product, error) {
+	ids, _, filters, err := s.filters(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.products.Filter(ctx, repository.ProductFilter{CategoryIDs: ids, Attributes: filters}, q)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving products")
+	}
+	return products, nil
+}
+
+// Facets returns the values of the attributes products of the category with the given ID may have, among its
+// products and those of its descendants matching the attribute filters of params, with the number of products
+// having each, or a not found error.
+func (s *Categories) Facets(ctx context.Context, id uint, params map[string][]string) ([]models.Facet, error) {
+	ids, attributes, filters, err := s.filters(ctx, id, params)
+	if err != nil {
+		return nil, err
+	}
+	return facets(ctx, s.products, ids, attributes, filters)
+}
+
+// Attributes returns the attributes the products of the category with the given ID have, those it defines and
+// those it inherits from its ancestors, from the root down, or a not found error.
+func (s *Categories) Attributes(ctx context.Context, id uint) ([]models.Attribute, error) {
+	category, err := s.Get(ctx, id, repository.Query{})
+	if err != nil {
+		return nil, err
+	}
+	return s.attributes.Schema(ctx, category)
+}
+
+// filters returns the IDs of the category with the given ID and of its descendants, the attributes related to
+// the category and the attribute filters of params on them, or a not found error.
+func (s *Categories) filters(ctx context.Context, id uint, params map[string][]string) ([]uint, []models.Attribute, []models.AttributeFilter, error) {
+	category, err := s.Get(ctx, id, repository.Query{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	descendants, err := s.descendants(ctx, category, repository.Query{})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	ids := []uint{category.ID}
+	for _, descendant := range descendants {
+		ids = append(ids, descendant.ID)
+	}
+	attributes, err := s.attributes.Related(ctx, id)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	filters, err := attributeFilters(params, attributes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ids, attributes, filters, nil
+}
+
+// descendants returns the categories below category, parents before their children.
+func (s *Categories) descendants(ctx context.Context, category *models.Category, q repository.Query) ([]models.Category, error) {
+	descendants, err := s.categories.Descendants(ctx, category.Path, q)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving categories")
+	}
+	return descendants, nil
+}
+
+// height returns how many levels of descendants category has, counting those in the trash, which move with it.
+func (s *Categories) height(ctx context.Context, category *models.Category) (int, error) {
+	descendants, err := s.descendants(ctx, category, repository.Query{Trashed: repository.WithTrashed})
+	if err != nil {
+		return 0, err
+	}
+	height := 0
+	for _, descendant := range descendants {
+		if levels := descendant.Depth() - category.Depth(); levels > height {
+			height = levels
+		}
+	}
+	return height, nil
+}
+
+// saveTree saves category like save. A parent moved or deleted by another request since the path of category was
+// derived from it is answered with a conflict, so that the client reads the tree again before moving the category.
+func (s *Categories) saveTree(ctx context.Context, category *models.Category, check error, columns ...string) error {
+	err := s.save(ctx, category, check, columns...)
+	if errors.Is(err, models.ErrParentMoved) {
+		return apperr.Conflict("The parent category was moved or deleted meanwhile, read it again", err)
+	}
+	return err
+}
+
+// check validates the input data for a category, its name, description and parent, and derives the path of category
+// from its parent. height is how many levels of descendants move with it.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Categories) check(ctx context.Context, category *models.Category, newCategory models.Category, height int, only ...string) error {
+	v := validation.New(only...)
+	v.Check("name", category.SetName(newCategory.Name))
+	v.Check("description", category.SetDescription(newCategory.Description))
+	v.Check("parent_id", category.SetParent(newCategory.Parent_ID, lookup[models.Category](ctx, s.categories), height))
+	return v.Err()
+}
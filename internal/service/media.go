@@ -0,0 +1,125 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/storage"
+	"E-Commerce_Website_Database/internal/tools"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// The directories of the storage holding the images of products and the logos of brands, one subdirectory per record.
+// They are the only ones Sweep looks into, so that the storage can share its root with other files.
+const (
+	productMediaDir = "products"
+	brandMediaDir   = "brands"
+)
+
+// Media stores the files of uploaded images. Uploads are sniffed and checked against the limits, given a thumbnail
+// and stored under new keys, so that a stored file never changes. Files no longer referenced by any image,
+// even one in the trash, are removed by Sweep.
+type Media struct {
+	files  storage.Storage
+	limits imaging.Limits
+	images repository.ProductImageRepository
+	brands repository.BrandRepository
+}
+
+// MaxUploadSize returns the size in bytes of the largest image accepted.
+func (s *Media) MaxUploadSize() int64 {
+	return s.limits.MaxBytes
+}
+
+// Open returns the file stored under key, to be closed by the caller, or a not found error.
+func (s *Media) Open(ctx context.Context, key string) (*storage.Object, error) {
+	object, err := s.files.Open(ctx, key)
+	switch {
+	case err == nil:
+		return object, nil
+	case errors.Is(err, storage.ErrNotFound), errors.Is(err, storage.ErrInvalidKey):
+		return nil, apperr.NotFound("File not found")
+	}
+	return nil, apperr.Internal("Failed to read file", err)
+}
+
+// store reads an image from r and stores it and its thumbnail below dir, returning their description.
+// Images over the limits, of another type or that cannot be decoded are refused.
+func (s *Media) store(ctx context.Context, dir string, r io.Reader) (*models.Image, error) {
+	img, err := imaging.Read(r, s.limits)
+	switch {
+	case errors.Is(err, imaging.ErrTooLarge):
+		return nil, apperr.New(apperr.KindTooLarge,
+			fmt.Sprintf("Images are limited to %d bytes and %d pixels", s.limits.MaxBytes, s.limits.MaxPixels))
+	case errors.Is(err, imaging.ErrUnsupported):
+		return nil, apperr.New(apperr.KindUnsupportedMediaType, "Upload a JPEG, PNG or GIF image")
+	case errors.Is(err, imaging.ErrInvalid):
+		return nil, apperr.BadRequest("Invalid image", err)
+	case err != nil:
+		return nil, apperr.Internal("Failed to read image", err)
+	}
+
+	id := tools.GenerateID()
+	file := fmt.Sprintf("%s/%d%s", dir, id, imaging.Extension(img.ContentType))
+	thumbnail := fmt.Sprintf("%s/%d_thumb%s", dir, id, imaging.Extension(img.ThumbnailType))
+	if err := s.files.Put(ctx, file, bytes.NewReader(img.Data), img.ContentType); err != nil {
+		return nil, apperr.Internal("Failed to store image", err)
+	}
+	if err := s.files.Put(ctx, thumbnail, bytes.NewReader(img.Thumbnail), img.ThumbnailType); err != nil {
+		s.files.Delete(ctx, file)
+		return nil, apperr.Internal("Failed to store image", err)
+	}
+	image := &models.Image{Content_Type: img.ContentType, Size: int64(len(img.Data)), Width: img.Width, Height: img.Height}
+	image.SetFiles(file, thumbnail)
+	return image, nil
+}
+
+// remove deletes the files of image once no record references them. Files that cannot be deleted are left
+// to Sweep.
+func (s *Media) remove(ctx context.Context, image *models.Image) {
+	for _, key := range image.Files() {
+		s.files.Delete(ctx, key)
+	}
+}
+
+// Sweep deletes the files stored under the directories of product images and brand logos that no image of a product
+// or logo of a brand references, including those in the trash, and returns how many were deleted. Only files stored
+// before the given time are deleted, so that uploads whose record is still being written are spared. Files elsewhere
+// in the storage are never deleted.
+func (s *Media) Sweep(ctx context.Context, before time.Time) (int, error) {
+	referenced := map[string]bool{}
+	for _, list := range []func(context.Context) ([]string, error){s.images.Files, s.brands.Files} {
+		keys, err := list(ctx)
+		if err != nil {
+			return 0, apperr.FromDB(err, "Error retrieving images")
+		}
+		for _, key := range keys {
+			referenced[key] = true
+		}
+	}
+	var stored []storage.Info
+	for _, dir := range []string{productMediaDir, brandMediaDir} {
+		files, err := s.files.List(ctx, dir+"/")
+		if err != nil {
+			return 0, apperr.Internal("Failed to list files", err)
+		}
+		stored = append(stored, files...)
+	}
+	swept := 0
+	for _, file := range stored {
+		if referenced[file.Key] || !file.ModTime.Before(before) {
+			continue
+		}
+		if err := s.files.Delete(ctx, file.Key); err != nil {
+			return swept, apperr.Internal("Failed to delete file", err)
+		}
+		swept++
+	}
+	return swept, nil
+}
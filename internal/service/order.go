@@ -0,0 +1,65 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"gorm.io/gorm"
+)
+
+// Orders applies the rules of orders, which reference the user who placed them.
+type Orders struct {
+	records[models.Order]
+	users repository.UserRepository
+}
+
+// Create validates input and inserts it as a new order with a generated ID.
+// An omitted order date is set to the current time. The total amount of input is ignored: it starts at 0 and follows
+// the items of the order.
+func (s *Orders) Create(ctx context.Context, input models.Order) (*models.Order, error) {
+	input.Order_date = dateOr(input.Order_date, now())
+	order := models.Order{
+		User_ID:    input.User_ID,
+		Order_date: input.Order_date,
+		Status:     input.Status,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &order, s.check(ctx, order, input)); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// Update replaces the fields of order with those of input once validated, and saves it.
+// An omitted order date keeps its value, and the total amount is kept whatever input holds.
+func (s *Orders) Update(ctx context.Context, order *models.Order, input models.Order) error {
+	input.Order_date = dateOr(input.Order_date, order.Order_date)
+	order.User_ID = input.User_ID
+	order.Order_date = input.Order_date
+	order.Status = input.Status
+	return s.save(ctx, order, s.check(ctx, *order, input))
+}
+
+// Patch validates and saves the fields of order changed by a merge patch. Without fields nothing is saved.
+func (s *Orders) Patch(ctx context.Context, order *models.Order, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	order.Order_date = order.Order_date.UTC()
+	return s.save(ctx, order, s.check(ctx, *order, *order, fields...), fields...)
+}
+
+// check validates the input data for an order, including that its user exists.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Orders) check(ctx context.Context, order models.Order, newOrder models.Order, only ...string) error {
+	v := validation.New(only...)
+	v.Check("user_id", order.SetUserID(newOrder.User_ID, exists[models.User](ctx, s.users)))
+	v.Check("order_date", order.SetOrderDate(newOrder.Order_date))
+	v.Check("status", order.SetStatus(newOrder.Status))
+	return v.Err()
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"gorm.io/gorm"
+	"slices"
+)
+
+// OrderItems applies the rules of order items, which reference their order, the product ordered and, for a product
+// with variants, the variant ordered.
+type OrderItems struct {
+	records[models.OrderItem]
+	repos    *repository.Repositories
+	orders   repository.OrderRepository
+	products repository.ProductRepository
+	variants repository.ProductVariantRepository
+}
+
+// newOrderItems returns the service of the order items stored in repos.
+func newOrderItems(repos *repository.Repositories) *OrderItems {
+	return &OrderItems{
+		records:  newRecords[models.OrderItem](repos.OrderItems, "Order item", "order items"),
+		repos:    repos,
+		orders:   repos.Orders,
+		products: repos.Products,
+		variants: repos.ProductVariants,
+	}
+}
+
+// inTransaction runs write with the service bound to a transaction, so that the write of an item, the check of
+// the stock it orders and the update of the total amounts of orders are committed together or not at all.
+func (s *OrderItems) inTransaction(ctx context.Context, write func(tx *OrderItems) error) error {
+	err := s.repos.Transaction(ctx, func(repos *repository.Repositories) error {
+		return write(newOrderItems(repos))
+	})
+	if err != nil {
+		return apperr.FromDB(err, "Failed to update order items")
+	}
+	return nil
+}
+
+// Create validates input and inserts it as a new order item with a generated ID, priced from the product or variant
+// ordered, and updates the total amount of its order in the same transaction. The subtotal of input is ignored.
+func (s *OrderItems) Create(ctx context.Context, input models.OrderItem) (*models.OrderItem, error) {
+	orderItem := models.OrderItem{
+		Order_ID:   input.Order_ID,
+		Product_ID: input.Product_ID,
+		Variant_ID: input.Variant_ID,
+		Quantity:   input.Quantity,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	variants, err := s.variantsOf(ctx, input.Product_ID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.inTransaction(ctx, func(tx *OrderItems) error {
+		if err := tx.create(ctx, &orderItem, tx.price(ctx, &orderItem, tx.check(ctx, orderItem, input, variants))); err != nil {
+			return err
+		}
+		return tx.updateTotals(ctx, orderItem.Order_ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &orderItem, nil
+}
+
+// Update replaces the fields of orderItem with those of input once validated, prices it again and saves it,
+// then updates the total amounts of the orders it was and is now part of, in one transaction. The subtotal of input
+// is ignored.
+func (s *OrderItems) Update(ctx context.Context, orderItem *models.OrderItem, input models.OrderItem) error {
+	variants, err := s.variantsOf(ctx, input.Product_ID)
+	if err != nil {
+		return err
+	}
+	previousOrderID := orderItem.Order_ID
+	orderItem.Order_ID = input.Order_ID
+	orderItem.Product_ID = input.Product_ID
+	orderItem.Variant_ID = input.Variant_ID
+	orderItem.Quantity = input.Quantity
+	return s.inTransaction(ctx, func(tx *OrderItems) error {
+		if err := tx.save(ctx, orderItem, tx.price(ctx, orderItem, tx.check(ctx, *orderItem, input, variants))); err != nil {
+			return err
+		}
+		return tx.updateTotals(ctx, previousOrderID, orderItem.Order_ID)
+	})
+}
+
+// Patch validates and saves the fields of orderItem changed by a merge patch. Without fields nothing is saved.
+// An item changed to another product has its variant checked against the variants of that product, and an item
+// whose product, variant or quantity changed is priced again. The total amounts of the orders it was and is now
+// part of are updated in the same transaction.
+func (s *OrderItems) Patch(ctx context.Context, orderItem *models.OrderItem, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	if slices.Contains(fields, "product_id") && !slices.Contains(fields, "variant_id") {
+		fields = append(fields, "variant_id")
+	}
+	previous, err := s.Get(ctx, orderItem.ID, repository.Query{})
+	if err != nil {
+		return err
+	}
+	variants, err := s.variantsOf(ctx, orderItem.Product_ID)
+	if err != nil {
+		return err
+	}
+	return s.inTransaction(ctx, func(tx *OrderItems) error {
+		check := tx.check(ctx, *orderItem, *orderItem, variants, fields...)
+		if slices.Contains(fields, "variant_id") || slices.Contains(fields, "quantity") {
+			check = tx.price(ctx, orderItem, check)
+			fields = append(fields, "subtotal")
+		}
+		if err := tx.save(ctx, orderItem, check, fields...); err != nil {
+			return err
+		}
+		return tx.updateTotals(ctx, previous.Order_ID, orderItem.Order_ID)
+	})
+}
+
+// Delete moves the order item with the given ID to the trash, like records.Delete, and updates the total amount
+// of its order in the same transaction.
+func (s *OrderItems) Delete(ctx context.Context, id, version uint) error {
+	orderItem, err := s.Get(ctx, id, repository.Query{})
+	if err != nil {
+		return err
+	}
+	return s.inTransaction(ctx, func(tx *OrderItems) error {
+		if err := tx.records.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		return tx.updateTotals(ctx, orderItem.Order_ID)
+	})
+}
+
+// Restore takes the order item with the given ID out of the trash, like records.Restore, and updates the total amount
+// of its order in the same transaction.
+func (s *OrderItems) Restore(ctx context.Context, id uint) (*models.OrderItem, error) {
+	var orderItem *models.OrderItem
+	err := s.inTransaction(ctx, func(tx *OrderItems) error {
+		var err error
+		if orderItem, err = tx.records.Restore(ctx, id); err != nil {
+			return err
+		}
+		return tx.updateTotals(ctx, orderItem.Order_ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderItem, nil
+}
+
+// price sets the subtotal of orderItem from the price of its product, or of its variant when one is ordered,
+// once check, the validation of its fields, succeeded. Ordering more than is in stock is a validation error
+// of the quantity; the stock is checked, not reserved.
+func (s *OrderItems) price(ctx context.Context, orderItem *models.OrderItem, check error) error {
+	if check != nil {
+		return check
+	}
+	product, err := s.products.Get(ctx, orderItem.Product_ID, repository.Query{})
+	if err != nil {
+		return apperr.FromDB(err, "Error retrieving the product ordered")
+	}
+	var variant *models.ProductVariant
+	if orderItem.Variant_ID != nil {
+		if variant, err = s.variants.Get(ctx, *orderItem.Variant_ID, repository.Query{}); err != nil {
+			return apperr.FromDB(err, "Error retrieving the variant ordered")
+		}
+	}
+	v := validation.New()
+	v.Check("quantity", orderItem.SetPrice(product, variant))
+	return v.Err()
+}
+
+// updateTotals sets the total amount of each order with the given ID to the sum of the subtotals of its items.
+func (s *OrderItems) updateTotals(ctx context.Context, orderIDs ...uint) error {
+	slices.Sort(orderIDs)
+	for _, id := range slices.Compact(orderIDs) {
+		if err := s.orders.UpdateTotal(ctx, id); err != nil {
+			return apperr.FromDB(err, "Failed to update the total amount of the order")
+		}
+	}
+	return nil
+}
+
+// variantsOf returns the variants of the product with the given ID, among which an item of it must select one.
+func (s *OrderItems) variantsOf(ctx context.Context, productID uint) ([]models.ProductVariant, error) {
+	variants, err := s.variants.ListByProduct(ctx, productID, repository.Query{})
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving product variants")
+	}
+	return variants, nil
+}
+
+// check validates the input data for an order item, including that its order and product exist and that it selects
+// one of variants, the variants of the product, when there are any.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *OrderItems) check(ctx context.Context, orderItem models.OrderItem, newOrderItem models.OrderItem, variants []models.ProductVariant, only ...string) error {
+	v := validation.New(only...)
+	v.Check("order_id", orderItem.SetOrderID(newOrderItem.Order_ID, exists[models.Order](ctx, s.orders)))
+	v.Check("product_id", orderItem.SetProductID(newOrderItem.Product_ID, exists[models.Product](ctx, s.products)))
+	v.Check("variant_id", orderItem.SetVariantID(newOrderItem.Variant_ID, lookup[models.ProductVariant](ctx, s.variants), variants))
+	v.Check("quantity", orderItem.SetQuantity(newOrderItem.Quantity))
+	return v.Err()
+}
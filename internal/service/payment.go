@@ -0,0 +1,70 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"gorm.io/gorm"
+)
+
+// Payments applies the rules of payments, which reference the order they pay.
+type Payments struct {
+	records[models.Payment]
+	orders repository.OrderRepository
+}
+
+// Create validates input and inserts it as a new payment with a generated ID.
+// An omitted payment date is set to the current time.
+func (s *Payments) Create(ctx context.Context, input models.Payment) (*models.Payment, error) {
+	input.Payment_date = dateOr(input.Payment_date, now())
+	payment := models.Payment{
+		Order_ID:       input.Order_ID,
+		Payment_method: input.Payment_method,
+		Amount:         input.Amount,
+		Payment_date:   input.Payment_date,
+		Status:         input.Status,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &payment, s.check(ctx, payment, input)); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// Update replaces the fields of payment with those of input once validated, and saves it.
+// An omitted payment date keeps its value.
+func (s *Payments) Update(ctx context.Context, payment *models.Payment, input models.Payment) error {
+	input.Payment_date = dateOr(input.Payment_date, payment.Payment_date)
+	payment.Order_ID = input.Order_ID
+	payment.Payment_method = input.Payment_method
+	payment.Amount = input.Amount
+	payment.Payment_date = input.Payment_date
+	payment.Status = input.Status
+	return s.save(ctx, payment, s.check(ctx, *payment, input))
+}
+
+// Patch validates and saves the fields of payment changed by a merge patch. Without fields nothing is saved.
+func (s *Payments) Patch(ctx context.Context, payment *models.Payment, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	payment.Payment_date = payment.Payment_date.UTC()
+	return s.save(ctx, payment, s.check(ctx, *payment, *payment, fields...), fields...)
+}
+
+// check validates the input data for a payment, including that its order exists.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Payments) check(ctx context.Context, payment models.Payment, newPayment models.Payment, only ...string) error {
+	v := validation.New(only...)
+	v.Check("order_id", payment.SetOrderID(newPayment.Order_ID, exists[models.Order](ctx, s.orders)))
+	v.Check("payment_method", payment.SetPaymentMethod(newPayment.Payment_method))
+	v.Check("amount", payment.SetAmount(newPayment.Amount))
+	v.Check("payment_date", payment.SetPaymentDate(newPayment.Payment_date))
+	v.Check("status", payment.SetStatus(newPayment.Status))
+	return v.Err()
+}
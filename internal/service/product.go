@@ -0,0 +1,234 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"slices"
+	"sort"
+)
+
+// Products applies the rules of products, which reference a brand and a category, and of their specification
+// sheets, the values of the attributes of their category.
+type Products struct {
+	records[models.Product]
+	brands     repository.BrandRepository
+	categories repository.CategoryRepository
+	products   repository.ProductRepository
+	attributes *Attributes
+}
+
+// Create validates input and inserts it as a new product with a generated ID.
+func (s *Products) Create(ctx context.Context, input models.Product) (*models.Product, error) {
+	product := models.Product{
+		Name:           input.Name,
+		Description:    input.Description,
+		Price:          input.Price,
+		Stock_quantity: input.Stock_quantity,
+		Brand_ID:       input.Brand_ID,
+		Category_ID:    input.Category_ID,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &product, s.check(ctx, product, input)); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// Update replaces the fields of product with those of input once validated, and saves it.
+// A product moved to another category loses the values of attributes its new category does not have.
+func (s *Products) Update(ctx context.Context, product *models.Product, input models.Product) error {
+	moved := product.Category_ID != input.Category_ID
+	product.Name = input.Name
+	product.Description = input.Description
+	product.Price = input.Price
+	product.Stock_quantity = input.Stock_quantity
+	product.Brand_ID = input.Brand_ID
+	product.Category_ID = input.Category_ID
+	if err := s.save(ctx, product, s.check(ctx, *product, input)); err != nil || !moved {
+		return err
+	}
+	return s.dropForeignAttributes(ctx, product)
+}
+
+// Patch validates and saves the fields of product changed by a merge patch. Without fields nothing is saved.
+// A product moved to another category loses the values of attributes its new category does not have.
+func (s *Products) Patch(ctx context.Context, product *models.Product, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	if err := s.save(ctx, product, s.check(ctx, *product, *product, fields...), fields...); err != nil {
+		return err
+	}
+	if !slices.Contains(fields, "category_id") {
+		return nil
+	}
+	return s.dropForeignAttributes(ctx, product)
+}
+
+// Filter returns the products whose values match the attribute filters of params, the query string of the listing,
+// such as ram_gb[gte]=16. Filters name the attributes of any category.
+func (s *Products) Filter(ctx context.Context, params map[string][]string, q repository.Query) ([]models.Product, error) {
+	if len(params) == 0 {
+		return s.List(ctx, q)
+	}
+	attributes, err := s.attributes.OfCategories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := attributeFilters(params, attributes)
+	if err != nil {
+		return nil, err
+	}
+	products, err := s.products.Filter(ctx, repository.ProductFilter{Attributes: filters}, q)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving products")
+	}
+	return products, nil
+}
+
+// Facets returns the values of the attributes of every category among the products matching the attribute filters
+// of params, with the number of products having each.
+func (s *Products) Facets(ctx context.Context, params map[string][]string) ([]models.Facet, error) {
+	attributes, err := s.attributes.OfCategories(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := attributeFilters(params, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return facets(ctx, s.products, nil, attributes, filters)
+}
+
+// Attributes returns the specification sheet of the product with the given ID, its values with their attribute,
+// or a not found error.
+func (s *Products) Attributes(ctx context.Context, id uint) ([]models.ProductAttribute, error) {
+	if _, err := s.Get(ctx, id, repository.Query{}); err != nil {
+		return nil, err
+	}
+	values, err := s.products.Attributes(ctx, id)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving product attributes")
+	}
+	return values, nil
+}
+
+// SetAttributes replaces the specification sheet of product by input, values keyed by attribute name, once they
+// are validated against the attributes of its category: every name must be that of one of them and every value
+// of its type. Attributes left out or set to null lose their value. The version of product is incremented with the
+// sheet, a product changed since it was read being answered with a precondition failed error. It returns the new sheet.
+func (s *Products) SetAttributes(ctx context.Context, product *models.Product, input map[string]interface{}) ([]models.ProductAttribute, error) {
+	schema, err := s.schema(ctx, product.Category_ID)
+	if err != nil {
+		return nil, err
+	}
+	byName := map[string]*models.Attribute{}
+	for i := range schema {
+		byName[schema[i].Name] = &schema[i]
+	}
+	names := make([]string, 0, len(input))
+	for name := range input {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	v := validation.New()
+	values := []models.ProductAttribute{}
+	for _, name := range names {
+		attribute := byName[name]
+		if err := validation.Exists(attribute != nil, "attribute of the category"); err != nil {
+			v.Check(name, err)
+			continue
+		}
+		if input[name] == nil {
+			continue
+		}
+		value := models.ProductAttribute{ID: tools.GenerateID()}
+		err := value.SetValue(attribute, input[name])
+		v.Check(name, err)
+		if err == nil {
+			values = append(values, value)
+		}
+	}
+	if err := v.Err(); err != nil {
+		return nil, apperr.Validation(err)
+	}
+	if err := s.setAttributes(ctx, product, values); err != nil {
+		return nil, err
+	}
+	return s.Attributes(ctx, product.ID)
+}
+
+// dropForeignAttributes removes the values of product for attributes the schema of its category does not have,
+// once it moved to another category.
+func (s *Products) dropForeignAttributes(ctx context.Context, product *models.Product) error {
+	values, err := s.products.Attributes(ctx, product.ID)
+	if err != nil {
+		return apperr.FromDB(err, "Error retrieving product attributes")
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	schema, err := s.schema(ctx, product.Category_ID)
+	if err != nil {
+		return err
+	}
+	kept := []models.ProductAttribute{}
+	for _, value := range values {
+		for _, attribute := range schema {
+			if attribute.ID == value.Attribute_ID {
+				kept = append(kept, value)
+				break
+			}
+		}
+	}
+	if len(kept) == len(values) {
+		return nil
+	}
+	return s.setAttributes(ctx, product, kept)
+}
+
+// setAttributes replaces the values of product by values, incrementing its version.
+func (s *Products) setAttributes(ctx context.Context, product *models.Product, values []models.ProductAttribute) error {
+	err := s.products.SetAttributes(ctx, product, values)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, models.ErrVersionConflict):
+		return apperr.Wrap(apperr.KindPreconditionFailed, "The product was modified since it was read", err)
+	}
+	return apperr.FromDB(err, "Failed to update product attributes")
+}
+
+// schema returns the attributes of the products of the category with the given ID, none if it does not exist.
+func (s *Products) schema(ctx context.Context, categoryID uint) ([]models.Attribute, error) {
+	category, err := s.categories.Get(ctx, categoryID, repository.Query{})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving categories")
+	}
+	return s.attributes.Schema(ctx, category)
+}
+
+// check performs validation checks on product data, including that its brand and category exist.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Products) check(ctx context.Context, product models.Product, newProduct models.Product, only ...string) error {
+	v := validation.New(only...)
+	v.Check("name", product.SetName(newProduct.Name))
+	v.Check("description", product.SetDescription(newProduct.Description))
+	v.Check("price", product.SetPrice(newProduct.Price))
+	v.Check("stock_quantity", product.SetStockQuantity(newProduct.Stock_quantity))
+	v.Check("brand_id", product.SetBrandID(newProduct.Brand_ID, exists[models.Brands](ctx, s.brands)))
+	v.Check("category_id", product.SetCategoryID(newProduct.Category_ID, exists[models.Category](ctx, s.categories)))
+	return v.Err()
+}
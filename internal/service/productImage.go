@@ -0,0 +1,212 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"fmt"
+	"gorm.io/gorm"
+	"io"
+	"slices"
+)
+
+// ProductImages applies the rules of the galleries of products: images are ordered, and a product with images
+// has exactly one primary image, its first image until another one is made primary.
+type ProductImages struct {
+	records[models.ProductImage]
+	images   repository.ProductImageRepository
+	products repository.ProductRepository
+	media    *Media
+}
+
+// OfProduct returns the images of the product with the given ID in their order, or a not found error
+// if the product does not exist.
+func (s *ProductImages) OfProduct(ctx context.Context, productID uint) ([]models.ProductImage, error) {
+	if err := s.product(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.gallery(ctx, productID)
+}
+
+// Image returns the image with the given ID of the product with the given ID, or a not found error
+// if the image belongs to another product.
+func (s *ProductImages) Image(ctx context.Context, productID, id uint, q repository.Query) (*models.ProductImage, error) {
+	image, err := s.Get(ctx, id, q)
+	if err != nil {
+		return nil, err
+	}
+	if image.Product_ID != productID {
+		return nil, apperr.NotFound(s.name + " not found")
+	}
+	return image, nil
+}
+
+// Upload reads an image from r and adds it to the end of the gallery of the product with the given ID, with the
+// alternative text and primary flag of input. The first image of a product is primary whatever input says.
+// The position and primary flag are settled when the image is added, so that concurrent uploads queue up.
+func (s *ProductImages) Upload(ctx context.Context, productID uint, r io.Reader, input models.ProductImage) (*models.ProductImage, error) {
+	if err := s.product(ctx, productID); err != nil {
+		return nil, err
+	}
+	image := models.ProductImage{
+		Product_ID: productID,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.check(&image, input); err != nil {
+		return nil, apperr.Validation(err)
+	}
+
+	stored, err := s.media.store(ctx, fmt.Sprintf("%s/%d", productMediaDir, productID), r)
+	if err != nil {
+		return nil, err
+	}
+	image.Image = *stored
+	if err := s.images.Add(ctx, &image); err != nil {
+		s.media.remove(ctx, stored)
+		return nil, apperr.Lookup(err, "Product not found")
+	}
+	return &image, nil
+}
+
+// Patch validates and saves the fields of image changed by a merge patch. Without fields nothing is saved.
+// Making the image primary makes the other images of its product not primary.
+func (s *ProductImages) Patch(ctx context.Context, image *models.ProductImage, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	// The patch was applied to image: whether it was primary is read from the stored image.
+	stored, err := s.Get(ctx, image.ID, repository.Query{})
+	if err != nil {
+		return err
+	}
+	input := *image
+	image.Primary = stored.Primary
+	if err := s.check(image, input, fields...); err != nil {
+		return apperr.Validation(err)
+	}
+	if slices.Contains(fields, "alt") {
+		if err := s.save(ctx, image, nil, "alt"); err != nil {
+			return err
+		}
+	}
+	if !image.Primary || stored.Primary {
+		return nil
+	}
+	if err := s.images.SetPrimary(ctx, image.Product_ID, image.ID); err != nil {
+		return apperr.FromDB(err, "Failed to update product images")
+	}
+	updated, err := s.Get(ctx, image.ID, repository.Query{})
+	if err != nil {
+		return err
+	}
+	*image = *updated
+	return nil
+}
+
+// Reorder moves the images of the product with the given ID to their index in ids, which must list each of them
+// exactly once, and returns them in their new order.
+func (s *ProductImages) Reorder(ctx context.Context, productID uint, ids []uint) ([]models.ProductImage, error) {
+	gallery, err := s.OfProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	current := make([]uint, len(gallery))
+	for i, image := range gallery {
+		current[i] = image.ID
+	}
+	v := validation.New()
+	v.Check("image_ids", validation.Arrangement(ids, current, "image of the product"))
+	if err := v.Err(); err != nil {
+		return nil, apperr.Validation(err)
+	}
+	if err := s.images.Reorder(ctx, productID, ids); err != nil {
+		return nil, apperr.FromDB(err, "Failed to update product images")
+	}
+	return s.gallery(ctx, productID)
+}
+
+// Delete moves the image with the given ID of the product with the given ID to the trash. When it was the primary
+// image, the first remaining image becomes primary. Its files are kept until it is purged. A version other than 0
+// is the version the image must still have, like for records.Delete.
+func (s *ProductImages) Delete(ctx context.Context, productID, id, version uint) error {
+	image, err := s.Image(ctx, productID, id, repository.Query{})
+	if err != nil {
+		return err
+	}
+	if err := s.records.Delete(ctx, id, version); err != nil {
+		return err
+	}
+	if !image.Primary {
+		return nil
+	}
+	gallery, err := s.gallery(ctx, productID)
+	if err != nil || len(gallery) == 0 {
+		return err
+	}
+	if err := s.images.SetPrimary(ctx, productID, gallery[0].ID); err != nil {
+		return apperr.FromDB(err, "Failed to update product images")
+	}
+	return nil
+}
+
+// Restore takes the image with the given ID of the product with the given ID out of the trash and returns it.
+// It is primary again only when no other image of the product is.
+func (s *ProductImages) Restore(ctx context.Context, productID, id uint) (*models.ProductImage, error) {
+	if _, err := s.Image(ctx, productID, id, repository.Query{Trashed: repository.OnlyTrashed}); err != nil {
+		return nil, err
+	}
+	if _, err := s.records.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+	gallery, err := s.gallery(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	primary := id
+	for _, image := range gallery {
+		if image.Primary && image.ID != id {
+			primary = image.ID
+			break
+		}
+	}
+	if err := s.images.SetPrimary(ctx, productID, primary); err != nil {
+		return nil, apperr.FromDB(err, "Failed to update product images")
+	}
+	return s.Get(ctx, id, repository.Query{})
+}
+
+// product returns a not found error unless the product with the given ID exists.
+func (s *ProductImages) product(ctx context.Context, productID uint) error {
+	found, err := s.products.Exists(ctx, productID)
+	if err != nil {
+		return apperr.FromDB(err, "Error retrieving product")
+	}
+	if !found {
+		return apperr.NotFound("Product not found")
+	}
+	return nil
+}
+
+// gallery returns the images of the product with the given ID in their order.
+func (s *ProductImages) gallery(ctx context.Context, productID uint) ([]models.ProductImage, error) {
+	images, err := s.images.ListByProduct(ctx, productID)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving product images")
+	}
+	return images, nil
+}
+
+// check validates the input data for an image, its alternative text and primary flag.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *ProductImages) check(image *models.ProductImage, newImage models.ProductImage, only ...string) error {
+	v := validation.New(only...)
+	v.Check("alt", image.SetAlt(newImage.Alt))
+	v.Check("primary", image.SetPrimary(newImage.Primary))
+	return v.Err()
+}
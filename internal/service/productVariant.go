@@ -0,0 +1,95 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"gorm.io/gorm"
+	"slices"
+)
+
+// ProductVariants applies the rules of product variants, which belong to a product and differ from its other
+// variants by their options.
+type ProductVariants struct {
+	records[models.ProductVariant]
+	products repository.ProductRepository
+	variants repository.ProductVariantRepository
+}
+
+// Create validates input and inserts it as a new variant with a generated ID.
+func (s *ProductVariants) Create(ctx context.Context, input models.ProductVariant) (*models.ProductVariant, error) {
+	variant := models.ProductVariant{
+		Product_ID:     input.Product_ID,
+		SKU:            input.SKU,
+		Options:        input.Options,
+		Price:          input.Price,
+		Stock_quantity: input.Stock_quantity,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	siblings, err := s.siblings(ctx, variant.Product_ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.create(ctx, &variant, s.check(ctx, variant, input, siblings)); err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// Update replaces the fields of variant with those of input once validated, and saves it.
+func (s *ProductVariants) Update(ctx context.Context, variant *models.ProductVariant, input models.ProductVariant) error {
+	siblings, err := s.siblings(ctx, input.Product_ID)
+	if err != nil {
+		return err
+	}
+	variant.Product_ID = input.Product_ID
+	variant.SKU = input.SKU
+	variant.Options = input.Options
+	variant.Price = input.Price
+	variant.Stock_quantity = input.Stock_quantity
+	return s.save(ctx, variant, s.check(ctx, *variant, input, siblings))
+}
+
+// Patch validates and saves the fields of variant changed by a merge patch. Without fields nothing is saved.
+// A variant moved to another product has its options checked against the variants of that product.
+func (s *ProductVariants) Patch(ctx context.Context, variant *models.ProductVariant, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	if slices.Contains(fields, "product_id") && !slices.Contains(fields, "options") {
+		fields = append(fields, "options")
+	}
+	siblings, err := s.siblings(ctx, variant.Product_ID)
+	if err != nil {
+		return err
+	}
+	return s.save(ctx, variant, s.check(ctx, *variant, *variant, siblings, fields...), fields...)
+}
+
+// siblings returns the variants of the product with the given ID.
+func (s *ProductVariants) siblings(ctx context.Context, productID uint) ([]models.ProductVariant, error) {
+	variants, err := s.variants.ListByProduct(ctx, productID, repository.Query{})
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving product variants")
+	}
+	return variants, nil
+}
+
+// check validates the input data for a variant, including that its product exists and that its options differ
+// from those of siblings, the other variants of the product.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *ProductVariants) check(ctx context.Context, variant models.ProductVariant, newVariant models.ProductVariant, siblings []models.ProductVariant, only ...string) error {
+	v := validation.New(only...)
+	v.Check("product_id", variant.SetProductID(newVariant.Product_ID, exists[models.Product](ctx, s.products)))
+	v.Check("sku", variant.SetSKU(newVariant.SKU))
+	v.Check("options", variant.SetOptions(newVariant.Options, siblings))
+	v.Check("price", variant.SetPrice(newVariant.Price))
+	v.Check("stock_quantity", variant.SetStockQuantity(newVariant.Stock_quantity))
+	return v.Err()
+}
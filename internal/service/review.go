@@ -0,0 +1,71 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"gorm.io/gorm"
+)
+
+// Reviews applies the rules of reviews, which reference the product reviewed and their author.
+type Reviews struct {
+	records[models.Review]
+	products repository.ProductRepository
+	users    repository.UserRepository
+}
+
+// Create validates input and inserts it as a new review with a generated ID.
+// An omitted review date is set to the current time.
+func (s *Reviews) Create(ctx context.Context, input models.Review) (*models.Review, error) {
+	input.Review_Date = dateOr(input.Review_Date, now())
+	review := models.Review{
+		Product_ID:  input.Product_ID,
+		User_ID:     input.User_ID,
+		Rating:      input.Rating,
+		Comment:     input.Comment,
+		Review_Date: input.Review_Date,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &review, s.check(ctx, review, input)); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Update replaces the fields of review with those of input once validated, and saves it.
+// An omitted review date keeps its value.
+func (s *Reviews) Update(ctx context.Context, review *models.Review, input models.Review) error {
+	input.Review_Date = dateOr(input.Review_Date, review.Review_Date)
+	review.Product_ID = input.Product_ID
+	review.User_ID = input.User_ID
+	review.Rating = input.Rating
+	review.Comment = input.Comment
+	review.Review_Date = input.Review_Date
+	return s.save(ctx, review, s.check(ctx, *review, input))
+}
+
+// Patch validates and saves the fields of review changed by a merge patch. Without fields nothing is saved.
+func (s *Reviews) Patch(ctx context.Context, review *models.Review, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	review.Review_Date = review.Review_Date.UTC()
+	return s.save(ctx, review, s.check(ctx, *review, *review, fields...), fields...)
+}
+
+// check validates the input data for a review, including that its product and user exist.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Reviews) check(ctx context.Context, review models.Review, newReview models.Review, only ...string) error {
+	v := validation.New(only...)
+	v.Check("product_id", review.SetProductID(newReview.Product_ID, exists[models.Product](ctx, s.products)))
+	v.Check("user_id", review.SetUserID(newReview.User_ID, exists[models.User](ctx, s.users)))
+	v.Check("rating", review.SetRating(newReview.Rating))
+	v.Check("comment", review.SetComment(newReview.Comment))
+	v.Check("review_date", review.SetReviewDate(newReview.Review_Date))
+	return v.Err()
+}
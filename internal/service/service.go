@@ -0,0 +1,206 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/storage"
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+// Services holds the service of every entity. Services apply the business rules, such as validation, references
+// to other records and password hashing, and return *apperr.Error values ready to be answered to clients.
+type Services struct {
+	Users           *Users
+	Brands          *Brands
+	Categories      *Categories
+	Products        *Products
+	ProductVariants *ProductVariants
+	ProductImages   *ProductImages
+	Attributes      *Attributes
+	Orders          *Orders
+	OrderItems      *OrderItems
+	Payments        *Payments
+	ShippingDetails *ShippingDetails
+	Reviews         *Reviews
+	Audit           *Audit
+	Media           *Media
+}
+
+// New returns the services of every entity, storing records in repos and the files of images, accepted within
+// limits, in files.
+func New(repos *repository.Repositories, files storage.Storage, limits imaging.Limits) *Services {
+	media := &Media{files: files, limits: limits, images: repos.ProductImages, brands: repos.Brands}
+	attributes := &Attributes{records: newRecords[models.Attribute](repos.Attributes, "Attribute", "attributes"), attributes: repos.Attributes, categories: repos.Categories}
+	return &Services{
+		Users:           &Users{records: newRecords[models.User](repos.Users, "User", "users"), users: repos.Users},
+		Brands:          &Brands{records: newRecords[models.Brands](repos.Brands, "Brand", "brands"), media: media},
+		Categories:      &Categories{records: newRecords[models.Category](repos.Categories, "Category", "categories"), categories: repos.Categories, products: repos.Products, attributes: attributes},
+		Products:        &Products{records: newRecords[models.Product](repos.Products, "Product", "products"), brands: repos.Brands, categories: repos.Categories, products: repos.Products, attributes: attributes},
+		ProductVariants: &ProductVariants{records: newRecords[models.ProductVariant](repos.ProductVariants, "Product variant", "product variants"), products: repos.Products, variants: repos.ProductVariants},
+		ProductImages:   &ProductImages{records: newRecords[models.ProductImage](repos.ProductImages, "Product image", "product images"), images: repos.ProductImages, products: repos.Products, media: media},
+		Attributes:      attributes,
+		Orders:          &Orders{records: newRecords[models.Order](repos.Orders, "Order", "orders"), users: repos.Users},
+		OrderItems:      newOrderItems(repos),
+		Payments:        &Payments{records: newRecords[models.Payment](repos.Payments, "Payment", "payments"), orders: repos.Orders},
+		ShippingDetails: &ShippingDetails{records: newRecords[models.ShippingDetails](repos.ShippingDetails, "Shipping detail", "shipping details"), orders: repos.Orders},
+		Reviews:         &Reviews{records: newRecords[models.Review](repos.Reviews, "Review", "reviews"), products: repos.Products, users: repos.Users},
+		Audit:           &Audit{log: repos.Audit},
+		Media:           media,
+	}
+}
+
+// records implements the operations shared by the services of every entity on the rows of repo.
+// name and plural name the entity in the messages of errors, e.g. "Order item" and "order items".
+type records[T any] struct {
+	repo   repository.Repository[T]
+	name   string
+	plural string
+}
+
+// newRecords returns the shared operations of the entity stored in repo.
+func newRecords[T any](repo repository.Repository[T], name, plural string) records[T] {
+	return records[T]{repo: repo, name: name, plural: plural}
+}
+
+// Get returns the row with the given ID, or a not found error.
+func (s *records[T]) Get(ctx context.Context, id uint, q repository.Query) (*T, error) {
+	row, err := s.repo.Get(ctx, id, q)
+	if err != nil {
+		return nil, apperr.Lookup(err, s.name+" not found")
+	}
+	return row, nil
+}
+
+// List returns every row.
+func (s *records[T]) List(ctx context.Context, q repository.Query) ([]T, error) {
+	rows, err := s.repo.List(ctx, q)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Error retrieving "+s.plural)
+	}
+	return rows, nil
+}
+
+// Search returns the rows matching params, an empty list when none do.
+func (s *records[T]) Search(ctx context.Context, params map[string]interface{}, q repository.Query) ([]T, error) {
+	rows, err := s.repo.Search(ctx, params, q)
+	if err != nil {
+		return nil, apperr.FromDB(err, "Failed to retrieve "+s.plural)
+	}
+	return rows, nil
+}
+
+// Version returns the version of the row with the given ID, or a not found error.
+func (s *records[T]) Version(ctx context.Context, id uint) (uint, error) {
+	version, err := s.repo.Version(ctx, id)
+	if err != nil {
+		return 0, apperr.Lookup(err, s.name+" not found")
+	}
+	return version, nil
+}
+
+// Delete moves the row with the given ID to the trash. Rows still referencing it are answered with a conflict
+// listing them as dependents. A version other than 0 is the version the row must still have, as read to check
+// If-Match; a row changed since is answered with a precondition failed error.
+func (s *records[T]) Delete(ctx context.Context, id, version uint) error {
+	err := s.repo.Delete(ctx, id, version)
+	var dependentsErr *models.DependentsError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, models.ErrVersionConflict):
+		return apperr.Wrap(apperr.KindPreconditionFailed, "The record was modified since it was read", err)
+	case errors.As(err, &dependentsErr):
+		return apperr.New(apperr.KindConflict, "Still referenced by other records: "+err.Error()).With("dependents", dependentsErr.Dependents)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return apperr.Lookup(err, s.name+" not found")
+	}
+	return apperr.FromDB(err, "Error deleting "+strings.ToLower(s.name))
+}
+
+// Restore takes the row with the given ID out of the trash and returns it. A row referencing a deleted row
+// is answered with a conflict.
+func (s *records[T]) Restore(ctx context.Context, id uint) (*T, error) {
+	row, err := s.repo.Restore(ctx, id)
+	var parentErr *models.DeletedParentError
+	switch {
+	case err == nil:
+		return row, nil
+	case errors.As(err, &parentErr):
+		return nil, apperr.New(apperr.KindConflict, "Referenced record is deleted: "+err.Error())
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, apperr.Lookup(err, s.name+" not found in trash")
+	}
+	return nil, apperr.FromDB(err, "Error restoring "+strings.ToLower(s.name))
+}
+
+// create inserts row once check, the validation of its fields, succeeded.
+func (s *records[T]) create(ctx context.Context, row *T, check error) error {
+	if check != nil {
+		return apperr.Validation(check)
+	}
+	if err := s.repo.Create(ctx, row); err != nil {
+		return apperr.FromDB(err, "Failed to create "+strings.ToLower(s.name))
+	}
+	return nil
+}
+
+// save writes the given columns of row, or all of them, once check, the validation of its fields, succeeded.
+// A row changed since it was read is answered with a precondition failed error.
+func (s *records[T]) save(ctx context.Context, row *T, check error, columns ...string) error {
+	if check != nil {
+		return apperr.Validation(check)
+	}
+	err := s.repo.Save(ctx, row, columns...)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, models.ErrVersionConflict):
+		return apperr.Wrap(apperr.KindPreconditionFailed, "The record was modified since it was read", err)
+	}
+	return apperr.FromDB(err, "Failed to update "+strings.ToLower(s.name))
+}
+
+// dateOr returns date in UTC, or fallback when date is not set, for the dates the server assigns when clients omit them.
+func dateOr(date, fallback time.Time) time.Time {
+	if date.IsZero() {
+		return fallback
+	}
+	return date.UTC()
+}
+
+// now returns the current time in UTC, the default of dates such as the order date.
+func now() time.Time {
+	return time.Now().UTC()
+}
+
+// exists returns the models.ExistsFunc of the rows of repo. Rows that cannot be read are an error, answered
+// as such rather than as a missing reference.
+func exists[T any](ctx context.Context, repo repository.Repository[T]) models.ExistsFunc {
+	return func(id uint) (bool, error) {
+		found, err := repo.Exists(ctx, id)
+		if err != nil {
+			return false, apperr.FromDB(err, "Error checking a referenced record")
+		}
+		return found, nil
+	}
+}
+
+// lookup returns a function reading the rows of repo by ID, nil without error when the row does not exist.
+func lookup[T any](ctx context.Context, repo repository.Repository[T]) func(id uint) (*T, error) {
+	return func(id uint) (*T, error) {
+		row, err := repo.Get(ctx, id, repository.Query{})
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, nil
+		case err != nil:
+			return nil, apperr.FromDB(err, "Error checking a referenced record")
+		}
+		return row, nil
+	}
+}
@@ -0,0 +1,664 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/imaging"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/storage"
+	"E-Commerce_Website_Database/internal/validation"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"image"
+	"image/png"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBrands_Create checks that a valid brand is stored with a generated ID, and that invalid fields
+// are all reported together as a validation error without storing anything.
+func TestBrands_Create(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	assert.NotZero(t, brand.ID)
+	stored, err := s.Brands.Get(ctx, brand.ID, repository.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Acme", stored.Name)
+
+	_, err = s.Brands.Create(ctx, models.Brands{})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, "name", fieldErrs[0].Field)
+	}
+	brands, err := s.Brands.List(ctx, repository.Query{})
+	assert.NoError(t, err)
+	assert.Len(t, brands, 1)
+}
+
+// TestProducts_References checks that a product must reference a brand and a category that exist and are not deleted.
+func TestProducts_References(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	category, err := s.Categories.Create(ctx, models.Category{Name: "Laptops", Description: "Portable computers"})
+	assert.NoError(t, err)
+
+	product, err := s.Products.Create(ctx, models.Product{Name: "Laptop", Description: "A laptop", Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Brands.Delete(ctx, brand.ID, 0))
+	product.Brand_ID = brand.ID
+	err = s.Products.Patch(ctx, product, []string{"brand_id"})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Len(t, fieldErrs, 1)
+		assert.Equal(t, "brand_id", fieldErrs[0].Field)
+	}
+}
+
+// TestRecords_Save checks that saving a row changed since it was read is a precondition failure,
+// and that an empty patch saves nothing.
+func TestRecords_Save(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	stale := *brand
+
+	assert.NoError(t, s.Brands.Update(ctx, brand, models.Brands{Name: "Acme Corp", Description: "Gadgets"}))
+	assert.Equal(t, uint(2), brand.Version)
+	err = s.Brands.Update(ctx, &stale, models.Brands{Name: "Stale", Description: "Gadgets"})
+	assert.Equal(t, apperr.KindPreconditionFailed, apperr.KindOf(err))
+
+	assert.NoError(t, s.Brands.Patch(ctx, brand, nil))
+	version, err := s.Brands.Version(ctx, brand.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), version)
+}
+
+// TestRecords_Trash checks the errors of reading, deleting and restoring rows that are missing or not in the trash.
+func TestRecords_Trash(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	order, err := s.Orders.Get(ctx, 999, repository.Query{})
+	assert.Nil(t, order)
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(err))
+	assert.Equal(t, "Order not found", err.(*apperr.Error).Message)
+
+	err = s.Orders.Delete(ctx, 999, 0)
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(err))
+	_, err = s.Orders.Restore(ctx, 999)
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(err))
+	assert.Equal(t, "Order not found in trash", err.(*apperr.Error).Message)
+}
+
+// TestUsers checks that users are created with the regular role and a hashed password, that a patched password
+// is hashed too, and that only the right password authenticates them.
+func TestUsers(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	user, err := s.Users.Create(ctx, models.User{Username: "alice", Password: "Password123", Email: "alice@example.com",
+		First_Name: "Alice", Last_Name: "Smith", Address: "1 Main St", Role: "admin"})
+	assert.NoError(t, err)
+	assert.Equal(t, "regular", user.Role)
+	assert.NotEqual(t, "Password123", user.Password)
+
+	authenticated, err := s.Users.Authenticate(ctx, "alice", "Password123")
+	assert.NoError(t, err)
+	assert.Equal(t, user.ID, authenticated.ID)
+	_, err = s.Users.Authenticate(ctx, "alice", "wrong")
+	assert.Equal(t, apperr.KindUnauthorized, apperr.KindOf(err))
+
+	user.Password = "weak"
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(s.Users.Patch(ctx, user, []string{"password"})))
+	user.Password = "NewPassword456"
+	assert.NoError(t, s.Users.Patch(ctx, user, []string{"password"}))
+	_, err = s.Users.Authenticate(ctx, "alice", "NewPassword456")
+	assert.NoError(t, err)
+}
+
+// TestOrders_Dates checks that an omitted order date is set to the current time on create and kept on update,
+// and that a given date is stored in UTC.
+func TestOrders_Dates(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	user, err := s.Users.Create(ctx, models.User{Username: "alice", Password: "Password123", Email: "alice@example.com",
+		First_Name: "Alice", Last_Name: "Smith", Address: "1 Main St"})
+	assert.NoError(t, err)
+
+	before := time.Now()
+	order, err := s.Orders.Create(ctx, models.Order{User_ID: user.ID, Status: "pending"})
+	assert.NoError(t, err)
+	assert.WithinRange(t, order.Order_date, before, time.Now())
+	assert.Equal(t, time.UTC, order.Order_date.Location())
+
+	placed := time.Date(2024, 1, 15, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	assert.NoError(t, s.Orders.Update(ctx, order, models.Order{User_ID: user.ID, Order_date: placed, Status: "pending"}))
+	assert.Equal(t, time.Date(2024, 1, 15, 8, 30, 0, 0, time.UTC), order.Order_date)
+	assert.NoError(t, s.Orders.Update(ctx, order, models.Order{User_ID: user.ID, Status: "completed"}))
+	assert.True(t, placed.Equal(order.Order_date), "an omitted order date should keep its value")
+
+	_, err = s.Orders.Create(ctx, models.Order{User_ID: user.ID, Order_date: time.Now().AddDate(0, 0, 1), Status: "pending"})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+}
+
+// TestShippingDetails_Dates checks that an estimated arrival before the shipping date is reported on both dates,
+// or only on the date a patch changes.
+func TestShippingDetails_Dates(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	user, err := s.Users.Create(ctx, models.User{Username: "alice", Password: "Password123", Email: "alice@example.com",
+		First_Name: "Alice", Last_Name: "Smith", Address: "1 Main St"})
+	assert.NoError(t, err)
+	order, err := s.Orders.Create(ctx, models.Order{User_ID: user.ID, Status: "shipped"})
+	assert.NoError(t, err)
+	shipped := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	_, err = s.ShippingDetails.Create(ctx, models.ShippingDetails{Order_ID: order.ID, Address: "1 Main St",
+		Shipping_Date: shipped, Estimated_Arrival: shipped.AddDate(0, 0, -1), Status: "shipped"})
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, []string{"estimated_arrival", "shipping_date"}, fields(fieldErrs))
+	}
+
+	detail, err := s.ShippingDetails.Create(ctx, models.ShippingDetails{Order_ID: order.ID, Address: "1 Main St",
+		Shipping_Date: shipped, Estimated_Arrival: shipped.AddDate(0, 0, 4), Status: "shipped"})
+	assert.NoError(t, err)
+	detail.Estimated_Arrival = shipped.AddDate(0, 0, -1)
+	err = s.ShippingDetails.Patch(ctx, detail, []string{"estimated_arrival"})
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, []string{"estimated_arrival"}, fields(fieldErrs))
+	}
+}
+
+// TestCategories_Tree checks that moving a category moves its subtree, that a category cannot be moved below itself
+// or one of its descendants, and that the breadcrumbs and products of a category follow the tree.
+func TestCategories_Tree(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	create := func(name string, parentID *uint) *models.Category {
+		category, err := s.Categories.Create(ctx, models.Category{Name: name, Description: "All kinds of " + name, Parent_ID: parentID})
+		assert.NoError(t, err)
+		return category
+	}
+	computers := create("Computers", nil)
+	laptops := create("Laptops", &computers.ID)
+	gaming := create("Gaming laptops", &laptops.ID)
+	portable := create("Portable", nil)
+	assert.Equal(t, models.CategoryPath(laptops, gaming.ID), gaming.Path)
+
+	err := s.Categories.Move(ctx, laptops, &gaming.ID)
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, "parent_id", fieldErrs[0].Field)
+		assert.Equal(t, validation.CodeCycle, fieldErrs[0].Code)
+	}
+	missing := uint(999)
+	_, err = s.Categories.Create(ctx, models.Category{Name: "Orphans", Description: "Nowhere", Parent_ID: &missing})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+
+	laptops, err = s.Categories.Get(ctx, laptops.ID, repository.Query{})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Categories.Move(ctx, laptops, &portable.ID))
+	moved, err := s.Categories.Get(ctx, gaming.ID, repository.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, models.CategoryPath(laptops, gaming.ID), moved.Path)
+
+	breadcrumbs, err := s.Categories.Breadcrumbs(ctx, gaming.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, breadcrumbs, 3) {
+		assert.Equal(t, []uint{portable.ID, laptops.ID, gaming.ID}, []uint{breadcrumbs[0].ID, breadcrumbs[1].ID, breadcrumbs[2].ID})
+	}
+	trees, err := s.Categories.Tree(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, trees, 2) {
+		assert.Empty(t, trees[0].Children)
+		assert.Equal(t, laptops.ID, trees[1].Children[0].ID)
+	}
+
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	product, err := s.Products.Create(ctx, models.Product{Name: "Laptop", Description: "A laptop", Brand_ID: brand.ID, Category_ID: gaming.ID})
+	assert.NoError(t, err)
+	products, err := s.Categories.Products(ctx, portable.ID, nil, repository.Query{})
+	assert.NoError(t, err)
+	if assert.Len(t, products, 1) {
+		assert.Equal(t, product.ID, products[0].ID)
+	}
+	products, err = s.Categories.Products(ctx, computers.ID, nil, repository.Query{})
+	assert.NoError(t, err)
+	assert.Empty(t, products)
+}
+
+// TestProductVariants checks that the variants of a product have the same option names and distinct combinations
+// of values, and that an item of a product with variants must select one of them.
+func TestProductVariants(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	category, err := s.Categories.Create(ctx, models.Category{Name: "Phones", Description: "Mobile phones"})
+	assert.NoError(t, err)
+	phone, err := s.Products.Create(ctx, models.Product{Name: "Phone", Description: "A phone", Price: 999, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+	charger, err := s.Products.Create(ctx, models.Product{Name: "Charger", Description: "A charger", Price: 29, Stock_quantity: 10, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+
+	black, err := s.ProductVariants.Create(ctx, models.ProductVariant{Product_ID: phone.ID, SKU: "PHN-128-BLK",
+		Options: map[string]string{"color": "black", "storage": "128GB"}, Stock_quantity: 5})
+	assert.NoError(t, err)
+	_, err = s.ProductVariants.Create(ctx, models.ProductVariant{Product_ID: phone.ID, SKU: "PHN-256-BLK",
+		Options: map[string]string{"color": "black", "storage": "256GB"}})
+	assert.NoError(t, err)
+	for code, options := range map[string]map[string]string{
+		validation.CodeDuplicate: {"color": "black", "storage": "128GB"},
+		validation.CodeInvalid:   {"color": "white"},
+	} {
+		_, err = s.ProductVariants.Create(ctx, models.ProductVariant{Product_ID: phone.ID, SKU: "PHN-OTHER", Options: options})
+		var fieldErrs validation.Errors
+		if assert.True(t, errors.As(err, &fieldErrs), code) {
+			assert.Equal(t, []string{"options"}, fields(fieldErrs))
+			assert.Equal(t, code, fieldErrs[0].Code)
+		}
+	}
+	assert.NoError(t, s.ProductVariants.Patch(ctx, black, []string{"stock_quantity"}))
+
+	user, err := s.Users.Create(ctx, models.User{Username: "alice", Password: "Password123", Email: "alice@example.com",
+		First_Name: "Alice", Last_Name: "Smith", Address: "1 Main St"})
+	assert.NoError(t, err)
+	order, err := s.Orders.Create(ctx, models.Order{User_ID: user.ID, Status: "pending"})
+	assert.NoError(t, err)
+	item, err := s.OrderItems.Create(ctx, models.OrderItem{Order_ID: order.ID, Product_ID: phone.ID, Variant_ID: &black.ID, Quantity: 1})
+	assert.NoError(t, err)
+	_, err = s.OrderItems.Create(ctx, models.OrderItem{Order_ID: order.ID, Product_ID: charger.ID, Quantity: 1})
+	assert.NoError(t, err)
+
+	for name, input := range map[string]models.OrderItem{
+		"no variant":      {Order_ID: order.ID, Product_ID: phone.ID, Quantity: 1},
+		"another product": {Order_ID: order.ID, Product_ID: charger.ID, Variant_ID: &black.ID, Quantity: 1},
+	} {
+		_, err = s.OrderItems.Create(ctx, input)
+		var fieldErrs validation.Errors
+		if assert.True(t, errors.As(err, &fieldErrs), name) {
+			assert.Equal(t, []string{"variant_id"}, fields(fieldErrs), name)
+		}
+	}
+
+	item.Product_ID = charger.ID
+	err = s.OrderItems.Patch(ctx, item, []string{"product_id"})
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, []string{"variant_id"}, fields(fieldErrs))
+	}
+}
+
+// TestOrderItems_Pricing checks that order items are priced from their product or variant whatever subtotal is sent,
+// that ordering more than in stock is a validation error of the quantity, and that the total amount of an order
+// follows its items as they are created, changed, deleted and restored.
+func TestOrderItems_Pricing(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	category, err := s.Categories.Create(ctx, models.Category{Name: "Phones", Description: "Mobile phones"})
+	assert.NoError(t, err)
+	phone, err := s.Products.Create(ctx, models.Product{Name: "Phone", Description: "A phone", Price: 999, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+	cable, err := s.Products.Create(ctx, models.Product{Name: "Cable", Description: "A cable", Price: 9.99, Stock_quantity: 3, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+	price := 1099.5
+	large, err := s.ProductVariants.Create(ctx, models.ProductVariant{Product_ID: phone.ID, SKU: "PHN-256", Options: map[string]string{"storage": "256GB"}, Price: &price, Stock_quantity: 2})
+	assert.NoError(t, err)
+	user, err := s.Users.Create(ctx, models.User{Username: "alice", Password: "Password123", Email: "alice@example.com",
+		First_Name: "Alice", Last_Name: "Smith", Address: "1 Main St"})
+	assert.NoError(t, err)
+	order, err := s.Orders.Create(ctx, models.Order{User_ID: user.ID, Status: "pending", Total_amount: 5000})
+	assert.NoError(t, err)
+	assert.Zero(t, order.Total_amount, "a new order has no items")
+	total := func() float64 {
+		stored, err := s.Orders.Get(ctx, order.ID, repository.Query{})
+		assert.NoError(t, err)
+		return stored.Total_amount
+	}
+
+	phones, err := s.OrderItems.Create(ctx, models.OrderItem{Order_ID: order.ID, Product_ID: phone.ID, Variant_ID: &large.ID, Quantity: 2, Subtotal: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 2199.0, phones.Subtotal, "the variant price times the quantity")
+	cables, err := s.OrderItems.Create(ctx, models.OrderItem{Order_ID: order.ID, Product_ID: cable.ID, Quantity: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, 29.97, cables.Subtotal, "the product price times the quantity, rounded to the cent")
+	assert.Equal(t, 2228.97, total())
+
+	for name, input := range map[string]models.OrderItem{
+		"product": {Order_ID: order.ID, Product_ID: cable.ID, Quantity: 4},
+		"variant": {Order_ID: order.ID, Product_ID: phone.ID, Variant_ID: &large.ID, Quantity: 3},
+	} {
+		_, err = s.OrderItems.Create(ctx, input)
+		var fieldErrs validation.Errors
+		if assert.True(t, errors.As(err, &fieldErrs), name) {
+			assert.Equal(t, []string{"quantity"}, fields(fieldErrs), name)
+			assert.Equal(t, validation.CodeOutOfRange, fieldErrs[0].Code, name)
+		}
+	}
+	assert.Equal(t, 2228.97, total(), "a rejected item leaves the total unchanged")
+
+	cables.Quantity = 1
+	assert.NoError(t, s.OrderItems.Patch(ctx, cables, []string{"quantity"}))
+	assert.Equal(t, 9.99, cables.Subtotal)
+	assert.Equal(t, 2208.99, total())
+	assert.NoError(t, s.OrderItems.Update(ctx, phones, models.OrderItem{Order_ID: order.ID, Product_ID: phone.ID, Variant_ID: &large.ID, Quantity: 1, Subtotal: 1}))
+	assert.Equal(t, 1099.5, phones.Subtotal)
+	assert.Equal(t, 1109.49, total())
+
+	assert.NoError(t, s.OrderItems.Delete(ctx, phones.ID, 0))
+	assert.Equal(t, 9.99, total())
+	_, err = s.OrderItems.Restore(ctx, phones.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1109.49, total())
+}
+
+// TestOrderItems_Transaction checks, against a database, that an order item is not written when the total amount of
+// its order cannot be updated, so that a retry does not add the item twice.
+func TestOrderItems_Transaction(t *testing.T) {
+	ctx := context.Background()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "service.db")), &gorm.Config{TranslateError: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.AutoMigrate(&models.Brands{}, &models.Category{}, &models.Product{}, &models.ProductVariant{}, &models.User{},
+		&models.Order{}, &models.OrderItem{}); err != nil {
+		t.Fatalf("Failed to migrate database: %v", err)
+	}
+	s := New(repository.NewGORM(db), storage.NewMemory(), imaging.DefaultLimits)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	category, err := s.Categories.Create(ctx, models.Category{Name: "Cables", Description: "Cables"})
+	assert.NoError(t, err)
+	cable, err := s.Products.Create(ctx, models.Product{Name: "Cable", Description: "A cable", Price: 10, Stock_quantity: 5, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+	user, err := s.Users.Create(ctx, models.User{Username: "alice", Password: "Password123", Email: "alice@example.com",
+		First_Name: "Alice", Last_Name: "Smith", Address: "1 Main St"})
+	assert.NoError(t, err)
+	order, err := s.Orders.Create(ctx, models.Order{User_ID: user.ID, Status: "pending"})
+	assert.NoError(t, err)
+
+	failure := errors.New("orders are locked")
+	assert.NoError(t, db.Callback().Update().Before("gorm:update").Register("fail_orders", func(tx *gorm.DB) {
+		if tx.Statement.Table == "orders" {
+			tx.AddError(failure)
+		}
+	}))
+	_, err = s.OrderItems.Create(ctx, models.OrderItem{Order_ID: order.ID, Product_ID: cable.ID, Quantity: 2})
+	assert.ErrorIs(t, err, failure)
+	items, err := s.OrderItems.List(ctx, repository.Query{})
+	assert.NoError(t, err)
+	assert.Empty(t, items, "the item is rolled back with the total")
+
+	assert.NoError(t, db.Callback().Update().Remove("fail_orders"))
+	item, err := s.OrderItems.Create(ctx, models.OrderItem{Order_ID: order.ID, Product_ID: cable.ID, Quantity: 2})
+	assert.NoError(t, err)
+	stored, err := s.Orders.Get(ctx, order.ID, repository.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, 20.0, stored.Total_amount)
+
+	assert.NoError(t, db.Callback().Update().Before("gorm:update").Register("fail_orders", func(tx *gorm.DB) {
+		if tx.Statement.Table == "orders" {
+			tx.AddError(failure)
+		}
+	}))
+	assert.ErrorIs(t, s.OrderItems.Delete(ctx, item.ID, 0), failure)
+	_, err = s.OrderItems.Get(ctx, item.ID, repository.Query{})
+	assert.NoError(t, err, "the deletion is rolled back with the total")
+}
+
+// TestAttributes checks that attribute names differ along a branch of the category tree, that attributes sharing
+// a name have the same type, and that the type of an attribute products have values of is kept.
+func TestAttributes(t *testing.T) {
+	ctx := context.Background()
+	s := New(repository.NewMemory(), storage.NewMemory(), imaging.DefaultLimits)
+	computers, err := s.Categories.Create(ctx, models.Category{Name: "Computers", Description: "All kinds of computers"})
+	assert.NoError(t, err)
+	laptops, err := s.Categories.Create(ctx, models.Category{Name: "Laptops", Description: "Portable computers", Parent_ID: &computers.ID})
+	assert.NoError(t, err)
+	phones, err := s.Categories.Create(ctx, models.Category{Name: "Phones", Description: "Mobile phones"})
+	assert.NoError(t, err)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+
+	ram, err := s.Attributes.Create(ctx, models.Attribute{Category_ID: computers.ID, Name: "ram_gb", Label: "RAM", Type: models.AttributeNumber, Unit: "GB"})
+	assert.NoError(t, err)
+	_, err = s.Attributes.Create(ctx, models.Attribute{Category_ID: phones.ID, Name: "ram_gb", Label: "RAM", Type: models.AttributeNumber, Unit: "GB"})
+	assert.NoError(t, err, "a name may be reused on another branch")
+	for field, input := range map[string]models.Attribute{
+		"name": {Category_ID: laptops.ID, Name: "ram_gb", Label: "Memory", Type: models.AttributeNumber},
+		"type": {Category_ID: phones.ID, Name: "ram_gb", Label: "Memory", Type: models.AttributeText},
+	} {
+		_, err = s.Attributes.Create(ctx, input)
+		var fieldErrs validation.Errors
+		if assert.True(t, errors.As(err, &fieldErrs), field) {
+			assert.Contains(t, fields(fieldErrs), field)
+		}
+	}
+	wifi, err := s.Attributes.Create(ctx, models.Attribute{Category_ID: laptops.ID, Name: "wifi", Label: "Wi-Fi", Type: models.AttributeBoolean})
+	assert.NoError(t, err)
+
+	laptop, err := s.Products.Create(ctx, models.Product{Name: "Laptop", Description: "A laptop", Price: 1299, Brand_ID: brand.ID, Category_ID: laptops.ID})
+	assert.NoError(t, err)
+	_, err = s.Products.SetAttributes(ctx, laptop, map[string]interface{}{"ram_gb": "16", "screen_in": 14.0})
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, []string{"ram_gb", "screen_in"}, fields(fieldErrs))
+	}
+	values, err := s.Products.SetAttributes(ctx, laptop, map[string]interface{}{"ram_gb": 16.0, "wifi": true})
+	assert.NoError(t, err)
+	assert.Len(t, values, 2)
+
+	ram.Type = models.AttributeText
+	err = s.Attributes.Patch(ctx, ram, []string{"type"})
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, []string{"type"}, fields(fieldErrs))
+		assert.Equal(t, validation.CodeNotAllowed, fieldErrs[0].Code)
+	}
+	wifi.Label = "Wireless"
+	assert.NoError(t, s.Attributes.Patch(ctx, wifi, []string{"label"}))
+
+	products, err := s.Products.Filter(ctx, map[string][]string{"ram_gb[gte]": {"16"}}, repository.Query{})
+	assert.NoError(t, err)
+	assert.Len(t, products, 1)
+	products, err = s.Categories.Products(ctx, computers.ID, map[string][]string{"wifi": {"false"}}, repository.Query{})
+	assert.NoError(t, err)
+	assert.Empty(t, products)
+	_, err = s.Products.Filter(ctx, map[string][]string{"weight_kg[lt]": {"2"}}, repository.Query{})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err))
+	products, err = s.Products.Filter(ctx, map[string][]string{"weight_kg": {"2"}, "page": {"2"}}, repository.Query{})
+	assert.NoError(t, err, "keys naming no attribute and without an operator should be ignored")
+	assert.Len(t, products, 1)
+
+	facets, err := s.Categories.Facets(ctx, computers.ID, map[string][]string{"ram_gb": {"32"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Facet{
+		{Name: "ram_gb", Label: "RAM", Type: models.AttributeNumber, Unit: "GB", Values: []models.FacetValue{{Value: 16.0, Count: 1}}},
+	}, facets, "a facet is counted without its own filters, the others applying")
+}
+
+// TestProductImages checks that uploads are sniffed and stored with a thumbnail, that the first image of a product
+// is primary, and that the primary image stays unique through patches, deletions and restorations.
+func TestProductImages(t *testing.T) {
+	ctx := context.Background()
+	files := storage.NewMemory()
+	s := New(repository.NewMemory(), files, imaging.Limits{MaxBytes: 1 << 20, MaxPixels: 1_000_000, ThumbnailSize: 16})
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+	category, err := s.Categories.Create(ctx, models.Category{Name: "Phones", Description: "Mobile phones"})
+	assert.NoError(t, err)
+	phone, err := s.Products.Create(ctx, models.Product{Name: "Phone", Description: "A phone", Price: 999, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+
+	front, err := s.ProductImages.Upload(ctx, phone.ID, bytes.NewReader(pngImage(t, 64, 32)), models.ProductImage{Alt: "Front"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, front.Primary, "the first image is primary")
+	assert.Equal(t, []int{64, 32}, []int{front.Width, front.Height})
+	assert.Equal(t, "image/png", front.Content_Type)
+	thumbnail, err := files.Open(ctx, front.Thumbnail)
+	if assert.NoError(t, err) {
+		config, _, err := image.DecodeConfig(thumbnail)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{16, 8}, []int{config.Width, config.Height})
+	}
+	back, err := s.ProductImages.Upload(ctx, phone.ID, bytes.NewReader(pngImage(t, 8, 8)), models.ProductImage{Primary: true})
+	assert.NoError(t, err)
+	side, err := s.ProductImages.Upload(ctx, phone.ID, bytes.NewReader(pngImage(t, 8, 8)), models.ProductImage{})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, side.Position)
+	primaries := func() []uint {
+		var ids []uint
+		images, err := s.ProductImages.OfProduct(ctx, phone.ID)
+		assert.NoError(t, err)
+		for _, image := range images {
+			if image.Primary {
+				ids = append(ids, image.ID)
+			}
+		}
+		return ids
+	}
+	assert.Equal(t, []uint{back.ID}, primaries())
+
+	for kind, data := range map[apperr.Kind][]byte{
+		apperr.KindUnsupportedMediaType: []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`),
+		apperr.KindTooLarge:             pngImage(t, 1001, 1000),
+	} {
+		_, err = s.ProductImages.Upload(ctx, phone.ID, bytes.NewReader(data), models.ProductImage{})
+		assert.Equal(t, kind, apperr.KindOf(err))
+	}
+	_, err = s.ProductImages.Upload(ctx, 12345, bytes.NewReader(pngImage(t, 8, 8)), models.ProductImage{})
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(err))
+
+	side.Primary = true
+	assert.NoError(t, s.ProductImages.Patch(ctx, side, []string{"primary"}))
+	assert.Equal(t, []uint{side.ID}, primaries())
+	side.Primary = false
+	err = s.ProductImages.Patch(ctx, side, []string{"primary"})
+	var fieldErrs validation.Errors
+	if assert.True(t, errors.As(err, &fieldErrs)) {
+		assert.Equal(t, []string{"primary"}, fields(fieldErrs))
+	}
+
+	_, err = s.ProductImages.Reorder(ctx, phone.ID, []uint{side.ID, front.ID})
+	assert.Equal(t, apperr.KindValidation, apperr.KindOf(err), "every image must be listed")
+	images, err := s.ProductImages.Reorder(ctx, phone.ID, []uint{side.ID, front.ID, back.ID})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []uint{side.ID, front.ID, back.ID}, []uint{images[0].ID, images[1].ID, images[2].ID})
+	}
+
+	assert.NoError(t, s.ProductImages.Delete(ctx, phone.ID, side.ID, 0))
+	assert.Equal(t, []uint{front.ID}, primaries(), "the first remaining image becomes primary")
+	restored, err := s.ProductImages.Restore(ctx, phone.ID, side.ID)
+	assert.NoError(t, err)
+	assert.False(t, restored.Primary, "another image is primary")
+	assert.Equal(t, []uint{front.ID}, primaries())
+	assert.Equal(t, apperr.KindNotFound, apperr.KindOf(s.ProductImages.Delete(ctx, brand.ID, front.ID, 0)), "the image is of another product")
+
+	stored, err := files.List(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, stored, 6)
+	swept, err := s.Media.Sweep(ctx, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Zero(t, swept, "every file is referenced")
+
+	tablet, err := s.Products.Create(ctx, models.Product{Name: "Tablet", Description: "A tablet", Price: 499, Brand_ID: brand.ID, Category_ID: category.ID})
+	assert.NoError(t, err)
+	data := pngImage(t, 8, 8)
+	var wg sync.WaitGroup
+	concurrent := make([]*models.ProductImage, 4)
+	for i := range concurrent {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			concurrent[i], _ = s.ProductImages.Upload(ctx, tablet.ID, bytes.NewReader(data), models.ProductImage{})
+		}(i)
+	}
+	wg.Wait()
+	positions, primary := map[int]bool{}, 0
+	for _, image := range concurrent {
+		if assert.NotNil(t, image) {
+			positions[image.Position] = true
+			if image.Primary {
+				primary++
+			}
+		}
+	}
+	assert.Len(t, positions, len(concurrent))
+	assert.Equal(t, 1, primary)
+}
+
+// TestBrands_Logo checks that a new logo replaces the files of the previous one, and that removing it deletes them.
+func TestBrands_Logo(t *testing.T) {
+	ctx := context.Background()
+	files := storage.NewMemory()
+	s := New(repository.NewMemory(), files, imaging.DefaultLimits)
+	brand, err := s.Brands.Create(ctx, models.Brands{Name: "Acme", Description: "Gadgets"})
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Brands.SetLogo(ctx, brand, bytes.NewReader(pngImage(t, 40, 20))))
+	first := *brand.Logo
+	assert.NoError(t, s.Brands.SetLogo(ctx, brand, bytes.NewReader(pngImage(t, 20, 20))))
+	assert.NotEqual(t, first.File, brand.Logo.File)
+	_, err = files.Open(ctx, first.File)
+	assert.ErrorIs(t, err, storage.ErrNotFound, "the files of the previous logo are deleted")
+	stored, err := s.Brands.Get(ctx, brand.ID, repository.Query{})
+	assert.NoError(t, err)
+	assert.Equal(t, brand.Logo, stored.Logo)
+	assert.Equal(t, uint(3), stored.Version)
+
+	current := *brand.Logo
+	assert.NoError(t, s.Brands.RemoveLogo(ctx, brand))
+	assert.Nil(t, brand.Logo)
+	listed, err := files.List(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, listed)
+
+	// Files left behind, e.g. by a failed deletion, are swept once no logo references them, while files the
+	// application did not store are kept.
+	assert.NoError(t, files.Put(ctx, current.File, bytes.NewReader([]byte("orphan")), "image/png"))
+	for _, key := range []string{"backup.tar", "brandsheet.pdf", "exports/products/1.csv"} {
+		assert.NoError(t, files.Put(ctx, key, bytes.NewReader([]byte("foreign")), "application/octet-stream"))
+	}
+	swept, err := s.Media.Sweep(ctx, time.Now().Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, swept)
+	listed, err = files.List(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, listed, 3, "files outside the directories of images are not swept")
+}
+
+// pngImage returns a width by height black PNG image.
+func pngImage(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("failed to encode image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// fields returns the names of the fields of errs in order.
+func fields(errs validation.Errors) []string {
+	var names []string
+	for _, err := range errs {
+		names = append(names, err.Field)
+	}
+	return names
+}
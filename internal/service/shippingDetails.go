@@ -0,0 +1,78 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"gorm.io/gorm"
+)
+
+// ShippingDetails applies the rules of shipping details, which reference the order shipped.
+type ShippingDetails struct {
+	records[models.ShippingDetails]
+	orders repository.OrderRepository
+}
+
+// Create validates input and inserts it as a new shipping detail with a generated ID.
+// An omitted shipping date is set to the current time.
+func (s *ShippingDetails) Create(ctx context.Context, input models.ShippingDetails) (*models.ShippingDetails, error) {
+	input.Shipping_Date = dateOr(input.Shipping_Date, now())
+	input.Estimated_Arrival = input.Estimated_Arrival.UTC()
+	shippingDetail := models.ShippingDetails{
+		Order_ID:          input.Order_ID,
+		Address:           input.Address,
+		Shipping_Date:     input.Shipping_Date,
+		Estimated_Arrival: input.Estimated_Arrival,
+		Status:            input.Status,
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &shippingDetail, s.check(ctx, shippingDetail, input)); err != nil {
+		return nil, err
+	}
+	return &shippingDetail, nil
+}
+
+// Update replaces the fields of shippingDetail with those of input once validated, and saves it.
+// An omitted shipping date keeps its value.
+func (s *ShippingDetails) Update(ctx context.Context, shippingDetail *models.ShippingDetails, input models.ShippingDetails) error {
+	input.Shipping_Date = dateOr(input.Shipping_Date, shippingDetail.Shipping_Date)
+	input.Estimated_Arrival = input.Estimated_Arrival.UTC()
+	shippingDetail.Order_ID = input.Order_ID
+	shippingDetail.Address = input.Address
+	shippingDetail.Shipping_Date = input.Shipping_Date
+	shippingDetail.Estimated_Arrival = input.Estimated_Arrival
+	shippingDetail.Status = input.Status
+	return s.save(ctx, shippingDetail, s.check(ctx, *shippingDetail, input))
+}
+
+// Patch validates and saves the fields of shippingDetail changed by a merge patch. Without fields nothing is saved.
+func (s *ShippingDetails) Patch(ctx context.Context, shippingDetail *models.ShippingDetails, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	shippingDetail.Shipping_Date = shippingDetail.Shipping_Date.UTC()
+	shippingDetail.Estimated_Arrival = shippingDetail.Estimated_Arrival.UTC()
+	return s.save(ctx, shippingDetail, s.check(ctx, *shippingDetail, *shippingDetail, fields...), fields...)
+}
+
+// check validates the input data for a shipping detail, including that its order exists and that the estimated
+// arrival is not before the shipping date, which is reported on both dates or on the one a PATCH changes.
+// The errors of all invalid fields are returned together as validation.Errors.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *ShippingDetails) check(ctx context.Context, shippingDetail models.ShippingDetails, newShippingDetail models.ShippingDetails, only ...string) error {
+	v := validation.New(only...)
+	v.Check("order_id", shippingDetail.SetOrderID(newShippingDetail.Order_ID, exists[models.Order](ctx, s.orders)))
+	v.Check("address", shippingDetail.SetAddress(newShippingDetail.Address))
+	v.Check("shipping_date", shippingDetail.SetShippingDate(newShippingDetail.Shipping_Date))
+	v.Check("estimated_arrival", shippingDetail.SetEstimatedArrival(newShippingDetail.Estimated_Arrival))
+	v.Check("status", shippingDetail.SetStatus(newShippingDetail.Status))
+	if !shippingDetail.Shipping_Date.IsZero() && !shippingDetail.Estimated_Arrival.IsZero() {
+		v.Check("estimated_arrival", validation.NotBefore(shippingDetail.Estimated_Arrival, shippingDetail.Shipping_Date, "the shipping date"))
+		v.Check("shipping_date", validation.NotAfter(shippingDetail.Shipping_Date, shippingDetail.Estimated_Arrival, "the estimated arrival"))
+	}
+	return v.Err()
+}
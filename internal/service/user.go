@@ -0,0 +1,136 @@
+package service
+
+import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"E-Commerce_Website_Database/internal/models"
+	"E-Commerce_Website_Database/internal/repository"
+	"E-Commerce_Website_Database/internal/tools"
+	"E-Commerce_Website_Database/internal/validation"
+	"context"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"slices"
+)
+
+// Users applies the rules of users: passwords are stored hashed with bcrypt, and new users get the regular role.
+type Users struct {
+	records[models.User]
+	users repository.UserRepository
+}
+
+// Create validates input and inserts it as a new regular user with a generated ID and the hash of its password.
+func (s *Users) Create(ctx context.Context, input models.User) (*models.User, error) {
+	hashedPassword, err := hashPassword(input.Password)
+	if err != nil {
+		return nil, err
+	}
+	user := models.User{
+		Username:   input.Username,
+		Password:   hashedPassword,
+		Email:      input.Email,
+		First_Name: input.First_Name,
+		Last_Name:  input.Last_Name,
+		Address:    input.Address,
+		Mobile:     input.Mobile,
+		Role:       "regular",
+		Model: gorm.Model{
+			ID: tools.GenerateID(),
+		},
+	}
+	if err := s.create(ctx, &user, s.check(user, input, true)); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update replaces the fields of user with those of input once validated, and saves it.
+// The password is optional: it is only validated and replaced when input has one.
+func (s *Users) Update(ctx context.Context, user *models.User, input models.User) error {
+	if err := s.check(*user, input, input.Password != ""); err != nil {
+		return apperr.Validation(err)
+	}
+	if input.Password != "" {
+		hashedPassword, err := hashPassword(input.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+	}
+	user.Username = input.Username
+	user.Email = input.Email
+	user.First_Name = input.First_Name
+	user.Last_Name = input.Last_Name
+	user.Address = input.Address
+	user.Mobile = input.Mobile
+	return s.save(ctx, user, nil)
+}
+
+// Patch validates and saves the fields of user changed by a merge patch, hashing a new password.
+// Without fields nothing is saved.
+func (s *Users) Patch(ctx context.Context, user *models.User, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	patchesPassword := slices.Contains(fields, "password")
+	if err := s.check(*user, *user, patchesPassword, fields...); err != nil {
+		return apperr.Validation(err)
+	}
+	if patchesPassword {
+		hashedPassword, err := hashPassword(user.Password)
+		if err != nil {
+			return err
+		}
+		user.Password = hashedPassword
+	}
+	return s.save(ctx, user, nil, fields...)
+}
+
+// GetByUsername returns the user with the given username, or a not found error.
+func (s *Users) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, apperr.Lookup(err, "User not found")
+	}
+	return user, nil
+}
+
+// Authenticate returns the user with the given username when password matches its hash.
+// A wrong password is answered with an unauthorized error, while a failing lookup,
+// including of an unknown username, is an internal error.
+func (s *Users) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := s.users.GetByUsername(ctx, username)
+	if err != nil {
+		return nil, apperr.Internal("server error", err)
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, apperr.Unauthorized("authentication failed")
+	}
+	return user, nil
+}
+
+// check performs validation checks on user data.
+// The errors of all invalid fields are returned together as validation.Errors, or nil if the data is valid.
+// The password is only checked when checkPassword is set, as it is optional when updating a user.
+// When only lists field names, as for a PATCH, the other fields are not checked.
+func (s *Users) check(user models.User, newUser models.User, checkPassword bool, only ...string) error {
+	v := validation.New(only...)
+	v.Check("first_name", user.SetFirstName(newUser.First_Name))
+	v.Check("last_name", user.SetLastName(newUser.Last_Name))
+	v.Check("username", user.SetUsername(newUser.Username))
+	if checkPassword {
+		v.Check("password", user.SetPassword(newUser.Password))
+	}
+	v.Check("email", user.SetEmail(newUser.Email))
+	v.Check("address", user.SetAddress(newUser.Address))
+	v.Check("mobile", user.SetPhone(newUser.Mobile))
+	return v.Err()
+}
+
+// hashPassword returns the bcrypt hash of password.
+func hashPassword(password string) (string, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", apperr.Internal("Failed to hash password", err)
+	}
+	return string(hashedPassword), nil
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory stores files in a map, for unit tests that should not write to disk.
+type Memory struct {
+	mu    sync.Mutex
+	files map[string]memoryFile
+}
+
+// memoryFile is the content of a file stored in memory.
+type memoryFile struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+// NewMemory returns an empty storage held in memory.
+func NewMemory() *Memory {
+	return &Memory{files: map[string]memoryFile{}}
+}
+
+func (s *Memory) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[key] = memoryFile{data: data, contentType: contentType, modTime: time.Now()}
+	return nil
+}
+
+func (s *Memory) Open(ctx context.Context, key string) (*Object, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, found := s.files[key]
+	if !found {
+		return nil, ErrNotFound
+	}
+	return &Object{
+		ReadSeekCloser: nopCloser{bytes.NewReader(file.data)},
+		Info:           Info{Key: key, Size: int64(len(file.data)), ModTime: file.modTime},
+		ContentType:    file.contentType,
+	}, nil
+}
+
+func (s *Memory) Delete(ctx context.Context, key string) error {
+	if !ValidKey(key) {
+		return ErrInvalidKey
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, key)
+	return nil
+}
+
+func (s *Memory) List(ctx context.Context, prefix string) ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := []Info{}
+	for key, file := range s.files {
+		if strings.HasPrefix(key, prefix) {
+			files = append(files, Info{Key: key, Size: int64(len(file.data)), ModTime: file.modTime})
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Key < files[j].Key })
+	return files, nil
+}
+
+// nopCloser adds a Close method doing nothing to a reader of bytes held in memory.
+type nopCloser struct {
+	io.ReadSeeker
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
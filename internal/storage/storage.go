@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no file is stored under a key.
+	ErrNotFound = errors.New("file not found")
+	// ErrInvalidKey is returned for keys that ValidKey rejects.
+	ErrInvalidKey = errors.New("invalid file key")
+)
+
+// Storage stores files by key, a slash-separated path such as "products/12/34.jpg".
+// Implementations are safe for concurrent use, and a file is either stored completely or not at all,
+// so that readers never see a partial upload. The local filesystem is supported; an S3-compatible object store
+// only has to implement the same methods.
+type Storage interface {
+	// Put stores the content of r under key with the given media type, replacing any file stored there.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Open returns the file stored under key, to be closed by the caller. It returns ErrNotFound if there is none.
+	Open(ctx context.Context, key string) (*Object, error)
+	// Delete removes the file stored under key. Deleting a missing file is not an error.
+	Delete(ctx context.Context, key string) error
+	// List returns the files whose key starts with prefix, ordered by key.
+	List(ctx context.Context, prefix string) ([]Info, error)
+}
+
+// Info describes a stored file.
+type Info struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Object is a stored file opened for reading, with its media type.
+type Object struct {
+	io.ReadSeekCloser
+	Info
+	ContentType string
+}
+
+// ValidKey reports whether key can name a file: segments separated by single slashes, each made of letters, digits,
+// dashes, underscores and dots and not starting with a dot, so that keys never leave the storage nor name hidden files.
+func ValidKey(key string) bool {
+	if key == "" || len(key) > 255 {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || strings.HasPrefix(segment, ".") {
+			return false
+		}
+		for _, char := range segment {
+			letter := char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z'
+			if !letter && (char < '0' || char > '9') && !strings.ContainsRune("-_.", char) {
+				return false
+			}
+		}
+	}
+	return true
+}
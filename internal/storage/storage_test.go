@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStorage checks that both implementations store, list, open and delete files by key, and refuse keys
+// that could leave the storage.
+func TestStorage(t *testing.T) {
+	local, err := NewLocal(filepath.Join(t.TempDir(), "media"))
+	assert.NoError(t, err)
+	for name, files := range map[string]Storage{"local": local, "memory": NewMemory()} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			assert.NoError(t, files.Put(ctx, "products/12/34.png", strings.NewReader("first"), "image/png"))
+			assert.NoError(t, files.Put(ctx, "products/12/34.png", strings.NewReader("picture"), "image/png"))
+			assert.NoError(t, files.Put(ctx, "brands/7/logo.jpg", strings.NewReader("logo"), "image/jpeg"))
+			for _, key := range []string{"../secret", "products//34.png", "/etc/passwd", "products/.hidden", ""} {
+				assert.ErrorIs(t, files.Put(ctx, key, strings.NewReader("x"), "text/plain"), ErrInvalidKey, key)
+			}
+
+			listed, err := files.List(ctx, "products/")
+			assert.NoError(t, err)
+			if assert.Len(t, listed, 1) {
+				assert.Equal(t, "products/12/34.png", listed[0].Key)
+				assert.Equal(t, int64(7), listed[0].Size)
+			}
+			all, err := files.List(ctx, "")
+			assert.NoError(t, err)
+			assert.Len(t, all, 2)
+
+			object, err := files.Open(ctx, "products/12/34.png")
+			if assert.NoError(t, err) {
+				content, err := io.ReadAll(object)
+				assert.NoError(t, err)
+				assert.Equal(t, "picture", string(content))
+				assert.Equal(t, "image/png", object.ContentType)
+				assert.NoError(t, object.Close())
+			}
+
+			assert.NoError(t, files.Delete(ctx, "products/12/34.png"))
+			assert.NoError(t, files.Delete(ctx, "products/12/34.png"), "deleting a missing file is not an error")
+			_, err = files.Open(ctx, "products/12/34.png")
+			assert.ErrorIs(t, err, ErrNotFound)
+			_, err = files.Open(ctx, "../storage_test.go")
+			assert.ErrorIs(t, err, ErrNotFound)
+		})
+	}
+}
+
+// TestLocal_ListSkipsUnfinishedUploads checks that the temporary files of uploads in progress are not listed.
+func TestLocal_ListSkipsUnfinishedUploads(t *testing.T) {
+	root := t.TempDir()
+	files, err := NewLocal(root)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(root, ".upload-123"), []byte("partial"), 0o644))
+	listed, err := files.List(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Empty(t, listed)
+}
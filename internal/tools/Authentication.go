@@ -1,53 +1,90 @@
 package tools
 
 import (
+	"E-Commerce_Website_Database/internal/apperr"
+	"errors"
 	"fmt"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
-	"net/http"
 	"strings"
 	"time"
 )
 
-// mySigningKey is a secret key used for signing JWT tokens
-var mySigningKey = []byte("secret")
+// signingKey is the secret key signing and verifying JWT tokens, the JWT_SECRET of the configuration.
+// Until SetSigningKey is called, no token is generated or accepted.
+var signingKey []byte
+
+// ErrNoSigningKey is returned when a token is generated or parsed before SetSigningKey is called.
+var ErrNoSigningKey = errors.New("no JWT signing key configured")
+
+// SetSigningKey makes tokens signed and verified with key. Tokens signed with a previous key are no longer accepted.
+func SetSigningKey(key string) error {
+	if key == "" {
+		return errors.New("the JWT signing key must not be empty")
+	}
+	signingKey = []byte(key)
+	return nil
+}
 
 // GenerateToken generates a JWT token
 
 // TokenAuthMiddleware is the middleware for JWT authentication
 // It checks the Authorization header for a valid JWT token
-// If the token is valid, it sets the username in the request context and calls the next handler
-// If the token is invalid, it returns a 401 Unauthorized response
+// If the token is valid, it sets the username and role in the request context and calls the next handler
+// If the token is invalid, it aborts with an unauthorized error, answered with 401 by the middleware.Errors middleware
 func TokenAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-
-		// Strip 'Bearer ' prefix if it exists
-		if len(tokenString) > 7 && strings.ToUpper(tokenString[0:7]) == "BEARER " {
-			tokenString = tokenString[7:]
-		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-			}
-			return mySigningKey, nil
-		})
-
+		claims, err := ParseToken(c.GetHeader("Authorization"))
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token", "details": err.Error()})
+			c.Error(apperr.Unauthorized("Invalid token: " + err.Error()))
 			c.Abort()
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			c.Set("username", claims["username"])
-			c.Next()
-		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token", "details": err.Error()})
+		c.Set("username", claims["username"])
+		c.Set("role", claims["role"])
+		c.Next()
+	}
+}
+
+// ParseToken validates a JWT token, with or without the 'Bearer ' prefix of the Authorization header,
+// and returns its claims.
+func ParseToken(tokenString string) (jwt.MapClaims, error) {
+	// Strip 'Bearer ' prefix if it exists
+	if len(tokenString) > 7 && strings.ToUpper(tokenString[0:7]) == "BEARER " {
+		tokenString = tokenString[7:]
+	}
+
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
+		}
+		if len(signingKey) == 0 {
+			return nil, ErrNoSigningKey
+		}
+		return signingKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid token claims")
+	}
+	return claims, nil
+}
+
+// AdminOnly is the middleware restricting a route to administrators.
+// It must run after TokenAuthMiddleware, and aborts with a forbidden error, answered with 403, when the token's role is not admin.
+func AdminOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if role, _ := c.Get("role"); role != "admin" {
+			c.Error(apperr.Forbidden("Admin role required"))
 			c.Abort()
 			return
 		}
+		c.Next()
 	}
 }
 
@@ -64,6 +101,9 @@ type JWTTokenService struct{}
 // It returns the token string and an error if the token generation fails
 // The token is signed using HMAC with SHA-256 and has an expiration time of 72 hours
 func (service *JWTTokenService) GenerateTokenWithClaims(username string, role string) (string, error) {
+	if len(signingKey) == 0 {
+		return "", ErrNoSigningKey
+	}
 	token := jwt.New(jwt.SigningMethodHS256) // Create a new JWT token using HMAC with SHA-256
 	claims := token.Claims.(jwt.MapClaims)   // Use MapClaims for easy map-like syntax with claims
 
@@ -71,6 +111,6 @@ func (service *JWTTokenService) GenerateTokenWithClaims(username string, role st
 	claims["role"] = role
 	claims["exp"] = time.Now().Add(time.Hour * 72).Unix() // Token expiration set to 72 hours from now
 
-	tokenString, err := token.SignedString(mySigningKey) // Sign the token with our secret key
+	tokenString, err := token.SignedString(signingKey) // Sign the token with our secret key
 	return tokenString, err
 }
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"E-Commerce_Website_Database/internal/middleware"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,7 @@ import (
 // It then checks if the token was generated successfully
 // Finally, it checks if the token is valid
 func TestGenerateTokenWithClaims(t *testing.T) {
+	useSigningKey(t, "test-secret")
 	tokenService := JWTTokenService{}
 
 	username := "testuser"
@@ -23,7 +25,7 @@ func TestGenerateTokenWithClaims(t *testing.T) {
 
 	// check the claims
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return mySigningKey, nil
+		return signingKey, nil
 	})
 	assert.Nil(t, err)
 
@@ -41,8 +43,10 @@ func TestGenerateTokenWithClaims(t *testing.T) {
 // Finally, it checks if the response status code is 200
 func TestTokenAuthMiddlewareWithValidToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	useSigningKey(t, "test-secret")
 
 	router := gin.New()
+	router.Use(middleware.Errors())
 	router.Use(TokenAuthMiddleware())
 	router.GET("/protected", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"result": "access granted"})
@@ -69,6 +73,7 @@ func TestTokenAuthMiddlewareWithInValidToken(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	router := gin.New()
+	router.Use(middleware.Errors())
 	router.Use(TokenAuthMiddleware())
 	router.GET("/protected", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"result": "access granted"})
@@ -81,4 +86,59 @@ func TestTokenAuthMiddlewareWithInValidToken(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"unauthorized"`)
+}
+
+// TestAdminOnly tests the AdminOnly middleware behind TokenAuthMiddleware
+// It sends a request with an admin token and one with a regular token to a route restricted to administrators
+// Finally, it checks that only the admin is let through and the regular user gets a 403
+func TestAdminOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useSigningKey(t, "test-secret")
+
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.Use(TokenAuthMiddleware(), AdminOnly())
+	router.GET("/admin", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"result": "access granted"})
+	})
+
+	tokenService := JWTTokenService{}
+	for role, expected := range map[string]int{"admin": http.StatusOK, "regular": http.StatusForbidden} {
+		token, _ := tokenService.GenerateTokenWithClaims("testuser", role)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code, role)
+	}
+}
+
+// TestSigningKey checks that no token is generated or accepted before a key is set,
+// and that tokens signed with another key are refused.
+func TestSigningKey(t *testing.T) {
+	useSigningKey(t, "test-secret")
+	signingKey = nil
+	tokenService := JWTTokenService{}
+	_, err := tokenService.GenerateTokenWithClaims("testuser", "admin")
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+	assert.Error(t, SetSigningKey(""))
+
+	useSigningKey(t, "old-secret")
+	token, err := tokenService.GenerateTokenWithClaims("testuser", "admin")
+	assert.NoError(t, err)
+	_, err = ParseToken(token)
+	assert.NoError(t, err)
+	useSigningKey(t, "new-secret")
+	_, err = ParseToken(token)
+	assert.Error(t, err, "tokens signed with the previous key are refused")
+}
+
+// useSigningKey signs and verifies tokens with key until the end of the test.
+func useSigningKey(t *testing.T, key string) {
+	previous := signingKey
+	if err := SetSigningKey(key); err != nil {
+		t.Fatalf("failed to set signing key: %v", err)
+	}
+	t.Cleanup(func() { signingKey = previous })
 }
@@ -167,6 +167,7 @@ func TestCheckDate(t *testing.T) {
 		{"Valid Date", "2023-04-01", true},
 		{"Invalid Date", "2023/04/01", false},
 		{"Invalid Date", "20ab-cd-0e", false},
+		{"Out of Calendar Date", "2024-99-99", false},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IDs are 53-bit integers made of the milliseconds since IDEpoch, the node that generated them and a sequence
+// number, from the most to the least significant bits. They increase with time, are unique as long as every
+// instance sharing a database has its own node, and stay below 2^53 so that JSON clients parsing numbers as
+// doubles, such as JavaScript, read them exactly.
+const (
+	idNodeBits     = 5
+	idSequenceBits = 7
+	idTimeBits     = 41
+
+	// MaxIDNode is the highest node number an instance can generate IDs as.
+	MaxIDNode = 1<<idNodeBits - 1
+	// MaxIDSequence is the highest sequence number, so each node generates up to MaxIDSequence+1 IDs per millisecond.
+	MaxIDSequence = 1<<idSequenceBits - 1
+	// MaxID is the highest ID that can be generated.
+	MaxID = 1<<(idTimeBits+idNodeBits+idSequenceBits) - 1
+)
+
+// IDEpoch is the time encoded as zero in IDs. The 41 bits of milliseconds last until 2089.
+var IDEpoch = time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// IDGenerator generates time-ordered IDs for one node. It is safe for concurrent use.
+type IDGenerator struct {
+	mu       sync.Mutex
+	node     uint
+	last     int64
+	sequence uint
+	now      func() time.Time
+}
+
+// NewIDGenerator returns a generator of the IDs of node, which must not be above MaxIDNode.
+func NewIDGenerator(node uint) (*IDGenerator, error) {
+	if node > MaxIDNode {
+		return nil, fmt.Errorf("ID node %d is above the maximum of %d", node, MaxIDNode)
+	}
+	return &IDGenerator{node: node, now: time.Now}, nil
+}
+
+// Next returns a new ID, greater than every ID returned before.
+// Once the sequence of the current millisecond is used up, and while the clock is behind the last ID after being
+// set back, it keeps counting from the time of the last ID rather than waiting or repeating an ID.
+func (g *IDGenerator) Next() uint {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ms := g.now().UnixMilli() - IDEpoch.UnixMilli()
+	if ms > g.last {
+		g.last, g.sequence = ms, 0
+	} else if g.sequence < MaxIDSequence {
+		g.sequence++
+	} else {
+		g.last, g.sequence = g.last+1, 0
+	}
+	return composeID(g.last, g.node, g.sequence)
+}
+
+// ComposeID returns the ID generated by node at time t with the given sequence number, for IDs that must be
+// reproducible such as the ones of seeded data. t must not be before IDEpoch, and node and sequence are truncated
+// to their number of bits.
+func ComposeID(t time.Time, node, sequence uint) uint {
+	return composeID(t.UnixMilli()-IDEpoch.UnixMilli(), node, sequence)
+}
+
+// composeID packs the milliseconds since IDEpoch, the node and the sequence number into an ID.
+func composeID(ms int64, node, sequence uint) uint {
+	return uint(ms)<<(idNodeBits+idSequenceBits) | (node&MaxIDNode)<<idSequenceBits | sequence&MaxIDSequence
+}
+
+// IDTime returns the time an ID was generated at, to the millisecond.
+func IDTime(id uint) time.Time {
+	return IDEpoch.Add(time.Duration(id>>(idNodeBits+idSequenceBits)) * time.Millisecond).UTC()
+}
+
+// ids is the generator used by GenerateID, for node 0 until SetIDNode is called.
+var ids, _ = NewIDGenerator(0)
+
+// SetIDNode makes GenerateID generate the IDs of node. Instances sharing a database must use different nodes.
+func SetIDNode(node uint) error {
+	g, err := NewIDGenerator(node)
+	if err != nil {
+		return err
+	}
+	ids = g
+	return nil
+}
+
+// GenerateID returns a new unique ID for a database row.
+func GenerateID() uint {
+	return ids.Next()
+}
@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestGenerateID checks that concurrently generated IDs are unique, non-zero and below MaxID.
+func TestGenerateID(t *testing.T) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := map[uint]bool{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := GenerateID()
+				mu.Lock()
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 8000)
+	for id := range seen {
+		assert.NotZero(t, id)
+		assert.LessOrEqual(t, id, uint(MaxID))
+	}
+}
+
+// TestIDGenerator checks that IDs increase and encode their time and node, including when the sequence of a
+// millisecond is used up and when the clock is set back.
+func TestIDGenerator(t *testing.T) {
+	g, err := NewIDGenerator(3)
+	assert.NoError(t, err)
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	g.now = func() time.Time { return now }
+
+	first := g.Next()
+	assert.Equal(t, now, IDTime(first))
+	assert.Equal(t, ComposeID(now, 3, 0), first)
+
+	previous := first
+	for i := 0; i < MaxIDSequence+10; i++ {
+		id := g.Next()
+		assert.Greater(t, id, previous)
+		previous = id
+	}
+	assert.Equal(t, now.Add(time.Millisecond), IDTime(previous), "a used up sequence should move on to the next millisecond")
+
+	now = now.Add(-time.Hour)
+	assert.Greater(t, g.Next(), previous, "IDs should keep increasing when the clock is set back")
+
+	_, err = NewIDGenerator(MaxIDNode + 1)
+	assert.Error(t, err)
+	assert.Error(t, SetIDNode(MaxIDNode+1))
+}
@@ -5,29 +5,22 @@ import (
 	"testing"
 )
 
-// TestGenerateUUID by ensuring it returns a non-zero value.
-// This test checks if the GenerateUUID function returns a non-zero value.
-func TestGenerateUUID(t *testing.T) {
-	// Generate a random UUID
-	uuid := GenerateUUID()
-	assert.NotZero(t, uuid, "GenerateUUID should return a non zero uint32")
-}
-
-// TestConvertStringToUint tests the conversion of string to uint32.
-// It checks if the function can convert a string to a uint32 and returns the correct value.
+// TestConvertStringToUint tests the conversion of string to an ID.
+// It checks if the function can convert a string to a uint and returns the correct value.
 // It also checks if the function returns 0 when the conversion fails.
 func TestConvertStringToUint(t *testing.T) {
 	tests := []struct {
 		name        string
 		input       string
-		expected    uint32
+		expected    uint
 		shouldError bool
 	}{
 		{"Valid Number", "12345", 12345, false},
 		{"Zero Value", "0", 0, false},
 		{"Negative Number", "-123", 0, true},
 		{"Non numeric String", "abc123", 0, true},
-		{"Overflow Number", "42958766587452", 0, true}, // This value exceeds uint32 max value
+		{"Generated ID", "42958766587452", 42958766587452, false},
+		{"Overflow Number", "9007199254740992", 0, true}, // This value exceeds MaxID
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"testing"
+)
+
+// widget is a small model used to exercise the GORM plugin without depending on the application models.
+type widget struct {
+	ID   uint
+	Name string
+}
+
+// TestGormPlugin_ChildSpans tests that queries issued with a request context create child spans
+// carrying the sanitized statement and table name.
+func TestGormPlugin_ChildSpans(t *testing.T) {
+	recorder := setupTestProvider(t)
+
+	db, err := gorm.Open(sqlite.Open("file:tracing_plugin?mode=memory"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	assert.NoError(t, db.AutoMigrate(&widget{}))
+	assert.NoError(t, db.Use(&GormPlugin{}))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	db.WithContext(ctx).Create(&widget{Name: "speaker"})
+	var found []widget
+	db.WithContext(ctx).Where("name = ?", "speaker").Find(&found)
+	db.WithContext(ctx).Raw("SELECT * FROM widgets WHERE name = 'speaker' AND id > 10").Scan(&found)
+	parent.End()
+
+	var children []string
+	for _, span := range recorder.Ended() {
+		if span.Name() == "request" {
+			continue
+		}
+		children = append(children, span.Name())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		for _, attr := range span.Attributes() {
+			if attr.Key == semconv.DBStatementKey {
+				assert.NotContains(t, attr.Value.AsString(), "speaker", "Literals must not be exported")
+			}
+		}
+	}
+	assert.Equal(t, []string{"gorm.create", "gorm.query", "gorm.row"}, children)
+}
+
+// TestSanitizeSQL tests that string and numeric literals are replaced while placeholders and identifiers are kept.
+func TestSanitizeSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"String literal", "SELECT * FROM users WHERE username = 'bob'", "SELECT * FROM users WHERE username = ?"},
+		{"Escaped quote", "WHERE name LIKE 'O''Brien%'", "WHERE name LIKE ?"},
+		{"Numeric literal", "WHERE price > 19.99 AND id = 7", "WHERE price > ? AND id = ?"},
+		{"Placeholders kept", "WHERE id = $1 AND name = ?", "WHERE id = $1 AND name = ?"},
+		{"Identifiers kept", "SELECT table2.col1 FROM table2", "SELECT table2.col1 FROM table2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, SanitizeSQL(test.input))
+		})
+	}
+}
@@ -0,0 +1,519 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Values accepted by Status, PaymentMethod, Role, AttributeType and FilterOperator.
+var (
+	Statuses       = []string{"pending", "shipped", "delivered", "returned", "cancelled", "refunded", "processing", "completed"}
+	PaymentMethods = []string{"credit card", "debit card", "paypal", "cash", "check"}
+	Roles          = []string{"admin", "regular"}
+	AttributeTypes = []string{"number", "text", "boolean"}
+	// FilterOperators are the comparisons of filters on attribute values; the ranges only apply to numbers.
+	FilterOperators = []string{"eq", "ne", "gt", "gte", "lt", "lte"}
+)
+
+// ClockSkew is how far in the future Past accepts times, since the clocks of clients may be slightly ahead.
+const ClockSkew = time.Minute
+
+// Machine-readable codes of field errors, stable for clients to branch on.
+const (
+	CodeRequired      = "required"
+	CodeTooLong       = "too_long"
+	CodeOutOfRange    = "out_of_range"
+	CodeInvalidFormat = "invalid_format"
+	CodeNotAllowed    = "not_allowed"
+	CodeWeakPassword  = "weak_password"
+	CodeNotFound      = "not_found"
+	CodeInvalid       = "invalid"
+	CodeCycle         = "cycle"
+	CodeDuplicate     = "duplicate"
+)
+
+// FieldError is the validation failure of one field. Validators leave Field empty; it is set by Validator.Check.
+type FieldError struct {
+	Field   string `json:"-"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error returns the message, prefixed by the field when it is known.
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Failure is the error of a check that could not be made, such as a reference the database failed to look up,
+// rather than of an invalid value. Check keeps it apart from the field errors, and Err returns its cause instead
+// of them, so that it is answered as what it is rather than as a validation error.
+type Failure struct {
+	Err error
+}
+
+// Failed returns err as the *Failure of a check.
+func Failed(err error) error {
+	return &Failure{Err: err}
+}
+
+// Error returns the message of the cause.
+func (e *Failure) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the cause of the failure.
+func (e *Failure) Unwrap() error {
+	return e.Err
+}
+
+// Errors holds every field error of a record, in the order they were found.
+type Errors []*FieldError
+
+// Error joins the messages of all field errors.
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ByField groups the errors by field name.
+func (e Errors) ByField() map[string][]*FieldError {
+	fields := map[string][]*FieldError{}
+	for _, err := range e {
+		fields[err.Field] = append(fields[err.Field], err)
+	}
+	return fields
+}
+
+// Fields returns the sorted names of the invalid fields.
+func (e Errors) Fields() []string {
+	var fields []string
+	for field := range e.ByField() {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Validator collects the errors of every field of a record instead of stopping at the first one.
+// When created with a list of fields, as for a PATCH, the errors of the other fields are ignored.
+type Validator struct {
+	only    []string
+	errors  Errors
+	failure error
+}
+
+// New returns a validator of the given fields, or of every field when none are given.
+func New(only ...string) *Validator {
+	return &Validator{only: only}
+}
+
+// Check records err, as returned by a setter or validator, as an error of field. Nil errors are ignored.
+// The cause of the first *Failure is kept instead, to be returned by Err.
+func (v *Validator) Check(field string, err error) {
+	if err == nil || !v.wants(field) {
+		return
+	}
+	var failure *Failure
+	if errors.As(err, &failure) {
+		if v.failure == nil {
+			v.failure = failure.Err
+		}
+		return
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) {
+		fieldErr = &FieldError{Code: CodeInvalid, Message: err.Error()}
+	}
+	v.errors = append(v.errors, &FieldError{Field: field, Code: fieldErr.Code, Message: fieldErr.Message})
+}
+
+// Err returns the cause of the first failed check, otherwise the collected errors as Errors, or nil if every field
+// is valid.
+func (v *Validator) Err() error {
+	if v.failure != nil {
+		return v.failure
+	}
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return v.errors
+}
+
+// wants reports whether the errors of field are collected.
+func (v *Validator) wants(field string) bool {
+	if len(v.only) == 0 {
+		return true
+	}
+	for _, name := range v.only {
+		if name == field {
+			return true
+		}
+	}
+	return false
+}
+
+// String requires a non-empty string of at most maxLength bytes.
+func String(value string, maxLength int) error {
+	switch {
+	case value == "":
+		return &FieldError{Code: CodeRequired, Message: "must not be empty"}
+	case len(value) > maxLength:
+		return &FieldError{Code: CodeTooLong, Message: fmt.Sprintf("must be at most %d characters long", maxLength)}
+	}
+	return nil
+}
+
+// NonNegativeInt requires an integer of 0 or more.
+func NonNegativeInt(value int) error {
+	if value < 0 {
+		return &FieldError{Code: CodeOutOfRange, Message: "must not be negative"}
+	}
+	return nil
+}
+
+// InStock requires a quantity ordered of at most stock, the quantity in stock.
+func InStock(quantity, stock int) error {
+	if quantity > stock {
+		return &FieldError{Code: CodeOutOfRange, Message: fmt.Sprintf("must not exceed the %d in stock", stock)}
+	}
+	return nil
+}
+
+// NonNegativeFloat requires a number of 0 or more.
+func NonNegativeFloat(value float64) error {
+	if value < 0 {
+		return &FieldError{Code: CodeOutOfRange, Message: "must not be negative"}
+	}
+	return nil
+}
+
+// Rating requires a rating between 0 and 5.
+func Rating(value int) error {
+	if value < 0 || value > 5 {
+		return &FieldError{Code: CodeOutOfRange, Message: "must be between 0 and 5"}
+	}
+	return nil
+}
+
+// Email requires something that looks like an email address: it must contain an '@' and a '.'.
+func Email(value string) error {
+	if !strings.Contains(value, "@") || !strings.Contains(value, ".") {
+		return &FieldError{Code: CodeInvalidFormat, Message: "must be an email address"}
+	}
+	return nil
+}
+
+// Date requires a calendar date formatted as YYYY-MM-DD, ignoring surrounding spaces.
+func Date(value string) error {
+	if _, err := time.Parse(time.DateOnly, strings.TrimSpace(value)); err != nil {
+		return &FieldError{Code: CodeInvalidFormat, Message: "must be a date formatted as YYYY-MM-DD"}
+	}
+	return nil
+}
+
+// Time requires a time to be set.
+func Time(value time.Time) error {
+	if value.IsZero() {
+		return &FieldError{Code: CodeRequired, Message: "must be set"}
+	}
+	return nil
+}
+
+// Past requires a time that is set and not in the future, allowing for ClockSkew between clients and the server.
+func Past(value time.Time) error {
+	if err := Time(value); err != nil {
+		return err
+	}
+	if value.After(time.Now().Add(ClockSkew)) {
+		return &FieldError{Code: CodeOutOfRange, Message: "must not be in the future"}
+	}
+	return nil
+}
+
+// NotBefore requires a time that is not before limit, described by what in the message.
+func NotBefore(value, limit time.Time, what string) error {
+	if value.Before(limit) {
+		return &FieldError{Code: CodeOutOfRange, Message: "must not be before " + what}
+	}
+	return nil
+}
+
+// NotAfter requires a time that is not after limit, described by what in the message.
+func NotAfter(value, limit time.Time, what string) error {
+	if value.After(limit) {
+		return &FieldError{Code: CodeOutOfRange, Message: "must not be after " + what}
+	}
+	return nil
+}
+
+// Phone requires a phone number of at most maxLength digits.
+func Phone(value string, maxLength int) error {
+	if len(value) > maxLength {
+		return &FieldError{Code: CodeTooLong, Message: fmt.Sprintf("must be at most %d digits long", maxLength)}
+	}
+	for _, char := range value {
+		if !unicode.IsDigit(char) {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must only contain digits"}
+		}
+	}
+	return nil
+}
+
+// Password requires a password of at least 8 characters with a number, an uppercase and a lowercase letter.
+func Password(value string) error {
+	if len(value) < 8 || !strings.ContainsAny(value, "1234567890") ||
+		!strings.ContainsAny(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") ||
+		!strings.ContainsAny(value, "abcdefghijklmnopqrstuvwxyz") {
+		return &FieldError{Code: CodeWeakPassword, Message: "must be at least 8 characters long and contain an uppercase letter, a lowercase letter and a number"}
+	}
+	return nil
+}
+
+// Status requires one of Statuses.
+func Status(value string) error {
+	return oneOf(value, Statuses)
+}
+
+// PaymentMethod requires one of PaymentMethods, in any case.
+func PaymentMethod(value string) error {
+	return oneOf(strings.ToLower(value), PaymentMethods)
+}
+
+// Role requires one of Roles.
+func Role(value string) error {
+	return oneOf(value, Roles)
+}
+
+// Exists requires a reference to an existing record, described by what, e.g. "order".
+func Exists(found bool, what string) error {
+	if !found {
+		return &FieldError{Code: CodeNotFound, Message: "must reference an existing " + what}
+	}
+	return nil
+}
+
+// NotDescendant requires a parent that is neither the record itself, described by what, nor one of its descendants.
+func NotDescendant(descendant bool, what string) error {
+	if descendant {
+		return &FieldError{Code: CodeCycle, Message: "must not be the " + what + " itself or one of its descendants"}
+	}
+	return nil
+}
+
+// Depth requires a nesting depth of at most max levels.
+func Depth(depth, max int) error {
+	if depth > max {
+		return &FieldError{Code: CodeOutOfRange, Message: fmt.Sprintf("must not nest more than %d levels deep", max)}
+	}
+	return nil
+}
+
+// SKU requires a stock keeping unit of at most maxLength letters, digits, dashes, underscores and dots.
+func SKU(value string, maxLength int) error {
+	if err := String(value, maxLength); err != nil {
+		return err
+	}
+	for _, char := range value {
+		if !unicode.IsLetter(char) && !unicode.IsDigit(char) && !strings.ContainsRune("-_.", char) {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must only contain letters, digits, dashes, underscores and dots"}
+		}
+	}
+	return nil
+}
+
+// Options requires at least one option, each with a name and a value of at most maxLength bytes. When names is
+// not nil, the options must have exactly these names.
+func Options(options map[string]string, names []string, maxLength int) error {
+	if len(options) == 0 {
+		return &FieldError{Code: CodeRequired, Message: "must not be empty"}
+	}
+	for name, value := range options {
+		if name == "" || value == "" {
+			return &FieldError{Code: CodeRequired, Message: "must not have an empty name or value"}
+		}
+		if len(name) > maxLength || len(value) > maxLength {
+			return &FieldError{Code: CodeTooLong, Message: fmt.Sprintf("must have names and values of at most %d characters", maxLength)}
+		}
+	}
+	if names == nil {
+		return nil
+	}
+	same := len(options) == len(names)
+	for _, name := range names {
+		if _, found := options[name]; !found {
+			same = false
+		}
+	}
+	if !same {
+		return &FieldError{Code: CodeInvalid, Message: "must have the options " + strings.Join(names, ", ")}
+	}
+	return nil
+}
+
+// Distinct requires a value that no other record has, described by what, e.g. "variant of the product".
+func Distinct(duplicate bool, what string) error {
+	if duplicate {
+		return &FieldError{Code: CodeDuplicate, Message: "must differ from that of every other " + what}
+	}
+	return nil
+}
+
+// Selected requires a reference to be set when there are records to choose from, described by what, e.g. "variant".
+func Selected(selected, choices bool, what string) error {
+	if !selected && choices {
+		return &FieldError{Code: CodeRequired, Message: "must select a " + what}
+	}
+	return nil
+}
+
+// BelongsTo requires a reference to a record of the parent described by what, e.g. "ordered product".
+func BelongsTo(belongs bool, what string) error {
+	if !belongs {
+		return &FieldError{Code: CodeNotAllowed, Message: "must belong to the " + what}
+	}
+	return nil
+}
+
+// AttributeType requires one of AttributeTypes.
+func AttributeType(value string) error {
+	return oneOf(value, AttributeTypes)
+}
+
+// Identifier requires a name of at most maxLength lowercase letters, digits and underscores, starting with a letter,
+// that is none of reserved. Such names can be used as keys of query strings, e.g. ram_gb.
+func Identifier(value string, maxLength int, reserved []string) error {
+	if err := String(value, maxLength); err != nil {
+		return err
+	}
+	for i, char := range value {
+		letter, digit := char >= 'a' && char <= 'z', char >= '0' && char <= '9'
+		if !letter && (i == 0 || !digit && char != '_') {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must start with a lowercase letter and only contain lowercase letters, digits and underscores"}
+		}
+	}
+	for _, name := range reserved {
+		if value == name {
+			return &FieldError{Code: CodeNotAllowed, Message: "must not be one of " + strings.Join(reserved, ", ")}
+		}
+	}
+	return nil
+}
+
+// Optional requires a string of at most maxLength bytes, which may be empty.
+func Optional(value string, maxLength int) error {
+	if len(value) > maxLength {
+		return &FieldError{Code: CodeTooLong, Message: fmt.Sprintf("must be at most %d characters long", maxLength)}
+	}
+	return nil
+}
+
+// Unchanged requires a value that did not change, as it cannot while the condition described by while holds,
+// e.g. "products have values of the attribute".
+func Unchanged(changed bool, while string) error {
+	if changed {
+		return &FieldError{Code: CodeNotAllowed, Message: "must not change while " + while}
+	}
+	return nil
+}
+
+// SameAs requires a value equal to that of other records, described by what, e.g. "the type of the other ram_gb attributes".
+func SameAs(same bool, what string) error {
+	if !same {
+		return &FieldError{Code: CodeInvalid, Message: "must be the same as " + what}
+	}
+	return nil
+}
+
+// AttributeValue requires a value of the attribute type: a finite number, a non-empty string of at most maxLength bytes
+// for text, or a boolean.
+func AttributeValue(value interface{}, attributeType string, maxLength int) error {
+	switch attributeType {
+	case "number":
+		number, ok := value.(float64)
+		if !ok {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must be a number"}
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must be a finite number"}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must be true or false"}
+		}
+	default:
+		text, ok := value.(string)
+		if !ok {
+			return &FieldError{Code: CodeInvalidFormat, Message: "must be a string"}
+		}
+		return String(text, maxLength)
+	}
+	return nil
+}
+
+// FilterOperator requires one of FilterOperators that applies to values of the attribute type.
+func FilterOperator(operator, attributeType string) error {
+	if err := oneOf(operator, FilterOperators); err != nil {
+		return err
+	}
+	if attributeType != "number" && operator != "eq" && operator != "ne" {
+		return &FieldError{Code: CodeNotAllowed, Message: "must be eq or ne for a " + attributeType + " attribute"}
+	}
+	return nil
+}
+
+// Repeated requires a query parameter that is not repeated, when it cannot be.
+func Repeated(repeated bool) error {
+	if repeated {
+		return &FieldError{Code: CodeInvalid, Message: "must not be repeated"}
+	}
+	return nil
+}
+
+// Kept requires a flag that is not cleared, as it only moves to another record by the action described by how,
+// e.g. "making another image primary".
+func Kept(cleared bool, how string) error {
+	if cleared {
+		return &FieldError{Code: CodeNotAllowed, Message: "cannot be cleared, only moved by " + how}
+	}
+	return nil
+}
+
+// Arrangement requires a list of the IDs of every record described by what exactly once, in any order.
+func Arrangement(ids, current []uint, what string) error {
+	listed := map[uint]bool{}
+	for _, id := range ids {
+		listed[id] = true
+	}
+	complete := len(ids) == len(current) && len(listed) == len(current)
+	for _, id := range current {
+		complete = complete && listed[id]
+	}
+	if !complete {
+		return &FieldError{Code: CodeInvalid, Message: "must list every " + what + " exactly once"}
+	}
+	return nil
+}
+
+// oneOf requires value to be one of allowed.
+func oneOf(value string, allowed []string) error {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return nil
+		}
+	}
+	if value == "" {
+		return &FieldError{Code: CodeRequired, Message: "must not be empty"}
+	}
+	return &FieldError{Code: CodeNotAllowed, Message: "must be one of " + strings.Join(allowed, ", ")}
+}
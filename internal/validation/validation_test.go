@@ -0,0 +1,149 @@
+package validation
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+// code returns the code of a field error, or "" for nil.
+func code(err error) string {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Code
+	}
+	return ""
+}
+
+// TestValidators testing the error code returned by each validator for valid and invalid values.
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"Valid string", String("Hello", 10), ""},
+		{"Empty string", String("", 10), CodeRequired},
+		{"Too long string", String("Hello Hello!", 10), CodeTooLong},
+		{"Negative int", NonNegativeInt(-1), CodeOutOfRange},
+		{"Quantity in stock", InStock(3, 3), ""},
+		{"Quantity above stock", InStock(4, 3), CodeOutOfRange},
+		{"Zero float", NonNegativeFloat(0), ""},
+		{"Negative float", NonNegativeFloat(-0.5), CodeOutOfRange},
+		{"Rating above 5", Rating(6), CodeOutOfRange},
+		{"Invalid email", Email("not-an-email"), CodeInvalidFormat},
+		{"Invalid date", Date("21-04-2021"), CodeInvalidFormat},
+		{"Date out of calendar", Date("2024-99-99"), CodeInvalidFormat},
+		{"Valid date", Date(" 2024-02-29 "), ""},
+		{"Unset time", Time(time.Time{}), CodeRequired},
+		{"Past time", Past(time.Now().Add(-time.Hour)), ""},
+		{"Future time", Past(time.Now().Add(time.Hour)), CodeOutOfRange},
+		{"Time before limit", NotBefore(time.Now(), time.Now().Add(time.Hour), "the shipping date"), CodeOutOfRange},
+		{"Time after limit", NotAfter(time.Now().Add(time.Hour), time.Now(), "the estimated arrival"), CodeOutOfRange},
+		{"Letters in phone", Phone("12a", 11), CodeInvalidFormat},
+		{"Too long phone", Phone("123456789012", 11), CodeTooLong},
+		{"Weak password", Password("password"), CodeWeakPassword},
+		{"Valid status", Status("pending"), ""},
+		{"Unknown status", Status("lost"), CodeNotAllowed},
+		{"Empty status", Status(""), CodeRequired},
+		{"Payment method in upper case", PaymentMethod("PayPal"), ""},
+		{"Unknown role", Role("root"), CodeNotAllowed},
+		{"Missing reference", Exists(false, "order"), CodeNotFound},
+		{"Parent below the record", NotDescendant(true, "category"), CodeCycle},
+		{"Other parent", NotDescendant(false, "category"), ""},
+		{"Deepest level", Depth(100, 100), ""},
+		{"Too deep", Depth(101, 100), CodeOutOfRange},
+		{"Valid SKU", SKU("PHN-256.BLK_1", 64), ""},
+		{"SKU with spaces", SKU("PHN 256", 64), CodeInvalidFormat},
+		{"Empty SKU", SKU("", 64), CodeRequired},
+		{"Valid options", Options(map[string]string{"color": "black"}, nil, 64), ""},
+		{"No options", Options(map[string]string{}, nil, 64), CodeRequired},
+		{"Option without value", Options(map[string]string{"color": ""}, nil, 64), CodeRequired},
+		{"Options of the other variants", Options(map[string]string{"storage": "256GB", "color": "black"}, []string{"color", "storage"}, 64), ""},
+		{"Other options", Options(map[string]string{"color": "black"}, []string{"color", "storage"}, 64), CodeInvalid},
+		{"Duplicate", Distinct(true, "variant of the product"), CodeDuplicate},
+		{"No variant to select", Selected(false, false, "variant"), ""},
+		{"Missing variant", Selected(false, true, "variant"), CodeRequired},
+		{"Foreign variant", BelongsTo(false, "ordered product"), CodeNotAllowed},
+		{"Attribute type", AttributeType("boolean"), ""},
+		{"Unknown attribute type", AttributeType("date"), CodeNotAllowed},
+		{"Valid identifier", Identifier("ram_gb2", 64, nil), ""},
+		{"Identifier with a leading digit", Identifier("4k", 64, nil), CodeInvalidFormat},
+		{"Identifier in upper case", Identifier("Ram", 64, nil), CodeInvalidFormat},
+		{"Reserved identifier", Identifier("include", 64, []string{"include", "trashed"}), CodeNotAllowed},
+		{"Empty optional string", Optional("", 32), ""},
+		{"Too long optional string", Optional("kilowatt-hours", 4), CodeTooLong},
+		{"Changed while locked", Unchanged(true, "products have values of the attribute"), CodeNotAllowed},
+		{"Different from the others", SameAs(false, "the type of the other ram_gb attributes"), CodeInvalid},
+		{"Number value", AttributeValue(16.0, "number", 255), ""},
+		{"Text for a number", AttributeValue("16", "number", 255), CodeInvalidFormat},
+		{"Infinite number", AttributeValue(math.Inf(1), "number", 255), CodeInvalidFormat},
+		{"NaN", AttributeValue(math.NaN(), "number", 255), CodeInvalidFormat},
+		{"Number for a boolean", AttributeValue(1.0, "boolean", 255), CodeInvalidFormat},
+		{"Empty text", AttributeValue("", "text", 255), CodeRequired},
+		{"Range of numbers", FilterOperator("gte", "number"), ""},
+		{"Range of text", FilterOperator("lt", "text"), CodeNotAllowed},
+		{"Unknown operator", FilterOperator("like", "text"), CodeNotAllowed},
+		{"Repeated parameter", Repeated(true), CodeInvalid},
+		{"Cleared flag", Kept(true, "making another image primary"), CodeNotAllowed},
+		{"Complete arrangement", Arrangement([]uint{3, 1, 2}, []uint{1, 2, 3}, "image"), ""},
+		{"Missing from arrangement", Arrangement([]uint{3, 1}, []uint{1, 2, 3}, "image"), CodeInvalid},
+		{"Repeated in arrangement", Arrangement([]uint{3, 1, 1}, []uint{1, 2, 3}, "image"), CodeInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, code(test.err))
+		})
+	}
+}
+
+// TestValidator_Check checks that the validator collects the errors of every field in order,
+// ignores nil errors and wraps errors that are not field errors.
+func TestValidator_Check(t *testing.T) {
+	v := New()
+	v.Check("name", String("", 255))
+	v.Check("description", nil)
+	v.Check("price", NonNegativeFloat(-1))
+	v.Check("brand_id", errors.New("lookup failed"))
+
+	var errs Errors
+	if !assert.ErrorAs(t, v.Err(), &errs) {
+		return
+	}
+	assert.Equal(t, []string{"brand_id", "name", "price"}, errs.Fields())
+	assert.Equal(t, CodeRequired, errs[0].Code)
+	assert.Equal(t, CodeInvalid, errs[2].Code)
+	assert.Equal(t, "name: must not be empty; price: must not be negative; brand_id: lookup failed", errs.Error())
+}
+
+// TestValidator_Failure checks that a failed check is returned instead of the field errors, rather than
+// being reported as an invalid field.
+func TestValidator_Failure(t *testing.T) {
+	lost := errors.New("connection lost")
+	v := New()
+	v.Check("name", String("", 255))
+	v.Check("brand_id", Failed(lost))
+	v.Check("category_id", Failed(errors.New("timeout")))
+	assert.Same(t, lost, v.Err())
+
+	v = New("name")
+	v.Check("brand_id", Failed(lost))
+	assert.NoError(t, v.Err(), "fields that are not validated are ignored")
+}
+
+// TestValidator_Only checks that a validator of some fields ignores the errors of the others,
+// and returns no error when none of its fields are invalid.
+func TestValidator_Only(t *testing.T) {
+	v := New("description")
+	v.Check("name", String("", 255))
+	assert.NoError(t, v.Err())
+
+	v.Check("description", String("", 1000))
+	var errs Errors
+	if assert.ErrorAs(t, v.Err(), &errs) {
+		assert.Equal(t, []string{"description"}, errs.Fields())
+	}
+}